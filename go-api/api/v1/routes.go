@@ -0,0 +1,57 @@
+// Package v1 - маршруты первой версии API. Несовместимые изменения
+// оформляются отдельным пакетом (api/v2) со своим префиксом
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/gorilla/mux"
+)
+
+// Version - значение заголовка X-API-Version для маршрутов пакета
+const Version = "v1"
+
+// Таймаут маршрутов заказов
+const ordersRouteTimeout = 5 * time.Second
+
+// Options - секреты защищенных маршрутов версии
+type Options struct {
+	// Секрет HS256 для JWT, пустой отключает проверку
+	JWTSecret string
+
+	// API ключи, принимаются вместо JWT, nil отключает их
+	APIKeys middleware.APIKeyStore
+
+	// Токен для сброса кэшей, пустой закрывает доступ
+	AdminToken string
+}
+
+// RegisterRoutes регистрирует маршруты v1 на подроутере s, например
+// r.PathPrefix("/v1").Subrouter(). Все ответы получают X-API-Version
+func RegisterRoutes(s *mux.Router, h *handlers.Handler, opts Options) {
+	s.Use(middleware.APIVersion(Version))
+
+	// Эндпоинты, требующие JWT или API ключ, заказы дополнительно
+	// ограничены по времени
+	auth := middleware.Named("auth", middleware.APIKeyOr(opts.APIKeys, middleware.JWTAuth(opts.JWTSecret)))
+	orders := middleware.Chain(auth, middleware.Named("orders_timeout", middleware.Timeout(ordersRouteTimeout)))
+	admin := middleware.Named("admin_token", middleware.AdminToken(opts.AdminToken))
+
+	s.Handle("/users", auth(http.HandlerFunc(h.UsersHandler))).Methods("GET")
+	s.Handle("/users", auth(http.HandlerFunc(h.CreateUserHandler))).Methods("POST")
+	s.Handle("/users/bulk", auth(http.HandlerFunc(h.BulkCreateUsersHandler))).Methods("POST")
+	s.Handle("/users/{id}", auth(http.HandlerFunc(h.GetUserHandler))).Methods("GET")
+	s.Handle("/users/{id}", auth(http.HandlerFunc(h.DeleteUserHandler))).Methods("DELETE")
+	s.Handle("/orders", orders(http.HandlerFunc(h.ListOrdersHandler))).Methods("GET")
+	s.Handle("/orders", orders(http.HandlerFunc(h.OrdersHandler))).Methods("POST")
+	s.Handle("/orders/{id}", orders(http.HandlerFunc(h.GetOrderHandler))).Methods("GET")
+	s.Handle("/orders/{id}/status", orders(http.HandlerFunc(h.UpdateOrderStatusHandler))).Methods("PUT")
+	s.Handle("/products", auth(http.HandlerFunc(h.ProductsHandler))).Methods("GET")
+	s.Handle("/products/{id}/stock", auth(http.HandlerFunc(h.UpdateProductStockHandler))).Methods("PUT")
+
+	// Сброс кэшей API доступен только с токеном администратора
+	s.Handle("/cache/flush", admin(http.HandlerFunc(h.CacheFlushHandler))).Methods("PATCH")
+}
@@ -0,0 +1,162 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	v1 "github.com/crazy1997/go-api/api/v1"
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/router"
+	"github.com/crazy1997/go-api/testutil"
+)
+
+// noFaults отключает имитацию сбоев обработчиков
+type noFaults struct{}
+
+func (noFaults) Intn(n int) int { return n - 1 }
+
+func TestRoutes_VersionedAndUnversioned(t *testing.T) {
+	srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{
+		Router: router.Options{
+			Handler:           handlers.New(handlers.Config{Logger: logging.NoopLogger{}, Rand: noFaults{}}),
+			AdminAllowedCIDRs: []string{"127.0.0.0/8"},
+			APIKeys:           middleware.NewMemoryAPIKeyStore(map[string]string{"key-routes-0001": "routes"}),
+		},
+	})
+
+	tests := []struct {
+		name        string
+		path        string
+		wantStatus  int
+		wantVersion string
+		wantLabel   string
+	}{
+		{name: "versioned users", path: "/v1/users", wantStatus: http.StatusOK, wantVersion: v1.Version, wantLabel: "/v1/users"},
+		{name: "versioned user", path: "/v1/users/1", wantStatus: http.StatusOK, wantVersion: v1.Version, wantLabel: "/v1/users/{id}"},
+		{name: "versioned products", path: "/v1/products", wantStatus: http.StatusOK, wantVersion: v1.Version, wantLabel: "/v1/products"},
+		{name: "legacy users", path: "/api/users", wantStatus: http.StatusOK, wantVersion: v1.Version, wantLabel: "/api/users"},
+		{name: "unversioned health", path: "/api/health", wantStatus: http.StatusOK, wantLabel: "/api/health"},
+		{name: "unversioned metrics", path: "/metrics", wantStatus: http.StatusOK},
+		{name: "health is not versioned", path: "/v1/health", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestsBefore := testutil.MetricValue(t, "http_requests_total", map[string]string{"path": tt.wantLabel})
+
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+tt.path, nil)
+			req.Header.Set(middleware.APIKeyHeader, "key-routes-0001")
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("GET %s: %v", tt.path, err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get(middleware.APIVersionHeader); got != tt.wantVersion {
+				t.Errorf("%s = %q, want %q", middleware.APIVersionHeader, got, tt.wantVersion)
+			}
+			if tt.wantLabel == "" {
+				return
+			}
+
+			if got := testutil.MetricValue(t, "http_requests_total", map[string]string{"path": tt.wantLabel}) - requestsBefore; got != 1 {
+				t.Errorf("http_requests_total{path=%q} increased by %v, want 1", tt.wantLabel, got)
+			}
+
+			// В ELK попадает фактический путь запроса
+			logged := false
+			for _, entry := range srv.Logger().Entries() {
+				if entry.Message == "HTTP request" && entry.Fields["path"] == tt.path {
+					logged = true
+				}
+			}
+			if !logged {
+				t.Errorf("no access log entry with path %s", tt.path)
+			}
+		})
+	}
+}
+
+// product_stock_level обновляется после каждого изменения остатка
+func TestRoutes_ProductStockGauge(t *testing.T) {
+	srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{
+		Router: router.Options{
+			Handler: handlers.New(handlers.Config{Logger: logging.NoopLogger{}, Rand: noFaults{}}),
+			APIKeys: middleware.NewMemoryAPIKeyStore(map[string]string{"key-inventory-0001": "inventory"}),
+		},
+	})
+
+	putStock := func(delta string) (int, handlers.Product) {
+		req, _ := http.NewRequest(http.MethodPut, srv.URL+"/v1/products/1/stock", strings.NewReader(`{"delta": `+delta+`}`))
+		req.Header.Set(middleware.APIKeyHeader, "key-inventory-0001")
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatalf("PUT stock: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var product handlers.Product
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&product); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+		}
+		return resp.StatusCode, product
+	}
+	gauge := func() float64 {
+		return testutil.MetricValue(t, "product_stock_level", map[string]string{"product_id": "1"})
+	}
+
+	// Остаток каталога по умолчанию заранее неизвестен, отсчитываем от первого ответа
+	code, product := putStock("100")
+	if code != http.StatusOK {
+		t.Fatalf("restock status = %d, want 200", code)
+	}
+	level := product.StockLevel
+	if got := gauge(); got != float64(level) {
+		t.Fatalf("product_stock_level = %v, want %d", got, level)
+	}
+
+	tests := []struct {
+		name     string
+		delta    int
+		sellAll  bool
+		wantCode int
+	}{
+		{name: "sell", delta: -30, wantCode: http.StatusOK},
+		{name: "restock", delta: 7, wantCode: http.StatusOK},
+		{name: "over-depletion", delta: -1000000, wantCode: http.StatusConflict},
+		{name: "sell the rest", sellAll: true, wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta := tt.delta
+			if tt.sellAll {
+				delta = -level
+			}
+
+			code, product := putStock(strconv.Itoa(delta))
+			if code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", code, tt.wantCode)
+			}
+			if code == http.StatusOK {
+				if product.StockLevel != level+delta {
+					t.Errorf("stock_level = %d, want %d", product.StockLevel, level+delta)
+				}
+				level = product.StockLevel
+			}
+			if got := gauge(); got != float64(level) {
+				t.Errorf("product_stock_level = %v, want %d", got, level)
+			}
+		})
+	}
+}
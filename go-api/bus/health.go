@@ -0,0 +1,83 @@
+package bus
+
+import (
+	"sync"
+	"time"
+)
+
+// Статусы компонентов
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// HealthEvent описывает изменение состояния компонента
+type HealthEvent struct {
+	Component string    `json:"component"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// HealthBus рассылает события о состоянии компонентов подписчикам
+type HealthBus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]chan<- HealthEvent
+	last        map[string]HealthEvent
+}
+
+// Default - общая шина приложения
+var Default = New()
+
+func New() *HealthBus {
+	return &HealthBus{
+		subscribers: make(map[string][]chan<- HealthEvent),
+		last:        make(map[string]HealthEvent),
+	}
+}
+
+// Subscribe подписывает канал на события компонента.
+// Если событие уже публиковалось, последнее сразу отправляется в канал
+func (b *HealthBus) Subscribe(component string, ch chan<- HealthEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers[component] = append(b.subscribers[component], ch)
+
+	if event, ok := b.last[component]; ok {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Publish рассылает событие подписчикам. Медленные подписчики
+// не блокируют публикацию: событие для них пропускается
+func (b *HealthBus) Publish(event HealthEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.Lock()
+	b.last[event.Component] = event
+	subscribers := b.subscribers[event.Component]
+	b.mu.Unlock()
+
+	for _, ch := range subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe подписывает канал на события в общей шине
+func Subscribe(component string, ch chan<- HealthEvent) {
+	Default.Subscribe(component, ch)
+}
+
+// Publish публикует событие в общую шину
+func Publish(event HealthEvent) {
+	Default.Publish(event)
+}
@@ -0,0 +1,74 @@
+package bus
+
+import (
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, ch <-chan HealthEvent) HealthEvent {
+	t.Helper()
+	select {
+	case event := <-ch:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return HealthEvent{}
+	}
+}
+
+func TestHealthBus_PublishToComponentSubscribers(t *testing.T) {
+	b := New()
+	logstash := make(chan HealthEvent, 1)
+	metrics := make(chan HealthEvent, 1)
+	b.Subscribe("logstash", logstash)
+	b.Subscribe("metrics", metrics)
+
+	b.Publish(HealthEvent{Component: "logstash", Status: StatusDown, Error: "connection refused"})
+
+	event := receive(t, logstash)
+	if event.Status != StatusDown || event.Error != "connection refused" || event.Timestamp.IsZero() {
+		t.Errorf("event = %+v, want logstash down with a timestamp", event)
+	}
+	select {
+	case event := <-metrics:
+		t.Errorf("metrics subscriber received %+v", event)
+	default:
+	}
+}
+
+func TestHealthBus_SubscribeReplaysLastEvent(t *testing.T) {
+	b := New()
+	b.Publish(HealthEvent{Component: "logstash", Status: StatusDown})
+	b.Publish(HealthEvent{Component: "logstash", Status: StatusUp})
+
+	ch := make(chan HealthEvent, 1)
+	b.Subscribe("logstash", ch)
+
+	if event := receive(t, ch); event.Status != StatusUp {
+		t.Errorf("replayed status = %q, want the last published %q", event.Status, StatusUp)
+	}
+}
+
+func TestHealthBus_SlowSubscriberDoesNotBlock(t *testing.T) {
+	b := New()
+	slow := make(chan HealthEvent)
+	fast := make(chan HealthEvent, 2)
+	b.Subscribe("logstash", slow)
+	b.Subscribe("logstash", fast)
+
+	done := make(chan struct{})
+	go func() {
+		b.Publish(HealthEvent{Component: "logstash", Status: StatusDown})
+		b.Publish(HealthEvent{Component: "logstash", Status: StatusUp})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a subscriber that does not read")
+	}
+	if first, second := receive(t, fast), receive(t, fast); first.Status != StatusDown || second.Status != StatusUp {
+		t.Errorf("fast subscriber got %q then %q, want down then up", first.Status, second.Status)
+	}
+}
@@ -0,0 +1,433 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config - вся конфигурация приложения из переменных окружения.
+// Пакеты получают нужные значения от main, а не читают окружение сами
+type Config struct {
+	// Сервис
+	Port           string
+	Environment    string
+	ServerIP       string
+	ServiceVersion string
+
+	// Логи
+	LogstashURL string
+
+	// Тонкая настройка логгера из LOG_*, см. logging.InitLogger.
+	// Значения по умолчанию задает Load
+	LogLevel              string
+	LogComponentLevels    map[string]string
+	LogSampleRates        map[string]float64
+	LogFieldPrefix        string
+	LogMaskFields         []string
+	LogConsoleFormat      string
+	LogECSMode            bool
+	LogTimestampPrecision string
+	LogMaxEntryBytes      int
+	LogSigningKey         string
+	LogComplianceFile     string
+	LogTransport          string
+	LogstashAddr          string
+	LogWorkerCount        int
+	LogQueueSize          int
+	LogQueueBlock         bool
+	LogMaxRetries         int
+	LogBatchSize          int
+	LogBatchInterval      time.Duration
+	LogCircuitFailures    int
+	LogCircuitCooldown    time.Duration
+	LogDisableKeepAlives  bool
+	LogMaxConnLifetime    time.Duration
+	LogAuthHeader         string
+	LogAuthToken          string
+	LogClientCertFile     string
+	LogClientKeyFile      string
+	LogFallbackFile       string
+	LogFallbackMaxMB      int
+	LogProbeInterval      time.Duration
+
+	// Трассировка, пустой OTELEndpoint отключает ее
+	OTELEndpoint    string
+	OTELServiceName string
+
+	// Отправка ошибок в Sentry, пустой DSN отключает ее
+	SentryDSN string
+
+	// Метрики
+	MetricsNamespace      string
+	MetricsSubsystem      string
+	MetricsMaxPaths       int
+	MetricsGCPollInterval time.Duration
+	SLOTarget             float64
+	BurnRateThreshold     float64
+	ProductViewsTTL       time.Duration
+
+	// HTTP сервер
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+
+	// TLS
+	TLSCertFile      string
+	TLSKeyFile       string
+	HTTPRedirectPort string
+	ACMEDomains      []string
+	ACMECacheDir     string
+	ACMEEmail        string
+
+	// Запуск: сколько ждать Logstash, прежде чем считать сервис запущенным
+	StartupTimeout time.Duration
+
+	// Остановка
+	ShutdownTimeout time.Duration
+	ShutdownGrace   time.Duration
+
+	// Роутер и middleware
+	AdminToken         string
+	AdminAllowedCIDRs  []string
+	TrustedProxyCIDRs  []string
+	JWTSecret          string
+	APIKeys            map[string]string
+	CORSAllowedOrigins []string
+	MirrorURL          string
+	MirrorPercentage   float64
+	SlowRequestDetail  bool
+	MaxBodyBytes       int64
+	RateLimitRPS       float64
+	RateLimitBurst     int
+	MaxActiveRequests  int
+	PushManifestPath   string
+	OpenAPISpecPath    string
+
+	// Обработчики
+	UserCacheTTL      time.Duration
+	LowStockThreshold int
+	CursorSecret      string
+}
+
+// Load читает конфигурацию из окружения и применяет значения по умолчанию.
+// Возвращает все ошибки валидации разом, чтобы их можно было исправить за раз
+func Load() (*Config, error) {
+	e := &env{}
+
+	cfg := &Config{
+		Port:           e.string("PORT", "8080"),
+		Environment:    e.string("ENVIRONMENT", "production"),
+		ServerIP:       e.string("SERVER_IP", "147.45.183.143"),
+		ServiceVersion: e.string("SERVICE_VERSION", "1.0.0"),
+
+		// По умолчанию сервис logstash в Docker сети, LOGSTASH_URL позволяет
+		// направить логи на другой адрес (например, mock в тестах)
+		LogstashURL: e.string("LOGSTASH_URL", "http://logstash:5000"),
+
+		LogLevel:              strings.ToUpper(os.Getenv("LOG_LEVEL")),
+		LogFieldPrefix:        os.Getenv("LOG_FIELD_PREFIX"),
+		LogConsoleFormat:      e.string("LOG_CONSOLE_FORMAT", "text"),
+		LogECSMode:            e.bool("LOG_ECS_MODE", false),
+		LogTimestampPrecision: e.string("LOG_TIMESTAMP_PRECISION", "ms"),
+		LogMaxEntryBytes:      e.int("LOG_MAX_ENTRY_BYTES", 64*1024, 0, math.MaxInt32),
+		LogSigningKey:         os.Getenv("LOG_SIGNING_KEY"),
+		LogComplianceFile:     os.Getenv("LOG_COMPLIANCE_FILE"),
+		LogTransport:          e.string("LOG_TRANSPORT", "http"),
+		LogstashAddr:          e.string("LOGSTASH_ADDR", "logstash:5001"),
+		LogWorkerCount:        e.int("LOG_WORKER_COUNT", 4, 1, math.MaxInt16),
+		LogQueueSize:          e.int("LOG_QUEUE_SIZE", 1000, 1, math.MaxInt32),
+		LogQueueBlock:         e.bool("LOG_QUEUE_BLOCK", false),
+		LogMaxRetries:         e.int("LOG_MAX_RETRIES", 3, 0, 100),
+		LogBatchSize:          e.int("LOG_BATCH_SIZE", 0, 0, math.MaxInt32),
+		LogBatchInterval:      time.Duration(e.int("LOG_BATCH_INTERVAL", 0, 0, math.MaxInt32)) * time.Millisecond,
+		LogCircuitFailures:    e.int("LOG_CIRCUIT_FAILURES", 5, 0, math.MaxInt32),
+		LogCircuitCooldown:    e.duration("LOG_CIRCUIT_COOLDOWN", 30*time.Second),
+		LogDisableKeepAlives:  e.bool("LOG_DISABLE_KEEPALIVES", false),
+		LogMaxConnLifetime:    e.duration("LOG_MAX_CONN_LIFETIME", 0),
+		LogAuthHeader:         os.Getenv("LOG_AUTH_HEADER"),
+		LogAuthToken:          os.Getenv("LOG_AUTH_TOKEN"),
+		LogClientCertFile:     os.Getenv("LOG_CLIENT_CERT_FILE"),
+		LogClientKeyFile:      os.Getenv("LOG_CLIENT_KEY_FILE"),
+		LogFallbackFile:       e.string("LOG_FALLBACK_FILE", "/tmp/app-fallback.log"),
+		LogFallbackMaxMB:      e.int("LOG_FALLBACK_MAX_MB", 10, 1, math.MaxInt16),
+		LogProbeInterval:      e.duration("LOG_PROBE_INTERVAL", 15*time.Second),
+
+		OTELEndpoint:    os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		OTELServiceName: e.string("OTEL_SERVICE_NAME", "go-api"),
+
+		SentryDSN: os.Getenv("SENTRY_DSN"),
+
+		MetricsNamespace:      e.string("METRICS_NAMESPACE", "goapi"),
+		MetricsSubsystem:      e.string("METRICS_SUBSYSTEM", "http"),
+		MetricsMaxPaths:       e.int("METRICS_MAX_PATHS", 100, 1, math.MaxInt32),
+		MetricsGCPollInterval: e.duration("METRICS_GC_POLL_INTERVAL", 5*time.Second),
+		SLOTarget:             e.float("SLO_TARGET", 0.999, 0.5, 0.99999),
+		BurnRateThreshold:     e.float("SLO_BURN_RATE_THRESHOLD", 14.4, 0.1, 1000),
+		ProductViewsTTL:       e.duration("PRODUCT_VIEWS_TTL", 24*time.Hour),
+
+		ReadTimeout:       e.seconds("HTTP_READ_TIMEOUT_SECONDS", 15*time.Second),
+		ReadHeaderTimeout: e.seconds("HTTP_READ_HEADER_TIMEOUT_SECONDS", 0),
+		WriteTimeout:      e.seconds("HTTP_WRITE_TIMEOUT_SECONDS", 15*time.Second),
+		IdleTimeout:       e.seconds("HTTP_IDLE_TIMEOUT_SECONDS", 60*time.Second),
+		MaxHeaderBytes:    e.int("HTTP_MAX_HEADER_BYTES", http.DefaultMaxHeaderBytes, 1, math.MaxInt32),
+
+		TLSCertFile:      e.string("TLS_CERT_FILE", ""),
+		TLSKeyFile:       e.string("TLS_KEY_FILE", ""),
+		HTTPRedirectPort: e.string("HTTP_REDIRECT_PORT", "80"),
+		ACMEDomains:      strings.Fields(os.Getenv("ACME_DOMAINS")),
+		ACMECacheDir:     e.string("ACME_CACHE_DIR", "acme-cache"),
+		ACMEEmail:        os.Getenv("ACME_EMAIL"),
+
+		StartupTimeout:  e.seconds("STARTUP_TIMEOUT_SECONDS", 30*time.Second),
+		ShutdownTimeout: e.seconds("SHUTDOWN_TIMEOUT_SECONDS", 10*time.Second),
+		ShutdownGrace:   e.seconds("SHUTDOWN_GRACE_SECONDS", 0),
+
+		AdminToken:        os.Getenv("ADMIN_TOKEN"),
+		JWTSecret:         os.Getenv("JWT_SECRET"),
+		MirrorURL:         os.Getenv("MIRROR_URL"),
+		MirrorPercentage:  e.float("MIRROR_PERCENTAGE", 1.0, 0, 1),
+		MaxBodyBytes:      int64(e.int("MAX_BODY_BYTES", 1<<20, 0, math.MaxInt)),
+		RateLimitRPS:      e.float("RATE_LIMIT_RPS", 50, 0, math.MaxFloat64),
+		RateLimitBurst:    e.int("RATE_LIMIT_BURST", 100, 0, math.MaxInt32),
+		MaxActiveRequests: e.int("MAX_ACTIVE_REQUESTS", 0, 0, math.MaxInt32),
+		PushManifestPath:  e.string("PUSH_MANIFEST_PATH", "./static/manifest.json"),
+		OpenAPISpecPath:   e.string("OPENAPI_SPEC_PATH", "openapi.json"),
+
+		UserCacheTTL:      e.seconds("USER_CACHE_TTL_SECONDS", 30*time.Second),
+		LowStockThreshold: e.int("LOW_STOCK_THRESHOLD", 10, 0, math.MaxInt32),
+		CursorSecret:      os.Getenv("CURSOR_SECRET"),
+	}
+
+	// API ключи интеграций в формате key1:name1,key2:name2
+	if raw := os.Getenv("API_KEYS"); raw != "" {
+		cfg.APIKeys = make(map[string]string)
+		for i, pair := range strings.Split(raw, ",") {
+			key, name, ok := strings.Cut(strings.TrimSpace(pair), ":")
+			if !ok || key == "" || name == "" {
+				// Сама запись не выводится: в ней секретный ключ
+				e.fail(fmt.Errorf("API_KEYS: invalid entry #%d, expected key:name", i+1))
+				continue
+			}
+			cfg.APIKeys[key] = name
+		}
+	}
+
+	// Уровни компонентов в формате db=DEBUG,handlers=WARN
+	if raw := os.Getenv("LOG_COMPONENT_LEVELS"); raw != "" {
+		cfg.LogComponentLevels = make(map[string]string)
+		for _, pair := range strings.Split(raw, ",") {
+			component, level, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || component == "" || !logLevels[strings.ToUpper(level)] {
+				e.fail(fmt.Errorf("LOG_COMPONENT_LEVELS: invalid entry %q, expected component=LEVEL", pair))
+				continue
+			}
+			cfg.LogComponentLevels[component] = strings.ToUpper(level)
+		}
+	}
+
+	// Доли сэмплирования по уровням, например LOG_SAMPLE_INFO=0.1
+	for level := range logLevels {
+		name := "LOG_SAMPLE_" + level
+		if os.Getenv(name) == "" {
+			continue
+		}
+		if cfg.LogSampleRates == nil {
+			cfg.LogSampleRates = make(map[string]float64)
+		}
+		cfg.LogSampleRates[level] = e.float(name, 1, 0, 1)
+	}
+
+	if patterns := os.Getenv("LOG_MASK_FIELDS"); patterns != "" {
+		cfg.LogMaskFields = strings.Split(patterns, ",")
+	}
+
+	// Подсети, которым доступны /admin и /metrics
+	cfg.AdminAllowedCIDRs = e.cidrs("ADMIN_ALLOWED_CIDRS")
+
+	// Подсети обратных прокси, которым доверяется X-Forwarded-For
+	cfg.TrustedProxyCIDRs = e.cidrs("TRUSTED_PROXY_CIDRS")
+
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		cfg.CORSAllowedOrigins = strings.Split(origins, ",")
+	}
+
+	// Детали медленных запросов по умолчанию пишем только вне production
+	cfg.SlowRequestDetail = e.bool("SLOW_REQUEST_DETAIL_ENABLED", cfg.Environment != "production")
+
+	if cfg.LogLevel != "" && !logLevels[cfg.LogLevel] {
+		e.fail(fmt.Errorf("LOG_LEVEL: unknown level %q", cfg.LogLevel))
+	}
+	e.oneOf("LOG_CONSOLE_FORMAT", cfg.LogConsoleFormat, "text", "json")
+	e.oneOf("LOG_TIMESTAMP_PRECISION", cfg.LogTimestampPrecision, "s", "ms", "ns")
+	e.oneOf("LOG_TRANSPORT", cfg.LogTransport, "http", "tcp", "udp")
+	if (cfg.LogClientCertFile == "") != (cfg.LogClientKeyFile == "") {
+		e.fail(errors.New("LOG_CLIENT_CERT_FILE and LOG_CLIENT_KEY_FILE must be set together"))
+	}
+
+	e.port("PORT", cfg.Port)
+	e.port("HTTP_REDIRECT_PORT", cfg.HTTPRedirectPort)
+
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		e.fail(errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	if len(cfg.ACMEDomains) > 0 && cfg.TLSCertFile != "" {
+		e.fail(errors.New("ACME_DOMAINS cannot be combined with TLS_CERT_FILE and TLS_KEY_FILE"))
+	}
+	// Курсоры пагинации должны проверяться любым экземпляром и после перезапуска
+	if cfg.CursorSecret == "" {
+		e.fail(errors.New("CURSOR_SECRET is required"))
+	}
+	// Без секрета защищенные маршруты были бы открыты
+	if cfg.JWTSecret == "" {
+		e.fail(errors.New("JWT_SECRET is required"))
+	}
+	if cfg.ShutdownGrace > 0 && cfg.ShutdownGrace >= cfg.ShutdownTimeout {
+		e.fail(errors.New("SHUTDOWN_GRACE_SECONDS must be shorter than SHUTDOWN_TIMEOUT_SECONDS"))
+	}
+
+	if len(e.errs) > 0 {
+		return nil, errors.Join(e.errs...)
+	}
+	return cfg, nil
+}
+
+// TLSEnabled - TLS включен, когда заданы сертификат и ключ или домены ACME
+func (c *Config) TLSEnabled() bool {
+	return (c.TLSCertFile != "" && c.TLSKeyFile != "") || c.ACMEEnabled()
+}
+
+// ACMEEnabled - сертификаты выпускаются автоматически для ACME_DOMAINS
+func (c *Config) ACMEEnabled() bool {
+	return len(c.ACMEDomains) > 0
+}
+
+// Уровни логов, допустимые в LOG_LEVEL и LOG_COMPONENT_LEVELS
+var logLevels = map[string]bool{"DEBUG": true, "INFO": true, "WARN": true, "ERROR": true, "FATAL": true}
+
+// env читает переменные окружения и копит ошибки разбора
+type env struct {
+	errs []error
+}
+
+func (e *env) fail(err error) {
+	e.errs = append(e.errs, err)
+}
+
+func (e *env) string(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+func (e *env) int(name string, def, min, max int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		e.fail(fmt.Errorf("%s: invalid integer %q", name, raw))
+		return def
+	}
+	if v < min || v > max {
+		e.fail(fmt.Errorf("%s: %d is out of range [%d, %d]", name, v, min, max))
+		return def
+	}
+	return v
+}
+
+func (e *env) float(name string, def, min, max float64) float64 {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		e.fail(fmt.Errorf("%s: invalid number %q", name, raw))
+		return def
+	}
+	if v < min || v > max {
+		e.fail(fmt.Errorf("%s: %g is out of range [%g, %g]", name, v, min, max))
+		return def
+	}
+	return v
+}
+
+func (e *env) bool(name string, def bool) bool {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		e.fail(fmt.Errorf("%s: invalid boolean %q", name, raw))
+		return def
+	}
+	return v
+}
+
+// seconds читает неотрицательное число секунд
+func (e *env) seconds(name string, def time.Duration) time.Duration {
+	return time.Duration(e.int(name, int(def/time.Second), 0, math.MaxInt32)) * time.Second
+}
+
+// duration читает положительную длительность в формате time.ParseDuration
+func (e *env) duration(name string, def time.Duration) time.Duration {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+
+	v, err := time.ParseDuration(raw)
+	if err != nil || v <= 0 {
+		e.fail(fmt.Errorf("%s: invalid duration %q", name, raw))
+		return def
+	}
+	return v
+}
+
+// cidrs читает список подсетей через запятую
+func (e *env) cidrs(name string) []string {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return nil
+	}
+
+	var cidrs []string
+	for _, cidr := range strings.Split(raw, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			e.fail(fmt.Errorf("%s: invalid CIDR %q", name, cidr))
+			continue
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs
+}
+
+// oneOf проверяет, что value - одно из допустимых значений
+func (e *env) oneOf(name, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		e.fail(fmt.Errorf("%s: %q is not one of %s", name, value, strings.Join(allowed, ", ")))
+	}
+}
+
+func (e *env) port(name, value string) {
+	if v, err := strconv.Atoi(value); err != nil || v < 1 || v > 65535 {
+		e.fail(fmt.Errorf("%s: invalid port %q", name, value))
+	}
+}
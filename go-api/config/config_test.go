@@ -0,0 +1,217 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setRequiredEnv задает обязательные переменные, чтобы тест проверял только свои
+func setRequiredEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CURSOR_SECRET", "test-secret")
+	t.Setenv("JWT_SECRET", "test-jwt-secret")
+}
+
+func TestLoad_RequiredFields(t *testing.T) {
+	for _, name := range []string{"CURSOR_SECRET", "JWT_SECRET"} {
+		t.Run(name, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv(name, "")
+
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), name+" is required") {
+				t.Fatalf("Load error = %v, want %s is required", err, name)
+			}
+		})
+	}
+
+	setRequiredEnv(t)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.CursorSecret != "test-secret" {
+		t.Errorf("CursorSecret = %q, want test-secret", cfg.CursorSecret)
+	}
+	if cfg.JWTSecret != "test-jwt-secret" {
+		t.Errorf("JWTSecret = %q, want test-jwt-secret", cfg.JWTSecret)
+	}
+}
+
+func TestLoad_TLS(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		wantErr  string
+		wantTLS  bool
+		wantACME bool
+	}{
+		{name: "plain HTTP", env: map[string]string{}},
+		{
+			name:    "certificate files",
+			env:     map[string]string{"TLS_CERT_FILE": "cert.pem", "TLS_KEY_FILE": "key.pem"},
+			wantTLS: true,
+		},
+		{
+			name:    "certificate without key",
+			env:     map[string]string{"TLS_CERT_FILE": "cert.pem"},
+			wantErr: "TLS_CERT_FILE and TLS_KEY_FILE must be set together",
+		},
+		{
+			name:     "acme domains",
+			env:      map[string]string{"ACME_DOMAINS": "example.com www.example.com"},
+			wantTLS:  true,
+			wantACME: true,
+		},
+		{
+			name:    "acme with certificate files",
+			env:     map[string]string{"ACME_DOMAINS": "example.com", "TLS_CERT_FILE": "cert.pem", "TLS_KEY_FILE": "key.pem"},
+			wantErr: "ACME_DOMAINS cannot be combined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequiredEnv(t)
+			for _, name := range []string{"TLS_CERT_FILE", "TLS_KEY_FILE", "ACME_DOMAINS"} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			cfg, err := Load()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load error = %v, want %q", err, tt.wantErr)
+				}
+				if strings.Contains(err.Error(), "secret-key") {
+					t.Errorf("Load error = %v, leaks the API key", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.TLSEnabled() != tt.wantTLS || cfg.ACMEEnabled() != tt.wantACME {
+				t.Errorf("TLSEnabled = %v, ACMEEnabled = %v, want %v and %v", cfg.TLSEnabled(), cfg.ACMEEnabled(), tt.wantTLS, tt.wantACME)
+			}
+		})
+	}
+}
+
+func TestLoad_LogDefaults(t *testing.T) {
+	setRequiredEnv(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.LogTransport != "http" || cfg.LogstashAddr != "logstash:5001" {
+		t.Errorf("transport = %q %q, want http logstash:5001", cfg.LogTransport, cfg.LogstashAddr)
+	}
+	if cfg.LogWorkerCount != 4 || cfg.LogQueueSize != 1000 || cfg.LogMaxRetries != 3 {
+		t.Errorf("workers = %d, queue = %d, retries = %d, want 4, 1000, 3", cfg.LogWorkerCount, cfg.LogQueueSize, cfg.LogMaxRetries)
+	}
+	if cfg.LogCircuitFailures != 5 || cfg.LogCircuitCooldown != 30*time.Second {
+		t.Errorf("circuit = %d %v, want 5 30s", cfg.LogCircuitFailures, cfg.LogCircuitCooldown)
+	}
+	if cfg.LogProbeInterval != 15*time.Second || cfg.LogFallbackMaxMB != 10 {
+		t.Errorf("probe = %v, fallback = %d MB, want 15s and 10 MB", cfg.LogProbeInterval, cfg.LogFallbackMaxMB)
+	}
+}
+
+func TestLoad_LogSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "batching and component levels",
+			env:  map[string]string{"LOG_BATCH_SIZE": "50", "LOG_BATCH_INTERVAL": "250", "LOG_COMPONENT_LEVELS": "db=debug, handlers=WARN"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.LogBatchSize != 50 || cfg.LogBatchInterval != 250*time.Millisecond {
+					t.Errorf("batch = %d %v, want 50 250ms", cfg.LogBatchSize, cfg.LogBatchInterval)
+				}
+				if cfg.LogComponentLevels["db"] != "DEBUG" || cfg.LogComponentLevels["handlers"] != "WARN" {
+					t.Errorf("component levels = %v", cfg.LogComponentLevels)
+				}
+			},
+		},
+		{
+			name: "sample rates",
+			env:  map[string]string{"LOG_SAMPLE_INFO": "0.1"},
+			check: func(t *testing.T, cfg *Config) {
+				if len(cfg.LogSampleRates) != 1 || cfg.LogSampleRates["INFO"] != 0.1 {
+					t.Errorf("sample rates = %v, want INFO: 0.1", cfg.LogSampleRates)
+				}
+			},
+		},
+		{name: "unknown level", env: map[string]string{"LOG_LEVEL": "verbose"}, wantErr: "LOG_LEVEL"},
+		{name: "unknown transport", env: map[string]string{"LOG_TRANSPORT": "grpc"}, wantErr: "LOG_TRANSPORT"},
+		{name: "unknown precision", env: map[string]string{"LOG_TIMESTAMP_PRECISION": "us"}, wantErr: "LOG_TIMESTAMP_PRECISION"},
+		{name: "zero workers", env: map[string]string{"LOG_WORKER_COUNT": "0"}, wantErr: "LOG_WORKER_COUNT"},
+		{name: "sample rate out of range", env: map[string]string{"LOG_SAMPLE_DEBUG": "2"}, wantErr: "LOG_SAMPLE_DEBUG"},
+		{name: "invalid cooldown", env: map[string]string{"LOG_CIRCUIT_COOLDOWN": "soon"}, wantErr: "LOG_CIRCUIT_COOLDOWN"},
+		{name: "invalid component level", env: map[string]string{"LOG_COMPONENT_LEVELS": "db=LOUD"}, wantErr: "LOG_COMPONENT_LEVELS"},
+		{name: "client cert without key", env: map[string]string{"LOG_CLIENT_CERT_FILE": "client.pem"}, wantErr: "LOG_CLIENT_KEY_FILE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequiredEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := Load()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoad_APIKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    map[string]string
+		wantErr string
+	}{
+		{name: "unset"},
+		{name: "single key", raw: "key1:billing", want: map[string]string{"key1": "billing"}},
+		{name: "several keys with spaces", raw: "key1:billing, key2:reports", want: map[string]string{"key1": "billing", "key2": "reports"}},
+		{name: "missing name", raw: "key1:billing,secret-key-0002", wantErr: "API_KEYS: invalid entry #2"},
+		{name: "empty key", raw: ":billing", wantErr: "API_KEYS: invalid entry #1, expected key:name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv("API_KEYS", tt.raw)
+
+			cfg, err := Load()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if !reflect.DeepEqual(cfg.APIKeys, tt.want) {
+				t.Errorf("APIKeys = %v, want %v", cfg.APIKeys, tt.want)
+			}
+		})
+	}
+}
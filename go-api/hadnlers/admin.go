@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/crazy1997/go-api/logging"
+)
+
+// LogLevelHandler меняет уровень логирования без перезапуска
+func (h *Handler) LogLevelHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Level string `json:"level"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		WriteError(w, http.StatusBadRequest, ErrTypeInvalidJSON, "Invalid JSON")
+		return
+	}
+
+	// Уровень - настройка общего логгера процесса
+	global := logging.GetLogger()
+	previous := global.Level()
+
+	if err := global.SetLevel(request.Level); err != nil {
+		WriteError(w, http.StatusBadRequest, ErrTypeValidation, "Unknown log level")
+		return
+	}
+
+	h.logger(r).Warn("Log level changed", map[string]interface{}{
+		"from":      previous,
+		"to":        global.Level(),
+		"client_ip": r.RemoteAddr,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"level":    global.Level(),
+		"previous": previous,
+	})
+}
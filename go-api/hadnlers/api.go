@@ -1,43 +1,89 @@
 package handlers
 
 import (
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"math/rand"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/crazy1997/go-api/logging"
 	"github.com/crazy1997/go-api/metrics"
+	"github.com/crazy1997/go-api/validators"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type User struct {
-	ID        int    `json:"id"`
-	Name      string `json:"name"`
-	Email     string `json:"email"`
-	CreatedAt string `json:"created_at"`
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	CreatedAt string     `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type Order struct {
-	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
-	Total     float64   `json:"total"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int         `json:"id"`
+	UserID    int         `json:"user_id"`
+	Items     []OrderItem `json:"items"`
+	Total     float64     `json:"total"`
+	Status    string      `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
 }
 
-// HealthHandler возвращает статус приложения
-func HealthHandler(w http.ResponseWriter, r *http.Request) {
-	logging.Info("Health check requested", map[string]interface{}{
+type OrderItem struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// validateOrder проверяет данные заказа правилами validators.ValidateOrder
+func validateOrder(userID int, items []OrderItem) validators.Errors {
+	toValidate := make([]validators.OrderItem, len(items))
+	for i, item := range items {
+		toValidate[i] = validators.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	return validators.ValidateOrder(userID, toValidate)
+}
+
+// Версия бинарника для /api/health, задается из main
+var buildInfo = metrics.BuildInfo{Version: "1.0.0", Commit: "unknown", BuildDate: "unknown"}
+
+// SetBuildInfo задает версию, коммит и дату сборки для /api/health
+func SetBuildInfo(info metrics.BuildInfo) {
+	buildInfo = info
+}
+
+// HealthHandler возвращает статус приложения. До завершения запуска
+// отвечает 503 со статусом starting
+func (h *Handler) HealthHandler(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Health check requested", map[string]interface{}{
 		"client_ip":  r.RemoteAddr,
 		"user_agent": r.UserAgent(),
 	})
 
+	if h.Started != nil && !h.Started.Load() {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "starting"})
+		return
+	}
+
 	response := map[string]interface{}{
-		"status":    "healthy",
-		"timestamp": time.Now().Unix(),
-		"version":   "1.0.0",
-		"service":   "go-api",
+		"status":     "healthy",
+		"timestamp":  time.Now().Unix(),
+		"version":    buildInfo.Version,
+		"commit":     buildInfo.Commit,
+		"build_date": buildInfo.BuildDate,
+		"service":    "go-api",
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -45,136 +91,417 @@ func HealthHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // UsersHandler возвращает список пользователей
-func UsersHandler(w http.ResponseWriter, r *http.Request) {
-	requestID := fmt.Sprintf("req-%d", time.Now().UnixNano())
+func (h *Handler) UsersHandler(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger(r)
 
-	logging.Info("Processing users request", map[string]interface{}{
-		"request_id": requestID,
-		"method":     r.Method,
-		"path":       r.URL.Path,
+	logger.Info("Processing users request", map[string]interface{}{
+		"method": r.Method,
+		"path":   r.URL.Path,
 	})
 
-	// 20% шанс ошибки
-	if rand.Intn(100) < 20 {
+	format, ok := negotiateFormat(r)
+	if !ok {
+		writeNotAcceptable(w)
+		return
+	}
+
+	page, limit, field, err := parsePagination(r)
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, err.Error(), field)
+		return
+	}
+
+	// Имитация ошибки БД с вероятностью USERS_ERROR_RATE
+	if h.chance(usersErrorRate) {
 		errMsg := "Database connection failed"
-		logging.Error(errMsg, map[string]interface{}{
-			"request_id":  requestID,
+		logger.Error(errMsg, map[string]interface{}{
 			"error_type":  "database_error",
 			"retry_count": 2,
 		})
 
-		metrics.RecordError("database", "/api/users")
-		http.Error(w, `{"error": "`+errMsg+`"}`, http.StatusInternalServerError)
+		h.Metrics.RecordError("database", "/api/users")
+		WriteError(w, http.StatusInternalServerError, ErrTypeDatabase, errMsg)
 		return
 	}
 
-	// Симуляция задержки БД
-	delay := time.Duration(rand.Intn(200)) * time.Millisecond
-	time.Sleep(delay)
+	includeDeleted := r.URL.Query().Get("include_deleted") == "true"
 
-	users := []User{
-		{
-			ID:        1,
-			Name:      "John Doe",
-			Email:     "john@example.com",
-			CreatedAt: time.Now().Add(-24 * time.Hour).Format(time.RFC3339),
-		},
-		{
-			ID:        2,
-			Name:      "Jane Smith",
-			Email:     "jane@example.com",
-			CreatedAt: time.Now().Add(-12 * time.Hour).Format(time.RFC3339),
-		},
-		{
-			ID:        3,
-			Name:      "Bob Johnson",
-			Email:     "bob@example.com",
-			CreatedAt: time.Now().Add(-6 * time.Hour).Format(time.RFC3339),
-		},
+	// Потоковая выдача всех пользователей без пагинации
+	if r.URL.Query().Get("stream") == "true" {
+		h.streamUsers(w, r, includeDeleted, format)
+		return
+	}
+
+	// Курсорная пагинация, первая страница - пустой cursor
+	if r.URL.Query().Has("cursor") {
+		h.usersByCursor(w, r, logger, format, limit, includeDeleted)
+		return
+	}
+
+	started := time.Now()
+	result, err := h.listUsers(r.Context(), usersCacheKey{page: page, limit: limit, includeDeleted: includeDeleted})
+	if err != nil {
+		logger.Error("Failed to list users", map[string]interface{}{
+			"error": err,
+		})
+
+		h.Metrics.RecordError("database", "/api/users")
+		WriteError(w, http.StatusInternalServerError, ErrTypeDatabase, "Failed to list users")
+		return
+	}
+	users := result.users
+
+	if format == formatCSV {
+		setCSVHeaders(w, "users.csv")
+		cw := csv.NewWriter(w)
+		cw.Write(userCSVHeader)
+		for _, u := range users {
+			cw.Write(userCSVRow(u))
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			logger.Error("Failed to encode users response", map[string]interface{}{
+				"error": err,
+			})
+			return
+		}
+
+		logger.Info("Users request completed", map[string]interface{}{
+			"user_count":    len(users),
+			"format":        format,
+			"response_time": time.Since(started).Milliseconds(),
+		})
+		return
+	}
+
+	response := PageResponse{
+		Data: users,
+		Meta: PageMeta{Page: page, Limit: limit, Total: result.total},
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(users); err != nil {
-		logging.Error("Failed to encode users response", map[string]interface{}{
-			"request_id": requestID,
-			"error":      err.Error(),
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Error("Failed to encode users response", map[string]interface{}{
+			"error": err,
 		})
 		return
 	}
 
-	logging.Info("Users request completed", map[string]interface{}{
-		"request_id":    requestID,
+	logger.Info("Users request completed", map[string]interface{}{
 		"user_count":    len(users),
-		"response_time": delay.Milliseconds(),
+		"response_time": time.Since(started).Milliseconds(),
+	})
+}
+
+// Ограничения полей пользователя
+const maxUserNameLength = 255
+
+// Базовая проверка формата email (local@domain.tld)
+var emailPattern = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)+$`)
+
+// validateUser проверяет имя и email пользователя, nil - данные корректны
+func validateUser(name, email string) *validators.FieldError {
+	switch {
+	case name == "":
+		return &validators.FieldError{Field: "name", Message: "is required"}
+	case utf8.RuneCountInString(name) > maxUserNameLength:
+		return &validators.FieldError{Field: "name", Message: "must be at most 255 characters"}
+	case !emailPattern.MatchString(email):
+		return &validators.FieldError{Field: "email", Message: "is invalid"}
+	}
+	return nil
+}
+
+// CreateUserHandler создает пользователя
+func (h *Handler) CreateUserHandler(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger(r)
+
+	var input struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("Failed to parse user data", map[string]interface{}{
+			"error": err,
+		})
+
+		h.Metrics.RecordError("validation", "/api/users")
+		writeDecodeError(w, err)
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	input.Email = strings.TrimSpace(input.Email)
+
+	if fe := validateUser(input.Name, input.Email); fe != nil {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, fe.Error(), fe.Field)
+		return
+	}
+
+	user, err := usersStore.Create(r.Context(), input.Name, input.Email)
+	if errors.Is(err, ErrDuplicateEmail) {
+		writeFieldError(w, http.StatusConflict, ErrTypeConflict, err.Error(), "email")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to create user", map[string]interface{}{
+			"error": err,
+		})
+
+		h.Metrics.RecordError("database", "/api/users")
+		WriteError(w, http.StatusInternalServerError, ErrTypeDatabase, "Failed to create user")
+		return
+	}
+
+	h.Metrics.RecordUserRegistration()
+	h.flushUsersCache()
+
+	logger.Info("User created", map[string]interface{}{
+		"user_id": user.ID,
+		"email":   user.Email,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+// GetUserHandler возвращает пользователя по ID
+func (h *Handler) GetUserHandler(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger(r)
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, "id must be an integer", "id")
+		return
+	}
+
+	user, err := usersStore.Get(r.Context(), id)
+	if err == nil && user.DeletedAt != nil {
+		err = ErrUserNotFound
+	}
+	if errors.Is(err, ErrUserNotFound) {
+		logger.Warn("User not found", map[string]interface{}{
+			"user_id": id,
+		})
+
+		WriteError(w, http.StatusNotFound, ErrTypeNotFound, "user not found")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to get user", map[string]interface{}{
+			"user_id": id,
+			"error":   err,
+		})
+
+		h.Metrics.RecordError("database", "/api/users/{id}")
+		WriteError(w, http.StatusInternalServerError, ErrTypeDatabase, "Failed to get user")
+		return
+	}
+
+	logger.Info("User found", map[string]interface{}{
+		"user_id": id,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// DeleteUserHandler помечает пользователя удаленным
+func (h *Handler) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger(r)
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, "id must be an integer", "id")
+		return
+	}
+
+	err = usersStore.Delete(r.Context(), id)
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		WriteError(w, http.StatusNotFound, ErrTypeNotFound, "user not found")
+		return
+	case errors.Is(err, ErrUserDeleted):
+		WriteError(w, http.StatusConflict, ErrTypeConflict, "user already deleted")
+		return
+	case err != nil:
+		logger.Error("Failed to delete user", map[string]interface{}{
+			"user_id": id,
+			"error":   err,
+		})
+
+		h.Metrics.RecordError("database", "/api/users/{id}")
+		WriteError(w, http.StatusInternalServerError, ErrTypeDatabase, "Failed to delete user")
+		return
+	}
+
+	h.Metrics.RecordUserDeletion()
+	h.flushUsersCache()
+
+	// Аудит: request_id инициатора добавляется логгером из контекста
+	logger.Info("User deleted", map[string]interface{}{
+		"user_id":   id,
+		"audit":     true,
+		"client_ip": r.RemoteAddr,
 	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Заказы в обработке, ожидаемые при остановке сервера
+var ordersInFlight sync.WaitGroup
+
+// WaitForOrders ждет завершения обработки заказов или отмены ctx
+func WaitForOrders(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		ordersInFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-// OrdersHandler создает новый заказ
-func OrdersHandler(w http.ResponseWriter, r *http.Request) {
-	requestID := fmt.Sprintf("order-%d", time.Now().UnixNano())
+// Ответы на запросы с ключом идемпотентности
+var orderIdempotency = newIdempotencyStore()
+
+// OrdersHandler создает новый заказ. Запросы с одинаковым
+// X-Idempotency-Key создают заказ только один раз
+func (h *Handler) OrdersHandler(w http.ResponseWriter, r *http.Request) {
+	ordersInFlight.Add(1)
+	defer ordersInFlight.Done()
+
+	if key := r.Header.Get("X-Idempotency-Key"); key != "" {
+		orderIdempotency.serve(key, w, r, h.createOrder)
+		return
+	}
+
+	h.createOrder(w, r)
+}
+
+func (h *Handler) createOrder(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger(r)
 
 	if r.Method != http.MethodPost {
-		logging.Warn("Invalid method for orders endpoint", map[string]interface{}{
-			"request_id": requestID,
-			"method":     r.Method,
-			"expected":   "POST",
+		logger.Warn("Invalid method for orders endpoint", map[string]interface{}{
+			"method":   r.Method,
+			"expected": "POST",
 		})
 
-		http.Error(w, `{"error": "Method not allowed"}`, http.StatusMethodNotAllowed)
+		WriteError(w, http.StatusMethodNotAllowed, ErrTypeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	var orderData struct {
-		UserID int `json:"user_id"`
-		Items  []struct {
-			ProductID int `json:"product_id"`
-			Quantity  int `json:"quantity"`
-		} `json:"items"`
+		UserID int         `json:"user_id"`
+		Items  []OrderItem `json:"items"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&orderData); err != nil {
-		logging.Error("Failed to parse order data", map[string]interface{}{
-			"request_id": requestID,
-			"error":      err.Error(),
+		logger.Error("Failed to parse order data", map[string]interface{}{
+			"error": err,
 		})
 
-		metrics.RecordError("validation", "/api/orders")
-		http.Error(w, `{"error": "Invalid JSON"}`, http.StatusBadRequest)
+		h.Metrics.RecordError("validation", "/api/orders")
+		writeDecodeError(w, err)
 		return
 	}
 
-	logging.Info("Processing order", map[string]interface{}{
-		"request_id": requestID,
+	if errs := validateOrder(orderData.UserID, orderData.Items); errs != nil {
+		logger.Warn("Order validation failed", map[string]interface{}{
+			"user_id": orderData.UserID,
+			"errors":  errs.Messages(),
+		})
+
+		h.Metrics.RecordError("validation", "/api/orders")
+		writeErrorDetails(w, http.StatusUnprocessableEntity, ErrTypeValidation, "order validation failed", map[string]interface{}{
+			"errors": errs.Messages(),
+		})
+		return
+	}
+
+	logger.Info("Processing order", map[string]interface{}{
 		"user_id":    orderData.UserID,
 		"item_count": len(orderData.Items),
 	})
 
-	// 15% шанс ошибки обработки
-	if rand.Intn(100) < 15 {
+	// Имитация ошибки оплаты с вероятностью ORDERS_ERROR_RATE
+	if h.chance(ordersErrorRate) {
 		errMsg := "Payment processing failed"
-		logging.Error(errMsg, map[string]interface{}{
-			"request_id": requestID,
+		logger.Error(errMsg, map[string]interface{}{
 			"error_type": "payment_error",
 			"user_id":    orderData.UserID,
 		})
 
-		metrics.RecordError("payment", "/api/orders")
-		http.Error(w, `{"error": "`+errMsg+`"}`, http.StatusPaymentRequired)
+		h.Metrics.RecordError("payment", "/api/orders")
+		trace.SpanFromContext(r.Context()).AddEvent("order.payment_failed", trace.WithAttributes(
+			attribute.Int("user_id", orderData.UserID),
+		))
+		markRetryable(w)
+		WriteError(w, http.StatusPaymentRequired, ErrTypePayment, errMsg)
 		return
 	}
 
-	// Симуляция обработки
-	processingTime := time.Duration(rand.Intn(300)) * time.Millisecond
-	time.Sleep(processingTime)
+	orderID := nextOrderID()
+
+	// Симуляция обработки с учетом отключения клиента
+	processingTime := h.orderProcessingDelay()
+	started := time.Now()
+	select {
+	case <-time.After(processingTime):
+	case <-r.Context().Done():
+		// Дедлайн маршрута (middleware.Timeout) - не отключение клиента
+		if errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+			logger.Warn("Order processing timed out", map[string]interface{}{
+				"order_id":   orderID,
+				"elapsed_ms": time.Since(started).Milliseconds(),
+			})
+
+			h.Metrics.RecordError("timeout", "/api/orders")
+			return
+		}
+
+		logger.Info("Client disconnected during order processing", map[string]interface{}{
+			"order_id":   orderID,
+			"elapsed_ms": time.Since(started).Milliseconds(),
+		})
+
+		h.Metrics.RecordOrderCancelledByClient()
+		return
+	}
 
 	order := Order{
-		ID:        rand.Intn(10000),
+		ID:        orderID,
 		UserID:    orderData.UserID,
-		Total:     float64(rand.Intn(1000)) + 0.99,
-		Status:    "completed",
+		Items:     orderData.Items,
+		Total:     float64(h.Rand.Intn(1000)) + 0.99,
+		Status:    OrderStatusCompleted,
 		CreatedAt: time.Now(),
 	}
 
+	if err := ordersStore.Save(r.Context(), order); err != nil {
+		logger.Error("Failed to save order", map[string]interface{}{
+			"order_id": order.ID,
+			"error":    err,
+		})
+
+		h.Metrics.RecordError("database", "/api/orders")
+		WriteError(w, http.StatusInternalServerError, ErrTypeDatabase, "Failed to save order")
+		return
+	}
+
+	trace.SpanFromContext(r.Context()).AddEvent("order.saved", trace.WithAttributes(
+		attribute.Int("order_id", order.ID),
+		attribute.Int("item_count", len(order.Items)),
+		attribute.Float64("total", order.Total),
+	))
+
 	response := map[string]interface{}{
 		"success":   true,
 		"order_id":  order.ID,
@@ -187,98 +514,207 @@ func OrdersHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		logging.Error("Failed to encode order response", map[string]interface{}{
-			"request_id": requestID,
-			"error":      err.Error(),
+		logger.Error("Failed to encode order response", map[string]interface{}{
+			"error": err,
 		})
 		return
 	}
 
 	// Записываем бизнес метрику
-	metrics.RecordOrder()
+	h.Metrics.RecordOrder()
+	h.Metrics.RecordOrderRevenue(order.Total)
 
 	// Записываем просмотры продуктов
 	for _, item := range orderData.Items {
-		metrics.RecordProductView(fmt.Sprintf("%d", item.ProductID))
+		h.Metrics.RecordProductView(fmt.Sprintf("%d", item.ProductID))
 	}
 
-	logging.Info("Order processed successfully", map[string]interface{}{
-		"request_id":      requestID,
+	withTags(logger, logging.TagFinancial).Info("Order processed successfully", map[string]interface{}{
 		"order_id":        order.ID,
 		"processing_time": processingTime.Milliseconds(),
 		"total_amount":    order.Total,
 	})
 }
 
+// GetOrderHandler возвращает заказ по ID
+func (h *Handler) GetOrderHandler(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger(r)
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, "id must be an integer", "id")
+		return
+	}
+
+	order, err := ordersStore.Get(r.Context(), id)
+	if errors.Is(err, ErrOrderNotFound) {
+		logger.Warn("Order not found", map[string]interface{}{
+			"order_id": id,
+		})
+
+		WriteError(w, http.StatusNotFound, ErrTypeNotFound, "order not found")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to get order", map[string]interface{}{
+			"order_id": id,
+			"error":    err,
+		})
+
+		h.Metrics.RecordError("database", "/api/orders/{id}")
+		WriteError(w, http.StatusInternalServerError, ErrTypeDatabase, "Failed to get order")
+		return
+	}
+
+	logger.Info("Order lookup", map[string]interface{}{
+		"order_id": id,
+		"status":   order.Status,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// Кэш результатов выборки каталога по параметрам фильтра
+var productsCache = NewCache[string, []Product](time.Minute)
+
+// Метрики кэша регистрируются в init: к этому моменту инициализированы
+// все переменные пакета, включая общие векторы метрик кэшей
+func init() {
+	if err := productsCache.RegisterMetrics(prometheus.DefaultRegisterer, "products"); err != nil {
+		panic(err)
+	}
+	if err := productsETags.RegisterMetrics(prometheus.DefaultRegisterer, "products_etag"); err != nil {
+		panic(err)
+	}
+}
+
+// loadProducts возвращает каталог продуктов из источника данных
+func loadProducts() []Product {
+	return []Product{
+		{ID: 1, Name: "Laptop Pro", Price: 1299.99, Category: "electronics", StockLevel: 25, Rating: 4.5},
+		{ID: 2, Name: "Wireless Mouse", Price: 49.99, Category: "accessories", StockLevel: 150, Rating: 4.2},
+		{ID: 3, Name: "Mechanical Keyboard", Price: 89.99, Category: "accessories", StockLevel: 0, Rating: 4.7},
+	}
+}
+
 // ProductsHandler возвращает информацию о продуктах
-func ProductsHandler(w http.ResponseWriter, r *http.Request) {
-	requestID := fmt.Sprintf("prod-%d", time.Now().UnixNano())
+func (h *Handler) ProductsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger(r)
 
-	logging.Debug("Processing products request", map[string]interface{}{
-		"request_id": requestID,
+	format, ok := negotiateFormat(r)
+	if !ok {
+		writeNotAcceptable(w)
+		return
+	}
+
+	filter, field, err := parseProductFilter(r)
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, err.Error(), field)
+		return
+	}
+
+	order, field, err := parseProductSort(r)
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, err.Error(), field)
+		return
+	}
+
+	logger.Debug("Processing products request", map[string]interface{}{
+		"category":  filter.category,
+		"min_price": filter.minPrice,
+		"max_price": filter.maxPriceParam(),
 	})
 
-	// 10% шанс медленного ответа
-	if rand.Intn(100) < 10 {
-		logging.Warn("Simulating slow response", map[string]interface{}{
-			"request_id": requestID,
-			"delay_ms":   2000,
+	// Клиент уже получил актуальную версию выборки
+	etagKey := format + "|" + filter.key() + "|" + order.key()
+	w.Header().Set("Vary", "Accept")
+	if etag, ok := productsETags.Get(etagKey); ok && etagMatches(r, etag) {
+		writeNotModified(w, etag)
+		return
+	}
+
+	// Имитация медленного ответа с вероятностью PRODUCTS_SLOW_RATE
+	if h.chance(productsSlowRate) {
+		logger.Warn("Simulating slow response", map[string]interface{}{
+			"delay_ms": 2000,
 		})
 
 		time.Sleep(2 * time.Second)
 	}
 
-	products := []map[string]interface{}{
-		{
-			"id":       1,
-			"name":     "Laptop Pro",
-			"price":    1299.99,
-			"category": "electronics",
-			"in_stock": true,
-			"rating":   4.5,
-		},
-		{
-			"id":       2,
-			"name":     "Wireless Mouse",
-			"price":    49.99,
-			"category": "accessories",
-			"in_stock": true,
-			"rating":   4.2,
-		},
-		{
-			"id":       3,
-			"name":     "Mechanical Keyboard",
-			"price":    89.99,
-			"category": "accessories",
-			"in_stock": false,
-			"rating":   4.7,
-		},
+	cacheKey := filter.key()
+	products, ok := productsCache.Get(cacheKey)
+	if !ok {
+		products, err = productsStore.Filter(r.Context(), filter.category, filter.minPrice, filter.maxPrice)
+		if err != nil {
+			logger.Error("Failed to load products", map[string]interface{}{
+				"error": err,
+			})
+
+			h.Metrics.RecordError("database", "/api/products")
+			WriteError(w, http.StatusInternalServerError, ErrTypeDatabase, "Failed to load products")
+			return
+		}
+		productsCache.Set(cacheKey, products)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(products); err != nil {
-		logging.Error("Failed to encode products response", map[string]interface{}{
-			"request_id": requestID,
-			"error":      err.Error(),
+	// Сортируем копию, чтобы не менять закэшированную выборку
+	products = order.apply(products)
+
+	var body []byte
+	if format == formatCSV {
+		body, err = encodeProductsCSV(products)
+	} else {
+		body, err = json.Marshal(products)
+		body = append(body, '\n')
+	}
+	if err != nil {
+		logger.Error("Failed to encode products response", map[string]interface{}{
+			"error": err,
 		})
+
+		WriteError(w, http.StatusInternalServerError, ErrTypeInternal, "Failed to encode products")
 		return
 	}
 
-	logging.Info("Products request completed", map[string]interface{}{
-		"request_id":    requestID,
+	etag := productsETag(body)
+	productsETags.Set(etagKey, etag)
+	if etagMatches(r, etag) {
+		writeNotModified(w, etag)
+		return
+	}
+
+	if format == formatCSV {
+		setCSVHeaders(w, "products.csv")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", productsCacheControl)
+	w.Write(body)
+
+	logger.Info("Products request completed", map[string]interface{}{
 		"product_count": len(products),
+		"format":        format,
+		"category":      filter.category,
+		"min_price":     filter.minPrice,
+		"max_price":     filter.maxPriceParam(),
 	})
 }
 
-// MetricsHandler возвращает текущие метрики приложения
-func MetricsHandler(w http.ResponseWriter, r *http.Request) {
-	// Эту функцию не нужно реализовывать, так как Prometheus сам обслуживает /metrics
-	// Это просто для демонстрации другого эндпоинта
-	response := map[string]interface{}{
-		"message":   "Metrics are available at /metrics endpoint",
-		"timestamp": time.Now().Unix(),
+// MetricsHandler возвращает сводку основных метрик приложения.
+// Полный набор метрик доступен на /metrics
+func (h *Handler) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	summary, err := metrics.GetSummary(prometheus.DefaultGatherer)
+	if err != nil {
+		h.logger(r).Error("Failed to gather metrics", map[string]interface{}{
+			"error": err,
+		})
+		WriteError(w, http.StatusInternalServerError, ErrTypeInternal, "Failed to gather metrics")
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(summary)
 }
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Метрики кэшей, общие для всех экземпляров и различаемые по cache_name
+var (
+	cacheHits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_hits_total",
+			Help: "Total number of cache hits",
+		},
+		[]string{"cache_name"},
+	)
+
+	cacheMisses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_misses_total",
+			Help: "Total number of cache misses",
+		},
+		[]string{"cache_name"},
+	)
+
+	cacheEvictions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_evictions_total",
+			Help: "Total number of cache evictions",
+		},
+		[]string{"cache_name", "reason"},
+	)
+
+	cacheEntries = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cache_entries",
+			Help: "Current number of entries in cache",
+		},
+		[]string{"cache_name"},
+	)
+)
+
+type cacheItem[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+type cacheMetrics struct {
+	hits          prometheus.Counter
+	misses        prometheus.Counter
+	evictedTTL    prometheus.Counter
+	evictedManual prometheus.Counter
+	entries       prometheus.Gauge
+
+	// Только для LRUCache
+	evictedCapacity prometheus.Counter
+}
+
+// Cache - потокобезопасный in-memory кэш с TTL
+type Cache[K comparable, V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	items   map[K]cacheItem[V]
+	metrics *cacheMetrics
+}
+
+// NewCache создает кэш, записи которого живут ttl
+func NewCache[K comparable, V any](ttl time.Duration) *Cache[K, V] {
+	return &Cache[K, V]{
+		ttl:   ttl,
+		items: make(map[K]cacheItem[V]),
+	}
+}
+
+// RegisterMetrics включает сбор метрик кэша под именем name.
+// Повторная регистрация общих метрик для другого кэша не считается ошибкой.
+func (c *Cache[K, V]) RegisterMetrics(reg prometheus.Registerer, name string) error {
+	m, err := newCacheMetrics(reg, name)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.metrics = m
+	c.metrics.entries.Set(float64(len(c.items)))
+
+	return nil
+}
+
+// newCacheMetrics регистрирует общие метрики кэшей и возвращает серии для name
+func newCacheMetrics(reg prometheus.Registerer, name string) (*cacheMetrics, error) {
+	for _, collector := range []prometheus.Collector{cacheHits, cacheMisses, cacheEvictions, cacheEntries} {
+		if err := reg.Register(collector); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			if !errors.As(err, &are) {
+				return nil, err
+			}
+		}
+	}
+
+	return &cacheMetrics{
+		hits:            cacheHits.WithLabelValues(name),
+		misses:          cacheMisses.WithLabelValues(name),
+		evictedTTL:      cacheEvictions.WithLabelValues(name, "ttl"),
+		evictedManual:   cacheEvictions.WithLabelValues(name, "manual"),
+		evictedCapacity: cacheEvictions.WithLabelValues(name, "capacity"),
+		entries:         cacheEntries.WithLabelValues(name),
+	}, nil
+}
+
+// Get возвращает значение, если оно есть и не истекло
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[key]
+	if ok && time.Now().After(item.expiresAt) {
+		delete(c.items, key)
+		ok = false
+
+		if c.metrics != nil {
+			c.metrics.evictedTTL.Inc()
+			c.metrics.entries.Set(float64(len(c.items)))
+		}
+	}
+
+	if !ok {
+		if c.metrics != nil {
+			c.metrics.misses.Inc()
+		}
+		var zero V
+		return zero, false
+	}
+
+	if c.metrics != nil {
+		c.metrics.hits.Inc()
+	}
+	return item.value, true
+}
+
+// Set сохраняет значение с TTL кэша
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[key] = cacheItem[V]{
+		value:     value,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+
+	if c.metrics != nil {
+		c.metrics.entries.Set(float64(len(c.items)))
+	}
+}
+
+// Delete удаляет значение из кэша вручную
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.items[key]; !ok {
+		return
+	}
+	delete(c.items, key)
+
+	if c.metrics != nil {
+		c.metrics.evictedManual.Inc()
+		c.metrics.entries.Set(float64(len(c.items)))
+	}
+}
+
+// Clear удаляет все значения из кэша вручную
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.items) == 0 {
+		return
+	}
+
+	if c.metrics != nil {
+		c.metrics.evictedManual.Add(float64(len(c.items)))
+		c.metrics.entries.Set(0)
+	}
+	c.items = make(map[K]cacheItem[V])
+}
+
+// LRUCache - потокобезопасный кэш с TTL и ограничением числа записей.
+// При переполнении вытесняется запись, к которой дольше всего не обращались
+type LRUCache[K comparable, V any] struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	capacity int
+	items    map[K]*list.Element
+	order    *list.List // от недавно использованных к давно
+	metrics  *cacheMetrics
+}
+
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// NewLRUCache создает кэш не более чем на capacity записей, живущих ttl
+func NewLRUCache[K comparable, V any](capacity int, ttl time.Duration) *LRUCache[K, V] {
+	return &LRUCache[K, V]{
+		ttl:      ttl,
+		capacity: capacity,
+		items:    make(map[K]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// RegisterMetrics включает сбор метрик кэша под именем name
+func (c *LRUCache[K, V]) RegisterMetrics(reg prometheus.Registerer, name string) error {
+	m, err := newCacheMetrics(reg, name)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.metrics = m
+	c.metrics.entries.Set(float64(len(c.items)))
+
+	return nil
+}
+
+// Get возвращает значение, если оно есть и не истекло
+func (c *LRUCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if ok && time.Now().After(elem.Value.(*lruEntry[K, V]).expiresAt) {
+		c.remove(elem)
+		ok = false
+
+		if c.metrics != nil {
+			c.metrics.evictedTTL.Inc()
+		}
+	}
+
+	if !ok {
+		if c.metrics != nil {
+			c.metrics.misses.Inc()
+		}
+		var zero V
+		return zero, false
+	}
+
+	c.order.MoveToFront(elem)
+	if c.metrics != nil {
+		c.metrics.hits.Inc()
+	}
+	return elem.Value.(*lruEntry[K, V]).value, true
+}
+
+// Set сохраняет значение с TTL кэша, вытесняя самую старую запись при переполнении
+func (c *LRUCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*lruEntry[K, V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	if c.capacity > 0 && c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+
+		if c.metrics != nil {
+			c.metrics.evictedCapacity.Inc()
+		}
+	}
+
+	if c.metrics != nil {
+		c.metrics.entries.Set(float64(len(c.items)))
+	}
+}
+
+// Clear удаляет все значения из кэша вручную
+func (c *LRUCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.items) == 0 {
+		return
+	}
+
+	if c.metrics != nil {
+		c.metrics.evictedManual.Add(float64(len(c.items)))
+		c.metrics.entries.Set(0)
+	}
+	c.items = make(map[K]*list.Element)
+	c.order.Init()
+}
+
+func (c *LRUCache[K, V]) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*lruEntry[K, V]).key)
+
+	if c.metrics != nil {
+		c.metrics.entries.Set(float64(len(c.items)))
+	}
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// gatherCache возвращает значения метрик кэша name из reg по имени метрики
+// (для cache_evictions_total - с суффиксом причины, например cache_evictions_total/ttl)
+func gatherCache(t *testing.T, reg prometheus.Gatherer, name string) map[string]float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, pair := range m.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			if labels["cache_name"] != name {
+				continue
+			}
+
+			key := family.GetName()
+			if reason, ok := labels["reason"]; ok {
+				key += "/" + reason
+			}
+			values[key] = metricValue(m)
+		}
+	}
+	return values
+}
+
+func metricValue(m *dto.Metric) float64 {
+	if m.Counter != nil {
+		return m.GetCounter().GetValue()
+	}
+	return m.GetGauge().GetValue()
+}
+
+func TestCache_HitRateFromCounters(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cache := NewCache[string, int](time.Minute)
+	if err := cache.RegisterMetrics(reg, "test_hit_rate"); err != nil {
+		t.Fatalf("RegisterMetrics: %v", err)
+	}
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+
+	// 6 попаданий и 2 промаха
+	for _, key := range []string{"a", "b", "a", "missing", "b", "a", "other", "b"} {
+		cache.Get(key)
+	}
+
+	values := gatherCache(t, reg, "test_hit_rate")
+	hits, misses := values["cache_hits_total"], values["cache_misses_total"]
+	if hits != 6 || misses != 2 {
+		t.Fatalf("hits = %v, misses = %v, want 6 and 2", hits, misses)
+	}
+	if hitRate := hits / (hits + misses); hitRate != 0.75 {
+		t.Errorf("hit_rate = %v, want 0.75", hitRate)
+	}
+	if values["cache_entries"] != 2 {
+		t.Errorf("cache_entries = %v, want 2", values["cache_entries"])
+	}
+}
+
+func TestCache_EvictionReasons(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cache := NewCache[string, int](10 * time.Millisecond)
+	if err := cache.RegisterMetrics(reg, "test_evictions"); err != nil {
+		t.Fatalf("RegisterMetrics: %v", err)
+	}
+
+	cache.Set("expired", 1)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := cache.Get("expired"); ok {
+		t.Fatal("expired entry returned")
+	}
+
+	cache.Set("deleted", 2)
+	cache.Delete("deleted")
+	cache.Delete("deleted")
+
+	cache.Set("x", 3)
+	cache.Set("y", 4)
+	cache.Clear()
+
+	values := gatherCache(t, reg, "test_evictions")
+	want := map[string]float64{
+		"cache_evictions_total/ttl":    1,
+		"cache_evictions_total/manual": 3,
+		"cache_entries":                0,
+		"cache_misses_total":           1,
+	}
+	for key, v := range want {
+		if values[key] != v {
+			t.Errorf("%s = %v, want %v", key, values[key], v)
+		}
+	}
+}
+
+func TestCache_RegisterMetricsTwice(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	first := NewCache[string, int](time.Minute)
+	second := NewCache[string, int](time.Minute)
+
+	if err := first.RegisterMetrics(reg, "test_first"); err != nil {
+		t.Fatalf("first RegisterMetrics: %v", err)
+	}
+	if err := second.RegisterMetrics(reg, "test_second"); err != nil {
+		t.Fatalf("second RegisterMetrics on the same registry: %v", err)
+	}
+}
+
+// BenchmarkCacheGet сравнивает пропускную способность чтения с метриками и без
+func BenchmarkCacheGet(b *testing.B) {
+	for _, withMetrics := range []bool{false, true} {
+		name := "without_metrics"
+		if withMetrics {
+			name = "with_metrics"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			cache := NewCache[string, int](time.Minute)
+			if withMetrics {
+				if err := cache.RegisterMetrics(prometheus.NewRegistry(), "bench"); err != nil {
+					b.Fatalf("RegisterMetrics: %v", err)
+				}
+			}
+
+			keys := make([]string, 1024)
+			for i := range keys {
+				keys[i] = strconv.Itoa(i)
+				// Каждый второй ключ промахивается
+				if i%2 == 0 {
+					cache.Set(keys[i], i)
+				}
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					cache.Get(keys[i%len(keys)])
+					i++
+				}
+			})
+		})
+	}
+}
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cache := NewLRUCache[string, int](2, time.Minute)
+	if err := cache.RegisterMetrics(reg, "test_lru"); err != nil {
+		t.Fatalf("RegisterMetrics: %v", err)
+	}
+	// Счетчики общие для кэшей с одним именем, в том числе при -count
+	evictedBefore := gatherCache(t, reg, "test_lru")["cache_evictions_total/capacity"]
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	// Обращение к a делает b самой давней записью
+	cache.Get("a")
+	cache.Set("c", 3)
+
+	tests := []struct {
+		key    string
+		want   int
+		wantOK bool
+	}{
+		{key: "a", want: 1, wantOK: true},
+		{key: "b", wantOK: false},
+		{key: "c", want: 3, wantOK: true},
+	}
+	for _, tt := range tests {
+		if got, ok := cache.Get(tt.key); ok != tt.wantOK || got != tt.want {
+			t.Errorf("Get(%q) = %v %v, want %v %v", tt.key, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	values := gatherCache(t, reg, "test_lru")
+	if evicted := values["cache_evictions_total/capacity"] - evictedBefore; evicted != 1 || values["cache_entries"] != 2 {
+		t.Errorf("capacity evictions = %v, entries = %v, want 1 and 2", evicted, values["cache_entries"])
+	}
+}
+
+func TestLRUCache_TTL(t *testing.T) {
+	cache := NewLRUCache[string, int](10, 20*time.Millisecond)
+
+	cache.Set("a", 1)
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("fresh entry missing")
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := cache.Get("a"); ok {
+		t.Error("expired entry returned")
+	}
+
+	// Повторный Set продлевает TTL
+	cache.Set("b", 1)
+	time.Sleep(15 * time.Millisecond)
+	cache.Set("b", 2)
+	time.Sleep(15 * time.Millisecond)
+	if got, ok := cache.Get("b"); !ok || got != 2 {
+		t.Errorf("Get(b) = %v %v, want the refreshed 2", got, ok)
+	}
+}
+
+func TestLRUCache_Concurrent(t *testing.T) {
+	cache := NewLRUCache[int, int](16, time.Minute)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := (g*1000 + i) % 32
+				cache.Set(key, key)
+				if v, ok := cache.Get(key); ok && v != key {
+					t.Errorf("Get(%d) = %d", key, v)
+				}
+				if i%100 == 0 {
+					cache.Clear()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if n := cache.order.Len(); n > 16 || n != len(cache.items) {
+		t.Errorf("list has %d entries, map %d, want equal and at most 16", n, len(cache.items))
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Параметры имитации сбоев для нагрузочного тестирования. Вероятности -
+// проценты от 0 до 100, задаются переменными окружения при запуске
+var (
+	// USERS_ERROR_RATE - ошибка БД в списке пользователей
+	usersErrorRate = 20
+	// ORDERS_ERROR_RATE - ошибка оплаты при создании заказа
+	ordersErrorRate = 15
+	// PRODUCTS_SLOW_RATE - медленный ответ каталога
+	productsSlowRate = 10
+	// ORDERS_MAX_DELAY_MS - верхняя граница задержки обработки заказа
+	ordersMaxDelay = 300 * time.Millisecond
+)
+
+func init() {
+	loadChaosRates()
+}
+
+// loadChaosRates читает параметры имитации сбоев из окружения
+func loadChaosRates() {
+	usersErrorRate = chaosEnvInt("USERS_ERROR_RATE", usersErrorRate, 0, 100)
+	ordersErrorRate = chaosEnvInt("ORDERS_ERROR_RATE", ordersErrorRate, 0, 100)
+	productsSlowRate = chaosEnvInt("PRODUCTS_SLOW_RATE", productsSlowRate, 0, 100)
+
+	maxDelayMs := chaosEnvInt("ORDERS_MAX_DELAY_MS", int(ordersMaxDelay/time.Millisecond), 0, 60000)
+	ordersMaxDelay = time.Duration(maxDelayMs) * time.Millisecond
+}
+
+// chaosEnvInt читает целое из name в диапазоне [min, max]. Логгер еще
+// не создан, поэтому о неверном значении сообщается в stderr
+// и остается значение по умолчанию
+func chaosEnvInt(name string, def, min, max int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < min || v > max {
+		fmt.Fprintf(os.Stderr, "%s: %q is not an integer in [%d, %d], using %d\n", name, raw, min, max, def)
+		return def
+	}
+	return v
+}
+
+// chance возвращает true с вероятностью percent процентов
+func (h *Handler) chance(percent int) bool {
+	return h.Rand.Intn(100) < percent
+}
+
+// orderProcessingDelay - случайная задержка обработки заказа до ordersMaxDelay
+func (h *Handler) orderProcessingDelay() time.Duration {
+	maxMs := int(ordersMaxDelay / time.Millisecond)
+	if maxMs <= 0 {
+		return 0
+	}
+	return time.Duration(h.Rand.Intn(maxMs)) * time.Millisecond
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/logging"
+)
+
+// useChaosEnv задает переменные окружения и перечитывает параметры
+// имитации сбоев, после теста возвращает прежние значения
+func useChaosEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+
+	users, orders, products, delay := usersErrorRate, ordersErrorRate, productsSlowRate, ordersMaxDelay
+	t.Cleanup(func() {
+		usersErrorRate, ordersErrorRate, productsSlowRate, ordersMaxDelay = users, orders, products, delay
+	})
+
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+	loadChaosRates()
+}
+
+// chanceOnlyRand оставляет случайными только решения h.chance,
+// имитация задержек не срабатывает
+type chanceOnlyRand struct {
+	src *rand.Rand
+}
+
+func (r chanceOnlyRand) Intn(n int) int {
+	if n == 100 {
+		return r.src.Intn(n)
+	}
+	return 0
+}
+
+// assertRate проверяет, что failures из n попыток укладываются в пять
+// стандартных отклонений биномиального распределения с вероятностью rate%
+func assertRate(t *testing.T, failures, n, rate int) {
+	t.Helper()
+
+	p := float64(rate) / 100
+	mean := float64(n) * p
+	spread := 5 * math.Sqrt(float64(n)*p*(1-p))
+	if got := float64(failures); got < mean-spread || got > mean+spread {
+		t.Errorf("%d of %d requests failed, want %.0f ± %.0f for rate %d%%", failures, n, mean, spread, rate)
+	}
+}
+
+func TestLoadChaosRates(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantUsers    int
+		wantOrders   int
+		wantProducts int
+		wantDelay    time.Duration
+	}{
+		{name: "defaults", wantUsers: 20, wantOrders: 15, wantProducts: 10, wantDelay: 300 * time.Millisecond},
+		{
+			name:         "configured",
+			env:          map[string]string{"USERS_ERROR_RATE": "5", "ORDERS_ERROR_RATE": "0", "PRODUCTS_SLOW_RATE": "100", "ORDERS_MAX_DELAY_MS": "50"},
+			wantUsers:    5,
+			wantOrders:   0,
+			wantProducts: 100,
+			wantDelay:    50 * time.Millisecond,
+		},
+		{
+			name:         "out of range keeps defaults",
+			env:          map[string]string{"USERS_ERROR_RATE": "101", "ORDERS_ERROR_RATE": "-1", "PRODUCTS_SLOW_RATE": "ten", "ORDERS_MAX_DELAY_MS": "60001"},
+			wantUsers:    20,
+			wantOrders:   15,
+			wantProducts: 10,
+			wantDelay:    300 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useChaosEnv(t, map[string]string{"USERS_ERROR_RATE": "20", "ORDERS_ERROR_RATE": "15", "PRODUCTS_SLOW_RATE": "10", "ORDERS_MAX_DELAY_MS": "300"})
+			useChaosEnv(t, tt.env)
+
+			if usersErrorRate != tt.wantUsers || ordersErrorRate != tt.wantOrders || productsSlowRate != tt.wantProducts || ordersMaxDelay != tt.wantDelay {
+				t.Errorf("rates = users %d, orders %d, products %d, delay %v, want %d, %d, %d, %v",
+					usersErrorRate, ordersErrorRate, productsSlowRate, ordersMaxDelay,
+					tt.wantUsers, tt.wantOrders, tt.wantProducts, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestChaosRates_ObservedErrors(t *testing.T) {
+	const requests = 1000
+
+	tests := []struct {
+		name       string
+		env        map[string]string
+		handler    func(h *Handler) http.HandlerFunc
+		request    func() *http.Request
+		failStatus int
+		rate       int
+	}{
+		{
+			name:       "users 20%",
+			env:        map[string]string{"USERS_ERROR_RATE": "20"},
+			handler:    func(h *Handler) http.HandlerFunc { return h.UsersHandler },
+			request:    func() *http.Request { return httptest.NewRequest(http.MethodGet, "/api/users", nil) },
+			failStatus: http.StatusInternalServerError,
+			rate:       20,
+		},
+		{
+			name:       "users 50%",
+			env:        map[string]string{"USERS_ERROR_RATE": "50"},
+			handler:    func(h *Handler) http.HandlerFunc { return h.UsersHandler },
+			request:    func() *http.Request { return httptest.NewRequest(http.MethodGet, "/api/users", nil) },
+			failStatus: http.StatusInternalServerError,
+			rate:       50,
+		},
+		{
+			name:       "users disabled",
+			env:        map[string]string{"USERS_ERROR_RATE": "0"},
+			handler:    func(h *Handler) http.HandlerFunc { return h.UsersHandler },
+			request:    func() *http.Request { return httptest.NewRequest(http.MethodGet, "/api/users", nil) },
+			failStatus: http.StatusInternalServerError,
+			rate:       0,
+		},
+		{
+			name:       "orders 15%",
+			env:        map[string]string{"ORDERS_ERROR_RATE": "15", "ORDERS_MAX_DELAY_MS": "0"},
+			handler:    func(h *Handler) http.HandlerFunc { return h.OrdersHandler },
+			request:    func() *http.Request { return newOrderRequest("") },
+			failStatus: http.StatusPaymentRequired,
+			rate:       15,
+		},
+		{
+			name:       "orders always fail",
+			env:        map[string]string{"ORDERS_ERROR_RATE": "100", "ORDERS_MAX_DELAY_MS": "0"},
+			handler:    func(h *Handler) http.HandlerFunc { return h.OrdersHandler },
+			request:    func() *http.Request { return newOrderRequest("") },
+			failStatus: http.StatusPaymentRequired,
+			rate:       100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCursorStores(t, 10)
+			useChaosEnv(t, tt.env)
+			rnd := chanceOnlyRand{src: rand.New(rand.NewSource(time.Now().UnixNano()))}
+			handler := tt.handler(New(Config{Logger: logging.NoopLogger{}, Rand: rnd}))
+
+			failures := 0
+			for i := 0; i < requests; i++ {
+				rec := httptest.NewRecorder()
+				handler(rec, tt.request())
+
+				switch rec.Code {
+				case tt.failStatus:
+					failures++
+				case http.StatusOK, http.StatusCreated:
+				default:
+					t.Fatalf("request %d: status = %d: %s", i, rec.Code, rec.Body)
+				}
+			}
+			assertRate(t, failures, requests, tt.rate)
+		})
+	}
+}
+
+// Медленный ответ каталога занимает 2 с, поэтому PRODUCTS_SLOW_RATE
+// проверяется на самом решении об имитации
+func TestChaosRates_ProductsSlowRate(t *testing.T) {
+	const draws = 1000
+
+	for _, rate := range []string{"0", "10", "100"} {
+		t.Run(rate, func(t *testing.T) {
+			useChaosEnv(t, map[string]string{"PRODUCTS_SLOW_RATE": rate})
+			h := New(Config{Logger: logging.NoopLogger{}})
+
+			slow := 0
+			for i := 0; i < draws; i++ {
+				if h.chance(productsSlowRate) {
+					slow++
+				}
+			}
+			assertRate(t, slow, draws, productsSlowRate)
+		})
+	}
+}
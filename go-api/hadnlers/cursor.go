@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/crazy1997/go-api/logging"
+)
+
+// NextCursorHeader - курсор следующей страницы в CSV ответе, где нет конверта
+const NextCursorHeader = "X-Next-Cursor"
+
+const (
+	// Длина подписи курсора: усеченный HMAC-SHA256
+	cursorMACSize = 16
+
+	// Верхняя граница ID в курсоре, чтобы значение поместилось в int на любой платформе
+	maxCursorID = 1<<31 - 1
+)
+
+var errInvalidCursor = errors.New("cursor is invalid")
+
+// CursorMeta описывает страницу при курсорной пагинации
+type CursorMeta struct {
+	Limit int `json:"limit"`
+}
+
+// CursorPageResponse - конверт ответа со списком при курсорной пагинации.
+// NextCursor равен null на последней странице
+type CursorPageResponse struct {
+	Data       interface{} `json:"data"`
+	Meta       CursorMeta  `json:"meta"`
+	NextCursor *string     `json:"next_cursor"`
+}
+
+// encodeCursor упаковывает последний выданный ID с подписью в base64url
+func (h *Handler) encodeCursor(lastID int) string {
+	buf := make([]byte, 8, 8+cursorMACSize)
+	binary.BigEndian.PutUint64(buf, uint64(lastID))
+	buf = append(buf, h.cursorMAC(buf[:8])...)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// decodeCursor проверяет подпись и возвращает ID из курсора.
+// Пустой курсор - начало списка
+func (h *Handler) decodeCursor(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(buf) != 8+cursorMACSize {
+		return 0, errInvalidCursor
+	}
+	if !hmac.Equal(buf[8:], h.cursorMAC(buf[:8])) {
+		return 0, errInvalidCursor
+	}
+
+	id := binary.BigEndian.Uint64(buf[:8])
+	if id > uint64(maxCursorID) {
+		return 0, errInvalidCursor
+	}
+	return int(id), nil
+}
+
+func (h *Handler) cursorMAC(payload []byte) []byte {
+	mac := hmac.New(sha256.New, h.cursorSecret)
+	mac.Write(payload)
+	return mac.Sum(nil)[:cursorMACSize]
+}
+
+// usersByCursor отдает страницу пользователей после ID из курсора
+func (h *Handler) usersByCursor(w http.ResponseWriter, r *http.Request, logger logging.Logger, format string, limit int, includeDeleted bool) {
+	afterID, err := h.decodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, err.Error(), "cursor")
+		return
+	}
+
+	started := time.Now()
+
+	// Лишний пользователь показывает, есть ли следующая страница
+	users, err := usersStore.ListByCursor(r.Context(), afterID, limit+1, includeDeleted)
+	if err != nil {
+		logger.Error("Failed to list users", map[string]interface{}{
+			"error": err,
+		})
+
+		h.Metrics.RecordError("database", "/api/users")
+		WriteError(w, http.StatusInternalServerError, ErrTypeDatabase, "Failed to list users")
+		return
+	}
+
+	var nextCursor *string
+	if len(users) > limit {
+		users = users[:limit]
+		next := h.encodeCursor(users[len(users)-1].ID)
+		nextCursor = &next
+	}
+
+	if format == formatCSV {
+		if nextCursor != nil {
+			w.Header().Set(NextCursorHeader, *nextCursor)
+		}
+		setCSVHeaders(w, "users.csv")
+		cw := csv.NewWriter(w)
+		cw.Write(userCSVHeader)
+		for _, u := range users {
+			cw.Write(userCSVRow(u))
+		}
+		cw.Flush()
+		err = cw.Error()
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(CursorPageResponse{
+			Data:       users,
+			Meta:       CursorMeta{Limit: limit},
+			NextCursor: nextCursor,
+		})
+	}
+	if err != nil {
+		logger.Error("Failed to encode users response", map[string]interface{}{
+			"error": err,
+		})
+		return
+	}
+
+	logger.Info("Users request completed", map[string]interface{}{
+		"user_count":    len(users),
+		"format":        format,
+		"pagination":    "cursor",
+		"response_time": time.Since(started).Milliseconds(),
+	})
+}
+
+// ListOrdersHandler отдает заказы курсорной пагинацией: ?cursor=<token>&limit=20,
+// пустой или отсутствующий cursor - первая страница
+func (h *Handler) ListOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger(r)
+
+	_, limit, field, err := parsePagination(r)
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, err.Error(), field)
+		return
+	}
+
+	afterID, err := h.decodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, err.Error(), "cursor")
+		return
+	}
+
+	// Лишний заказ показывает, есть ли следующая страница
+	orders, err := ordersStore.ListByCursor(r.Context(), afterID, limit+1)
+	if err != nil {
+		logger.Error("Failed to list orders", map[string]interface{}{
+			"error": err,
+		})
+
+		h.Metrics.RecordError("database", "/api/orders")
+		WriteError(w, http.StatusInternalServerError, ErrTypeDatabase, "Failed to list orders")
+		return
+	}
+
+	var nextCursor *string
+	if len(orders) > limit {
+		orders = orders[:limit]
+		next := h.encodeCursor(orders[len(orders)-1].ID)
+		nextCursor = &next
+	}
+
+	logger.Info("Orders list request completed", map[string]interface{}{
+		"order_count": len(orders),
+		"pagination":  "cursor",
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CursorPageResponse{
+		Data:       orders,
+		Meta:       CursorMeta{Limit: limit},
+		NextCursor: nextCursor,
+	})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/crazy1997/go-api/logging"
+)
+
+// useCursorStores подменяет хранилища на count пользователей и заказов с ID 1..count
+func useCursorStores(t *testing.T, count int) {
+	t.Helper()
+
+	users := make([]User, count)
+	orders := newMemoryOrdersStore()
+	for i := range users {
+		users[i] = User{ID: i + 1, Name: fmt.Sprintf("user-%d", i+1), Email: fmt.Sprintf("user-%d@example.com", i+1)}
+		orders.orders[i+1] = Order{ID: i + 1, UserID: 1, Status: OrderStatusCompleted}
+	}
+
+	prevUsers, prevOrders := usersStore, ordersStore
+	SetUsersStore(newMemoryUsersStore(users))
+	SetOrdersStore(orders)
+	t.Cleanup(func() {
+		SetUsersStore(prevUsers)
+		SetOrdersStore(prevOrders)
+	})
+}
+
+func TestCursorPagination_Coverage(t *testing.T) {
+	const total, limit = 100, 10
+	useCursorStores(t, total)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}, CursorSecret: "test-secret"})
+
+	tests := []struct {
+		name    string
+		path    string
+		handler http.HandlerFunc
+	}{
+		{name: "users", path: "/api/users", handler: h.UsersHandler},
+		{name: "orders", path: "/api/orders", handler: h.ListOrdersHandler},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := make(map[int]bool, total)
+			cursor, pages := "", 0
+
+			for {
+				query := url.Values{"cursor": {cursor}, "limit": {fmt.Sprint(limit)}}
+				rec := httptest.NewRecorder()
+				tt.handler(rec, httptest.NewRequest(http.MethodGet, tt.path+"?"+query.Encode(), nil))
+				if rec.Code != http.StatusOK {
+					t.Fatalf("page %d: status = %d, body %s", pages+1, rec.Code, rec.Body)
+				}
+
+				var page struct {
+					Data       []struct{ ID int } `json:"data"`
+					NextCursor *string            `json:"next_cursor"`
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+					t.Fatalf("decode page %d: %v", pages+1, err)
+				}
+				pages++
+
+				if len(page.Data) != limit {
+					t.Errorf("page %d has %d items, want %d", pages, len(page.Data), limit)
+				}
+				for _, item := range page.Data {
+					if seen[item.ID] {
+						t.Errorf("item %d returned twice", item.ID)
+					}
+					seen[item.ID] = true
+				}
+
+				if page.NextCursor == nil {
+					break
+				}
+				if pages > total/limit {
+					t.Fatalf("next_cursor still set after %d pages", pages)
+				}
+				cursor = *page.NextCursor
+			}
+
+			if pages != total/limit {
+				t.Errorf("paginated in %d pages, want %d", pages, total/limit)
+			}
+			for id := 1; id <= total; id++ {
+				if !seen[id] {
+					t.Errorf("item %d was never returned", id)
+				}
+			}
+		})
+	}
+}
+
+func TestCursorPagination_RejectsForeignCursor(t *testing.T) {
+	useCursorStores(t, 20)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}, CursorSecret: "test-secret"})
+	other := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}, CursorSecret: "other-secret"})
+
+	tests := []struct {
+		name   string
+		cursor string
+		want   int
+	}{
+		{name: "own cursor", cursor: h.encodeCursor(10), want: http.StatusOK},
+		{name: "signed with another key", cursor: other.encodeCursor(10), want: http.StatusBadRequest},
+		{name: "not base64", cursor: "!!!", want: http.StatusBadRequest},
+		{name: "truncated", cursor: h.encodeCursor(10)[:8], want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ListOrdersHandler(rec, httptest.NewRequest(http.MethodGet, "/api/orders?cursor="+url.QueryEscape(tt.cursor), nil))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Типы ошибок в ответах API
+const (
+	ErrTypeValidation       = "validation_error"
+	ErrTypeInvalidJSON      = "invalid_json"
+	ErrTypeBodyTooLarge     = "body_too_large"
+	ErrTypeNotFound         = "not_found"
+	ErrTypeConflict         = "conflict"
+	ErrTypeInvalidState     = "invalid_state"
+	ErrTypeMethodNotAllowed = "method_not_allowed"
+	ErrTypeNotAcceptable    = "not_acceptable"
+	ErrTypePayment          = "payment_error"
+	ErrTypeDatabase         = "database_error"
+	ErrTypeInternal         = "internal_error"
+	ErrTypeUnavailable      = "service_unavailable"
+	ErrTypeTimeout          = "timeout"
+	ErrTypeInjectedFault    = "injected_fault"
+	ErrTypeUnauthorized     = "unauthorized"
+	ErrTypeForbidden        = "forbidden"
+	ErrTypeRateLimited      = "rate_limited"
+)
+
+// ErrorCode - числовые коды типов ошибок, стабильные для клиентов
+var ErrorCode = map[string]int{
+	ErrTypeValidation:       1001,
+	ErrTypeInvalidJSON:      1002,
+	ErrTypeBodyTooLarge:     1003,
+	ErrTypeNotFound:         1010,
+	ErrTypeConflict:         1020,
+	ErrTypeInvalidState:     1021,
+	ErrTypeMethodNotAllowed: 1030,
+	ErrTypeNotAcceptable:    1031,
+	ErrTypePayment:          1040,
+	ErrTypeDatabase:         1042,
+	ErrTypeInternal:         1050,
+	ErrTypeUnavailable:      1051,
+	ErrTypeTimeout:          1052,
+	ErrTypeInjectedFault:    1053,
+	ErrTypeUnauthorized:     1060,
+	ErrTypeForbidden:        1061,
+	ErrTypeRateLimited:      1070,
+}
+
+// Код для типов, отсутствующих в ErrorCode
+const unknownErrorCode = 1000
+
+// apiError - тело ошибки {"error": {...}}
+type apiError struct {
+	Type    string                 `json:"type"`
+	Message string                 `json:"message"`
+	Code    int                    `json:"code"`
+	Field   string                 `json:"field,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// WriteError отвечает ошибкой {"error": {"type", "message", "code"}}.
+// Переводы строк в message заменяются пробелами
+func WriteError(w http.ResponseWriter, status int, errType, message string) {
+	writeAPIError(w, status, apiError{Type: errType, Message: message})
+}
+
+// writeFieldError отвечает ошибкой с указанием поля запроса
+func writeFieldError(w http.ResponseWriter, status int, errType, message, field string) {
+	writeAPIError(w, status, apiError{Type: errType, Message: message, Field: field})
+}
+
+// writeErrorDetails отвечает ошибкой с дополнительными сведениями в details
+func writeErrorDetails(w http.ResponseWriter, status int, errType, message string, details map[string]interface{}) {
+	writeAPIError(w, status, apiError{Type: errType, Message: message, Details: details})
+}
+
+// writeDecodeError отвечает на ошибку разбора JSON тела запроса.
+// Превышение лимита MaxBodyBytes дает 413, остальные ошибки - 400
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		WriteError(w, http.StatusRequestEntityTooLarge, ErrTypeBodyTooLarge, "request body too large")
+		return
+	}
+
+	WriteError(w, http.StatusBadRequest, ErrTypeInvalidJSON, "Invalid JSON")
+}
+
+func writeAPIError(w http.ResponseWriter, status int, body apiError) {
+	code, ok := ErrorCode[body.Type]
+	if !ok {
+		code = unknownErrorCode
+	}
+	body.Code = code
+	body.Message = sanitizeErrorMessage(body.Message)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]apiError{"error": body})
+}
+
+var errorMessageReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// sanitizeErrorMessage убирает переводы строк из сообщения
+func sanitizeErrorMessage(message string) string {
+	return errorMessageReplacer.Replace(message)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteError_SpecialCharacters(t *testing.T) {
+	tests := []struct {
+		name        string
+		message     string
+		wantMessage string
+	}{
+		{name: "plain", message: "user not found", wantMessage: "user not found"},
+		{name: "quotes", message: `bad "name" value`, wantMessage: `bad "name" value`},
+		{name: "json injection", message: `"}, "admin": true, "x": {"`, wantMessage: `"}, "admin": true, "x": {"`},
+		{name: "backslashes", message: `C:\temp\new`, wantMessage: `C:\temp\new`},
+		{name: "newlines", message: "line one\nline two\r\nline three\rend", wantMessage: "line one line two line three end"},
+		{name: "html", message: "<script>alert('x')</script> & more", wantMessage: "<script>alert('x')</script> & more"},
+		{name: "control characters", message: "tab\there\x00nul", wantMessage: "tab\there\x00nul"},
+		{name: "unicode", message: "пользователь не найден ✓", wantMessage: "пользователь не найден ✓"},
+		{name: "invalid utf-8", message: "bad \xff byte", wantMessage: "bad \ufffd byte"},
+		{name: "empty", message: "", wantMessage: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			WriteError(rec, http.StatusBadRequest, ErrTypeValidation, tt.message)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			if !json.Valid(rec.Body.Bytes()) {
+				t.Fatalf("body is not valid JSON: %q", rec.Body)
+			}
+
+			var body map[string]map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(body) != 1 || len(body["error"]) != 3 {
+				t.Errorf("body = %v, want only error.type, error.message and error.code", body)
+			}
+			if got := body["error"]["message"]; got != tt.wantMessage {
+				t.Errorf("message = %q, want %q", got, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestWriteError_Codes(t *testing.T) {
+	tests := []struct {
+		errType  string
+		wantCode int
+	}{
+		{errType: ErrTypeValidation, wantCode: 1001},
+		{errType: ErrTypeNotFound, wantCode: 1010},
+		{errType: ErrTypeDatabase, wantCode: 1042},
+		{errType: "unregistered_type", wantCode: unknownErrorCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.errType, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			WriteError(rec, http.StatusInternalServerError, tt.errType, "failed")
+
+			if apiErr := decodeAPIError(t, rec); apiErr.Type != tt.errType || apiErr.Code != tt.wantCode {
+				t.Errorf("error = %+v, want type %s with code %d", apiErr, tt.errType, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestErrorCode_Unique(t *testing.T) {
+	seen := make(map[int]string, len(ErrorCode))
+	for errType, code := range ErrorCode {
+		if other, ok := seen[code]; ok {
+			t.Errorf("code %d is shared by %s and %s", code, errType, other)
+		}
+		seen[code] = errType
+	}
+}
+
+func TestWriteErrorVariants(t *testing.T) {
+	tests := []struct {
+		name        string
+		write       func(w http.ResponseWriter)
+		wantStatus  int
+		wantType    string
+		wantField   string
+		wantDetails bool
+	}{
+		{
+			name: "field error",
+			write: func(w http.ResponseWriter) {
+				writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, "email\nis invalid", "email")
+			},
+			wantStatus: http.StatusBadRequest,
+			wantType:   ErrTypeValidation,
+			wantField:  "email",
+		},
+		{
+			name: "details",
+			write: func(w http.ResponseWriter) {
+				writeErrorDetails(w, http.StatusConflict, ErrTypeConflict, "insufficient stock", map[string]interface{}{"note": "\"quoted\"\n"})
+			},
+			wantStatus:  http.StatusConflict,
+			wantType:    ErrTypeConflict,
+			wantDetails: true,
+		},
+		{
+			name: "body too large",
+			write: func(w http.ResponseWriter) {
+				writeDecodeError(w, fmt.Errorf("decode: %w", &http.MaxBytesError{Limit: 16}))
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantType:   ErrTypeBodyTooLarge,
+		},
+		{
+			name: "invalid json",
+			write: func(w http.ResponseWriter) {
+				writeDecodeError(w, errors.New("unexpected EOF"))
+			},
+			wantStatus: http.StatusBadRequest,
+			wantType:   ErrTypeInvalidJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.write(rec)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if !json.Valid(rec.Body.Bytes()) {
+				t.Fatalf("body is not valid JSON: %q", rec.Body)
+			}
+			apiErr := decodeAPIError(t, rec)
+			if apiErr.Type != tt.wantType || apiErr.Field != tt.wantField || (apiErr.Details != nil) != tt.wantDetails {
+				t.Errorf("error = %+v, want type %s, field %q, details %v", apiErr, tt.wantType, tt.wantField, tt.wantDetails)
+			}
+		})
+	}
+}
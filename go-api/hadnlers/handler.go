@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsRecorder - бизнес-метрики, которые записывают обработчики
+type MetricsRecorder interface {
+	RecordOrder()
+	RecordOrderRevenue(amount float64)
+	RecordOrderStatusTransition(from, to string)
+	RecordOrderCancelledByClient()
+	RecordUserRegistration()
+	RecordUsersBulkCreated(count int)
+	RecordUserDeletion()
+	RecordProductView(productID string)
+	SetProductStock(productID string, level int)
+	RecordError(errorType, endpoint string)
+}
+
+// Config - зависимости и настройки обработчиков
+type Config struct {
+	Logger  logging.Logger
+	Metrics MetricsRecorder
+
+	// Время жизни страниц списка пользователей в кэше, 0 отключает кэш
+	UserCacheTTL time.Duration
+
+	// Порог остатка продукта, ниже которого пишется INFO о малом остатке
+	LowStockThreshold int
+
+	// Источник случайности имитации сбоев, по умолчанию seed от времени
+	Rand Rand
+
+	// Ключ HMAC подписи курсоров пагинации. Общий для всех экземпляров
+	// сервиса, config.Load требует его в CURSOR_SECRET
+	CursorSecret string
+
+	// Признак завершения запуска, до него health отвечает 503 starting.
+	// nil - сервис считается запущенным
+	Started *atomic.Bool
+}
+
+// Handler содержит HTTP обработчики API с внедренными зависимостями
+type Handler struct {
+	Config
+
+	usersCache   *LRUCache[usersCacheKey, usersPage]
+	cursorSecret []byte
+}
+
+// New создает обработчики. Незаданные зависимости заменяются общим
+// логгером, метриками Prometheus и случайным источником от времени
+func New(cfg Config) *Handler {
+	if cfg.Logger == nil {
+		cfg.Logger = logging.GetLogger().AsLogger()
+	}
+	if cfg.Metrics == nil {
+		cfg.Metrics = metrics.Recorder{}
+	}
+
+	cfg.Rand = newRand(cfg.Rand)
+
+	h := &Handler{Config: cfg, cursorSecret: []byte(cfg.CursorSecret)}
+	if cfg.UserCacheTTL > 0 {
+		h.usersCache = NewLRUCache[usersCacheKey, usersPage](usersCacheCapacity, cfg.UserCacheTTL)
+		if err := h.usersCache.RegisterMetrics(prometheus.DefaultRegisterer, "users"); err != nil {
+			panic(err)
+		}
+	}
+	return h
+}
+
+// logger возвращает логгер запроса с request_id и контекстом трассировки
+func (h *Handler) logger(r *http.Request) logging.Logger {
+	if fields := logging.ContextFields(r.Context()); fields != nil {
+		return h.Logger.WithFields(fields)
+	}
+	return h.Logger
+}
+
+// withTags добавляет теги комплаенса, если логгер их поддерживает
+func withTags(logger logging.Logger, tags ...string) logging.Logger {
+	if l, ok := logger.(interface {
+		WithTags(tags ...string) *logging.ELKLogger
+	}); ok {
+		return l.WithTags(tags...).AsLogger()
+	}
+	return logger
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/metrics"
+)
+
+func TestNew_DefaultDependencies(t *testing.T) {
+	// Общий логгер требует InitLogger, поэтому задается явно
+	h := New(Config{Logger: logging.NoopLogger{}})
+
+	if _, ok := h.Metrics.(metrics.Recorder); !ok {
+		t.Errorf("Metrics = %T, want metrics.Recorder", h.Metrics)
+	}
+	if h.Rand == nil {
+		t.Error("Rand is nil, want a time-seeded source")
+	}
+}
+
+// Обработчики пишут логи и метрики только через внедренные зависимости
+func TestHandler_InjectedDependencies(t *testing.T) {
+	tests := []struct {
+		name       string
+		rand       Rand
+		handle     func(h *Handler, w http.ResponseWriter)
+		wantStatus int
+		wantLog    string
+		wantErrors []string
+		check      func(t *testing.T, recorder *countingRecorder)
+	}{
+		{
+			name: "order created",
+			rand: fixedRand{},
+			handle: func(h *Handler, w http.ResponseWriter) {
+				h.OrdersHandler(w, newOrderRequest(""))
+			},
+			wantStatus: http.StatusCreated,
+			wantLog:    "Processing order",
+			check: func(t *testing.T, recorder *countingRecorder) {
+				if got := recorder.orders.Load(); got != 1 {
+					t.Errorf("orders recorded = %d, want 1", got)
+				}
+			},
+		},
+		{
+			name: "payment failure",
+			rand: failingRand{},
+			handle: func(h *Handler, w http.ResponseWriter) {
+				h.OrdersHandler(w, newOrderRequest(""))
+			},
+			wantStatus: http.StatusPaymentRequired,
+			wantLog:    "Processing order",
+			wantErrors: []string{"payment"},
+			check: func(t *testing.T, recorder *countingRecorder) {
+				if got := recorder.orders.Load(); got != 0 {
+					t.Errorf("orders recorded = %d, want 0 for a failed payment", got)
+				}
+			},
+		},
+		{
+			name: "users database failure",
+			rand: failingRand{},
+			handle: func(h *Handler, w http.ResponseWriter) {
+				h.UsersHandler(w, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantLog:    "Processing users request",
+			wantErrors: []string{"database"},
+		},
+		{
+			name: "user registered",
+			rand: fixedRand{},
+			handle: func(h *Handler, w http.ResponseWriter) {
+				req := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(`{"name": "Alice", "email": "alice@example.com"}`))
+				h.CreateUserHandler(w, req)
+			},
+			wantStatus: http.StatusCreated,
+			wantLog:    "User created",
+			check: func(t *testing.T, recorder *countingRecorder) {
+				if got := recorder.registrations.Load(); got != 1 {
+					t.Errorf("registrations recorded = %d, want 1", got)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCursorStores(t, 3)
+
+			// Глобальный логгер не должен получать записи обработчиков
+			global := logging.NewBufferedLogger()
+			logging.SetDefault(global)
+			defer logging.SetDefault(nil)
+
+			logger := logging.NewBufferedLogger()
+			recorder := &countingRecorder{}
+			h := New(Config{Logger: logger, Metrics: recorder, Rand: tt.rand})
+
+			rec := httptest.NewRecorder()
+			tt.handle(h, rec)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if got := recorder.errors(); !reflect.DeepEqual(got, tt.wantErrors) {
+				t.Errorf("errors recorded = %v, want %v", got, tt.wantErrors)
+			}
+			if tt.check != nil {
+				tt.check(t, recorder)
+			}
+
+			logged := false
+			for _, entry := range logger.Entries() {
+				if entry.Message == tt.wantLog {
+					logged = true
+				}
+			}
+			if !logged {
+				t.Errorf("injected logger has no %q entry", tt.wantLog)
+			}
+			if entries := global.Entries(); len(entries) != 0 {
+				t.Errorf("global logger received %d entries, want 0", len(entries))
+			}
+		})
+	}
+}
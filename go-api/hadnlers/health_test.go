@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/metrics"
+)
+
+// getHealth выполняет GET /api/health и возвращает статус и тело ответа
+func getHealth(t *testing.T, h *Handler) (int, map[string]interface{}) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.HealthHandler(rec, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode health: %v", err)
+	}
+	return rec.Code, body
+}
+
+func TestHealthHandler_BuildInfo(t *testing.T) {
+	prev := buildInfo
+	t.Cleanup(func() { SetBuildInfo(prev) })
+	SetBuildInfo(metrics.BuildInfo{Version: "v1.4.2", Commit: "3f2c1ab", BuildDate: "2026-10-01T12:00:00Z"})
+
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+	code, body := getHealth(t, h)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+
+	want := map[string]string{
+		"status":     "healthy",
+		"version":    "v1.4.2",
+		"commit":     "3f2c1ab",
+		"build_date": "2026-10-01T12:00:00Z",
+		"service":    "go-api",
+	}
+	for key, value := range want {
+		if body[key] != value {
+			t.Errorf("%s = %v, want %q", key, body[key], value)
+		}
+	}
+}
+
+func TestHealthHandler_Startup(t *testing.T) {
+	tests := []struct {
+		name       string
+		started    func() *atomic.Bool
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "no startup flag", started: func() *atomic.Bool { return nil }, wantStatus: http.StatusOK, wantBody: "healthy"},
+		{name: "starting", started: func() *atomic.Bool { return new(atomic.Bool) }, wantStatus: http.StatusServiceUnavailable, wantBody: "starting"},
+		{
+			name: "started",
+			started: func() *atomic.Bool {
+				started := new(atomic.Bool)
+				started.Store(true)
+				return started
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "healthy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}, Started: tt.started()})
+			code, body := getHealth(t, h)
+			if code != tt.wantStatus || body["status"] != tt.wantBody {
+				t.Errorf("health = %d %v, want %d %q", code, body["status"], tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+// После завершения запуска тот же обработчик отвечает 200
+func TestHealthHandler_StartupTransition(t *testing.T) {
+	var started atomic.Bool
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}, Started: &started})
+
+	if code, body := getHealth(t, h); code != http.StatusServiceUnavailable || body["status"] != "starting" {
+		t.Fatalf("health before startup = %d %v, want 503 starting", code, body["status"])
+	}
+	started.Store(true)
+	if code, body := getHealth(t, h); code != http.StatusOK || body["status"] != "healthy" {
+		t.Errorf("health after startup = %d %v, want 200 healthy", code, body["status"])
+	}
+}
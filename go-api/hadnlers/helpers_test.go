@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/crazy1997/go-api/metrics"
+)
+
+// countingRecorder считает заказы и пользователей поверх метрик Prometheus
+type countingRecorder struct {
+	metrics.Recorder
+	orders        atomic.Int64
+	cancelled     atomic.Int64
+	registrations atomic.Int64
+	deletions     atomic.Int64
+	transitions   atomic.Int64
+	bulkCreated   atomic.Int64
+
+	mu          sync.Mutex
+	errorTypes  []string
+	stockLevels map[string]int
+}
+
+func (r *countingRecorder) RecordOrder() {
+	r.orders.Add(1)
+	r.Recorder.RecordOrder()
+}
+
+func (r *countingRecorder) RecordOrderCancelledByClient() {
+	r.cancelled.Add(1)
+	r.Recorder.RecordOrderCancelledByClient()
+}
+
+func (r *countingRecorder) RecordOrderStatusTransition(from, to string) {
+	r.transitions.Add(1)
+	r.Recorder.RecordOrderStatusTransition(from, to)
+}
+
+func (r *countingRecorder) RecordUsersBulkCreated(count int) {
+	r.bulkCreated.Add(int64(count))
+	r.Recorder.RecordUsersBulkCreated(count)
+}
+
+func (r *countingRecorder) RecordUserRegistration() {
+	r.registrations.Add(1)
+	r.Recorder.RecordUserRegistration()
+}
+
+func (r *countingRecorder) RecordUserDeletion() {
+	r.deletions.Add(1)
+	r.Recorder.RecordUserDeletion()
+}
+
+func (r *countingRecorder) RecordError(errorType, endpoint string) {
+	r.mu.Lock()
+	r.errorTypes = append(r.errorTypes, errorType)
+	r.mu.Unlock()
+	r.Recorder.RecordError(errorType, endpoint)
+}
+
+func (r *countingRecorder) SetProductStock(productID string, level int) {
+	r.mu.Lock()
+	if r.stockLevels == nil {
+		r.stockLevels = make(map[string]int)
+	}
+	r.stockLevels[productID] = level
+	r.mu.Unlock()
+	r.Recorder.SetProductStock(productID, level)
+}
+
+// stockLevel возвращает последний записанный остаток продукта
+func (r *countingRecorder) stockLevel(productID string) (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	level, ok := r.stockLevels[productID]
+	return level, ok
+}
+
+// errors возвращает типы записанных ошибок в порядке записи
+func (r *countingRecorder) errors() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.errorTypes...)
+}
+
+// fixedRand возвращает n-1: имитация сбоев никогда не срабатывает,
+// задержка обработки максимальна
+type fixedRand struct{}
+
+func (fixedRand) Intn(n int) int { return n - 1 }
+
+// failingRand возвращает 0: имитация сбоев срабатывает всегда
+type failingRand struct{}
+
+func (failingRand) Intn(n int) int { return 0 }
+
+const orderBody = `{"user_id": 1, "items": [{"product_id": 1, "quantity": 2}]}`
+
+func newOrderRequest(key string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/orders", strings.NewReader(orderBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Idempotency-Key", key)
+	return req
+}
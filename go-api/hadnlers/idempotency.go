@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Сколько хранится ответ для ключа идемпотентности и как часто
+// удаляются истекшие ответы
+const (
+	idempotencyTTL           = 24 * time.Hour
+	idempotencySweepInterval = time.Minute
+)
+
+// recordedResponse - сохраненный ответ для повторной отдачи
+type recordedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// responseRecorder буферизует ответ обработчика
+type responseRecorder struct {
+	header    http.Header
+	status    int
+	body      bytes.Buffer
+	retryable bool
+}
+
+// markRetryable помечает ответ временной ошибкой (например, имитацией
+// сбоя оплаты): он не сохраняется для ключа идемпотентности, и повтор
+// с тем же ключом выполнит запрос заново
+func markRetryable(w http.ResponseWriter) {
+	if rec, ok := w.(*responseRecorder); ok {
+		rec.retryable = true
+	}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+type idempotencyCall struct {
+	done      chan struct{}
+	resp      *recordedResponse
+	expiresAt time.Time
+}
+
+// idempotencyStore гарантирует, что запросы с одним ключом обрабатываются
+// один раз: проверка и резервирование ключа выполняются под одной блокировкой,
+// конкурентные дубликаты ждут первый запрос и получают его ответ.
+// Истекшие ответы удаляются не реже раза в idempotencySweepInterval
+// при обращениях к хранилищу, поэтому уникальные ключи не копятся
+type idempotencyStore struct {
+	mu        sync.Mutex
+	calls     map[string]*idempotencyCall
+	lastSweep time.Time
+}
+
+func newIdempotencyStore() *idempotencyStore {
+	return &idempotencyStore{calls: make(map[string]*idempotencyCall), lastSweep: time.Now()}
+}
+
+// sweepLocked удаляет истекшие ответы. Вызывается под s.mu
+func (s *idempotencyStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < idempotencySweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, call := range s.calls {
+		if call.resp != nil && now.After(call.expiresAt) {
+			delete(s.calls, key)
+		}
+	}
+}
+
+func (s *idempotencyStore) serve(key string, w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+	s.mu.Lock()
+	s.sweepLocked(time.Now())
+	call, ok := s.calls[key]
+	if ok && call.resp != nil && time.Now().After(call.expiresAt) {
+		delete(s.calls, key)
+		ok = false
+	}
+	if !ok {
+		call = &idempotencyCall{done: make(chan struct{})}
+		s.calls[key] = call
+	}
+	s.mu.Unlock()
+
+	if ok {
+		select {
+		case <-call.done:
+		case <-r.Context().Done():
+			return
+		}
+
+		if call.resp == nil {
+			// Первый запрос не завершился, ключ освобожден для повтора
+			WriteError(w, http.StatusConflict, ErrTypeConflict, "Request with this idempotency key failed, retry")
+			return
+		}
+
+		writeRecorded(w, call.resp, true)
+		return
+	}
+
+	rec := &responseRecorder{header: make(http.Header)}
+	handler(rec, r)
+
+	s.mu.Lock()
+	// Ответы с ошибкой сервера, временные ошибки и прерванные запросы
+	// не сохраняем, повтор с тем же ключом выполнится заново
+	if rec.status != 0 && rec.status < http.StatusInternalServerError && !rec.retryable {
+		call.resp = &recordedResponse{status: rec.status, header: rec.header, body: rec.body.Bytes()}
+		call.expiresAt = time.Now().Add(idempotencyTTL)
+	} else {
+		delete(s.calls, key)
+	}
+	close(call.done)
+	s.mu.Unlock()
+
+	if rec.status != 0 {
+		writeRecorded(w, &recordedResponse{status: rec.status, header: rec.header, body: rec.body.Bytes()}, false)
+	}
+}
+
+func writeRecorded(w http.ResponseWriter, resp *recordedResponse, replayed bool) {
+	for k, v := range resp.header {
+		w.Header()[k] = append([]string(nil), v...)
+	}
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/logging"
+)
+
+func TestOrdersHandler_IdempotencyKeyConcurrent(t *testing.T) {
+	t.Parallel()
+
+	recorder := &countingRecorder{}
+	h := New(Config{Logger: logging.NoopLogger{}, Metrics: recorder, Rand: fixedRand{}})
+
+	const clients = 20
+	var (
+		wg       sync.WaitGroup
+		start    = make(chan struct{})
+		statuses = make([]int, clients)
+		replayed atomic.Int64
+		orderIDs sync.Map
+	)
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+
+			rec := httptest.NewRecorder()
+			h.OrdersHandler(rec, newOrderRequest("concurrent-key"))
+
+			statuses[i] = rec.Code
+			if rec.Header().Get("Idempotent-Replayed") == "true" {
+				replayed.Add(1)
+			}
+			orderIDs.Store(rec.Body.String(), true)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for i, status := range statuses {
+		if status != http.StatusCreated {
+			t.Errorf("client %d got status %d, want 201", i, status)
+		}
+	}
+	if got := recorder.orders.Load(); got != 1 {
+		t.Errorf("orders processed = %d, want exactly 1", got)
+	}
+	if got := replayed.Load(); got != clients-1 {
+		t.Errorf("replayed responses = %d, want %d", got, clients-1)
+	}
+
+	bodies := 0
+	orderIDs.Range(func(_, _ interface{}) bool {
+		bodies++
+		return true
+	})
+	if bodies != 1 {
+		t.Errorf("clients received %d different bodies, want the same response for all", bodies)
+	}
+}
+
+// Имитированный сбой оплаты не сохраняется: повтор с тем же ключом
+// выполняет заказ заново и может пройти
+func TestOrdersHandler_IdempotencyRetryAfterPaymentFailure(t *testing.T) {
+	t.Parallel()
+
+	recorder := &countingRecorder{}
+	failing := New(Config{Logger: logging.NoopLogger{}, Metrics: recorder, Rand: failingRand{}})
+	healthy := New(Config{Logger: logging.NoopLogger{}, Metrics: recorder, Rand: fixedRand{}})
+
+	rec := httptest.NewRecorder()
+	failing.OrdersHandler(rec, newOrderRequest("retry-key"))
+	if rec.Code != http.StatusPaymentRequired {
+		t.Fatalf("first attempt status = %d, want 402", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	healthy.OrdersHandler(rec, newOrderRequest("retry-key"))
+	if rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("retry status = %d replayed=%q, want a fresh 201", rec.Code, rec.Header().Get("Idempotent-Replayed"))
+	}
+	if got := recorder.orders.Load(); got != 1 {
+		t.Errorf("orders processed = %d, want 1", got)
+	}
+}
+
+func TestIdempotencyStore_CachesOnlyFinalResponses(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		wantCalls int
+	}{
+		{
+			name:      "success is replayed",
+			handler:   func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusCreated) },
+			wantCalls: 1,
+		},
+		{
+			name:      "client error is replayed",
+			handler:   func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusUnprocessableEntity) },
+			wantCalls: 1,
+		},
+		{
+			name:      "server error is not cached",
+			handler:   func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) },
+			wantCalls: 2,
+		},
+		{
+			name: "retryable error is not cached",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				markRetryable(w)
+				w.WriteHeader(http.StatusPaymentRequired)
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newIdempotencyStore()
+			calls := 0
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				tt.handler(w, r)
+			}
+
+			for i := 0; i < 2; i++ {
+				store.serve("key", httptest.NewRecorder(), newOrderRequest("key"), handler)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyStore_SweepsExpiredResponses(t *testing.T) {
+	store := newIdempotencyStore()
+	created := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusCreated) }
+
+	for _, key := range []string{"a", "b", "c"} {
+		store.serve(key, httptest.NewRecorder(), newOrderRequest(key), created)
+	}
+
+	// Ответы a и b истекли, c еще действует
+	store.mu.Lock()
+	store.calls["a"].expiresAt = time.Now().Add(-time.Second)
+	store.calls["b"].expiresAt = time.Now().Add(-time.Second)
+	store.lastSweep = time.Now().Add(-2 * idempotencySweepInterval)
+	store.mu.Unlock()
+
+	store.serve("d", httptest.NewRecorder(), newOrderRequest("d"), created)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.calls) != 2 {
+		t.Errorf("store holds %d keys after sweep, want 2 (c and d)", len(store.calls))
+	}
+	for _, key := range []string{"c", "d"} {
+		if _, ok := store.calls[key]; !ok {
+			t.Errorf("key %q was swept", key)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetricsHandler_SummaryKeys(t *testing.T) {
+	gatherer := prometheus.DefaultGatherer
+	t.Cleanup(func() { prometheus.DefaultGatherer = gatherer })
+
+	reg := prometheus.NewRegistry()
+	requests := prometheus.NewCounter(prometheus.CounterOpts{Name: "http_requests_total", Help: "test"})
+	errorsTotal := prometheus.NewCounter(prometheus.CounterOpts{Name: "errors_total", Help: "test"})
+	reg.MustRegister(requests, errorsTotal)
+	prometheus.DefaultGatherer = reg
+
+	requests.Add(40)
+	errorsTotal.Add(2)
+
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+	rec := httptest.NewRecorder()
+	h.MetricsHandler(rec, httptest.NewRequest(http.MethodGet, "/api/metrics/info", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var body map[string]float64
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	for _, key := range []string{"orders_total", "requests_total", "errors_total", "error_rate_pct", "uptime_seconds"} {
+		if _, ok := body[key]; !ok {
+			t.Errorf("response has no %q key: %v", key, body)
+		}
+	}
+	if body["error_rate_pct"] != 5 {
+		t.Errorf("error_rate_pct = %v, want 5", body["error_rate_pct"])
+	}
+}
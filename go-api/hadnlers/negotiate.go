@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Форматы ответа списков
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// negotiateFormat выбирает формат ответа по заголовку Accept.
+// text/csv имеет приоритет, JSON - формат по умолчанию.
+// ok=false, если клиент принимает только неподдерживаемые типы
+func negotiateFormat(r *http.Request) (format string, ok bool) {
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return formatJSON, true
+	}
+
+	acceptsJSON := false
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case "text/csv":
+			return formatCSV, true
+		case "application/json", "application/*", "*/*":
+			acceptsJSON = true
+		}
+	}
+
+	if acceptsJSON {
+		return formatJSON, true
+	}
+	return "", false
+}
+
+// writeNotAcceptable отвечает 406 со списком поддерживаемых типов
+func writeNotAcceptable(w http.ResponseWriter) {
+	WriteError(w, http.StatusNotAcceptable, ErrTypeNotAcceptable, "Not acceptable, supported types: application/json, text/csv")
+}
+
+// Колонки CSV в порядке полей структур
+var (
+	productCSVHeader = []string{"id", "name", "price", "category", "stock_level", "rating"}
+	userCSVHeader    = []string{"id", "name", "email", "created_at", "deleted_at"}
+)
+
+func productCSVRow(p Product) []string {
+	return []string{
+		strconv.Itoa(p.ID),
+		p.Name,
+		strconv.FormatFloat(p.Price, 'f', -1, 64),
+		p.Category,
+		strconv.Itoa(p.StockLevel),
+		strconv.FormatFloat(p.Rating, 'f', -1, 64),
+	}
+}
+
+func userCSVRow(u User) []string {
+	deletedAt := ""
+	if u.DeletedAt != nil {
+		deletedAt = u.DeletedAt.Format(time.RFC3339)
+	}
+	return []string{strconv.Itoa(u.ID), u.Name, u.Email, u.CreatedAt, deletedAt}
+}
+
+// encodeProductsCSV сериализует продукты в CSV с заголовком
+func encodeProductsCSV(products []Product) ([]byte, error) {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+
+	cw.Write(productCSVHeader)
+	for _, p := range products {
+		cw.Write(productCSVRow(p))
+	}
+	cw.Flush()
+
+	return buf.Bytes(), cw.Error()
+}
+
+// setCSVHeaders выставляет заголовки CSV выгрузки файла filename
+func setCSVHeaders(w http.ResponseWriter, filename string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/crazy1997/go-api/logging"
+)
+
+// jsonFieldNames возвращает имена JSON полей структуры v в порядке объявления
+func jsonFieldNames(v interface{}) []string {
+	typ := reflect.TypeOf(v)
+	names := make([]string, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestCSVHeader_MatchesStructFields(t *testing.T) {
+	if got := jsonFieldNames(Product{}); !reflect.DeepEqual(productCSVHeader, got) {
+		t.Errorf("products CSV header = %v, want Product fields %v", productCSVHeader, got)
+	}
+	if got := jsonFieldNames(User{}); !reflect.DeepEqual(userCSVHeader, got) {
+		t.Errorf("users CSV header = %v, want User fields %v", userCSVHeader, got)
+	}
+}
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+		wantOK bool
+	}{
+		{accept: "", want: formatJSON, wantOK: true},
+		{accept: "application/json", want: formatJSON, wantOK: true},
+		{accept: "*/*", want: formatJSON, wantOK: true},
+		{accept: "application/*", want: formatJSON, wantOK: true},
+		{accept: "text/csv", want: formatCSV, wantOK: true},
+		{accept: "application/json, text/csv;q=0.5", want: formatCSV, wantOK: true},
+		{accept: "text/html, */*;q=0.8", want: formatJSON, wantOK: true},
+		{accept: "application/xml", wantOK: false},
+		{accept: "text/html, image/png", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/products", nil)
+			req.Header.Set("Accept", tt.accept)
+
+			got, ok := negotiateFormat(req)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("negotiateFormat(%q) = %q %v, want %q %v", tt.accept, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestListHandlers_ContentNegotiation(t *testing.T) {
+	useProductsStore(t)
+	useCursorStores(t, 2)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	tests := []struct {
+		name            string
+		handler         http.HandlerFunc
+		path            string
+		accept          string
+		wantStatus      int
+		wantType        string
+		wantDisposition string
+		wantHeader      []string
+		wantFirstRow    []string
+	}{
+		{
+			name:            "products csv",
+			handler:         h.ProductsHandler,
+			path:            "/api/products?sort_by=price",
+			accept:          "text/csv",
+			wantStatus:      http.StatusOK,
+			wantType:        "text/csv; charset=utf-8",
+			wantDisposition: `attachment; filename="products.csv"`,
+			wantHeader:      []string{"id", "name", "price", "category", "stock_level", "rating"},
+			wantFirstRow:    []string{"5", "USB Cable", "9.5", "accessories", "500", "3.9"},
+		},
+		{
+			name:            "users csv",
+			handler:         h.UsersHandler,
+			path:            "/api/users",
+			accept:          "text/csv",
+			wantStatus:      http.StatusOK,
+			wantType:        "text/csv; charset=utf-8",
+			wantDisposition: `attachment; filename="users.csv"`,
+			wantHeader:      []string{"id", "name", "email", "created_at", "deleted_at"},
+			wantFirstRow:    []string{"1", "user-1", "user-1@example.com", "", ""},
+		},
+		{name: "products json by default", handler: h.ProductsHandler, path: "/api/products", wantStatus: http.StatusOK, wantType: "application/json"},
+		{name: "users json by default", handler: h.UsersHandler, path: "/api/users", wantStatus: http.StatusOK, wantType: "application/json"},
+		{name: "products unsupported type", handler: h.ProductsHandler, path: "/api/products", accept: "application/xml", wantStatus: http.StatusNotAcceptable, wantType: "application/json"},
+		{name: "users unsupported type", handler: h.UsersHandler, path: "/api/users", accept: "application/xml", wantStatus: http.StatusNotAcceptable, wantType: "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			tt.handler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if got := rec.Header().Get("Content-Disposition"); got != tt.wantDisposition {
+				t.Errorf("Content-Disposition = %q, want %q", got, tt.wantDisposition)
+			}
+
+			switch {
+			case tt.wantStatus == http.StatusNotAcceptable:
+				if apiErr := decodeAPIError(t, rec); apiErr.Type != ErrTypeNotAcceptable {
+					t.Errorf("error type = %q, want %q", apiErr.Type, ErrTypeNotAcceptable)
+				}
+			case tt.wantHeader != nil:
+				rows, err := csv.NewReader(rec.Body).ReadAll()
+				if err != nil {
+					t.Fatalf("parse csv: %v", err)
+				}
+				if len(rows) < 2 {
+					t.Fatalf("csv has %d rows, want header and data", len(rows))
+				}
+				if !reflect.DeepEqual(rows[0], tt.wantHeader) {
+					t.Errorf("header = %v, want %v", rows[0], tt.wantHeader)
+				}
+				if !reflect.DeepEqual(rows[1], tt.wantFirstRow) {
+					t.Errorf("first row = %v, want %v", rows[1], tt.wantFirstRow)
+				}
+			default:
+				if !json.Valid(rec.Body.Bytes()) {
+					t.Errorf("body is not JSON: %s", rec.Body)
+				}
+			}
+		})
+	}
+}
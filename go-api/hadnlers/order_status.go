@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Статусы заказа
+const (
+	OrderStatusCompleted = "completed"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+)
+
+// orderTransitions - допустимые переходы статусов заказа
+var orderTransitions = map[string][]string{
+	OrderStatusCompleted: {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:   {OrderStatusDelivered},
+	OrderStatusDelivered: {},
+	OrderStatusCancelled: {},
+}
+
+func canTransition(from, to string) bool {
+	for _, allowed := range orderTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateOrderStatusHandler переводит заказ в новый статус
+func (h *Handler) UpdateOrderStatusHandler(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger(r)
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, "id must be an integer", "id")
+		return
+	}
+
+	var input struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Status == "" {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, "status is required", "status")
+		return
+	}
+
+	order, err := ordersStore.Get(r.Context(), id)
+	if err == nil {
+		if !canTransition(order.Status, input.Status) {
+			logger.Warn("Invalid order status transition", map[string]interface{}{
+				"order_id":    id,
+				"from_status": order.Status,
+				"to_status":   input.Status,
+			})
+
+			writeErrorDetails(w, http.StatusUnprocessableEntity, ErrTypeInvalidState, "invalid status transition", map[string]interface{}{
+				"from":    order.Status,
+				"to":      input.Status,
+				"allowed": orderTransitions[order.Status],
+			})
+			return
+		}
+
+		err = ordersStore.UpdateStatus(r.Context(), id, order.Status, input.Status)
+	}
+
+	switch {
+	case errors.Is(err, ErrOrderNotFound):
+		WriteError(w, http.StatusNotFound, ErrTypeNotFound, "order not found")
+		return
+	case errors.Is(err, ErrOrderStatusChanged):
+		WriteError(w, http.StatusConflict, ErrTypeConflict, "order status changed, retry the request")
+		return
+	case err != nil:
+		logger.Error("Failed to update order status", map[string]interface{}{
+			"order_id": id,
+			"error":    err,
+		})
+
+		h.Metrics.RecordError("database", "/api/orders/{id}/status")
+		WriteError(w, http.StatusInternalServerError, ErrTypeInternal, "Failed to update order status")
+		return
+	}
+
+	h.Metrics.RecordOrderStatusTransition(order.Status, input.Status)
+
+	logger.Info("Order status changed", map[string]interface{}{
+		"order_id":    id,
+		"from_status": order.Status,
+		"to_status":   input.Status,
+	})
+
+	order.Status = input.Status
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
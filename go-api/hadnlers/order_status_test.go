@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/gorilla/mux"
+)
+
+func TestUpdateOrderStatusHandler(t *testing.T) {
+	useCursorStores(t, 0)
+	for id, status := range map[int]string{1: OrderStatusCompleted, 2: OrderStatusCompleted, 3: OrderStatusDelivered} {
+		if err := ordersStore.Save(context.Background(), Order{ID: id, UserID: 1, Status: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger := logging.NewBufferedLogger()
+	recorder := &countingRecorder{}
+	h := New(Config{Logger: logger, Metrics: recorder, Rand: fixedRand{}})
+
+	// Подтесты выполняются по порядку: заказ 1 проходит completed -> shipped -> delivered
+	tests := []struct {
+		name        string
+		id          string
+		body        string
+		wantStatus  int
+		wantType    string
+		wantAllowed []interface{}
+	}{
+		{name: "completed to shipped", id: "1", body: `{"status": "shipped"}`, wantStatus: http.StatusOK},
+		{name: "shipped to delivered", id: "1", body: `{"status": "delivered"}`, wantStatus: http.StatusOK},
+		{name: "completed to cancelled", id: "2", body: `{"status": "cancelled"}`, wantStatus: http.StatusOK},
+		{name: "delivered is final", id: "1", body: `{"status": "shipped"}`, wantStatus: http.StatusUnprocessableEntity, wantType: ErrTypeInvalidState, wantAllowed: []interface{}{}},
+		{name: "delivered back to completed", id: "3", body: `{"status": "completed"}`, wantStatus: http.StatusUnprocessableEntity, wantType: ErrTypeInvalidState, wantAllowed: []interface{}{}},
+		{name: "cancelled is final", id: "2", body: `{"status": "lost"}`, wantStatus: http.StatusUnprocessableEntity, wantType: ErrTypeInvalidState, wantAllowed: []interface{}{}},
+		{name: "missing order", id: "99", body: `{"status": "shipped"}`, wantStatus: http.StatusNotFound, wantType: ErrTypeNotFound},
+		{name: "missing status", id: "1", body: `{}`, wantStatus: http.StatusBadRequest, wantType: ErrTypeValidation},
+		{name: "non-integer id", id: "one", body: `{"status": "shipped"}`, wantStatus: http.StatusBadRequest, wantType: ErrTypeValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/orders/"+tt.id+"/status", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			h.UpdateOrderStatusHandler(rec, mux.SetURLVars(req, map[string]string{"id": tt.id}))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantType == "" {
+				var order Order
+				if err := json.NewDecoder(rec.Body).Decode(&order); err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				var input struct{ Status string }
+				json.Unmarshal([]byte(tt.body), &input)
+				if order.Status != input.Status {
+					t.Errorf("order status = %q, want %q", order.Status, input.Status)
+				}
+				return
+			}
+
+			apiErr := decodeAPIError(t, rec)
+			if apiErr.Type != tt.wantType {
+				t.Errorf("error type = %q, want %q", apiErr.Type, tt.wantType)
+			}
+			if tt.wantAllowed != nil {
+				allowed, ok := apiErr.Details["allowed"].([]interface{})
+				if !ok || !reflect.DeepEqual(allowed, tt.wantAllowed) || apiErr.Details["from"] == nil || apiErr.Details["to"] == nil {
+					t.Errorf("details = %v, want from, to and allowed %v", apiErr.Details, tt.wantAllowed)
+				}
+			}
+		})
+	}
+
+	if n := recorder.transitions.Load(); n != 3 {
+		t.Errorf("recorded %d transitions, want 3", n)
+	}
+	var changes []string
+	for _, entry := range logger.Entries() {
+		if entry.Message == "Order status changed" {
+			changes = append(changes, entry.Fields["from_status"].(string)+"->"+entry.Fields["to_status"].(string))
+		}
+	}
+	if want := []string{"completed->shipped", "shipped->delivered", "completed->cancelled"}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("logged transitions %v, want %v", changes, want)
+	}
+}
+
+func TestUpdateOrderStatusHandler_AllowedTransitionsInBody(t *testing.T) {
+	useCursorStores(t, 0)
+	ordersStore.Save(context.Background(), Order{ID: 1, UserID: 1, Status: OrderStatusCompleted})
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	req := httptest.NewRequest(http.MethodPut, "/api/orders/1/status", strings.NewReader(`{"status": "delivered"}`))
+	rec := httptest.NewRecorder()
+	h.UpdateOrderStatusHandler(rec, mux.SetURLVars(req, map[string]string{"id": "1"}))
+
+	want := `{"error":{"type":"invalid_state","message":"invalid status transition","code":1021,"details":{"allowed":["shipped","cancelled"],"from":"completed","to":"delivered"}}}`
+	if rec.Code != http.StatusUnprocessableEntity || strings.TrimSpace(rec.Body.String()) != want {
+		t.Errorf("got %d %s, want 422 %s", rec.Code, rec.Body, want)
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Ошибки хранилища заказов
+var (
+	ErrOrderNotFound = errors.New("order not found")
+	// Статус заказа изменился между чтением и обновлением
+	ErrOrderStatusChanged = errors.New("order status changed concurrently")
+)
+
+// OrdersStore - хранилище заказов
+type OrdersStore interface {
+	// Save сохраняет заказ, заменяя существующий с тем же ID
+	Save(ctx context.Context, order Order) error
+
+	// Get возвращает заказ по ID или ErrOrderNotFound
+	Get(ctx context.Context, id int) (Order, error)
+
+	// ListByCursor возвращает до limit заказов с ID больше afterID
+	// по возрастанию ID (WHERE id > afterID ORDER BY id LIMIT limit)
+	ListByCursor(ctx context.Context, afterID, limit int) ([]Order, error)
+
+	// UpdateStatus меняет статус заказа с from на to.
+	// Если текущий статус уже не from, возвращает ErrOrderStatusChanged
+	UpdateStatus(ctx context.Context, id int, from, to string) error
+}
+
+// Хранилище, используемое обработчиками
+var ordersStore OrdersStore = newMemoryOrdersStore()
+
+// SetOrdersStore подменяет хранилище заказов (например, в тестах)
+func SetOrdersStore(store OrdersStore) {
+	ordersStore = store
+}
+
+// Последний выданный ID заказа
+var lastOrderID atomic.Int64
+
+// nextOrderID выдает уникальный в пределах процесса ID заказа
+func nextOrderID() int {
+	return int(lastOrderID.Add(1))
+}
+
+// memoryOrdersStore - in-memory хранилище заказов
+type memoryOrdersStore struct {
+	mu     sync.RWMutex
+	orders map[int]Order
+}
+
+func newMemoryOrdersStore() *memoryOrdersStore {
+	return &memoryOrdersStore{orders: make(map[int]Order)}
+}
+
+func (s *memoryOrdersStore) Save(ctx context.Context, order Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.orders[order.ID] = order
+	return nil
+}
+
+func (s *memoryOrdersStore) Get(ctx context.Context, id int) (Order, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	order, ok := s.orders[id]
+	if !ok {
+		return Order{}, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (s *memoryOrdersStore) ListByCursor(ctx context.Context, afterID, limit int) ([]Order, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]int, 0, len(s.orders))
+	for id := range s.orders {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	page := make([]Order, len(ids))
+	for i, id := range ids {
+		page[i] = s.orders[id]
+	}
+	return page, nil
+}
+
+func (s *memoryOrdersStore) UpdateStatus(ctx context.Context, id int, from, to string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[id]
+	if !ok {
+		return ErrOrderNotFound
+	}
+	if order.Status != from {
+		return ErrOrderStatusChanged
+	}
+
+	order.Status = to
+	s.orders[id] = order
+	return nil
+}
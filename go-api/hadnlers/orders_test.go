@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/gorilla/mux"
+)
+
+// getOrder выполняет GET /api/orders/{id}
+func getOrder(ctx context.Context, h *Handler, id string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/"+id, nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	h.GetOrderHandler(rec, mux.SetURLVars(req, map[string]string{"id": id}))
+	return rec
+}
+
+func TestOrdersHandler_ClientDisconnect(t *testing.T) {
+	tests := []struct {
+		name          string
+		ctx           func() (context.Context, context.CancelFunc)
+		wantMessage   string
+		wantLevel     string
+		wantCancelled int64
+		wantErrors    []string
+	}{
+		{
+			name: "client disconnected",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(ordersMaxDelay/2, cancel)
+				return ctx, cancel
+			},
+			wantMessage:   "Client disconnected during order processing",
+			wantLevel:     "INFO",
+			wantCancelled: 1,
+		},
+		{
+			name: "route timeout",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), ordersMaxDelay/2)
+			},
+			wantMessage: "Order processing timed out",
+			wantLevel:   "WARN",
+			wantErrors:  []string{"timeout"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := logging.NewBufferedLogger()
+			recorder := &countingRecorder{}
+			h := New(Config{Logger: logger, Metrics: recorder, Rand: fixedRand{}})
+
+			// fixedRand дает максимальную задержку, контекст завершается на ее середине
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			rec := httptest.NewRecorder()
+			start := time.Now()
+			h.OrdersHandler(rec, newOrderRequest("").WithContext(ctx))
+			elapsed := time.Since(start)
+
+			if elapsed >= ordersMaxDelay {
+				t.Errorf("handler returned after %s, want it to stop when the context ends", elapsed)
+			}
+			if rec.Body.Len() != 0 {
+				t.Errorf("response body = %q, want nothing written after the context ends", rec.Body)
+			}
+			if got := recorder.cancelled.Load(); got != tt.wantCancelled {
+				t.Errorf("orders cancelled by client = %d, want %d", got, tt.wantCancelled)
+			}
+			if got := recorder.orders.Load(); got != 0 {
+				t.Errorf("orders processed = %d, want 0", got)
+			}
+			if got := recorder.errors(); !reflect.DeepEqual(got, tt.wantErrors) {
+				t.Errorf("recorded errors = %v, want %v", got, tt.wantErrors)
+			}
+
+			var fields map[string]interface{}
+			for _, entry := range logger.Entries() {
+				switch entry.Message {
+				case tt.wantMessage:
+					if entry.Level != tt.wantLevel {
+						t.Errorf("%q logged at %s, want %s", entry.Message, entry.Level, tt.wantLevel)
+					}
+					fields = entry.Fields
+				case "Client disconnected during order processing", "Order processing timed out":
+					t.Errorf("unexpected %q entry", entry.Message)
+				}
+			}
+			if fields == nil {
+				t.Fatalf("%q was not logged", tt.wantMessage)
+			}
+			if id, _ := fields["order_id"].(int); id <= 0 {
+				t.Errorf("order_id = %v, want the ID of the abandoned order", fields["order_id"])
+			}
+			if ms, _ := fields["elapsed_ms"].(int64); ms <= 0 || time.Duration(ms)*time.Millisecond >= ordersMaxDelay {
+				t.Errorf("elapsed_ms = %v, want a value inside the processing delay", fields["elapsed_ms"])
+			}
+		})
+	}
+}
+
+func TestOrders_CreateThenGet(t *testing.T) {
+	useCursorStores(t, 0)
+	logger := logging.NewBufferedLogger()
+	h := New(Config{Logger: logger, Metrics: &countingRecorder{}, Rand: fixedRand{}})
+
+	rec := httptest.NewRecorder()
+	h.OrdersHandler(rec, newOrderRequest(""))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d, body %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		OrderID int     `json:"order_id"`
+		Total   float64 `json:"total"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode POST: %v", err)
+	}
+
+	ctx := logging.WithRequestID(context.Background(), "req-lookup")
+	rec = getOrder(ctx, h, fmt.Sprint(created.OrderID))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, body %s", rec.Code, rec.Body)
+	}
+	var order Order
+	if err := json.NewDecoder(rec.Body).Decode(&order); err != nil {
+		t.Fatalf("decode GET: %v", err)
+	}
+
+	wantItems := []OrderItem{{ProductID: 1, Quantity: 2}}
+	if order.ID != created.OrderID || order.UserID != 1 || order.Total != created.Total || order.Status != OrderStatusCompleted {
+		t.Errorf("order = %+v, want the created order %+v", order, created)
+	}
+	if !reflect.DeepEqual(order.Items, wantItems) {
+		t.Errorf("items = %+v, want %+v", order.Items, wantItems)
+	}
+
+	entries := logger.Entries()
+	last := entries[len(entries)-1]
+	if last.Message != "Order lookup" || last.Fields["request_id"] != "req-lookup" || last.Fields["order_id"] != created.OrderID {
+		t.Errorf("last entry = %+v, want the lookup with request_id", last)
+	}
+}
+
+func TestGetOrderHandler_Errors(t *testing.T) {
+	useCursorStores(t, 0)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	tests := []struct {
+		id         string
+		wantStatus int
+		wantType   string
+	}{
+		{id: "424242", wantStatus: http.StatusNotFound, wantType: ErrTypeNotFound},
+		{id: "first", wantStatus: http.StatusBadRequest, wantType: ErrTypeValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			rec := getOrder(context.Background(), h, tt.id)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if apiErr := decodeAPIError(t, rec); apiErr.Type != tt.wantType {
+				t.Errorf("error type = %q, want %q", apiErr.Type, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestOrdersHandler_Validation(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantErrors []interface{}
+	}{
+		{
+			name:       "invalid item",
+			body:       `{"user_id": 1, "items": [{"product_id": -1, "quantity": 0}]}`,
+			wantErrors: []interface{}{"items[0].product_id must be positive", "items[0].quantity must be >= 1"},
+		},
+		{
+			name:       "missing user and items",
+			body:       `{}`,
+			wantErrors: []interface{}{"user_id must be positive", "items must not be empty"},
+		},
+		{
+			name:       "second item invalid",
+			body:       `{"user_id": 7, "items": [{"product_id": 1, "quantity": 1}, {"product_id": 2, "quantity": -1}]}`,
+			wantErrors: []interface{}{"items[1].quantity must be >= 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCursorStores(t, 0)
+			recorder := &countingRecorder{}
+			h := New(Config{Logger: logging.NoopLogger{}, Metrics: recorder, Rand: fixedRand{}})
+
+			rec := httptest.NewRecorder()
+			h.OrdersHandler(rec, httptest.NewRequest(http.MethodPost, "/api/orders", strings.NewReader(tt.body)))
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want 422: %s", rec.Code, rec.Body)
+			}
+			apiErr := decodeAPIError(t, rec)
+			if apiErr.Type != ErrTypeValidation || apiErr.Message != "order validation failed" {
+				t.Errorf("error = %+v, want %s: order validation failed", apiErr, ErrTypeValidation)
+			}
+			if got := apiErr.Details["errors"]; !reflect.DeepEqual(got, tt.wantErrors) {
+				t.Errorf("details.errors = %v, want %v", got, tt.wantErrors)
+			}
+			if recorder.orders.Load() != 0 || !reflect.DeepEqual(recorder.errors(), []string{"validation"}) {
+				t.Errorf("orders = %d, errors = %v, want no order and one validation error", recorder.orders.Load(), recorder.errors())
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// Параметры пагинации по умолчанию
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// PageMeta описывает страницу в ответе списка
+type PageMeta struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+	Total int `json:"total"`
+}
+
+// PageResponse - конверт ответа со списком
+type PageResponse struct {
+	Data interface{} `json:"data"`
+	Meta PageMeta    `json:"meta"`
+}
+
+// parsePagination читает page (с 1) и limit (1–100, по умолчанию 20).
+// При ошибке возвращает имя неверного параметра
+func parsePagination(r *http.Request) (page, limit int, field string, err error) {
+	page, limit = 1, defaultPageLimit
+	query := r.URL.Query()
+
+	if v := query.Get("page"); v != "" {
+		page, err = strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return 0, 0, "page", errors.New("page must be a positive integer")
+		}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return 0, 0, "limit", errors.New("limit must be between 1 and 100")
+		}
+	}
+
+	return page, limit, "", nil
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Заголовок Cache-Control ответа каталога
+const productsCacheControl = "max-age=60, must-revalidate"
+
+// ETag ответов каталога по ключу фильтра и сортировки. Позволяет ответить
+// 304 без выборки и сериализации продуктов
+var productsETags = NewCache[string, string](time.Minute)
+
+// InvalidateProductsCache сбрасывает кэш выборок и ETag каталога.
+// Вызывается при любом изменении продуктов
+func InvalidateProductsCache() {
+	productsCache.Clear()
+	productsETags.Clear()
+}
+
+// productsETag - сильный ETag по CRC32 сериализованного ответа
+func productsETag(body []byte) string {
+	return fmt.Sprintf(`"%08x"`, crc32.ChecksumIEEE(body))
+}
+
+// etagMatches проверяет, есть ли etag в заголовке If-None-Match
+func etagMatches(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeNotModified отвечает 304 с заголовками кэширования и без тела
+func writeNotModified(w http.ResponseWriter, etag string) {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", productsCacheControl)
+	w.WriteHeader(http.StatusNotModified)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// productFilter - параметры выборки каталога из query
+type productFilter struct {
+	category string
+	minPrice float64
+	maxPrice float64
+}
+
+// parseProductFilter читает category, min_price и max_price.
+// При ошибке возвращает имя неверного параметра
+func parseProductFilter(r *http.Request) (productFilter, string, error) {
+	filter := productFilter{maxPrice: noMaxPrice}
+	query := r.URL.Query()
+
+	if values, ok := query["category"]; ok {
+		filter.category = strings.TrimSpace(values[0])
+		if filter.category == "" {
+			return filter, "category", errors.New("category must not be empty")
+		}
+	}
+
+	var err error
+	if filter.minPrice, err = parsePrice(query.Get("min_price"), 0); err != nil {
+		return filter, "min_price", err
+	}
+	if filter.maxPrice, err = parsePrice(query.Get("max_price"), noMaxPrice); err != nil {
+		return filter, "max_price", err
+	}
+	if filter.minPrice > filter.maxPrice {
+		return filter, "min_price", errors.New("min_price must not exceed max_price")
+	}
+
+	return filter, "", nil
+}
+
+func parsePrice(value string, def float64) (float64, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil || price < 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+		return 0, errors.New("price must be a non-negative number")
+	}
+	return price, nil
+}
+
+// key - ключ кэша для выборки
+func (f productFilter) key() string {
+	return fmt.Sprintf("%s|%g|%g", f.category, f.minPrice, f.maxPrice)
+}
+
+// maxPriceParam возвращает max_price для логов, nil если граница не задана
+func (f productFilter) maxPriceParam() interface{} {
+	if math.IsInf(f.maxPrice, 1) {
+		return nil
+	}
+	return f.maxPrice
+}
+
+// productSort - порядок выдачи каталога
+type productSort struct {
+	by   string
+	desc bool
+}
+
+// Функции сравнения для допустимых значений sort_by
+var productLess = map[string]func(a, b Product) bool{
+	"price":  func(a, b Product) bool { return a.Price < b.Price },
+	"rating": func(a, b Product) bool { return a.Rating < b.Rating },
+	"name":   func(a, b Product) bool { return a.Name < b.Name },
+}
+
+// parseProductSort читает sort_by (price, rating, name) и sort_order (asc, desc).
+// По умолчанию - name asc
+func parseProductSort(r *http.Request) (productSort, string, error) {
+	order := productSort{by: "name"}
+	query := r.URL.Query()
+
+	if by := query.Get("sort_by"); by != "" {
+		if _, ok := productLess[by]; !ok {
+			return order, "sort_by", errors.New("sort_by must be one of price, rating, name")
+		}
+		order.by = by
+	}
+
+	switch query.Get("sort_order") {
+	case "", "asc":
+	case "desc":
+		order.desc = true
+	default:
+		return order, "sort_order", errors.New("sort_order must be asc or desc")
+	}
+
+	return order, "", nil
+}
+
+// key - часть ключа кэша ETag, зависящая от порядка
+func (o productSort) key() string {
+	return fmt.Sprintf("%s|%t", o.by, o.desc)
+}
+
+// apply возвращает отсортированную копию products.
+// При равенстве ключа порядок определяется ID
+func (o productSort) apply(products []Product) []Product {
+	sorted := make([]Product, len(products))
+	copy(sorted, products)
+
+	less := productLess[o.by]
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if o.desc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
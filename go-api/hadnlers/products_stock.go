@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// UpdateProductStockHandler меняет остаток продукта на delta:
+// положительный - поступление, отрицательный - продажа
+func (h *Handler) UpdateProductStockHandler(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger(r)
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, "id must be an integer", "id")
+		return
+	}
+
+	var input struct {
+		Delta *int `json:"delta"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if input.Delta == nil || *input.Delta == 0 {
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, "delta must be a non-zero integer", "delta")
+		return
+	}
+	delta := *input.Delta
+
+	product, err := productsStore.AdjustStock(r.Context(), id, delta)
+	switch {
+	case errors.Is(err, ErrProductNotFound):
+		WriteError(w, http.StatusNotFound, ErrTypeNotFound, "product not found")
+		return
+	case errors.Is(err, ErrInsufficientStock):
+		logger.Warn("Insufficient product stock", map[string]interface{}{
+			"product_id":  id,
+			"stock_level": product.StockLevel,
+			"delta":       delta,
+		})
+
+		writeErrorDetails(w, http.StatusConflict, ErrTypeConflict, "insufficient stock", map[string]interface{}{
+			"stock_level": product.StockLevel,
+			"delta":       delta,
+		})
+		return
+	case err != nil:
+		logger.Error("Failed to update product stock", map[string]interface{}{
+			"product_id": id,
+			"error":      err,
+		})
+
+		h.Metrics.RecordError("database", "/api/products/{id}/stock")
+		WriteError(w, http.StatusInternalServerError, ErrTypeInternal, "Failed to update product stock")
+		return
+	}
+
+	h.Metrics.SetProductStock(strconv.Itoa(id), product.StockLevel)
+	InvalidateProductsCache()
+
+	logger.Info("Product stock changed", map[string]interface{}{
+		"product_id":  id,
+		"delta":       delta,
+		"stock_level": product.StockLevel,
+	})
+
+	// Продажа оставила остаток ниже порога
+	if delta < 0 && product.StockLevel < h.LowStockThreshold {
+		logger.Info("Product stock is low", map[string]interface{}{
+			"product_id":  id,
+			"stock_level": product.StockLevel,
+			"threshold":   h.LowStockThreshold,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/gorilla/mux"
+)
+
+// putStock выполняет PUT /api/products/{id}/stock
+func putStock(h *Handler, id, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPut, "/api/products/"+id+"/stock", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.UpdateProductStockHandler(rec, mux.SetURLVars(req, map[string]string{"id": id}))
+	return rec
+}
+
+func TestUpdateProductStockHandler(t *testing.T) {
+	useProductsStore(t)
+	logger := logging.NewBufferedLogger()
+	recorder := &countingRecorder{}
+	h := New(Config{Logger: logger, Metrics: recorder, Rand: fixedRand{}, LowStockThreshold: 10})
+
+	// Подтесты выполняются по порядку: остаток продукта 1 начинается с 25
+	tests := []struct {
+		name      string
+		id        string
+		body      string
+		wantCode  int
+		wantType  string
+		wantLevel int
+		wantLow   bool
+	}{
+		{name: "sell", id: "1", body: `{"delta": -5}`, wantCode: http.StatusOK, wantLevel: 20},
+		{name: "restock", id: "1", body: `{"delta": 10}`, wantCode: http.StatusOK, wantLevel: 30},
+		{name: "sell below threshold", id: "1", body: `{"delta": -25}`, wantCode: http.StatusOK, wantLevel: 5, wantLow: true},
+		{name: "over-depletion", id: "1", body: `{"delta": -6}`, wantCode: http.StatusConflict, wantType: ErrTypeConflict, wantLevel: 5},
+		{name: "sell to zero", id: "1", body: `{"delta": -5}`, wantCode: http.StatusOK, wantLevel: 0, wantLow: true},
+		{name: "sell from empty", id: "1", body: `{"delta": -1}`, wantCode: http.StatusConflict, wantType: ErrTypeConflict, wantLevel: 0},
+		{name: "restock from empty stays low", id: "1", body: `{"delta": 3}`, wantCode: http.StatusOK, wantLevel: 3},
+		{name: "zero delta", id: "1", body: `{"delta": 0}`, wantCode: http.StatusBadRequest, wantType: ErrTypeValidation, wantLevel: 3},
+		{name: "missing delta", id: "1", body: `{}`, wantCode: http.StatusBadRequest, wantType: ErrTypeValidation, wantLevel: 3},
+		{name: "fractional delta", id: "1", body: `{"delta": 1.5}`, wantCode: http.StatusBadRequest, wantType: ErrTypeInvalidJSON, wantLevel: 3},
+		{name: "unknown product", id: "99", body: `{"delta": 1}`, wantCode: http.StatusNotFound, wantType: ErrTypeNotFound},
+		{name: "non-integer id", id: "one", body: `{"delta": 1}`, wantCode: http.StatusBadRequest, wantType: ErrTypeValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(logger.Entries())
+			rec := putStock(h, tt.id, tt.body)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.wantCode, rec.Body)
+			}
+
+			if tt.wantType != "" {
+				apiErr := decodeAPIError(t, rec)
+				if apiErr.Type != tt.wantType {
+					t.Errorf("error type = %q, want %q", apiErr.Type, tt.wantType)
+				}
+				if tt.wantCode == http.StatusConflict && apiErr.Details["stock_level"] != float64(tt.wantLevel) {
+					t.Errorf("details = %v, want stock_level %d", apiErr.Details, tt.wantLevel)
+				}
+			} else {
+				var product Product
+				if err := json.NewDecoder(rec.Body).Decode(&product); err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				if product.StockLevel != tt.wantLevel {
+					t.Errorf("stock_level = %d, want %d", product.StockLevel, tt.wantLevel)
+				}
+			}
+
+			// Отклоненный запрос не меняет ни остаток, ни gauge
+			if tt.id == "1" {
+				if level, ok := recorder.stockLevel("1"); !ok || level != tt.wantLevel {
+					t.Errorf("product_stock_level{product_id=\"1\"} = %d (set %v), want %d", level, ok, tt.wantLevel)
+				}
+			}
+
+			low := false
+			for _, entry := range logger.Entries()[before:] {
+				if entry.Message == "Product stock is low" {
+					low = entry.Level == "INFO" && entry.Fields["stock_level"] == tt.wantLevel && entry.Fields["threshold"] == 10
+				}
+			}
+			if low != tt.wantLow {
+				t.Errorf("low stock entry logged = %v, want %v", low, tt.wantLow)
+			}
+		})
+	}
+}
+
+// Остатки разных продуктов не влияют друг на друга
+func TestUpdateProductStockHandler_PerProduct(t *testing.T) {
+	useProductsStore(t)
+	recorder := &countingRecorder{}
+	h := New(Config{Logger: logging.NoopLogger{}, Metrics: recorder, Rand: fixedRand{}})
+
+	for id, body := range map[string]string{"2": `{"delta": -50}`, "4": `{"delta": 8}`} {
+		if rec := putStock(h, id, body); rec.Code != http.StatusOK {
+			t.Fatalf("product %s: status = %d, body %s", id, rec.Code, rec.Body)
+		}
+	}
+
+	for id, want := range map[string]int{"2": 100, "4": 20} {
+		if level, _ := recorder.stockLevel(id); level != want {
+			t.Errorf("product_stock_level{product_id=%q} = %d, want %d", id, level, want)
+		}
+	}
+	if _, ok := recorder.stockLevel("1"); ok {
+		t.Error("gauge set for product 1 without a stock change")
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+)
+
+// Ошибки каталога продуктов
+var (
+	ErrProductNotFound   = errors.New("product not found")
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+// Product - продукт каталога
+type Product struct {
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	Price      float64 `json:"price"`
+	Category   string  `json:"category"`
+	StockLevel int     `json:"stock_level"`
+	Rating     float64 `json:"rating"`
+}
+
+// ProductsStore - каталог продуктов
+type ProductsStore interface {
+	// Filter возвращает продукты категории category (пустая - любая)
+	// с ценой в диапазоне [minPrice, maxPrice]
+	Filter(ctx context.Context, category string, minPrice, maxPrice float64) ([]Product, error)
+
+	// AdjustStock меняет остаток продукта на delta и возвращает продукт
+	// с новым остатком. ErrInsufficientStock - остаток стал бы отрицательным
+	AdjustStock(ctx context.Context, id, delta int) (Product, error)
+}
+
+// Каталог, используемый обработчиками
+var productsStore ProductsStore = newMemoryProductsStore(loadProducts())
+
+// SetProductsStore подменяет каталог продуктов (например, в тестах)
+func SetProductsStore(store ProductsStore) {
+	productsStore = store
+	InvalidateProductsCache()
+}
+
+// Верхняя граница цены, когда max_price не задан
+var noMaxPrice = math.Inf(1)
+
+// memoryProductsStore - in-memory каталог продуктов
+type memoryProductsStore struct {
+	mu       sync.RWMutex
+	products []Product
+}
+
+func newMemoryProductsStore(products []Product) *memoryProductsStore {
+	return &memoryProductsStore{products: products}
+}
+
+func (s *memoryProductsStore) Filter(ctx context.Context, category string, minPrice, maxPrice float64) ([]Product, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Product, 0, len(s.products))
+	for _, p := range s.products {
+		if category != "" && p.Category != category {
+			continue
+		}
+		if p.Price < minPrice || p.Price > maxPrice {
+			continue
+		}
+
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func (s *memoryProductsStore) AdjustStock(ctx context.Context, id, delta int) (Product, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.products {
+		if s.products[i].ID != id {
+			continue
+		}
+		if s.products[i].StockLevel+delta < 0 {
+			return s.products[i], ErrInsufficientStock
+		}
+
+		s.products[i].StockLevel += delta
+		return s.products[i], nil
+	}
+	return Product{}, ErrProductNotFound
+}
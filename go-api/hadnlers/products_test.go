@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/gorilla/mux"
+)
+
+// useProductsStore подменяет каталог тестовым набором продуктов
+func useProductsStore(t *testing.T) {
+	t.Helper()
+
+	prev := productsStore
+	SetProductsStore(newMemoryProductsStore([]Product{
+		{ID: 1, Name: "Laptop Pro", Price: 1299.99, Category: "electronics", StockLevel: 25, Rating: 4.5},
+		{ID: 2, Name: "Wireless Mouse", Price: 49.99, Category: "accessories", StockLevel: 150, Rating: 4.2},
+		{ID: 3, Name: "Mechanical Keyboard", Price: 89.99, Category: "accessories", StockLevel: 0, Rating: 4.7},
+		{ID: 4, Name: "Monitor", Price: 299, Category: "electronics", StockLevel: 12, Rating: 4.2},
+		{ID: 5, Name: "USB Cable", Price: 9.5, Category: "accessories", StockLevel: 500, Rating: 3.9},
+	}))
+	t.Cleanup(func() { SetProductsStore(prev) })
+}
+
+// getProducts выполняет GET /api/products и возвращает ID продуктов ответа
+func getProducts(t *testing.T, h *Handler, query string) (*httptest.ResponseRecorder, []int) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ProductsHandler(rec, httptest.NewRequest(http.MethodGet, "/api/products"+query, nil))
+	if rec.Code != http.StatusOK {
+		return rec, nil
+	}
+
+	var products []Product
+	if err := json.Unmarshal(rec.Body.Bytes(), &products); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	ids := make([]int, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	return rec, ids
+}
+
+func TestProductsHandler_Filters(t *testing.T) {
+	useProductsStore(t)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	// Без sort_by продукты упорядочены по имени
+	tests := []struct {
+		name    string
+		query   string
+		wantIDs []int
+	}{
+		{name: "no filters", query: "", wantIDs: []int{1, 3, 4, 5, 2}},
+		{name: "category", query: "?category=electronics", wantIDs: []int{1, 4}},
+		{name: "min price", query: "?min_price=100", wantIDs: []int{1, 4}},
+		{name: "max price", query: "?max_price=50", wantIDs: []int{5, 2}},
+		{name: "inclusive price range", query: "?min_price=49.99&max_price=299", wantIDs: []int{3, 4, 2}},
+		{name: "category and price", query: "?category=accessories&min_price=10&max_price=100", wantIDs: []int{3, 2}},
+		{name: "no matches", query: "?category=furniture", wantIDs: []int{}},
+		{name: "empty range", query: "?min_price=5000", wantIDs: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, ids := getProducts(t, h, tt.query)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("products %v, want %v", ids, tt.wantIDs)
+			}
+			// Пустая выборка - пустой массив, а не null
+			if len(tt.wantIDs) == 0 && rec.Body.String() != "[]\n" {
+				t.Errorf("body = %q, want []", rec.Body)
+			}
+		})
+	}
+}
+
+func TestProductsHandler_InvalidFilters(t *testing.T) {
+	useProductsStore(t)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	tests := []struct {
+		query     string
+		wantField string
+	}{
+		{query: "?category=", wantField: "category"},
+		{query: "?category=%20%20", wantField: "category"},
+		{query: "?min_price=cheap", wantField: "min_price"},
+		{query: "?min_price=-1", wantField: "min_price"},
+		{query: "?max_price=NaN", wantField: "max_price"},
+		{query: "?max_price=Inf", wantField: "max_price"},
+		{query: "?min_price=100&max_price=10", wantField: "min_price"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec, _ := getProducts(t, h, tt.query)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", rec.Code)
+			}
+			if apiErr := decodeAPIError(t, rec); apiErr.Type != ErrTypeValidation || apiErr.Field != tt.wantField {
+				t.Errorf("error = %+v, want %s on field %s", apiErr, ErrTypeValidation, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestProductsHandler_LogsFilter(t *testing.T) {
+	useProductsStore(t)
+	logger := logging.NewBufferedLogger()
+	h := New(Config{Logger: logger, Rand: fixedRand{}})
+
+	getProducts(t, h, "?category=accessories&min_price=10")
+
+	entries := logger.Entries()
+	last := entries[len(entries)-1]
+	if last.Message != "Products request completed" || last.Fields["category"] != "accessories" ||
+		last.Fields["min_price"] != 10.0 || last.Fields["max_price"] != nil || last.Fields["product_count"] != 2 {
+		t.Errorf("last entry = %+v, want the applied filter", last)
+	}
+}
+
+func TestProductsHandler_Sorting(t *testing.T) {
+	useProductsStore(t)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	// Равные рейтинги (2 и 4) упорядочиваются по ID в обоих направлениях
+	tests := []struct {
+		query   string
+		wantIDs []int
+	}{
+		{query: "?sort_by=price", wantIDs: []int{5, 2, 3, 4, 1}},
+		{query: "?sort_by=price&sort_order=asc", wantIDs: []int{5, 2, 3, 4, 1}},
+		{query: "?sort_by=price&sort_order=desc", wantIDs: []int{1, 4, 3, 2, 5}},
+		{query: "?sort_by=rating", wantIDs: []int{5, 2, 4, 1, 3}},
+		{query: "?sort_by=rating&sort_order=desc", wantIDs: []int{3, 1, 2, 4, 5}},
+		{query: "?sort_by=name", wantIDs: []int{1, 3, 4, 5, 2}},
+		{query: "?sort_by=name&sort_order=desc", wantIDs: []int{2, 5, 4, 3, 1}},
+		{query: "?sort_order=desc", wantIDs: []int{2, 5, 4, 3, 1}},
+		{query: "?category=electronics&sort_by=price&sort_order=desc", wantIDs: []int{1, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec, ids := getProducts(t, h, tt.query)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("products %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestProductsHandler_InvalidSort(t *testing.T) {
+	useProductsStore(t)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	tests := []struct {
+		query     string
+		wantField string
+	}{
+		{query: "?sort_by=stock", wantField: "sort_by"},
+		{query: "?sort_by=PRICE", wantField: "sort_by"},
+		{query: "?sort_by=price&sort_order=up", wantField: "sort_order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec, _ := getProducts(t, h, tt.query)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", rec.Code)
+			}
+			if apiErr := decodeAPIError(t, rec); apiErr.Type != ErrTypeValidation || apiErr.Field != tt.wantField {
+				t.Errorf("error = %+v, want %s on field %s", apiErr, ErrTypeValidation, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestProductsHandler_ETag(t *testing.T) {
+	useProductsStore(t)
+	h := New(Config{Logger: logging.NoopLogger{}, Metrics: &countingRecorder{}, Rand: fixedRand{}})
+
+	first, _ := getProducts(t, h, "?category=electronics")
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("response has no ETag")
+	}
+	if got := first.Header().Get("Cache-Control"); got != "max-age=60, must-revalidate" {
+		t.Errorf("Cache-Control = %q, want max-age=60, must-revalidate", got)
+	}
+
+	// Повторный запрос с тем же тегом
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{name: "same tag", ifNoneMatch: etag, wantStatus: http.StatusNotModified},
+		{name: "weak tag", ifNoneMatch: "W/" + etag, wantStatus: http.StatusNotModified},
+		{name: "tag in list", ifNoneMatch: `"deadbeef", ` + etag, wantStatus: http.StatusNotModified},
+		{name: "wildcard", ifNoneMatch: "*", wantStatus: http.StatusNotModified},
+		{name: "stale tag", ifNoneMatch: `"deadbeef"`, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/products?category=electronics", nil)
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			rec := httptest.NewRecorder()
+			h.ProductsHandler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("ETag"); got != etag {
+				t.Errorf("ETag = %q, want %q", got, etag)
+			}
+			if tt.wantStatus == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("304 has a %d byte body", rec.Body.Len())
+			}
+		})
+	}
+
+	// Изменение остатка сбрасывает кэш, ETag выборки меняется
+	req := httptest.NewRequest(http.MethodPatch, "/api/products/1/stock", strings.NewReader(`{"delta": -5}`))
+	rec := httptest.NewRecorder()
+	h.UpdateProductStockHandler(rec, mux.SetURLVars(req, map[string]string{"id": "1"}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stock update status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/products?category=electronics", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ProductsHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status after update = %d, want 200 with the new catalog", rec.Code)
+	}
+	if got := rec.Header().Get("ETag"); got == "" || got == etag {
+		t.Errorf("ETag after update = %q, want a new tag instead of %q", got, etag)
+	}
+}
+
+func TestProductsHandler_ETagPerQuery(t *testing.T) {
+	useProductsStore(t)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	byName, _ := getProducts(t, h, "")
+	byPrice, _ := getProducts(t, h, "?sort_by=price")
+	if byName.Header().Get("ETag") == byPrice.Header().Get("ETag") {
+		t.Error("different orderings share an ETag")
+	}
+
+	// Тег одной выборки не подходит для другой
+	req := httptest.NewRequest(http.MethodGet, "/api/products?sort_by=price", nil)
+	req.Header.Set("If-None-Match", byName.Header().Get("ETag"))
+	rec := httptest.NewRecorder()
+	h.ProductsHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 for a tag of another query", rec.Code)
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Rand - источник случайных чисел для имитации сбоев и задержек.
+// Подменяется в тестах для воспроизводимых сценариев
+type Rand interface {
+	Intn(n int) int
+}
+
+// DeterministicRand возвращает источник с фиксированным seed:
+// одинаковый seed дает одинаковую последовательность
+func DeterministicRand(seed int64) Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// lockedRand позволяет использовать *rand.Rand из параллельных запросов
+type lockedRand struct {
+	mu  sync.Mutex
+	src Rand
+}
+
+func (r *lockedRand) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.src.Intn(n)
+}
+
+// newRand оборачивает src для параллельного использования,
+// nil заменяется источником с seed от текущего времени
+func newRand(src Rand) Rand {
+	if src == nil {
+		src = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return &lockedRand{src: src}
+}
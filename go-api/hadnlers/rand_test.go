@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/logging"
+)
+
+func TestDeterministicRand_Sequence(t *testing.T) {
+	draw := func(seed int64) []int {
+		r := DeterministicRand(seed)
+		seq := make([]int, 1000)
+		for i := range seq {
+			seq[i] = r.Intn(100)
+		}
+		return seq
+	}
+
+	if a, b := draw(42), draw(42); !reflect.DeepEqual(a, b) {
+		t.Error("seed 42 produced different sequences")
+	}
+	if a, b := draw(42), draw(43); reflect.DeepEqual(a, b) {
+		t.Error("seeds 42 and 43 produced the same sequence")
+	}
+}
+
+// С seed 42 первые 100 запросов списка пользователей дают одни и те же ошибки
+func TestDeterministicRand_UsersErrors(t *testing.T) {
+	const requests, wantErrors = 100, 25
+
+	useCursorStores(t, 10)
+	useChaosEnv(t, map[string]string{"USERS_ERROR_RATE": "20"})
+
+	statuses := func() []int {
+		// Кэш страниц оставляет одну имитацию задержки БД на весь прогон
+		h := New(Config{Logger: logging.NoopLogger{}, Rand: DeterministicRand(42), UserCacheTTL: time.Minute})
+		codes := make([]int, requests)
+		for i := range codes {
+			rec := httptest.NewRecorder()
+			h.UsersHandler(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+			codes[i] = rec.Code
+		}
+		return codes
+	}
+
+	first := statuses()
+	errors := 0
+	for i, code := range first {
+		switch code {
+		case http.StatusInternalServerError:
+			errors++
+		case http.StatusOK:
+		default:
+			t.Fatalf("request %d: status = %d", i, code)
+		}
+	}
+	if errors != wantErrors {
+		t.Errorf("%d of %d requests failed with seed 42, want %d", errors, requests, wantErrors)
+	}
+	if second := statuses(); !reflect.DeepEqual(first, second) {
+		t.Errorf("statuses differ between runs with seed 42:\n%v\n%v", first, second)
+	}
+}
+
+// Источник из Config оборачивается для параллельных запросов
+func TestNew_RandConcurrent(t *testing.T) {
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: DeterministicRand(42)})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				h.chance(50)
+			}
+		}()
+	}
+	wg.Wait()
+}
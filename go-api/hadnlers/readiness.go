@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/crazy1997/go-api/bus"
+)
+
+// Последнее известное состояние компонентов, полученное из шины здоровья,
+// и зависимости, проверяемые на каждый запрос readiness
+var readiness = struct {
+	mu      sync.RWMutex
+	events  map[string]bus.HealthEvent
+	pingers map[string]Pinger
+}{
+	events:  make(map[string]bus.HealthEvent),
+	pingers: make(map[string]Pinger),
+}
+
+// Pinger проверяет доступность зависимости, например *logging.ELKLogger
+type Pinger interface {
+	Ping() error
+}
+
+// AddReadinessCheck добавляет зависимость, без которой сервис не готов
+// принимать трафик. Ping вызывается при каждом запросе /api/ready
+func AddReadinessCheck(component string, pinger Pinger) {
+	readiness.mu.Lock()
+	defer readiness.mu.Unlock()
+
+	readiness.pingers[component] = pinger
+}
+
+// WatchHealth подписывает readiness-проверку на события компонентов,
+// чтобы не опрашивать зависимости на каждый запрос
+func WatchHealth(b *bus.HealthBus, components ...string) {
+	ch := make(chan bus.HealthEvent, 16)
+	for _, component := range components {
+		b.Subscribe(component, ch)
+	}
+
+	go func() {
+		for event := range ch {
+			readiness.mu.Lock()
+			readiness.events[event.Component] = event
+			readiness.mu.Unlock()
+		}
+	}()
+}
+
+// ReadinessHandler сообщает, готов ли сервис принимать трафик
+func (h *Handler) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	readiness.mu.RLock()
+	reasons := []string{}
+	components := make(map[string]string, len(readiness.events)+len(readiness.pingers))
+	for name, event := range readiness.events {
+		components[name] = event.Status
+		if event.Status == bus.StatusDown {
+			reasons = append(reasons, name+": "+event.Error)
+		}
+	}
+	pingers := make(map[string]Pinger, len(readiness.pingers))
+	for name, pinger := range readiness.pingers {
+		pingers[name] = pinger
+	}
+	readiness.mu.RUnlock()
+
+	for name, pinger := range pingers {
+		components[name] = bus.StatusUp
+		if err := pinger.Ping(); err != nil {
+			components[name] = bus.StatusDown
+			reasons = append(reasons, name+": "+err.Error())
+		}
+	}
+	sort.Strings(reasons)
+
+	response := map[string]interface{}{
+		"ready":      len(reasons) == 0,
+		"components": components,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(reasons) > 0 {
+		response["reasons"] = reasons
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
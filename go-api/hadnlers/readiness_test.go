@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/bus"
+	"github.com/crazy1997/go-api/logging"
+)
+
+// resetReadiness очищает состояние readiness на время теста
+func resetReadiness(t *testing.T) {
+	t.Helper()
+
+	readiness.mu.Lock()
+	events, pingers := readiness.events, readiness.pingers
+	readiness.events = make(map[string]bus.HealthEvent)
+	readiness.pingers = make(map[string]Pinger)
+	readiness.mu.Unlock()
+
+	t.Cleanup(func() {
+		readiness.mu.Lock()
+		readiness.events, readiness.pingers = events, pingers
+		readiness.mu.Unlock()
+	})
+}
+
+type readinessResponse struct {
+	Ready      bool              `json:"ready"`
+	Components map[string]string `json:"components"`
+	Reasons    []string          `json:"reasons"`
+}
+
+func getReadiness(t *testing.T, h *Handler) (int, readinessResponse) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ReadinessHandler(rec, httptest.NewRequest(http.MethodGet, "/api/ready", nil))
+
+	var body readinessResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode readiness: %v", err)
+	}
+	return rec.Code, body
+}
+
+func TestReadinessHandler_HealthBusEvents(t *testing.T) {
+	resetReadiness(t)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	b := bus.New()
+	WatchHealth(b, logging.LogstashComponent)
+
+	steps := []struct {
+		event      bus.HealthEvent
+		wantStatus int
+		wantReady  bool
+	}{
+		{event: bus.HealthEvent{Component: logging.LogstashComponent, Status: bus.StatusDown, Error: "connection refused"}, wantStatus: http.StatusServiceUnavailable},
+		{event: bus.HealthEvent{Component: logging.LogstashComponent, Status: bus.StatusUp}, wantStatus: http.StatusOK, wantReady: true},
+	}
+
+	for _, step := range steps {
+		b.Publish(step.event)
+
+		// Подписчик обновляет состояние в своей горутине
+		var (
+			status int
+			body   readinessResponse
+		)
+		deadline := time.Now().Add(time.Second)
+		for {
+			status, body = getReadiness(t, h)
+			if body.Components[logging.LogstashComponent] == step.event.Status || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		if status != step.wantStatus || body.Ready != step.wantReady {
+			t.Errorf("after %s: status = %d ready = %v, want %d %v", step.event.Status, status, body.Ready, step.wantStatus, step.wantReady)
+		}
+		if got := body.Components[logging.LogstashComponent]; got != step.event.Status {
+			t.Errorf("after %s: components.logstash = %q", step.event.Status, got)
+		}
+		if !step.wantReady && (len(body.Reasons) != 1 || body.Reasons[0] != "logstash: connection refused") {
+			t.Errorf("reasons = %v, want [logstash: connection refused]", body.Reasons)
+		}
+	}
+}
+
+// pingFunc - Pinger из функции, заменяет ELKLogger.Ping в тестах
+type pingFunc func() error
+
+func (f pingFunc) Ping() error { return f() }
+
+func TestReadinessHandler_Ping(t *testing.T) {
+	healthy := pingFunc(func() error { return nil })
+	unreachable := pingFunc(func() error { return errors.New("dial tcp logstash:5000: connection refused") })
+
+	tests := []struct {
+		name        string
+		checks      map[string]Pinger
+		wantStatus  int
+		wantReady   bool
+		wantReasons []string
+	}{
+		{name: "no checks", wantStatus: http.StatusOK, wantReady: true},
+		{
+			name:       "logstash reachable",
+			checks:     map[string]Pinger{logging.LogstashComponent: healthy},
+			wantStatus: http.StatusOK,
+			wantReady:  true,
+		},
+		{
+			name:        "logstash unreachable",
+			checks:      map[string]Pinger{logging.LogstashComponent: unreachable},
+			wantStatus:  http.StatusServiceUnavailable,
+			wantReasons: []string{"logstash: dial tcp logstash:5000: connection refused"},
+		},
+		{
+			name:        "one of several down",
+			checks:      map[string]Pinger{logging.LogstashComponent: unreachable, "database": healthy},
+			wantStatus:  http.StatusServiceUnavailable,
+			wantReasons: []string{"logstash: dial tcp logstash:5000: connection refused"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetReadiness(t)
+			for component, pinger := range tt.checks {
+				AddReadinessCheck(component, pinger)
+			}
+			h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+			status, body := getReadiness(t, h)
+			if status != tt.wantStatus || body.Ready != tt.wantReady {
+				t.Errorf("status = %d ready = %v, want %d %v", status, body.Ready, tt.wantStatus, tt.wantReady)
+			}
+			if !reflect.DeepEqual(body.Reasons, tt.wantReasons) {
+				t.Errorf("reasons = %v, want %v", body.Reasons, tt.wantReasons)
+			}
+			for component := range tt.checks {
+				if _, ok := body.Components[component]; !ok {
+					t.Errorf("components has no %s", component)
+				}
+			}
+		})
+	}
+}
+
+// Liveness не зависит от готовности зависимостей
+func TestHealthHandler_IgnoresReadiness(t *testing.T) {
+	resetReadiness(t)
+	AddReadinessCheck(logging.LogstashComponent, pingFunc(func() error { return errors.New("connection refused") }))
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	if code, body := getHealth(t, h); code != http.StatusOK || body["status"] != "healthy" {
+		t.Errorf("health = %d %v, want 200 healthy while logstash is down", code, body["status"])
+	}
+}
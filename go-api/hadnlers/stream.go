@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+)
+
+// streamUsers пишет всех пользователей по одному элементу (JSON массив
+// или строки CSV), так что память на ответ не зависит от их числа
+func (h *Handler) streamUsers(w http.ResponseWriter, r *http.Request, includeDeleted bool, format string) {
+	logger := h.logger(r)
+
+	cursor, err := usersStore.Cursor(r.Context(), includeDeleted)
+	if err != nil {
+		logger.Error("Failed to open users cursor", map[string]interface{}{
+			"error": err,
+		})
+
+		h.Metrics.RecordError("database", "/api/users")
+		WriteError(w, http.StatusInternalServerError, ErrTypeDatabase, "Failed to list users")
+		return
+	}
+	defer cursor.Close()
+
+	var count int
+	if format == formatCSV {
+		setCSVHeaders(w, "users.csv")
+		count, err = writeCSVRows(w, userCSVHeader, cursor.Next, func() []string {
+			return userCSVRow(cursor.User())
+		})
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		count, err = writeJSONArray(w, cursor.Next, func(enc *json.Encoder) error {
+			return enc.Encode(cursor.User())
+		})
+	}
+	if err == nil {
+		err = cursor.Err()
+	}
+	if err != nil {
+		// Заголовки уже отправлены: клиент получит оборванный массив
+		logger.Error("Users stream interrupted", map[string]interface{}{
+			"streamed": count,
+			"error":    err,
+		})
+
+		h.Metrics.RecordError("database", "/api/users")
+		return
+	}
+
+	logger.Info("Users stream completed", map[string]interface{}{
+		"user_count": count,
+		"format":     format,
+	})
+}
+
+// writeJSONArray пишет "[", элементы через "," и "]". next переходит
+// к следующему элементу, encode сериализует текущий
+func writeJSONArray(w http.ResponseWriter, next func() bool, encode func(*json.Encoder) error) (int, error) {
+	enc := json.NewEncoder(w)
+
+	if _, err := w.Write([]byte("[")); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for next() {
+		if count > 0 {
+			if _, err := w.Write([]byte(",")); err != nil {
+				return count, err
+			}
+		}
+		if err := encode(enc); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	_, err := w.Write([]byte("]\n"))
+	return count, err
+}
+
+// writeCSVRows пишет заголовок и строки CSV, сбрасывая буфер writer'а
+// после каждой строки
+func writeCSVRows(w http.ResponseWriter, header []string, next func() bool, row func() []string) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for next() {
+		if err := cw.Write(row()); err != nil {
+			return count, err
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	cw.Flush()
+	return count, cw.Error()
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/crazy1997/go-api/logging"
+)
+
+// generatedUsersStore выдает count пользователей курсором, создавая
+// каждого при чтении, чтобы в памяти не было всего списка
+type generatedUsersStore struct {
+	UsersStore
+	count int
+	name  string
+	err   error
+
+	// onNext вызывается перед переходом к каждому следующему пользователю
+	onNext func(n int)
+}
+
+func (s *generatedUsersStore) Cursor(ctx context.Context, includeDeleted bool) (UserCursor, error) {
+	return &generatedUserCursor{store: s}, nil
+}
+
+type generatedUserCursor struct {
+	store *generatedUsersStore
+	n     int
+	err   error
+}
+
+func (c *generatedUserCursor) Next() bool {
+	if c.store.onNext != nil {
+		c.store.onNext(c.n)
+	}
+	if c.n == c.store.count {
+		c.err = c.store.err
+		return false
+	}
+	c.n++
+	return true
+}
+
+func (c *generatedUserCursor) User() User {
+	return User{ID: c.n, Name: c.store.name, Email: fmt.Sprintf("user-%d@example.com", c.n)}
+}
+
+func (c *generatedUserCursor) Err() error   { return c.err }
+func (c *generatedUserCursor) Close() error { return nil }
+
+// discardWriter - ResponseWriter, который только считает записанные байты
+type discardWriter struct {
+	header http.Header
+	code   int
+	n      int
+}
+
+func (w *discardWriter) Header() http.Header { return w.header }
+
+func (w *discardWriter) WriteHeader(code int) { w.code = code }
+
+func (w *discardWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return len(p), nil
+}
+
+// useGeneratedUsersStore подменяет хранилище пользователей на время теста
+func useGeneratedUsersStore(t *testing.T, store *generatedUsersStore) {
+	t.Helper()
+
+	prev := usersStore
+	SetUsersStore(store)
+	t.Cleanup(func() { SetUsersStore(prev) })
+}
+
+// Ответ на 10 000 пользователей по 1 KB не накапливается в памяти:
+// каждый пользователь уходит клиенту до чтения следующего
+func TestStreamUsers_TenThousandItems(t *testing.T) {
+	const count = 10000
+	name := strings.Repeat("n", 1024)
+
+	w := &discardWriter{header: make(http.Header)}
+	var baseline, peak uint64
+	var ms runtime.MemStats
+
+	store := &generatedUsersStore{count: count, name: name}
+	store.onNext = func(n int) {
+		// Предыдущий пользователь уже записан в ответ
+		if n > 0 && w.n < n*len(name) {
+			t.Fatalf("%d users read but only %d bytes written", n, w.n)
+		}
+		if n%500 == 0 {
+			runtime.ReadMemStats(&ms)
+			peak = max(peak, ms.HeapInuse)
+		}
+	}
+	useGeneratedUsersStore(t, store)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	runtime.GC()
+	runtime.ReadMemStats(&ms)
+	baseline = ms.HeapInuse
+
+	h.UsersHandler(w, httptest.NewRequest(http.MethodGet, "/api/users?stream=true", nil))
+
+	if w.code != 0 && w.code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.code)
+	}
+	if w.n < count*len(name) {
+		t.Fatalf("streamed %d bytes, want at least %d", w.n, count*len(name))
+	}
+	// Весь ответ занимает больше 10 MB, на сборщик мусора оставлен запас
+	if growth := int64(peak) - int64(baseline); growth > int64(w.n)/2 {
+		t.Errorf("heap in use grew by %d bytes while streaming %d bytes", growth, w.n)
+	}
+}
+
+func TestUsersHandler_Stream(t *testing.T) {
+	tests := []struct {
+		name    string
+		accept  string
+		count   int
+		err     error
+		check   func(t *testing.T, body string)
+		wantLog string
+	}{
+		{
+			name:  "json array",
+			count: 3,
+			check: func(t *testing.T, body string) {
+				var users []User
+				if err := json.Unmarshal([]byte(body), &users); err != nil {
+					t.Fatalf("decode %q: %v", body, err)
+				}
+				if len(users) != 3 || users[0].ID != 1 || users[2].ID != 3 {
+					t.Errorf("users = %+v, want IDs 1..3", users)
+				}
+			},
+			wantLog: "Users stream completed",
+		},
+		{
+			name: "empty",
+			check: func(t *testing.T, body string) {
+				if body != "[]\n" {
+					t.Errorf("body = %q, want []", body)
+				}
+			},
+			wantLog: "Users stream completed",
+		},
+		{
+			name:   "csv",
+			accept: "text/csv",
+			count:  2,
+			check: func(t *testing.T, body string) {
+				if lines := strings.Split(strings.TrimSpace(body), "\n"); len(lines) != 3 {
+					t.Errorf("csv has %d lines, want header and 2 rows: %q", len(lines), body)
+				}
+			},
+			wantLog: "Users stream completed",
+		},
+		{
+			name:  "cursor error",
+			count: 2,
+			err:   errors.New("connection reset"),
+			check: func(t *testing.T, body string) {
+				if strings.Count(body, `"id"`) != 2 {
+					t.Errorf("body = %q, want the 2 users read before the error", body)
+				}
+			},
+			wantLog: "Users stream interrupted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useGeneratedUsersStore(t, &generatedUsersStore{count: tt.count, name: "user", err: tt.err})
+			logger := logging.NewBufferedLogger()
+			h := New(Config{Logger: logger, Rand: fixedRand{}})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/users?stream=true", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			h.UsersHandler(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			tt.check(t, rec.Body.String())
+
+			logged := false
+			for _, entry := range logger.Entries() {
+				if entry.Message == tt.wantLog {
+					logged = true
+				}
+			}
+			if !logged {
+				t.Errorf("no %q entry", tt.wantLog)
+			}
+		})
+	}
+}
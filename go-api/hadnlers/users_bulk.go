@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/crazy1997/go-api/validators"
+)
+
+// Максимум пользователей в одном запросе массового создания
+const maxBulkUsers = 500
+
+// BulkCreateUsersHandler создает пользователей пакетом: либо все, либо
+// ни одного. Ошибки проверки возвращаются по каждой позиции пакета
+func (h *Handler) BulkCreateUsersHandler(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger(r)
+	started := time.Now()
+
+	var input struct {
+		Users []NewUser `json:"users"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("Failed to parse bulk user data", map[string]interface{}{
+			"error": err,
+		})
+
+		h.Metrics.RecordError("validation", "/api/users/bulk")
+		writeDecodeError(w, err)
+		return
+	}
+
+	switch {
+	case len(input.Users) == 0:
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, "users must not be empty", "users")
+		return
+	case len(input.Users) > maxBulkUsers:
+		writeFieldError(w, http.StatusBadRequest, ErrTypeValidation, fmt.Sprintf("users must contain at most %d items", maxBulkUsers), "users")
+		return
+	}
+
+	var errs validators.Errors
+	for i := range input.Users {
+		u := &input.Users[i]
+		u.Name = strings.TrimSpace(u.Name)
+		u.Email = strings.TrimSpace(u.Email)
+
+		if fe := validateUser(u.Name, u.Email); fe != nil {
+			fe.Field = fmt.Sprintf("users[%d].%s", i, fe.Field)
+			errs = append(errs, *fe)
+		}
+	}
+
+	var created []User
+	if len(errs) == 0 {
+		var err error
+		created, err = usersStore.CreateMany(r.Context(), input.Users)
+
+		var duplicates *DuplicateEmailsError
+		switch {
+		case errors.As(err, &duplicates):
+			for _, i := range duplicates.Indexes {
+				errs = append(errs, validators.FieldError{Field: fmt.Sprintf("users[%d].email", i), Message: "is already registered"})
+			}
+		case err != nil:
+			logger.Error("Failed to create users", map[string]interface{}{
+				"count": len(input.Users),
+				"error": err,
+			})
+
+			h.Metrics.RecordError("database", "/api/users/bulk")
+			WriteError(w, http.StatusInternalServerError, ErrTypeDatabase, "Failed to create users")
+			return
+		}
+	}
+
+	if len(errs) > 0 {
+		logger.Warn("Bulk user validation failed", map[string]interface{}{
+			"count":  len(input.Users),
+			"errors": len(errs),
+		})
+
+		h.Metrics.RecordError("validation", "/api/users/bulk")
+		writeErrorDetails(w, http.StatusUnprocessableEntity, ErrTypeValidation, "users validation failed, no users created", map[string]interface{}{
+			"errors": errs.Messages(),
+		})
+		return
+	}
+
+	h.Metrics.RecordUsersBulkCreated(len(created))
+	h.flushUsersCache()
+
+	ids := make([]int, len(created))
+	for i, u := range created {
+		ids[i] = u.ID
+	}
+
+	logger.Info("Users created in bulk", map[string]interface{}{
+		"count":       len(created),
+		"duration_ms": time.Since(started).Milliseconds(),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"created": len(created),
+		"ids":     ids,
+	})
+}
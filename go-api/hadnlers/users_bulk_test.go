@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/logging"
+)
+
+// bulkBody собирает тело {"users": [...]} из count корректных пользователей,
+// начиная с номера first, и подменяет позиции из override
+func bulkBody(first, count int, override map[int]string) string {
+	items := make([]string, count)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"name": "bulk-%d", "email": "bulk-%d@example.com"}`, first+i, first+i)
+		if item, ok := override[i]; ok {
+			items[i] = item
+		}
+	}
+	return `{"users": [` + strings.Join(items, ",") + `]}`
+}
+
+// storedUsers возвращает число пользователей в хранилище
+func storedUsers(t *testing.T) int {
+	t.Helper()
+
+	_, total, err := usersStore.List(context.Background(), 0, 1, true)
+	if err != nil {
+		t.Fatalf("list users: %v", err)
+	}
+	return total
+}
+
+func TestBulkCreateUsersHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantType   string
+		wantErrors []string
+	}{
+		{name: "valid batch", body: bulkBody(1, 3, nil), wantStatus: http.StatusCreated},
+		{name: "max batch", body: bulkBody(1, maxBulkUsers, nil), wantStatus: http.StatusCreated},
+		{
+			name:       "one invalid email",
+			body:       bulkBody(1, 5, map[int]string{2: `{"name": "broken", "email": "not-an-email"}`}),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantType:   ErrTypeValidation,
+			wantErrors: []string{"users[2].email"},
+		},
+		{
+			name:       "last item without name",
+			body:       bulkBody(1, 500, map[int]string{499: `{"name": "  ", "email": "blank@example.com"}`}),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantType:   ErrTypeValidation,
+			wantErrors: []string{"users[499].name"},
+		},
+		{
+			name:       "several invalid items",
+			body:       bulkBody(1, 4, map[int]string{0: `{"name": "", "email": "a@example.com"}`, 3: `{"name": "d", "email": "d"}`}),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantType:   ErrTypeValidation,
+			wantErrors: []string{"users[0].name", "users[3].email"},
+		},
+		{
+			name:       "email already registered",
+			body:       bulkBody(1, 3, map[int]string{1: `{"name": "copy", "email": "USER-1@example.com"}`}),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantType:   ErrTypeValidation,
+			wantErrors: []string{"users[1].email"},
+		},
+		{
+			name:       "email repeated in batch",
+			body:       bulkBody(1, 3, map[int]string{2: `{"name": "again", "email": "bulk-1@example.com"}`}),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantType:   ErrTypeValidation,
+			wantErrors: []string{"users[2].email"},
+		},
+		{name: "empty batch", body: `{"users": []}`, wantStatus: http.StatusBadRequest, wantType: ErrTypeValidation},
+		{name: "too many users", body: bulkBody(1, maxBulkUsers+1, nil), wantStatus: http.StatusBadRequest, wantType: ErrTypeValidation},
+		{name: "malformed json", body: `{"users": [`, wantStatus: http.StatusBadRequest, wantType: ErrTypeInvalidJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCursorStores(t, 10)
+			logger := logging.NewBufferedLogger()
+			recorder := &countingRecorder{}
+			h := New(Config{Logger: logger, Metrics: recorder, Rand: fixedRand{}})
+
+			rec := httptest.NewRecorder()
+			h.BulkCreateUsersHandler(rec, httptest.NewRequest(http.MethodPost, "/api/users/bulk", strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			if tt.wantType != "" {
+				apiErr := decodeAPIError(t, rec)
+				if apiErr.Type != tt.wantType {
+					t.Errorf("error type = %q, want %q", apiErr.Type, tt.wantType)
+				}
+				if tt.wantErrors != nil {
+					errs, _ := apiErr.Details["errors"].([]interface{})
+					if len(errs) != len(tt.wantErrors) {
+						t.Fatalf("errors = %v, want %d items", errs, len(tt.wantErrors))
+					}
+					for i, field := range tt.wantErrors {
+						if msg, _ := errs[i].(string); !strings.HasPrefix(msg, field) {
+							t.Errorf("errors[%d] = %q, want an error for %s", i, msg, field)
+						}
+					}
+				}
+
+				// Ни одной записи при любой ошибке
+				if got := storedUsers(t); got != 10 {
+					t.Errorf("%d users stored, want 10: failed batch persisted users", got)
+				}
+				if got := recorder.bulkCreated.Load(); got != 0 {
+					t.Errorf("users_bulk_created_total increased by %d, want 0", got)
+				}
+				return
+			}
+
+			var body struct {
+				Created int   `json:"created"`
+				IDs     []int `json:"ids"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			var input struct{ Users []NewUser }
+			json.Unmarshal([]byte(tt.body), &input)
+			want := len(input.Users)
+
+			if body.Created != want || len(body.IDs) != want {
+				t.Fatalf("created = %d with %d ids, want %d", body.Created, len(body.IDs), want)
+			}
+			for i, id := range body.IDs {
+				if id != 11+i {
+					t.Fatalf("ids[%d] = %d, want %d", i, id, 11+i)
+				}
+			}
+			if got := storedUsers(t); got != 10+want {
+				t.Errorf("%d users stored, want %d", got, 10+want)
+			}
+			if got := recorder.bulkCreated.Load(); got != int64(want) {
+				t.Errorf("users_bulk_created_total increased by %d, want %d", got, want)
+			}
+
+			logged := false
+			for _, entry := range logger.Entries() {
+				if entry.Message == "Users created in bulk" {
+					_, hasDuration := entry.Fields["duration_ms"]
+					logged = entry.Fields["count"] == want && hasDuration
+				}
+			}
+			if !logged {
+				t.Errorf("no \"Users created in bulk\" entry with count %d and duration_ms", want)
+			}
+		})
+	}
+}
+
+// Созданные пакетом пользователи сразу видны в списке, минуя кэш
+func TestBulkCreateUsersHandler_FlushesCache(t *testing.T) {
+	useCursorStores(t, 2)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}, UserCacheTTL: time.Minute})
+
+	list := func() int {
+		rec := httptest.NewRecorder()
+		h.UsersHandler(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+		var body struct{ Meta PageMeta }
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("decode users: %v", err)
+		}
+		return body.Meta.Total
+	}
+
+	if got := list(); got != 2 {
+		t.Fatalf("total before bulk = %d, want 2", got)
+	}
+	rec := httptest.NewRecorder()
+	h.BulkCreateUsersHandler(rec, httptest.NewRequest(http.MethodPost, "/api/users/bulk", strings.NewReader(bulkBody(1, 3, nil))))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("bulk status = %d, body %s", rec.Code, rec.Body)
+	}
+	if got := list(); got != 5 {
+		t.Errorf("total after bulk = %d, want 5", got)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Максимум страниц списка пользователей в кэше
+const usersCacheCapacity = 1000
+
+// usersCacheKey - параметры запроса списка пользователей
+type usersCacheKey struct {
+	page           int
+	limit          int
+	includeDeleted bool
+}
+
+// usersPage - страница пользователей и общее число записей
+type usersPage struct {
+	users []User
+	total int
+}
+
+// listUsers возвращает страницу пользователей из кэша или хранилища
+func (h *Handler) listUsers(ctx context.Context, key usersCacheKey) (usersPage, error) {
+	if h.usersCache != nil {
+		if cached, ok := h.usersCache.Get(key); ok {
+			trace.SpanFromContext(ctx).AddEvent("users.cache_hit", trace.WithAttributes(
+				attribute.Int("page", key.page),
+			))
+			return cached, nil
+		}
+	}
+
+	// Симуляция задержки БД
+	time.Sleep(time.Duration(h.Rand.Intn(200)) * time.Millisecond)
+
+	users, total, err := usersStore.List(ctx, (key.page-1)*key.limit, key.limit, key.includeDeleted)
+	if err != nil {
+		return usersPage{}, err
+	}
+	trace.SpanFromContext(ctx).AddEvent("users.loaded", trace.WithAttributes(
+		attribute.Int("page", key.page),
+		attribute.Int("user_count", len(users)),
+	))
+
+	result := usersPage{users: users, total: total}
+	if h.usersCache != nil {
+		h.usersCache.Set(key, result)
+	}
+	return result, nil
+}
+
+// flushUsersCache сбрасывает закэшированные страницы после изменения пользователей
+func (h *Handler) flushUsersCache() {
+	if h.usersCache != nil {
+		h.usersCache.Clear()
+	}
+}
+
+// CacheFlushHandler сбрасывает кэши пользователей и каталога продуктов
+func (h *Handler) CacheFlushHandler(w http.ResponseWriter, r *http.Request) {
+	h.flushUsersCache()
+	InvalidateProductsCache()
+
+	h.logger(r).Warn("Caches flushed", map[string]interface{}{
+		"caches":    []string{"users", "products"},
+		"client_ip": r.RemoteAddr,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"flushed": []string{"users", "products"},
+	})
+}
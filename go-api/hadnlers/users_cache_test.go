@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// countingUsersStore считает обращения к хранилищу за списком пользователей
+type countingUsersStore struct {
+	UsersStore
+	lists atomic.Int64
+}
+
+func (s *countingUsersStore) List(ctx context.Context, offset, limit int, includeDeleted bool) ([]User, int, error) {
+	s.lists.Add(1)
+	return s.UsersStore.List(ctx, offset, limit, includeDeleted)
+}
+
+// useCountingUsersStore оборачивает тестовое хранилище пользователей счетчиком
+func useCountingUsersStore(t *testing.T, count int) *countingUsersStore {
+	t.Helper()
+
+	useCursorStores(t, count)
+	store := &countingUsersStore{UsersStore: usersStore}
+	SetUsersStore(store)
+	return store
+}
+
+// getUsers выполняет GET /api/users и проверяет, что ответ успешный
+func getUsers(t *testing.T, h *Handler, query string) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.UsersHandler(rec, httptest.NewRequest(http.MethodGet, "/api/users"+query, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/users%s: status = %d: %s", query, rec.Code, rec.Body)
+	}
+}
+
+func TestUsersHandler_Cache(t *testing.T) {
+	tests := []struct {
+		name      string
+		queries   []string
+		wantLoads int64
+	}{
+		{name: "identical requests", queries: []string{"?page=1&limit=5", "?page=1&limit=5"}, wantLoads: 1},
+		{name: "different page", queries: []string{"?page=1&limit=5", "?page=2&limit=5"}, wantLoads: 2},
+		{name: "different limit", queries: []string{"?page=1&limit=5", "?page=1&limit=10"}, wantLoads: 2},
+		{name: "deleted filter", queries: []string{"?page=1&limit=5", "?page=1&limit=5&include_deleted=true"}, wantLoads: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := useCountingUsersStore(t, 20)
+			h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}, UserCacheTTL: time.Minute})
+
+			before := gatherCache(t, prometheus.DefaultGatherer, "users")
+			for _, query := range tt.queries {
+				getUsers(t, h, query)
+			}
+
+			if got := store.lists.Load(); got != tt.wantLoads {
+				t.Errorf("store queried %d times, want %d", got, tt.wantLoads)
+			}
+			after := gatherCache(t, prometheus.DefaultGatherer, "users")
+			hits := after["cache_hits_total"] - before["cache_hits_total"]
+			misses := after["cache_misses_total"] - before["cache_misses_total"]
+			if misses != float64(tt.wantLoads) || hits != float64(int64(len(tt.queries))-tt.wantLoads) {
+				t.Errorf("hits = %v, misses = %v, want %d and %d", hits, misses, int64(len(tt.queries))-tt.wantLoads, tt.wantLoads)
+			}
+		})
+	}
+}
+
+func TestUsersHandler_CacheExpires(t *testing.T) {
+	store := useCountingUsersStore(t, 5)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}, UserCacheTTL: 30 * time.Millisecond})
+
+	getUsers(t, h, "")
+	getUsers(t, h, "")
+	if got := store.lists.Load(); got != 1 {
+		t.Fatalf("store queried %d times before expiry, want 1", got)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	getUsers(t, h, "")
+	if got := store.lists.Load(); got != 2 {
+		t.Errorf("store queried %d times after TTL expiry, want 2", got)
+	}
+}
+
+func TestUsersHandler_CacheInvalidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(t *testing.T, h *Handler)
+	}{
+		{
+			name: "flush endpoint",
+			mutate: func(t *testing.T, h *Handler) {
+				rec := httptest.NewRecorder()
+				h.CacheFlushHandler(rec, httptest.NewRequest(http.MethodPatch, "/api/cache/flush", nil))
+
+				var body struct {
+					Flushed []string `json:"flushed"`
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatalf("decode flush response: %v", err)
+				}
+				if !reflect.DeepEqual(body.Flushed, []string{"users", "products"}) {
+					t.Errorf("flushed = %v, want [users products]", body.Flushed)
+				}
+			},
+		},
+		{
+			name: "user created",
+			mutate: func(t *testing.T, h *Handler) {
+				rec := httptest.NewRecorder()
+				h.CreateUserHandler(rec, httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(`{"name": "Alice", "email": "alice@example.com"}`)))
+				if rec.Code != http.StatusCreated {
+					t.Fatalf("create status = %d", rec.Code)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := useCountingUsersStore(t, 5)
+			h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}, UserCacheTTL: time.Minute})
+
+			getUsers(t, h, "")
+			tt.mutate(t, h)
+			getUsers(t, h, "")
+
+			if got := store.lists.Load(); got != 2 {
+				t.Errorf("store queried %d times, want a fresh load after invalidation", got)
+			}
+		})
+	}
+}
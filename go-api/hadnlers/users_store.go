@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Ошибки хранилища пользователей
+var (
+	ErrDuplicateEmail = errors.New("email already registered")
+	ErrUserNotFound   = errors.New("user not found")
+	ErrUserDeleted    = errors.New("user already deleted")
+)
+
+// UsersStore - хранилище пользователей
+type UsersStore interface {
+	// List возвращает до limit пользователей начиная с offset и общее их число.
+	// Удаленные пользователи включаются только при includeDeleted
+	List(ctx context.Context, offset, limit int, includeDeleted bool) ([]User, int, error)
+
+	// ListByCursor возвращает до limit пользователей с ID больше afterID
+	// по возрастанию ID (WHERE id > afterID ORDER BY id LIMIT limit)
+	ListByCursor(ctx context.Context, afterID, limit int, includeDeleted bool) ([]User, error)
+
+	// Create сохраняет пользователя с новым ID, email должен быть уникальным
+	Create(ctx context.Context, name, email string) (User, error)
+
+	// CreateMany сохраняет всех пользователей или ни одного. При занятых
+	// или повторяющихся email возвращает *DuplicateEmailsError
+	CreateMany(ctx context.Context, users []NewUser) ([]User, error)
+
+	// Get возвращает пользователя по ID, в том числе удаленного, или ErrUserNotFound
+	Get(ctx context.Context, id int) (User, error)
+
+	// Delete помечает пользователя удаленным (soft delete)
+	Delete(ctx context.Context, id int) error
+
+	// Cursor возвращает курсор по всем пользователям для потоковой выдачи.
+	// Удаленные пользователи включаются только при includeDeleted
+	Cursor(ctx context.Context, includeDeleted bool) (UserCursor, error)
+}
+
+// UserCursor читает пользователей по одному, не загружая весь список в память
+type UserCursor interface {
+	// Next переходит к следующему пользователю, false - пользователи кончились
+	Next() bool
+
+	// User возвращает текущего пользователя
+	User() User
+
+	// Err возвращает ошибку, прервавшую чтение
+	Err() error
+
+	Close() error
+}
+
+// NewUser - данные пользователя для создания
+type NewUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// DuplicateEmailsError - позиции пакета CreateMany, чьи email уже
+// зарегистрированы или повторяются внутри пакета
+type DuplicateEmailsError struct {
+	Indexes []int
+}
+
+func (e *DuplicateEmailsError) Error() string {
+	return fmt.Sprintf("%d emails already registered", len(e.Indexes))
+}
+
+func (e *DuplicateEmailsError) Unwrap() error {
+	return ErrDuplicateEmail
+}
+
+// Хранилище, используемое обработчиками
+var usersStore UsersStore = newMemoryUsersStore(seedUsers())
+
+// SetUsersStore подменяет хранилище пользователей (например, в тестах)
+func SetUsersStore(store UsersStore) {
+	usersStore = store
+}
+
+// memoryUsersStore - in-memory хранилище, пока у сервиса нет базы данных
+type memoryUsersStore struct {
+	mu     sync.RWMutex
+	users  []User
+	nextID int
+}
+
+func newMemoryUsersStore(users []User) *memoryUsersStore {
+	store := &memoryUsersStore{users: users, nextID: 1}
+	for _, u := range users {
+		if u.ID >= store.nextID {
+			store.nextID = u.ID + 1
+		}
+	}
+	return store
+}
+
+func (s *memoryUsersStore) List(ctx context.Context, offset, limit int, includeDeleted bool) ([]User, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	visible := s.users
+	if !includeDeleted {
+		visible = make([]User, 0, len(s.users))
+		for _, u := range s.users {
+			if u.DeletedAt == nil {
+				visible = append(visible, u)
+			}
+		}
+	}
+
+	total := len(visible)
+	if offset >= total {
+		return []User{}, total, nil
+	}
+
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	page := make([]User, end-offset)
+	copy(page, visible[offset:end])
+	return page, total, nil
+}
+
+func (s *memoryUsersStore) ListByCursor(ctx context.Context, afterID, limit int, includeDeleted bool) ([]User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// ID выдаются по возрастанию, поэтому users отсортирован по ID
+	start := sort.Search(len(s.users), func(i int) bool { return s.users[i].ID > afterID })
+
+	page := make([]User, 0, limit)
+	for _, u := range s.users[start:] {
+		if len(page) == limit {
+			break
+		}
+		if u.DeletedAt != nil && !includeDeleted {
+			continue
+		}
+		page = append(page, u)
+	}
+	return page, nil
+}
+
+func (s *memoryUsersStore) Create(ctx context.Context, name, email string) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if strings.EqualFold(u.Email, email) {
+			return User{}, ErrDuplicateEmail
+		}
+	}
+
+	user := User{
+		ID:        s.nextID,
+		Name:      name,
+		Email:     email,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+	s.nextID++
+	s.users = append(s.users, user)
+
+	return user, nil
+}
+
+func (s *memoryUsersStore) CreateMany(ctx context.Context, users []NewUser) ([]User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	taken := make(map[string]bool, len(s.users)+len(users))
+	for _, u := range s.users {
+		taken[strings.ToLower(u.Email)] = true
+	}
+
+	// Проверяем весь пакет до первой записи
+	var duplicates []int
+	for i, u := range users {
+		email := strings.ToLower(u.Email)
+		if taken[email] {
+			duplicates = append(duplicates, i)
+		}
+		taken[email] = true
+	}
+	if len(duplicates) > 0 {
+		return nil, &DuplicateEmailsError{Indexes: duplicates}
+	}
+
+	createdAt := time.Now().Format(time.RFC3339)
+	created := make([]User, len(users))
+	for i, u := range users {
+		created[i] = User{ID: s.nextID, Name: u.Name, Email: u.Email, CreatedAt: createdAt}
+		s.nextID++
+	}
+	s.users = append(s.users, created...)
+
+	return created, nil
+}
+
+func (s *memoryUsersStore) Get(ctx context.Context, id int) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return User{}, ErrUserNotFound
+}
+
+func (s *memoryUsersStore) Delete(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.users {
+		if s.users[i].ID != id {
+			continue
+		}
+		if s.users[i].DeletedAt != nil {
+			return ErrUserDeleted
+		}
+
+		now := time.Now()
+		s.users[i].DeletedAt = &now
+		return nil
+	}
+	return ErrUserNotFound
+}
+
+func (s *memoryUsersStore) Cursor(ctx context.Context, includeDeleted bool) (UserCursor, error) {
+	return &memoryUserCursor{ctx: ctx, store: s, includeDeleted: includeDeleted, pos: -1}, nil
+}
+
+// memoryUserCursor берет блокировку только на чтение одного элемента,
+// поэтому долгая выдача не блокирует запись
+type memoryUserCursor struct {
+	ctx            context.Context
+	store          *memoryUsersStore
+	includeDeleted bool
+	pos            int
+	current        User
+	err            error
+}
+
+func (c *memoryUserCursor) Next() bool {
+	if c.err != nil {
+		return false
+	}
+	if err := c.ctx.Err(); err != nil {
+		c.err = err
+		return false
+	}
+
+	c.store.mu.RLock()
+	defer c.store.mu.RUnlock()
+
+	for c.pos++; c.pos < len(c.store.users); c.pos++ {
+		u := c.store.users[c.pos]
+		if u.DeletedAt == nil || c.includeDeleted {
+			c.current = u
+			return true
+		}
+	}
+	return false
+}
+
+func (c *memoryUserCursor) User() User {
+	return c.current
+}
+
+func (c *memoryUserCursor) Err() error {
+	return c.err
+}
+
+func (c *memoryUserCursor) Close() error {
+	return nil
+}
+
+// seedUsers - начальные пользователи демо-окружения
+func seedUsers() []User {
+	now := time.Now()
+	return []User{
+		{
+			ID:        1,
+			Name:      "John Doe",
+			Email:     "john@example.com",
+			CreatedAt: now.Add(-24 * time.Hour).Format(time.RFC3339),
+		},
+		{
+			ID:        2,
+			Name:      "Jane Smith",
+			Email:     "jane@example.com",
+			CreatedAt: now.Add(-12 * time.Hour).Format(time.RFC3339),
+		},
+		{
+			ID:        3,
+			Name:      "Bob Johnson",
+			Email:     "bob@example.com",
+			CreatedAt: now.Add(-6 * time.Hour).Format(time.RFC3339),
+		},
+	}
+}
@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/gorilla/mux"
+)
+
+// decodeAPIError разбирает тело ошибки {"error": {...}}
+func decodeAPIError(t *testing.T, rec *httptest.ResponseRecorder) apiError {
+	t.Helper()
+
+	var body map[string]apiError
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode error body: %v", err)
+	}
+	return body["error"]
+}
+
+func TestUsersHandler_Pagination(t *testing.T) {
+	useCursorStores(t, 45)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	tests := []struct {
+		name      string
+		query     string
+		wantMeta  PageMeta
+		wantFirst int
+		wantCount int
+	}{
+		{name: "defaults", query: "", wantMeta: PageMeta{Page: 1, Limit: 20, Total: 45}, wantFirst: 1, wantCount: 20},
+		{name: "last partial page", query: "?page=3", wantMeta: PageMeta{Page: 3, Limit: 20, Total: 45}, wantFirst: 41, wantCount: 5},
+		{name: "page past the end", query: "?page=4", wantMeta: PageMeta{Page: 4, Limit: 20, Total: 45}, wantCount: 0},
+		{name: "minimum limit", query: "?page=45&limit=1", wantMeta: PageMeta{Page: 45, Limit: 1, Total: 45}, wantFirst: 45, wantCount: 1},
+		{name: "maximum limit", query: "?limit=100", wantMeta: PageMeta{Page: 1, Limit: 100, Total: 45}, wantFirst: 1, wantCount: 45},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.UsersHandler(rec, httptest.NewRequest(http.MethodGet, "/api/users"+tt.query, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+			}
+			var body struct {
+				Data []User   `json:"data"`
+				Meta PageMeta `json:"meta"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			if body.Meta != tt.wantMeta {
+				t.Errorf("meta = %+v, want %+v", body.Meta, tt.wantMeta)
+			}
+			if len(body.Data) != tt.wantCount {
+				t.Fatalf("got %d users, want %d", len(body.Data), tt.wantCount)
+			}
+			if tt.wantCount > 0 && body.Data[0].ID != tt.wantFirst {
+				t.Errorf("first user ID = %d, want %d", body.Data[0].ID, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestUsersHandler_InvalidPagination(t *testing.T) {
+	useCursorStores(t, 5)
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	tests := []struct {
+		query     string
+		wantField string
+	}{
+		{query: "page=0", wantField: "page"},
+		{query: "page=-1", wantField: "page"},
+		{query: "page=abc", wantField: "page"},
+		{query: "limit=0", wantField: "limit"},
+		{query: "limit=101", wantField: "limit"},
+		{query: "limit=ten", wantField: "limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.UsersHandler(rec, httptest.NewRequest(http.MethodGet, "/api/users?"+tt.query, nil))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", rec.Code)
+			}
+			apiErr := decodeAPIError(t, rec)
+			if apiErr.Type != ErrTypeValidation || apiErr.Field != tt.wantField || apiErr.Message == "" {
+				t.Errorf("error = %+v, want %s on field %s", apiErr, ErrTypeValidation, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestCreateUserHandler(t *testing.T) {
+	longName := strings.Repeat("я", maxUserNameLength)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantType   string
+		wantField  string
+	}{
+		{name: "valid", body: `{"name": "Alice", "email": "alice@example.com"}`, wantStatus: http.StatusCreated},
+		{name: "name at the limit", body: `{"name": "` + longName + `", "email": "long@example.com"}`, wantStatus: http.StatusCreated},
+		{name: "duplicate email", body: `{"name": "Copy", "email": "USER-1@example.com"}`, wantStatus: http.StatusConflict, wantType: ErrTypeConflict, wantField: "email"},
+		{name: "empty name", body: `{"name": "  ", "email": "empty@example.com"}`, wantStatus: http.StatusBadRequest, wantType: ErrTypeValidation, wantField: "name"},
+		{name: "name too long", body: `{"name": "` + longName + `x", "email": "longer@example.com"}`, wantStatus: http.StatusBadRequest, wantType: ErrTypeValidation, wantField: "name"},
+		{name: "email without domain", body: `{"name": "Bob", "email": "bob@"}`, wantStatus: http.StatusBadRequest, wantType: ErrTypeValidation, wantField: "email"},
+		{name: "email without tld", body: `{"name": "Bob", "email": "bob@localhost"}`, wantStatus: http.StatusBadRequest, wantType: ErrTypeValidation, wantField: "email"},
+		{name: "missing email", body: `{"name": "Bob"}`, wantStatus: http.StatusBadRequest, wantType: ErrTypeValidation, wantField: "email"},
+		{name: "invalid JSON", body: `{"name": `, wantStatus: http.StatusBadRequest, wantType: ErrTypeInvalidJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCursorStores(t, 3)
+			logger := logging.NewBufferedLogger()
+			recorder := &countingRecorder{}
+			h := New(Config{Logger: logger, Metrics: recorder, Rand: fixedRand{}})
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(tt.body))
+			h.CreateUserHandler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			if tt.wantStatus != http.StatusCreated {
+				apiErr := decodeAPIError(t, rec)
+				if apiErr.Type != tt.wantType || apiErr.Field != tt.wantField {
+					t.Errorf("error = %+v, want %s on field %q", apiErr, tt.wantType, tt.wantField)
+				}
+				if n := recorder.registrations.Load(); n != 0 {
+					t.Errorf("recorded %d registrations for a rejected user", n)
+				}
+				return
+			}
+
+			var user User
+			if err := json.NewDecoder(rec.Body).Decode(&user); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if user.ID != 4 || user.CreatedAt == "" {
+				t.Errorf("created %+v, want ID 4 and created_at", user)
+			}
+			if stored, err := usersStore.Get(req.Context(), user.ID); err != nil || stored.Email != user.Email {
+				t.Errorf("stored user = %+v, %v", stored, err)
+			}
+			if n := recorder.registrations.Load(); n != 1 {
+				t.Errorf("recorded %d registrations, want 1", n)
+			}
+
+			entries := logger.Entries()
+			last := entries[len(entries)-1]
+			if last.Level != "INFO" || last.Message != "User created" || last.Fields["user_id"] != user.ID || last.Fields["email"] != user.Email {
+				t.Errorf("last log entry = %+v, want User created with user_id and email", last)
+			}
+		})
+	}
+}
+
+func TestGetUserHandler(t *testing.T) {
+	useCursorStores(t, 3)
+	if err := usersStore.Delete(context.Background(), 3); err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.NewBufferedLogger()
+	h := New(Config{Logger: logger, Rand: fixedRand{}})
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+		wantBody   string
+		wantLevel  string
+	}{
+		{name: "existing user", id: "2", wantStatus: http.StatusOK, wantLevel: "INFO"},
+		{name: "missing user", id: "99", wantStatus: http.StatusNotFound, wantBody: `{"error":{"type":"not_found","message":"user not found","code":1010}}`, wantLevel: "WARN"},
+		{name: "deleted user", id: "3", wantStatus: http.StatusNotFound, wantBody: `{"error":{"type":"not_found","message":"user not found","code":1010}}`, wantLevel: "WARN"},
+		{name: "non-integer id", id: "abc", wantStatus: http.StatusBadRequest, wantBody: `{"error":{"type":"validation_error","message":"id must be an integer","code":1001,"field":"id"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger.Reset()
+			rec := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/users/"+tt.id, nil), map[string]string{"id": tt.id})
+			h.GetUserHandler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" {
+				if got := strings.TrimSpace(rec.Body.String()); got != tt.wantBody {
+					t.Errorf("body = %s, want %s", got, tt.wantBody)
+				}
+			} else {
+				var user User
+				if err := json.NewDecoder(rec.Body).Decode(&user); err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				if user.ID != 2 || user.Email != "user-2@example.com" {
+					t.Errorf("user = %+v, want user 2", user)
+				}
+			}
+
+			entries := logger.Entries()
+			if tt.wantLevel == "" {
+				if len(entries) != 0 {
+					t.Errorf("logged %+v, want nothing for a bad request", entries)
+				}
+				return
+			}
+			if len(entries) != 1 || entries[0].Level != tt.wantLevel || entries[0].Fields["user_id"] == nil {
+				t.Errorf("logged %+v, want one %s entry with user_id", entries, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestDeleteUserHandler(t *testing.T) {
+	useCursorStores(t, 3)
+	logger := logging.NewBufferedLogger()
+	recorder := &countingRecorder{}
+	h := New(Config{Logger: logger, Metrics: recorder, Rand: fixedRand{}})
+
+	deleteUser := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/api/users/"+id, nil)
+		req = req.WithContext(logging.WithRequestID(req.Context(), "req-admin-1"))
+		rec := httptest.NewRecorder()
+		h.DeleteUserHandler(rec, mux.SetURLVars(req, map[string]string{"id": id}))
+		return rec
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+		wantType   string
+	}{
+		{name: "delete", id: "2", wantStatus: http.StatusNoContent},
+		{name: "re-delete", id: "2", wantStatus: http.StatusConflict, wantType: ErrTypeConflict},
+		{name: "missing user", id: "99", wantStatus: http.StatusNotFound, wantType: ErrTypeNotFound},
+		{name: "non-integer id", id: "two", wantStatus: http.StatusBadRequest, wantType: ErrTypeValidation},
+	}
+
+	// Подтесты выполняются по порядку: повторное удаление идет после первого
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := deleteUser(tt.id)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantType != "" {
+				if apiErr := decodeAPIError(t, rec); apiErr.Type != tt.wantType {
+					t.Errorf("error type = %q, want %q", apiErr.Type, tt.wantType)
+				}
+			} else if rec.Body.Len() != 0 {
+				t.Errorf("body = %q, want empty 204", rec.Body)
+			}
+		})
+	}
+
+	if n := recorder.deletions.Load(); n != 1 {
+		t.Errorf("recorded %d deletions, want 1", n)
+	}
+
+	var audit []logging.LogEntry
+	for _, entry := range logger.Entries() {
+		if entry.Message == "User deleted" {
+			audit = append(audit, entry)
+		}
+	}
+	if len(audit) != 1 || audit[0].Fields["request_id"] != "req-admin-1" || audit[0].Fields["user_id"] != 2 {
+		t.Errorf("audit entries = %+v, want one with request_id and user_id", audit)
+	}
+}
+
+func TestUsersHandler_SoftDeletedFilter(t *testing.T) {
+	useCursorStores(t, 3)
+	if err := usersStore.Delete(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	h := New(Config{Logger: logging.NoopLogger{}, Rand: fixedRand{}})
+
+	tests := []struct {
+		query       string
+		wantIDs     []int
+		wantDeleted int
+	}{
+		{query: "", wantIDs: []int{1, 3}},
+		{query: "?include_deleted=false", wantIDs: []int{1, 3}},
+		{query: "?include_deleted=true", wantIDs: []int{1, 2, 3}, wantDeleted: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run("list"+tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.UsersHandler(rec, httptest.NewRequest(http.MethodGet, "/api/users"+tt.query, nil))
+
+			var body struct {
+				Data []User   `json:"data"`
+				Meta PageMeta `json:"meta"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			var ids []int
+			for _, u := range body.Data {
+				ids = append(ids, u.ID)
+				if (u.DeletedAt != nil) != (u.ID == tt.wantDeleted) {
+					t.Errorf("user %d deleted_at = %v", u.ID, u.DeletedAt)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) || body.Meta.Total != len(tt.wantIDs) {
+				t.Errorf("listed %v (total %d), want %v", ids, body.Meta.Total, tt.wantIDs)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Package httpclient - HTTP клиент для исходящих запросов сервиса
+// с метриками и трассировкой
+package httpclient
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/crazy1997/go-api/metrics"
+	"github.com/crazy1997/go-api/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentedClient возвращает клиент, который учитывает каждый запрос
+// в outbound_requests_total и outbound_request_duration_seconds с меткой
+// target и ведет клиентский спан с передачей traceparent получателю
+func InstrumentedClient(target string, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &instrumentedTransport{target: target, base: http.DefaultTransport},
+	}
+}
+
+type instrumentedTransport struct {
+	target string
+	base   http.RoundTripper
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := tracing.Tracer().Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.method", req.Method),
+			attribute.String("http.url", req.URL.Redacted()),
+			attribute.String("peer.service", t.target),
+		),
+	)
+	defer span.End()
+
+	if span.IsRecording() {
+		// RoundTripper не должен менять исходный запрос
+		req = req.Clone(ctx)
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		metrics.RecordOutboundRequest(t.target, req.Method, "error", time.Since(start))
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	metrics.RecordOutboundRequest(t.target, req.Method, strconv.Itoa(resp.StatusCode), time.Since(start))
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
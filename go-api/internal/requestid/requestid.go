@@ -0,0 +1,57 @@
+// Package requestid генерирует идентификаторы запросов
+package requestid
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+)
+
+// New генерирует случайный UUID версии 4 (RFC 4122) вида
+// xxxxxxxx-xxxx-4xxx-yxxx-xxxxxxxxxxxx
+func New() string {
+	var b [16]byte
+	rand.Read(b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Valid проверяет, что id - UUID версии 4 в каноническом виде,
+// шестнадцатеричные цифры допускаются в любом регистре
+func Valid(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		case 14:
+			// Версия
+			if c != '4' {
+				return false
+			}
+		case 19:
+			// Вариант RFC 4122: 10xx
+			if !strings.ContainsRune("89abAB", rune(c)) {
+				return false
+			}
+		default:
+			if !isHex(c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,53 @@
+package requestid
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var uuidV4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNew_UniqueAndWellFormed(t *testing.T) {
+	count := 1000000
+	if testing.Short() {
+		count = 10000
+	}
+
+	seen := make(map[string]struct{}, count)
+	for i := 0; i < count; i++ {
+		id := New()
+		if !uuidV4.MatchString(id) {
+			t.Fatalf("New() = %q, want xxxxxxxx-xxxx-4xxx-yxxx-xxxxxxxxxxxx", id)
+		}
+		if _, ok := seen[id]; ok {
+			t.Fatalf("collision after %d IDs: %s", i, id)
+		}
+		seen[id] = struct{}{}
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{id: "3f2c1ab0-9d4e-4c7a-8b21-0e5f6a7b8c9d", want: true},
+		{id: "3F2C1AB0-9D4E-4C7A-BB21-0E5F6A7B8C9D", want: true},
+		{id: New(), want: true},
+		{id: "", want: false},
+		{id: "req-1714000000000000000", want: false},
+		{id: "3f2c1ab0-9d4e-1c7a-8b21-0e5f6a7b8c9d", want: false},
+		{id: "3f2c1ab0-9d4e-4c7a-7b21-0e5f6a7b8c9d", want: false},
+		{id: "3f2c1ab0-9d4e-4c7a-8b21-0e5f6a7b8c9g", want: false},
+		{id: "3f2c1ab09d4e-4c7a-8b21-0e5f6a7b8c9d0", want: false},
+		{id: "3f2c1ab0-9d4e-4c7a-8b21-0e5f6a7b8c9d\n", want: false},
+		{id: strings.Repeat("a", 36), want: false},
+	}
+
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+    "crypto/tls"
+    "fmt"
+    "net/http"
+)
+
+// WithAuth добавляет заголовок авторизации ко всем HTTP запросам в Logstash.
+// Для заголовка Authorization значение передается как "Bearer <token>"
+func WithAuth(header, token string) Option {
+    return func(l *ELKLogger) {
+        if header == "" {
+            header = "Authorization"
+        }
+        l.authHeader = header
+        l.authToken = token
+    }
+}
+
+// WithClientCert включает mutual TLS с клиентским сертификатом из файлов
+func WithClientCert(certFile, keyFile string) Option {
+    return func(l *ELKLogger) {
+        l.clientCertFile = certFile
+        l.clientKeyFile = keyFile
+    }
+}
+
+// clientTLSConfig загружает клиентский сертификат, если он задан
+func (l *ELKLogger) clientTLSConfig() (*tls.Config, error) {
+    if l.clientCertFile == "" || l.clientKeyFile == "" {
+        return nil, nil
+    }
+    
+    cert, err := tls.LoadX509KeyPair(l.clientCertFile, l.clientKeyFile)
+    if err != nil {
+        return nil, fmt.Errorf("load logstash client certificate: %w", err)
+    }
+    
+    return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+// authTransport добавляет заголовок авторизации к каждому запросу
+type authTransport struct {
+    base   http.RoundTripper
+    header string
+    value  string
+}
+
+func newAuthTransport(base http.RoundTripper, header, token string) *authTransport {
+    value := token
+    if http.CanonicalHeaderKey(header) == "Authorization" {
+        value = "Bearer " + token
+    }
+    return &authTransport{base: base, header: header, value: value}
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+    // RoundTripper не должен менять исходный запрос
+    req = req.Clone(req.Context())
+    req.Header.Set(t.header, t.value)
+    return t.base.RoundTrip(req)
+}
+
+func (t *authTransport) CloseIdleConnections() {
+    if c, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+        c.CloseIdleConnections()
+    }
+}
\ No newline at end of file
@@ -0,0 +1,184 @@
+package logging
+
+import (
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/tls"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/pem"
+    "math/big"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+// newAuthLogstash отвечает 401 на запросы без заголовка header со значением want
+func newAuthLogstash(t *testing.T, header, want string) *httptest.Server {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Header.Get(header) != want {
+            w.WriteHeader(http.StatusUnauthorized)
+        }
+    }))
+    t.Cleanup(srv.Close)
+    return srv
+}
+
+func TestAuthHeader(t *testing.T) {
+    tests := []struct {
+        name    string
+        opts    []Option
+        header  string
+        want    string
+        wantErr string
+    }{
+        {
+            name:   "bearer token",
+            opts:   []Option{WithAuth("", "secret")},
+            header: "Authorization",
+            want:   "Bearer secret",
+        },
+        {
+            name:   "custom header",
+            opts:   []Option{WithAuth("X-Api-Key", "secret")},
+            header: "X-Api-Key",
+            want:   "secret",
+        },
+        {
+            name:    "wrong token",
+            opts:    []Option{WithAuth("", "stale")},
+            header:  "Authorization",
+            want:    "Bearer secret",
+            wantErr: "logstash returned error: 401",
+        },
+        {
+            name:    "no auth configured",
+            header:  "Authorization",
+            want:    "Bearer secret",
+            wantErr: "logstash returned error: 401",
+        },
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            srv := newAuthLogstash(t, tt.header, tt.want)
+            
+            l := newQueueTestLogger(tt.opts...)
+            l.logstashURL = srv.URL
+            l.httpClient = srv.Client()
+            if l.authToken != "" {
+                l.httpClient.Transport = newAuthTransport(l.httpClient.Transport, l.authHeader, l.authToken)
+            }
+            
+            err := l.postLogstash([]byte(`{"message":"auth"}`))
+            if tt.wantErr == "" && err != nil {
+                t.Fatalf("post: %v", err)
+            }
+            if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+                t.Fatalf("post error = %v, want %q", err, tt.wantErr)
+            }
+        })
+    }
+}
+
+// writeClientCert выпускает самоподписанный клиентский сертификат и
+// сохраняет его вместе с ключом в PEM файлы
+func writeClientCert(t *testing.T) (certFile, keyFile string, cert *x509.Certificate) {
+    t.Helper()
+    
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    tmpl := &x509.Certificate{
+        SerialNumber:          big.NewInt(1),
+        Subject:               pkix.Name{CommonName: "go-api"},
+        NotBefore:             time.Now().Add(-time.Hour),
+        NotAfter:              time.Now().Add(time.Hour),
+        KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+        ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+        BasicConstraintsValid: true,
+        IsCA:                  true,
+    }
+    der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    cert, err = x509.ParseCertificate(der)
+    if err != nil {
+        t.Fatal(err)
+    }
+    keyDER, err := x509.MarshalECPrivateKey(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    dir := t.TempDir()
+    certFile = filepath.Join(dir, "client.crt")
+    keyFile = filepath.Join(dir, "client.key")
+    if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    return certFile, keyFile, cert
+}
+
+func TestClientCert_MutualTLS(t *testing.T) {
+    certFile, keyFile, clientCert := writeClientCert(t)
+    
+    srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+    clientCAs := x509.NewCertPool()
+    clientCAs.AddCert(clientCert)
+    srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+    srv.StartTLS()
+    t.Cleanup(srv.Close)
+    
+    tests := []struct {
+        name    string
+        opts    []Option
+        wantErr bool
+    }{
+        {name: "client certificate", opts: []Option{WithClientCert(certFile, keyFile)}},
+        {name: "no client certificate", wantErr: true},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l := newQueueTestLogger(tt.opts...)
+            tlsConfig, err := l.clientTLSConfig()
+            if err != nil {
+                t.Fatalf("clientTLSConfig: %v", err)
+            }
+            if tlsConfig == nil {
+                tlsConfig = &tls.Config{}
+            }
+            // Доверяем самоподписанному сертификату тестового сервера
+            tlsConfig.RootCAs = x509.NewCertPool()
+            tlsConfig.RootCAs.AddCert(srv.Certificate())
+            
+            l.logstashURL = srv.URL
+            l.httpClient = newHTTPClient(false, 0, tlsConfig, nil)
+            
+            err = l.postLogstash([]byte(`{"message":"mtls"}`))
+            if (err != nil) != tt.wantErr {
+                t.Errorf("post error = %v, want error %v", err, tt.wantErr)
+            }
+        })
+    }
+}
+
+func TestClientCert_MissingFiles(t *testing.T) {
+    dir := t.TempDir()
+    l := newQueueTestLogger(WithClientCert(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")))
+    
+    if _, err := l.clientTLSConfig(); err == nil || !strings.Contains(err.Error(), "load logstash client certificate") {
+        t.Errorf("clientTLSConfig error = %v, want load failure", err)
+    }
+}
@@ -0,0 +1,128 @@
+package logging
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "os"
+    "sync"
+    "time"
+)
+
+const defaultBatchInterval = time.Second
+
+// WithBatching включает отправку записей пачками по HTTP: пачка уходит
+// одним JSON массивом при накоплении size записей или раз в interval.
+// Для TCP/UDP транспорта не применяется
+func WithBatching(size int, interval time.Duration) Option {
+    return func(l *ELKLogger) {
+        if size > 1 {
+            l.batchSize = size
+        }
+        if interval > 0 {
+            l.batchInterval = interval
+        }
+    }
+}
+
+// batchItem - запись и ее сериализованное представление
+type batchItem struct {
+    entry LogEntry
+    data  json.RawMessage
+}
+
+// batcher накапливает сериализованные записи и отправляет их пачкой
+type batcher struct {
+    mu       sync.Mutex
+    entries  []batchItem
+    size     int
+    interval time.Duration
+    send     func([]batchItem)
+}
+
+func newBatcher(size int, interval time.Duration, send func([]batchItem)) *batcher {
+    return &batcher{
+        entries:  make([]batchItem, 0, size),
+        size:     size,
+        interval: interval,
+        send:     send,
+    }
+}
+
+// add добавляет запись и отправляет пачку, если она заполнена
+func (b *batcher) add(entry LogEntry, data []byte) {
+    b.mu.Lock()
+    b.entries = append(b.entries, batchItem{entry: entry, data: data})
+    if len(b.entries) < b.size {
+        b.mu.Unlock()
+        return
+    }
+    batch := b.take()
+    b.mu.Unlock()
+    
+    b.send(batch)
+}
+
+// flush синхронно отправляет накопленные записи
+func (b *batcher) flush() {
+    b.mu.Lock()
+    batch := b.take()
+    b.mu.Unlock()
+    
+    if len(batch) > 0 {
+        b.send(batch)
+    }
+}
+
+// take забирает накопленные записи, вызывается под мьютексом
+func (b *batcher) take() []batchItem {
+    batch := b.entries
+    b.entries = make([]batchItem, 0, b.size)
+    return batch
+}
+
+// run отправляет неполные пачки по таймеру до отмены ctx
+func (b *batcher) run(ctx context.Context) {
+    ticker := time.NewTicker(b.interval)
+    defer ticker.Stop()
+    
+    for {
+        select {
+        case <-ticker.C:
+            b.flush()
+        case <-ctx.Done():
+            return
+        }
+    }
+}
+
+// sendBatch отправляет пачку одним запросом. При неудаче все записи
+// пачки считаются недоставленными и уходят в локальный fallback
+func (l *ELKLogger) sendBatch(batch []batchItem) {
+    raw := make([]json.RawMessage, len(batch))
+    for i, item := range batch {
+        raw[i] = item.data
+    }
+    
+    jsonData, err := json.Marshal(raw)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Failed to marshal log batch: %v\n", err)
+        return
+    }
+    
+    if err := l.postLogstash(jsonData); err != nil {
+        sendFailures.Add(float64(len(batch)))
+        fmt.Fprintf(os.Stderr, "Failed to send log batch of %d entries to ELK: %v\n", len(batch), err)
+        
+        for i := range batch {
+            l.saveFallback(batch[i].data)
+            l.fireHooks(OnError, &batch[i].entry)
+        }
+        return
+    }
+    
+    for i := range batch {
+        l.fireHooks(AfterSend, &batch[i].entry)
+    }
+}
+
@@ -0,0 +1,113 @@
+package logging
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "slices"
+    "sync"
+    "testing"
+    "time"
+)
+
+// batchLogstash запоминает размеры пачек, принятых Logstash
+type batchLogstash struct {
+    mu      sync.Mutex
+    batches []int
+}
+
+func (b *batchLogstash) sizes() []int {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    
+    return append([]int(nil), b.batches...)
+}
+
+func newBatchTestLogger(t *testing.T, size int, interval time.Duration) (*ELKLogger, *batchLogstash) {
+    t.Helper()
+    
+    received := &batchLogstash{}
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var entries []map[string]interface{}
+        if err := json.NewDecoder(r.Body).Decode(&entries); err != nil {
+            t.Errorf("logstash got a non-array body: %v", err)
+            w.WriteHeader(http.StatusBadRequest)
+            return
+        }
+        received.mu.Lock()
+        received.batches = append(received.batches, len(entries))
+        received.mu.Unlock()
+    }))
+    t.Cleanup(srv.Close)
+    
+    l := newQueueTestLogger(WithBatching(size, interval), WithQueueSize(64), WithTimestampPrecision("ms"))
+    l.httpClient = srv.Client()
+    l.logstashURL = srv.URL
+    l.batch = newBatcher(l.batchSize, l.batchInterval, l.sendBatch)
+    l.output = &logstashOutput{l: l}
+    return l, received
+}
+
+func TestBatching_RequestsPerEntries(t *testing.T) {
+    tests := []struct {
+        name    string
+        entries int
+        want    []int
+    }{
+        {name: "one full batch", entries: 10, want: []int{10}},
+        {name: "partial batch waits", entries: 9, want: nil},
+        {name: "two full batches", entries: 20, want: []int{10, 10}},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l, received := newBatchTestLogger(t, 10, time.Hour)
+            
+            for i := 0; i < tt.entries; i++ {
+                l.Info("batched", map[string]interface{}{"n": i})
+                l.sendLogAsync(l.lastQueued(t))
+            }
+            
+            if got := received.sizes(); !slices.Equal(got, tt.want) {
+                t.Errorf("logstash received batches %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestBatching_IntervalFlushesPartialBatch(t *testing.T) {
+    l, received := newBatchTestLogger(t, 10, 20*time.Millisecond)
+    go l.batch.run(t.Context())
+    
+    for i := 0; i < 3; i++ {
+        l.Info("batched", nil)
+        l.sendLogAsync(l.lastQueued(t))
+    }
+    
+    waitFor(t, "interval flush", func() bool { return len(received.sizes()) == 1 })
+    if got := received.sizes(); got[0] != 3 {
+        t.Errorf("interval flush sent %d entries, want 3", got[0])
+    }
+}
+
+func TestBatching_CloseFlushesSynchronously(t *testing.T) {
+    l, received := newBatchTestLogger(t, 10, time.Hour)
+    WithWorkers(2)(l)
+    l.startWorkers(context.Background())
+    
+    for i := 0; i < 4; i++ {
+        l.Info("batched", nil)
+    }
+    
+    ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+    defer cancel()
+    if err := l.Close(ctx); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    
+    // Close возвращается только после отправки остатка пачки
+    if got := received.sizes(); !slices.Equal(got, []int{4}) {
+        t.Errorf("logstash received batches %v after Close, want [4]", got)
+    }
+}
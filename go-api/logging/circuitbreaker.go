@@ -0,0 +1,156 @@
+package logging
+
+import (
+    "errors"
+    "sync"
+    "time"
+)
+
+// CircuitState - состояние circuit breaker отправки в Logstash
+type CircuitState int
+
+const (
+    // CircuitClosed - отправка работает в обычном режиме
+    CircuitClosed CircuitState = iota
+    // CircuitOpen - отправка пропускается до конца cooldown
+    CircuitOpen
+    // CircuitHalfOpen - пропускается один пробный запрос
+    CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+    switch s {
+    case CircuitOpen:
+        return "open"
+    case CircuitHalfOpen:
+        return "half_open"
+    default:
+        return "closed"
+    }
+}
+
+// ErrCircuitOpen - отправка пропущена, потому что цепь разомкнута
+var ErrCircuitOpen = errors.New("logstash circuit breaker is open")
+
+// Пауза по умолчанию, если WithCircuitBreaker получил cooldown <= 0
+const defaultCircuitCooldown = 30 * time.Second
+
+// circuitBreaker размыкает цепь после threshold сбоев подряд. Через cooldown
+// пропускает один пробный запрос: успех замыкает цепь, сбой снова размыкает
+type circuitBreaker struct {
+    mu        sync.Mutex
+    state     CircuitState
+    failures  int
+    threshold int
+    cooldown  time.Duration
+    openedAt  time.Time
+    probing   bool
+    
+    // Источник времени, подменяется в тестах
+    now func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+    cb := &circuitBreaker{
+        threshold: threshold,
+        cooldown:  cooldown,
+        now:       time.Now,
+    }
+    circuitState.Set(float64(CircuitClosed))
+    return cb
+}
+
+// allow решает, выполнять ли отправку. probe = true для пробного запроса
+// в HalfOpen, его не нужно повторять при сбое
+func (cb *circuitBreaker) allow() (ok, probe bool) {
+    cb.mu.Lock()
+    defer cb.mu.Unlock()
+    
+    switch cb.state {
+    case CircuitOpen:
+        if cb.now().Sub(cb.openedAt) < cb.cooldown {
+            return false, false
+        }
+        cb.setState(CircuitHalfOpen)
+        cb.probing = true
+        return true, true
+    case CircuitHalfOpen:
+        // Пробный запрос уже выполняется
+        if cb.probing {
+            return false, false
+        }
+        cb.probing = true
+        return true, true
+    }
+    return true, false
+}
+
+// record учитывает результат отправки
+func (cb *circuitBreaker) record(err error) {
+    cb.mu.Lock()
+    defer cb.mu.Unlock()
+    
+    cb.probing = false
+    if err == nil {
+        cb.failures = 0
+        cb.setState(CircuitClosed)
+        return
+    }
+    
+    cb.failures++
+    if cb.state == CircuitHalfOpen || cb.failures >= cb.threshold {
+        cb.openedAt = cb.now()
+        cb.setState(CircuitOpen)
+    }
+}
+
+// State возвращает текущее состояние
+func (cb *circuitBreaker) State() CircuitState {
+    cb.mu.Lock()
+    defer cb.mu.Unlock()
+    return cb.state
+}
+
+func (cb *circuitBreaker) setState(state CircuitState) {
+    if cb.state == state {
+        return
+    }
+    cb.state = state
+    circuitState.Set(float64(state))
+}
+
+// WithCircuitBreaker размыкает отправку в Logstash после failures сбоев
+// подряд на cooldown. failures <= 0 отключает circuit breaker
+func WithCircuitBreaker(failures int, cooldown time.Duration) Option {
+    return func(l *ELKLogger) {
+        if failures <= 0 {
+            l.breaker = nil
+            return
+        }
+        if cooldown <= 0 {
+            cooldown = defaultCircuitCooldown
+        }
+        l.breaker = newCircuitBreaker(failures, cooldown)
+    }
+}
+
+// postLogstash отправляет data в Logstash по HTTP через circuit breaker
+func (l *ELKLogger) postLogstash(data []byte) error {
+    if l.breaker == nil {
+        return retryablePost(l.httpClient, l.logstashURL, data, l.maxRetries)
+    }
+    
+    ok, probe := l.breaker.allow()
+    if !ok {
+        circuitOpenDrops.Inc()
+        return ErrCircuitOpen
+    }
+    
+    retries := l.maxRetries
+    if probe {
+        retries = 0
+    }
+    err := retryablePost(l.httpClient, l.logstashURL, data, retries)
+    l.breaker.record(err)
+    return err
+}
\ No newline at end of file
@@ -0,0 +1,146 @@
+package logging
+
+import (
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+    "time"
+    
+    promtest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// newToggleLogstash отвечает 200, пока up = true, иначе 503
+func newToggleLogstash(t *testing.T) (*httptest.Server, *atomic.Bool, *atomic.Int64) {
+    var up atomic.Bool
+    var calls atomic.Int64
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        calls.Add(1)
+        if !up.Load() {
+            w.WriteHeader(http.StatusServiceUnavailable)
+        }
+    }))
+    t.Cleanup(srv.Close)
+    return srv, &up, &calls
+}
+
+// errAny - в таблице шагов означает любую ошибку отправки, кроме ErrCircuitOpen
+var errAny = errors.New("any send error")
+
+func TestCircuitBreaker_Cycle(t *testing.T) {
+    const cooldown = time.Minute
+    
+    srv, up, calls := newToggleLogstash(t)
+    l := newQueueTestLogger(WithCircuitBreaker(3, cooldown))
+    l.httpClient, l.logstashURL = srv.Client(), srv.URL
+    
+    now := time.Now()
+    l.breaker.now = func() time.Time { return now }
+    
+    steps := []struct {
+        name      string
+        advance   time.Duration
+        up        bool
+        wantErr   error
+        wantState CircuitState
+        wantCalls int64
+        wantDrops float64
+    }{
+        {name: "first failure", wantErr: errAny, wantState: CircuitClosed, wantCalls: 1},
+        {name: "second failure", wantErr: errAny, wantState: CircuitClosed, wantCalls: 1},
+        {name: "threshold opens", wantErr: errAny, wantState: CircuitOpen, wantCalls: 1},
+        {name: "open skips send", wantErr: ErrCircuitOpen, wantState: CircuitOpen, wantDrops: 1},
+        {name: "recovered but cooling down", up: true, advance: cooldown / 2, wantErr: ErrCircuitOpen, wantState: CircuitOpen, wantDrops: 1},
+        {name: "failed probe reopens", advance: cooldown, wantErr: errAny, wantState: CircuitOpen, wantCalls: 1},
+        {name: "cooldown restarted", advance: cooldown / 2, wantErr: ErrCircuitOpen, wantState: CircuitOpen, wantDrops: 1},
+        {name: "successful probe closes", up: true, advance: cooldown, wantState: CircuitClosed, wantCalls: 1},
+        {name: "closed sends", up: true, wantState: CircuitClosed, wantCalls: 1},
+    }
+    
+    for _, step := range steps {
+        now = now.Add(step.advance)
+        up.Store(step.up)
+        callsBefore, dropsBefore := calls.Load(), promtest.ToFloat64(circuitOpenDrops)
+        
+        err := l.postLogstash([]byte(`{"message":"circuit"}`))
+        
+        switch {
+        case step.wantErr == nil && err != nil:
+            t.Errorf("%s: postLogstash: %v", step.name, err)
+        case step.wantErr == errAny && (err == nil || errors.Is(err, ErrCircuitOpen)):
+            t.Errorf("%s: error = %v, want a send failure", step.name, err)
+        case step.wantErr == ErrCircuitOpen && !errors.Is(err, ErrCircuitOpen):
+            t.Errorf("%s: error = %v, want ErrCircuitOpen", step.name, err)
+        }
+        if got := l.breaker.State(); got != step.wantState {
+            t.Errorf("%s: state = %s, want %s", step.name, got, step.wantState)
+        }
+        if got := promtest.ToFloat64(circuitState); got != float64(step.wantState) {
+            t.Errorf("%s: logstash_circuit_state = %v, want %v", step.name, got, float64(step.wantState))
+        }
+        if got := calls.Load() - callsBefore; got != step.wantCalls {
+            t.Errorf("%s: logstash received %d requests, want %d", step.name, got, step.wantCalls)
+        }
+        if got := promtest.ToFloat64(circuitOpenDrops) - dropsBefore; got != step.wantDrops {
+            t.Errorf("%s: circuit_open_drops increased by %v, want %v", step.name, got, step.wantDrops)
+        }
+    }
+}
+
+// В HalfOpen пропускается только один пробный запрос
+func TestCircuitBreaker_SingleProbe(t *testing.T) {
+    cb := newCircuitBreaker(1, time.Minute)
+    now := time.Now()
+    cb.now = func() time.Time { return now }
+    
+    cb.record(errors.New("connection refused"))
+    if ok, _ := cb.allow(); ok {
+        t.Fatal("open circuit allowed a send")
+    }
+    
+    now = now.Add(time.Minute)
+    if ok, probe := cb.allow(); !ok || !probe {
+        t.Fatalf("allow after cooldown = %v %v, want a probe", ok, probe)
+    }
+    if got := promtest.ToFloat64(circuitState); got != float64(CircuitHalfOpen) {
+        t.Errorf("logstash_circuit_state = %v, want %v", got, float64(CircuitHalfOpen))
+    }
+    if ok, _ := cb.allow(); ok {
+        t.Error("second send allowed while the probe is in flight")
+    }
+    
+    cb.record(nil)
+    if ok, probe := cb.allow(); !ok || probe || cb.State() != CircuitClosed {
+        t.Errorf("after probe success: allow = %v %v, state = %s, want closed", ok, probe, cb.State())
+    }
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+    tests := []struct {
+        name         string
+        failures     int
+        cooldown     time.Duration
+        wantDisabled bool
+        wantCooldown time.Duration
+    }{
+        {name: "configured", failures: 5, cooldown: 10 * time.Second, wantCooldown: 10 * time.Second},
+        {name: "default cooldown", failures: 5, wantCooldown: defaultCircuitCooldown},
+        {name: "disabled", failures: 0, cooldown: time.Second, wantDisabled: true},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l := newQueueTestLogger(WithCircuitBreaker(3, time.Second), WithCircuitBreaker(tt.failures, tt.cooldown))
+            if tt.wantDisabled {
+                if l.breaker != nil {
+                    t.Error("circuit breaker enabled, want disabled")
+                }
+                return
+            }
+            if l.breaker == nil || l.breaker.threshold != tt.failures || l.breaker.cooldown != tt.wantCooldown {
+                t.Errorf("breaker = %+v, want %d failures and %v cooldown", l.breaker, tt.failures, tt.wantCooldown)
+            }
+        })
+    }
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "time"
+
+    "golang.org/x/term"
+)
+
+// Форматы вывода в консоль
+const (
+    ConsoleText = "text"
+    ConsoleJSON = "json"
+)
+
+// WithConsoleFormat выбирает формат вывода в консоль: text (по умолчанию) или json
+func WithConsoleFormat(format string) Option {
+    return func(l *ELKLogger) {
+        if format == ConsoleText || format == ConsoleJSON {
+            l.consoleFormat = format
+        }
+    }
+}
+
+// consoleEntry - сокращенная запись для вывода в консоль в формате json
+type consoleEntry struct {
+    Timestamp string                 `json:"@timestamp"`
+    Level     string                 `json:"level"`
+    Message   string                 `json:"message"`
+    Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+// isTerminal сообщает, подключен ли вывод к терминалу
+func isTerminal(f *os.File) bool {
+    return term.IsTerminal(int(f.Fd()))
+}
+
+// logToConsole выводит запись через консольный приемник, nil отключает вывод
+func (l *ELKLogger) logToConsole(level, message string, fields map[string]interface{}) {
+    if l.console == nil {
+        return
+    }
+    l.console.Write(LogEntry{Level: level, Message: message, Fields: fields})
+}
+
+// writeConsoleJSON пишет запись одной JSON строкой
+func writeConsoleJSON(w io.Writer, level, message string, fields map[string]interface{}) {
+    data, err := json.Marshal(consoleEntry{
+        Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+        Level:     level,
+        Message:   message,
+        Fields:    fields,
+    })
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Failed to marshal console log: %v\n", err)
+        return
+    }
+    fmt.Fprintln(w, string(data))
+}
+
+// writeConsoleText пишет запись текстом, с ANSI цветами только для терминала
+func writeConsoleText(w io.Writer, level, message string, fields map[string]interface{}, colored bool) {
+    color, reset := "", ""
+    if colored {
+        reset = "\033[0m"
+        color = reset
+        switch level {
+        case "ERROR", "FATAL":
+            color = "\033[31m" // Красный
+        case "WARN":
+            color = "\033[33m" // Желтый
+        case "INFO":
+            color = "\033[32m" // Зеленый
+        case "DEBUG":
+            color = "\033[36m" // Голубой
+        }
+    }
+    
+    timestamp := time.Now().Format("15:04:05.000")
+    fmt.Fprintf(w, "%s[%s] %-5s %s%s", color, timestamp, level, message, reset)
+    
+    if len(fields) > 0 {
+        fmt.Fprint(w, " | ")
+        for k, v := range fields {
+            fmt.Fprintf(w, "%s=%v ", k, v)
+        }
+    }
+    fmt.Fprintln(w)
+}
\ No newline at end of file
@@ -0,0 +1,145 @@
+package logging
+
+import (
+    "bytes"
+    "encoding/json"
+    "io"
+    "os"
+    "strings"
+    "testing"
+)
+
+// captureStdout подменяет os.Stdout каналом на время fn и возвращает вывод
+func captureStdout(t *testing.T, fn func()) string {
+    t.Helper()
+    
+    r, w, err := os.Pipe()
+    if err != nil {
+        t.Fatal(err)
+    }
+    stdout := os.Stdout
+    os.Stdout = w
+    defer func() { os.Stdout = stdout }()
+    
+    fn()
+    w.Close()
+    
+    out, err := io.ReadAll(r)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return string(out)
+}
+
+func TestConsoleSink_Formats(t *testing.T) {
+    entry := LogEntry{Level: "INFO", Message: "order created", Fields: map[string]interface{}{"order_id": 7}}
+    
+    tests := []struct {
+        name   string
+        format string
+        check  func(t *testing.T, out string)
+    }{
+        {
+            name:   "json",
+            format: ConsoleJSON,
+            check: func(t *testing.T, out string) {
+                if strings.Count(out, "\n") != 1 {
+                    t.Errorf("output %q, want a single line", out)
+                }
+                var doc consoleEntry
+                if err := json.Unmarshal([]byte(out), &doc); err != nil {
+                    t.Fatalf("output %q is not JSON: %v", out, err)
+                }
+                if doc.Level != "INFO" || doc.Message != "order created" || doc.Fields["order_id"] != float64(7) || doc.Timestamp == "" {
+                    t.Errorf("decoded %+v, want the entry", doc)
+                }
+            },
+        },
+        {
+            name:   "text without terminal",
+            format: ConsoleText,
+            check: func(t *testing.T, out string) {
+                if strings.Contains(out, "\033[") {
+                    t.Errorf("output %q contains ANSI codes, stdout is not a terminal", out)
+                }
+                if !strings.Contains(out, "INFO  order created | order_id=7") {
+                    t.Errorf("output %q, want level, message and fields", out)
+                }
+            },
+        },
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            out := captureStdout(t, func() {
+                NewConsoleSink(tt.format).Write(entry)
+            })
+            tt.check(t, out)
+        })
+    }
+}
+
+func TestConsoleSink_TextColors(t *testing.T) {
+    tests := []struct {
+        level string
+        color string
+    }{
+        {level: "ERROR", color: "\033[31m"},
+        {level: "WARN", color: "\033[33m"},
+        {level: "INFO", color: "\033[32m"},
+        {level: "DEBUG", color: "\033[36m"},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.level, func(t *testing.T) {
+            var buf bytes.Buffer
+            sink := &ConsoleSink{out: &buf, format: ConsoleText, color: true}
+            sink.Write(LogEntry{Level: tt.level, Message: "colored"})
+            
+            out := buf.String()
+            if !strings.HasPrefix(out, tt.color) || !strings.Contains(out, "colored\033[0m") {
+                t.Errorf("output %q, want it wrapped in %q and reset", out, tt.color)
+            }
+        })
+    }
+}
+
+func TestWithConsoleFormat_IgnoresUnknown(t *testing.T) {
+    l := newQueueTestLogger(WithConsoleFormat(ConsoleText), WithConsoleFormat("yaml"))
+    if l.consoleFormat != ConsoleText {
+        t.Errorf("console format = %q, want %q", l.consoleFormat, ConsoleText)
+    }
+}
+
+// Каналы, файлы и /dev/null (символьное устройство) не терминал
+func TestIsTerminal_NotTTY(t *testing.T) {
+    r, w, err := os.Pipe()
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer r.Close()
+    defer w.Close()
+    
+    file, err := os.CreateTemp(t.TempDir(), "console")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer file.Close()
+    
+    for name, f := range map[string]*os.File{"pipe": w, "regular file": file, "dev null": mustOpen(t, os.DevNull)} {
+        if isTerminal(f) {
+            t.Errorf("isTerminal(%s) = true, want false", name)
+        }
+    }
+}
+
+func mustOpen(t *testing.T, path string) *os.File {
+    t.Helper()
+    
+    f, err := os.Open(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { f.Close() })
+    return f
+}
@@ -0,0 +1,105 @@
+package logging
+
+import (
+    "context"
+    "strings"
+
+    "go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID сохраняет идентификатор запроса в контексте
+func WithRequestID(ctx context.Context, id string) context.Context {
+    return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext возвращает идентификатор запроса из контекста
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+    id, ok := ctx.Value(requestIDKey{}).(string)
+    return id, ok && id != ""
+}
+
+// ParseTraceparent разбирает заголовок W3C traceparent вида
+// "00-<trace-id 32 hex>-<span-id 16 hex>-<flags>"
+func ParseTraceparent(header string) (traceID, spanID string, ok bool) {
+    parts := strings.Split(strings.TrimSpace(header), "-")
+    if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+        return "", "", false
+    }
+    
+    traceID, spanID = strings.ToLower(parts[1]), strings.ToLower(parts[2])
+    if !isHexID(traceID, 32) || !isHexID(spanID, 16) {
+        return "", "", false
+    }
+    return traceID, spanID, true
+}
+
+// isHexID проверяет длину и шестнадцатеричный формат, нулевой ID недопустим
+func isHexID(id string, length int) bool {
+    if len(id) != length || strings.Trim(id, "0") == "" {
+        return false
+    }
+    for _, c := range id {
+        if !strings.ContainsRune("0123456789abcdef", c) {
+            return false
+        }
+    }
+    return true
+}
+
+// WithOTELContext возвращает логгер, добавляющий trace_id и span_id
+// текущего спана OpenTelemetry, чтобы логи в Kibana связывались
+// с трассами Jaeger/Tempo
+func WithOTELContext(ctx context.Context) *ELKLogger {
+    return GetLogger().withTrace(ctx)
+}
+
+func (l *ELKLogger) withTrace(ctx context.Context) *ELKLogger {
+    fields := traceFields(ctx)
+    if fields == nil {
+        return l
+    }
+    return l.withFields(fields)
+}
+
+// traceFields возвращает trace_id и span_id спана из ctx, nil - спана нет
+func traceFields(ctx context.Context) map[string]interface{} {
+    sc := trace.SpanContextFromContext(ctx)
+    if !sc.IsValid() {
+        return nil
+    }
+    return map[string]interface{}{
+        "trace_id": sc.TraceID().String(),
+        "span_id":  sc.SpanID().String(),
+    }
+}
+
+// FromContext возвращает логгер, добавляющий request_id и контекст
+// трассировки ко всем записям. Без них возвращается общий логгер
+func FromContext(ctx context.Context) *ELKLogger {
+    return GetLogger().WithContext(ctx)
+}
+
+// WithContext возвращает дочерний логгер с request_id и контекстом
+// трассировки из ctx. Без них возвращается сам логгер
+func (l *ELKLogger) WithContext(ctx context.Context) *ELKLogger {
+    fields := ContextFields(ctx)
+    if fields == nil {
+        return l
+    }
+    return l.withFields(fields)
+}
+
+// ContextFields возвращает request_id, trace_id и span_id из ctx для
+// Logger.WithFields любой реализации. Без них возвращается nil
+func ContextFields(ctx context.Context) map[string]interface{} {
+    fields := traceFields(ctx)
+    if id, ok := RequestIDFromContext(ctx); ok {
+        if fields == nil {
+            fields = make(map[string]interface{}, 1)
+        }
+        fields["request_id"] = id
+    }
+    return fields
+}
\ No newline at end of file
@@ -0,0 +1,187 @@
+package logging
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// withSpanContext кладет в ctx контекст спана с заданными идентификаторами
+func withSpanContext(t *testing.T, ctx context.Context, traceID, spanID string) context.Context {
+    t.Helper()
+    
+    tid, err := trace.TraceIDFromHex(traceID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    sid, err := trace.SpanIDFromHex(spanID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid}))
+}
+
+// handlerLog - обработчик, который пишет лог без request_id в полях
+func handlerLog(ctx context.Context, l *ELKLogger) {
+    l.WithContext(ctx).Info("order created", map[string]interface{}{"order_id": 7})
+}
+
+func TestWithContext_RequestIDInMarshalledEntry(t *testing.T) {
+    tests := []struct {
+        name   string
+        ctx    context.Context
+        prefix string
+        want   map[string]interface{}
+    }{
+        {
+            name: "request id",
+            ctx:  WithRequestID(context.Background(), "0b3c9f6e-5d7a-4f0e-9a44-2b1f3c5d6e7f"),
+            want: map[string]interface{}{"request_id": "0b3c9f6e-5d7a-4f0e-9a44-2b1f3c5d6e7f", "order_id": float64(7)},
+        },
+        {
+            name:   "request id with field prefix",
+            ctx:    WithRequestID(context.Background(), "req-1"),
+            prefix: "app.",
+            want:   map[string]interface{}{"app.request_id": "req-1", "app.order_id": float64(7)},
+        },
+        {
+            name: "no request id",
+            ctx:  context.Background(),
+            want: map[string]interface{}{"order_id": float64(7)},
+        },
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l := newQueueTestLogger(WithFieldPrefix(tt.prefix), WithDisableCaller(), WithTimestampPrecision("ms"))
+            handlerLog(tt.ctx, l)
+            
+            data, err := l.marshalEntry(l.lastQueued(t))
+            if err != nil {
+                t.Fatalf("marshal: %v", err)
+            }
+            var doc struct {
+                Fields map[string]interface{} `json:"fields"`
+            }
+            if err := json.Unmarshal(data, &doc); err != nil {
+                t.Fatalf("unmarshal %s: %v", data, err)
+            }
+            
+            if len(doc.Fields) != len(tt.want) {
+                t.Errorf("fields = %v, want %v", doc.Fields, tt.want)
+            }
+            for k, v := range tt.want {
+                if doc.Fields[k] != v {
+                    t.Errorf("fields.%s = %v, want %v", k, doc.Fields[k], v)
+                }
+            }
+        })
+    }
+}
+
+func TestWithContext_ChildDoesNotLeakIntoParent(t *testing.T) {
+    l := newQueueTestLogger(WithDisableCaller())
+    
+    l.WithContext(WithRequestID(context.Background(), "req-1")).Info("child", nil)
+    l.lastQueued(t)
+    l.Info("parent", nil)
+    
+    if id, ok := l.lastQueued(t).Fields["request_id"]; ok {
+        t.Errorf("parent entry has request_id %v, want none", id)
+    }
+}
+
+func TestParseTraceparent(t *testing.T) {
+    const traceID, spanID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+    
+    tests := []struct {
+        name   string
+        header string
+        wantOK bool
+    }{
+        {name: "valid", header: "00-" + traceID + "-" + spanID + "-01", wantOK: true},
+        {name: "upper case", header: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01", wantOK: true},
+        {name: "future version with extra parts", header: "01-" + traceID + "-" + spanID + "-01-extra", wantOK: true},
+        {name: "empty", header: ""},
+        {name: "invalid version", header: "ff-" + traceID + "-" + spanID + "-01"},
+        {name: "zero trace id", header: "00-00000000000000000000000000000000-" + spanID + "-01"},
+        {name: "zero span id", header: "00-" + traceID + "-0000000000000000-01"},
+        {name: "short span id", header: "00-" + traceID + "-00f067aa-01"},
+        {name: "non-hex trace id", header: "00-4bf92f3577b34da6a3ce929d0e0e473z-" + spanID + "-01"},
+        {name: "missing flags", header: "00-" + traceID + "-" + spanID},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            gotTrace, gotSpan, ok := ParseTraceparent(tt.header)
+            if ok != tt.wantOK {
+                t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+            }
+            if ok && (gotTrace != traceID || gotSpan != spanID) {
+                t.Errorf("parsed %s %s, want %s %s", gotTrace, gotSpan, traceID, spanID)
+            }
+        })
+    }
+}
+
+func TestWithContext_TraceFieldsInMarshalledEntry(t *testing.T) {
+    ctx := withSpanContext(t, context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+    l := newQueueTestLogger(WithDisableCaller(), WithTimestampPrecision("ms"))
+    
+    l.withTrace(ctx).Info("traced", nil)
+    data, err := l.marshalEntry(l.lastQueued(t))
+    if err != nil {
+        t.Fatalf("marshal: %v", err)
+    }
+    
+    var doc struct {
+        Fields map[string]interface{} `json:"fields"`
+    }
+    if err := json.Unmarshal(data, &doc); err != nil {
+        t.Fatalf("unmarshal %s: %v", data, err)
+    }
+    if doc.Fields["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || doc.Fields["span_id"] != "00f067aa0ba902b7" {
+        t.Errorf("fields = %v, want trace_id and span_id", doc.Fields)
+    }
+    
+    // Без контекста трассировки возвращается тот же логгер
+    if got := l.withTrace(context.Background()); got != l {
+        t.Error("withTrace without a span returned a child logger")
+    }
+}
+
+// Спаны, начатые трейсером OpenTelemetry в обработчике, попадают в логи
+func TestWithContext_OTELSpan(t *testing.T) {
+    provider := sdktrace.NewTracerProvider()
+    defer provider.Shutdown(context.Background())
+    tracer := provider.Tracer("test")
+    l := newQueueTestLogger(WithDisableCaller())
+    
+    ctx, parent := tracer.Start(context.Background(), "request")
+    defer parent.End()
+    ctx, child := tracer.Start(ctx, "db.query")
+    defer child.End()
+    
+    tests := []struct {
+        name   string
+        logger *ELKLogger
+    }{
+        {name: "withTrace", logger: l.withTrace(ctx)},
+        {name: "WithContext", logger: l.WithContext(ctx)},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            tt.logger.Info("query", nil)
+            entry := l.lastQueued(t)
+            
+            sc := child.SpanContext()
+            if entry.Fields["trace_id"] != sc.TraceID().String() || entry.Fields["span_id"] != sc.SpanID().String() {
+                t.Errorf("fields = %v, want trace_id %s and span_id %s of the current span", entry.Fields, sc.TraceID(), sc.SpanID())
+            }
+        })
+    }
+}
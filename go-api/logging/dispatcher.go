@@ -0,0 +1,180 @@
+package logging
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "sync/atomic"
+)
+
+// dispatcher - пул горутин, отправляющих записи из очереди в Logstash.
+// Общий для логгера и всех дочерних логгеров
+type dispatcher struct {
+    // mu защищает closed: enqueue держит RLock, чтобы Close
+    // не начал ожидание, пока запись еще ставится в очередь
+    mu      sync.RWMutex
+    closed  bool
+    cancel  context.CancelFunc
+    workers sync.WaitGroup
+    
+    // pending - записи, принятые в очередь и еще не отправленные:
+    // ждущие в очереди и те, что воркеры отправляют прямо сейчас.
+    // abandoned выставляется, когда Close не уложился в срок: после
+    // этого pending не уменьшается при отбрасывании записей, и его
+    // значение в момент отказа - число брошенных записей
+    pending   atomic.Int64
+    abandoned atomic.Bool
+}
+
+// startWorkers запускает пул отправки, живущий до отмены ctx или Close
+func (l *ELKLogger) startWorkers(ctx context.Context) {
+    ctx, cancel := context.WithCancel(ctx)
+    
+    l.ctx = ctx
+    l.queue = make(chan LogEntry, l.queueSize)
+    l.dispatcher = &dispatcher{cancel: cancel}
+    
+    for i := 0; i < l.workerCount; i++ {
+        l.dispatcher.workers.Add(1)
+        go l.worker(ctx)
+    }
+}
+
+func (l *ELKLogger) worker(ctx context.Context) {
+    defer l.dispatcher.workers.Done()
+    
+    for {
+        select {
+        case entry := <-l.queue:
+            l.deliver(entry)
+        case <-ctx.Done():
+            // Отправляем то, что уже успело попасть в очередь
+            for {
+                select {
+                case entry := <-l.queue:
+                    l.deliver(entry)
+                default:
+                    return
+                }
+            }
+        }
+    }
+}
+
+// deliver отправляет запись из очереди. После истечения срока Close
+// запись отбрасывается без отправки
+func (l *ELKLogger) deliver(entry LogEntry) {
+    if l.dispatcher.abandoned.Load() {
+        l.drop()
+        return
+    }
+    
+    l.sendLogAsync(entry)
+    l.dispatcher.pending.Add(-1)
+    l.inflight.Done()
+}
+
+// enqueue ставит запись в очередь с учетом политики переполнения.
+// После Close или отмены контекста пула записи отбрасываются:
+// воркеры уже не разберут очередь
+func (l *ELKLogger) enqueue(entry LogEntry) {
+    l.dispatcher.mu.RLock()
+    defer l.dispatcher.mu.RUnlock()
+    
+    if l.dispatcher.closed || l.ctx.Err() != nil {
+        droppedLogs.Inc()
+        return
+    }
+    
+    l.inflight.Add(1)
+    
+    // Учитываем до постановки в очередь, иначе воркер может
+    // отправить запись раньше, чем она попадет в pending
+    l.dispatcher.pending.Add(1)
+    
+    if l.blockOnFull {
+        select {
+        case l.queue <- entry:
+        case <-l.ctx.Done():
+            l.dispatcher.pending.Add(-1)
+            l.drop()
+        }
+        return
+    }
+    
+    select {
+    case l.queue <- entry:
+    default:
+        l.dispatcher.pending.Add(-1)
+        l.drop()
+    }
+}
+
+func (l *ELKLogger) drop() {
+    droppedLogs.Inc()
+    l.inflight.Done()
+}
+
+// Close прекращает прием записей, отправляет оставшиеся в очереди,
+// дожидается завершения воркеров и отправляет неполную пачку.
+// Если ctx истек раньше, неотправленные записи отбрасываются и
+// возвращается ошибка с их числом, включая записи, отправка которых
+// еще не завершилась. Повторные вызовы ничего не делают
+func (l *ELKLogger) Close(ctx context.Context) error {
+    d := l.dispatcher
+    
+    d.mu.Lock()
+    if d.closed {
+        d.mu.Unlock()
+        return nil
+    }
+    d.closed = true
+    d.mu.Unlock()
+    
+    d.cancel()
+    
+    done := make(chan struct{})
+    go func() {
+        d.workers.Wait()
+        if l.batch != nil {
+            l.batch.flush()
+        }
+        close(done)
+    }()
+    
+    select {
+    case <-done:
+        return l.closeSinks(ctx)
+    case <-ctx.Done():
+    }
+    
+    // Воркеры заняты отправкой, остаток очереди отбрасываем сами.
+    // Флаг выставляется до чтения pending: отправки, завершившиеся
+    // раньше, уже вычтены, а отброшенные позже записи pending не меняют
+    d.abandoned.Store(true)
+    abandoned := d.pending.Load()
+    l.dropQueued()
+    l.closeSinks(ctx)
+    
+    return fmt.Errorf("logger closed with %d log entries abandoned: %w", abandoned, ctx.Err())
+}
+
+// closeSinks дописывает очереди дополнительных приемников не дольше ctx
+func (l *ELKLogger) closeSinks(ctx context.Context) error {
+    if l.sinkFanOut == nil {
+        return nil
+    }
+    return l.sinkFanOut.Close(ctx)
+}
+
+// dropQueued отбрасывает записи, оставшиеся в очереди
+func (l *ELKLogger) dropQueued() {
+    for {
+        select {
+        case <-l.queue:
+            l.drop()
+        default:
+            return
+        }
+    }
+}
\ No newline at end of file
@@ -0,0 +1,283 @@
+package logging
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "runtime"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+    
+    promtest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// waitFor ждет выполнения cond не дольше секунды
+func waitFor(t *testing.T, what string, cond func() bool) {
+    t.Helper()
+    
+    deadline := time.Now().Add(time.Second)
+    for !cond() {
+        if time.Now().After(deadline) {
+            t.Fatalf("timed out waiting for %s", what)
+        }
+        time.Sleep(time.Millisecond)
+    }
+}
+
+func TestDispatcher_BoundedGoroutines(t *testing.T) {
+    const workers, queueSize, callers, perCaller = 4, 16, 50, 200
+    
+    blocked := blockingSink{name: "test_bounded", release: make(chan struct{})}
+    defer close(blocked.release)
+    
+    before := runtime.NumGoroutine()
+    l := newQueueTestLogger(WithWorkers(workers), WithQueueSize(queueSize), WithDisableCaller())
+    l.output = blocked
+    l.startWorkers(t.Context())
+    droppedBefore := promtest.ToFloat64(droppedLogs)
+    
+    var wg sync.WaitGroup
+    for i := 0; i < callers; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for j := 0; j < perCaller; j++ {
+                l.enqueue(LogEntry{Message: "burst"})
+            }
+        }()
+    }
+    wg.Wait()
+    
+    // Вызывающие горутины завершились, остались только воркеры пула
+    if spawned := runtime.NumGoroutine() - before; spawned > workers {
+        t.Errorf("%d goroutines running after %d log calls, want at most %d workers", spawned, callers*perCaller, workers)
+    }
+    
+    accepted := int64(workers + queueSize)
+    if got := l.dispatcher.pending.Load(); got != accepted {
+        t.Errorf("pending = %d, want %d: workers busy and queue full", got, accepted)
+    }
+    if got := promtest.ToFloat64(droppedLogs) - droppedBefore; got != float64(callers*perCaller)-float64(accepted) {
+        t.Errorf("dropped_logs_total increased by %v, want %d", got, int64(callers*perCaller)-accepted)
+    }
+}
+
+func TestClose_CountsAbandonedEntries(t *testing.T) {
+    tests := []struct {
+        name    string
+        workers int
+        entries int
+    }{
+        {name: "in-flight only", workers: 2, entries: 2},
+        {name: "in-flight and queued", workers: 2, entries: 5},
+        {name: "single worker", workers: 1, entries: 4},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            blocked := blockingSink{name: "test_blocked", release: make(chan struct{})}
+            defer close(blocked.release)
+
+            l := newQueueTestLogger(WithWorkers(tt.workers), WithQueueSize(10))
+            l.output = blocked
+            l.startWorkers(context.Background())
+
+            for i := 0; i < tt.entries; i++ {
+                l.enqueue(LogEntry{Message: "pending"})
+            }
+
+            // Ждем, пока воркеры возьмут записи в отправку
+            deadline := time.Now().Add(time.Second)
+            for len(l.queue) != tt.entries-tt.workers && time.Now().Before(deadline) {
+                time.Sleep(time.Millisecond)
+            }
+
+            ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+            defer cancel()
+
+            err := l.Close(ctx)
+            if !errors.Is(err, context.DeadlineExceeded) {
+                t.Fatalf("Close error = %v, want deadline exceeded", err)
+            }
+            want := fmt.Sprintf(" %d log entries abandoned", tt.entries)
+            if !strings.Contains(err.Error(), want) {
+                t.Errorf("Close error = %q, want it to report%s", err, want)
+            }
+        })
+    }
+}
+
+func TestClose_NothingAbandonedAfterDelivery(t *testing.T) {
+    sink := &memorySink{name: "test_delivered"}
+    l := newQueueTestLogger(WithWorkers(2), WithQueueSize(10))
+    l.output = sink
+    l.startWorkers(context.Background())
+
+    for i := 0; i < 3; i++ {
+        l.enqueue(LogEntry{Message: "delivered"})
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+    defer cancel()
+    if err := l.Close(ctx); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    if n := l.dispatcher.pending.Load(); n != 0 {
+        t.Errorf("pending = %d after a clean Close, want 0", n)
+    }
+    if got := len(sink.written()); got != 3 {
+        t.Errorf("sent %d entries before Close returned, want 3", got)
+    }
+}
+
+func TestEnqueue_BlockOnFull(t *testing.T) {
+    blocked := blockingSink{name: "test_block_on_full", release: make(chan struct{})}
+    
+    l := newQueueTestLogger(WithWorkers(1), WithQueueSize(1), WithBlockOnFull(true))
+    l.output = blocked
+    l.startWorkers(t.Context())
+    
+    // Первую запись отправляет воркер, вторая занимает очередь
+    l.enqueue(LogEntry{Message: "sending"})
+    waitFor(t, "the worker to take the first entry", func() bool { return len(l.queue) == 0 })
+    l.enqueue(LogEntry{Message: "queued"})
+    
+    done := make(chan struct{})
+    go func() {
+        l.enqueue(LogEntry{Message: "waiting"})
+        close(done)
+    }()
+    
+    select {
+    case <-done:
+        t.Fatal("enqueue returned while the queue was full, want it to block")
+    case <-time.After(50 * time.Millisecond):
+    }
+    
+    close(blocked.release)
+    select {
+    case <-done:
+    case <-time.After(time.Second):
+        t.Fatal("enqueue still blocked after the queue drained")
+    }
+    
+    ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+    defer cancel()
+    if err := l.Close(ctx); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+}
+
+func TestDispatcher_StopsWithContext(t *testing.T) {
+    sink := &memorySink{name: "test_ctx_stop"}
+    ctx, cancel := context.WithCancel(context.Background())
+    
+    l := newQueueTestLogger(WithWorkers(2), WithQueueSize(10))
+    l.output = sink
+    l.startWorkers(ctx)
+    l.enqueue(LogEntry{Message: "before cancel"})
+    
+    cancel()
+    done := make(chan struct{})
+    go func() {
+        l.dispatcher.workers.Wait()
+        close(done)
+    }()
+    select {
+    case <-done:
+    case <-time.After(time.Second):
+        t.Fatal("workers still running after the context was cancelled")
+    }
+    
+    droppedBefore := promtest.ToFloat64(droppedLogs)
+    l.enqueue(LogEntry{Message: "after cancel"})
+    
+    if got := sink.written(); len(got) != 1 || got[0] != "before cancel" {
+        t.Errorf("sent %v, want only the entry queued before cancel", got)
+    }
+    if got := promtest.ToFloat64(droppedLogs) - droppedBefore; got != 1 {
+        t.Errorf("dropped_logs_total increased by %v, want 1 for the entry after cancel", got)
+    }
+}
+
+// slowSink записывает сообщения с задержкой, имитируя медленный Logstash
+type slowSink struct {
+    memorySink
+    delay time.Duration
+}
+
+func (s *slowSink) Write(entry LogEntry) error {
+    time.Sleep(s.delay)
+    return s.memorySink.Write(entry)
+}
+
+func TestClose_DrainsQueueBeforeReturning(t *testing.T) {
+    const entries = 20
+    
+    sink := &slowSink{memorySink: memorySink{name: "test_drain"}, delay: 2 * time.Millisecond}
+    l := newQueueTestLogger(WithWorkers(1), WithQueueSize(entries))
+    l.output = sink
+    l.startWorkers(context.Background())
+    
+    droppedBefore := promtest.ToFloat64(droppedLogs)
+    for i := 0; i < entries; i++ {
+        l.enqueue(LogEntry{Message: fmt.Sprintf("entry %d", i)})
+    }
+    
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    if err := l.Close(ctx); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    
+    got := sink.written()
+    if len(got) != entries {
+        t.Fatalf("sent %d entries before Close returned, want %d", len(got), entries)
+    }
+    for i, message := range got {
+        if want := fmt.Sprintf("entry %d", i); message != want {
+            t.Errorf("entry %d = %q, want %q", i, message, want)
+        }
+    }
+    if dropped := promtest.ToFloat64(droppedLogs) - droppedBefore; dropped != 0 {
+        t.Errorf("dropped %v entries, want 0", dropped)
+    }
+}
+
+// Записи, принятые до Close, отправляются, остальные учитываются как
+// отброшенные - ни одна не теряется молча
+func TestClose_ConcurrentWithLogging(t *testing.T) {
+    const callers, perCaller = 8, 100
+    
+    sink := &memorySink{name: "test_close_concurrent"}
+    l := newQueueTestLogger(WithWorkers(4), WithQueueSize(callers*perCaller), WithDisableCaller())
+    l.output = sink
+    l.startWorkers(context.Background())
+    
+    droppedBefore := promtest.ToFloat64(droppedLogs)
+    var wg sync.WaitGroup
+    for c := 0; c < callers; c++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for i := 0; i < perCaller; i++ {
+                l.Info("concurrent", nil)
+            }
+        }()
+    }
+    
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    if err := l.Close(ctx); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    wg.Wait()
+    
+    sent := len(sink.written())
+    dropped := int(promtest.ToFloat64(droppedLogs) - droppedBefore)
+    if sent+dropped != callers*perCaller {
+        t.Errorf("sent %d + dropped %d = %d, want %d", sent, dropped, sent+dropped, callers*perCaller)
+    }
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+    "encoding/json"
+    "os"
+    "time"
+)
+
+// Версия Elastic Common Schema, которой соответствуют записи в режиме ECS
+const ecsVersion = "8.11.0"
+
+// WithECSMode переключает формат отправляемых записей на Elastic Common Schema.
+// По умолчанию используется прежний плоский формат LogEntry
+func WithECSMode(enabled bool) Option {
+    return func(l *ELKLogger) {
+        l.ecsMode = enabled
+    }
+}
+
+// ecsEntry - запись в формате ECS: log.level, service.name, host.name и т.д.
+// Поля приложения остаются в fields, чтобы не пересекаться со схемой
+type ecsEntry struct {
+    Timestamp string                 `json:"@timestamp"`
+    Message   string                 `json:"message"`
+    ECS       ecsVersionInfo         `json:"ecs"`
+    Log       ecsLog                 `json:"log"`
+    Service   ecsService             `json:"service"`
+    Host      ecsHost                `json:"host"`
+    Process   ecsProcess             `json:"process"`
+    Event     ecsEvent               `json:"event"`
+    Error     *ErrorEntry            `json:"error,omitempty"`
+    Tags      []string               `json:"tags,omitempty"`
+    Fields    map[string]interface{} `json:"fields,omitempty"`
+    Signature string                 `json:"_sig,omitempty"`
+}
+
+type ecsVersionInfo struct {
+    Version string `json:"version"`
+}
+
+type ecsLog struct {
+    Level string `json:"level"`
+}
+
+type ecsService struct {
+    Name        string `json:"name"`
+    Version     string `json:"version"`
+    Environment string `json:"environment"`
+}
+
+type ecsHost struct {
+    Name string `json:"name"`
+    IP   string `json:"ip,omitempty"`
+}
+
+type ecsProcess struct {
+    PID int `json:"pid"`
+}
+
+type ecsEvent struct {
+    Created string `json:"created"`
+}
+
+// marshalEntry сериализует запись в формате, выбранном для логгера
+func (l *ELKLogger) marshalEntry(entry LogEntry) ([]byte, error) {
+    if !l.ecsMode {
+        return json.Marshal(entry)
+    }
+    
+    return json.Marshal(ecsEntry{
+        Timestamp: entry.Timestamp,
+        Message:   entry.Message,
+        ECS:       ecsVersionInfo{Version: ecsVersion},
+        Log:       ecsLog{Level: entry.Level},
+        Service: ecsService{
+            Name:        entry.Service,
+            Version:     l.serviceVersion,
+            Environment: entry.Environment,
+        },
+        Host:    ecsHost{Name: entry.Host, IP: entry.ServerIP},
+        Process: ecsProcess{PID: os.Getpid()},
+        Event:   ecsEvent{Created: entry.createdAt.UTC().Format(time.RFC3339Nano)},
+        Error:   entry.Error,
+        Tags:    entry.Tags,
+        Fields:  entry.Fields,
+        
+        // Подпись считается над этим же документом без _sig, см. VerifyDocument
+        Signature: entry.Signature,
+    })
+}
\ No newline at end of file
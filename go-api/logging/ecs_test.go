@@ -0,0 +1,107 @@
+package logging
+
+import (
+    "encoding/json"
+    "os"
+    "strings"
+    "testing"
+    "time"
+)
+
+// lookupPath возвращает значение по пути вида "service.name"
+func lookupPath(doc map[string]interface{}, path string) (interface{}, bool) {
+    var cur interface{} = doc
+    for _, key := range strings.Split(path, ".") {
+        m, ok := cur.(map[string]interface{})
+        if !ok {
+            return nil, false
+        }
+        if cur, ok = m[key]; !ok {
+            return nil, false
+        }
+    }
+    return cur, true
+}
+
+func TestMarshalEntry_ECSMode(t *testing.T) {
+    created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+    entry := LogEntry{
+        Timestamp:   "2026-03-01T12:00:00.000Z",
+        Level:       "WARN",
+        Service:     "go-api",
+        Message:     "slow query",
+        Fields:      map[string]interface{}{"rows": 3},
+        Environment: "production",
+        Host:        "api-1",
+        ServerIP:    "10.0.0.5",
+        GoVersion:   "go1.24",
+        createdAt:   created,
+    }
+    
+    tests := []struct {
+        name       string
+        ecsMode    bool
+        want       map[string]interface{}
+        wantAbsent []string
+    }{
+        {
+            name:    "ecs",
+            ecsMode: true,
+            want: map[string]interface{}{
+                "@timestamp":          "2026-03-01T12:00:00.000Z",
+                "message":             "slow query",
+                "ecs.version":         ecsVersion,
+                "log.level":           "WARN",
+                "service.name":        "go-api",
+                "service.version":     "1.2.3",
+                "service.environment": "production",
+                "host.name":           "api-1",
+                "host.ip":             "10.0.0.5",
+                "process.pid":         float64(os.Getpid()),
+                "event.created":       "2026-03-01T12:00:00Z",
+                "fields.rows":         float64(3),
+            },
+            wantAbsent: []string{"level", "server_ip", "environment", "go_version"},
+        },
+        {
+            name: "legacy",
+            want: map[string]interface{}{
+                "@timestamp":  "2026-03-01T12:00:00.000Z",
+                "level":       "WARN",
+                "service":     "go-api",
+                "host":        "api-1",
+                "server_ip":   "10.0.0.5",
+                "environment": "production",
+                "fields.rows": float64(3),
+            },
+            wantAbsent: []string{"ecs", "log", "process", "event"},
+        },
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l := newQueueTestLogger(WithECSMode(tt.ecsMode))
+            l.serviceVersion = "1.2.3"
+            
+            data, err := l.marshalEntry(entry)
+            if err != nil {
+                t.Fatalf("marshal: %v", err)
+            }
+            var doc map[string]interface{}
+            if err := json.Unmarshal(data, &doc); err != nil {
+                t.Fatalf("unmarshal %s: %v", data, err)
+            }
+            
+            for path, want := range tt.want {
+                if got, ok := lookupPath(doc, path); !ok || got != want {
+                    t.Errorf("%s = %v, want %v", path, got, want)
+                }
+            }
+            for _, key := range tt.wantAbsent {
+                if _, ok := doc[key]; ok {
+                    t.Errorf("top-level key %q present in %s", key, data)
+                }
+            }
+        })
+    }
+}
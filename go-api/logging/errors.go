@@ -0,0 +1,101 @@
+package logging
+
+import (
+    "fmt"
+    "reflect"
+    "runtime"
+    "strings"
+)
+
+// Максимальное число кадров стека в ErrorEntry
+const maxErrorStackFrames = 32
+
+// ErrorEntry - структурированная ошибка в записи лога.
+// В Kibana доступна как error.message, error.type и error.stack
+type ErrorEntry struct {
+    Message string   `json:"message"`
+    Type    string   `json:"type"`
+    Stack   []string `json:"stack,omitempty"`
+}
+
+// newErrorEntry описывает err и стек вызова frames. Кадры рантайма Go в стек не попадают
+func newErrorEntry(err error, frames []runtime.Frame) *ErrorEntry {
+    stack := make([]string, 0, len(frames))
+    for _, frame := range frames {
+        if !strings.HasPrefix(frame.Function, "runtime.") {
+            stack = append(stack, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+        }
+    }
+    
+    return &ErrorEntry{
+        Message: err.Error(),
+        Type:    fmt.Sprintf("%T", err),
+        Stack:   stack,
+    }
+}
+
+// Префикс имен функций пакета logging в стеке вызова
+var loggingPackage = reflect.TypeOf(ELKLogger{}).PkgPath() + "."
+
+// callerFrames возвращает до limit кадров стека, начиная с кода, вызвавшего логгер.
+// Кадры пакета logging (методы уровня, Log, LogError, глобальные функции, обертки
+// WithFields) пропускаются независимо от пути вызова, поэтому caller не зависит
+// от числа промежуточных вызовов. Сверх этого пропускается
+// callerDepth-defaultCallerDepth кадров внешних оберток, см. WithCallerDepth
+func (l *ELKLogger) callerFrames(limit int) []runtime.Frame {
+    pcs := make([]uintptr, maxErrorStackFrames+16)
+    n := runtime.Callers(2, pcs)
+    frames := runtime.CallersFrames(pcs[:n])
+    
+    skip := max(0, l.callerDepth-defaultCallerDepth)
+    inLogger := true
+    result := make([]runtime.Frame, 0, limit)
+    for len(result) < limit {
+        frame, more := frames.Next()
+        if inLogger && !isLoggerFrame(frame) {
+            inLogger = false
+        }
+        if !inLogger {
+            if skip > 0 {
+                skip--
+            } else {
+                result = append(result, frame)
+            }
+        }
+        if !more {
+            break
+        }
+    }
+    return result
+}
+
+// isLoggerFrame сообщает, относится ли кадр к самому пакету logging.
+// Тесты пакета считаются внешним кодом
+func isLoggerFrame(frame runtime.Frame) bool {
+    return strings.HasPrefix(frame.Function, loggingPackage) && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+// LogError пишет запись с сообщением ошибки и заполненным полем error.
+// То же происходит при передаче значения error в поле "error" любого метода
+func (l *ELKLogger) LogError(level string, err error, fields map[string]interface{}) {
+    l.logError(level, err.Error(), err, fields)
+}
+
+// logError пишет запись message с ошибкой err в поле error.
+// При err == nil используется значение error из fields, если оно есть
+func (l *ELKLogger) logError(level, message string, err error, fields map[string]interface{}) {
+    fields = l.mergeFields(fields)
+    
+    if err != nil {
+        withErr := make(map[string]interface{}, len(fields)+1)
+        for k, v := range fields {
+            withErr[k] = v
+        }
+        withErr["error"] = err
+        fields = withErr
+    }
+    
+    if l.accept(level, message, fields) {
+        l.enqueue(l.createLogEntry(level, message, fields))
+    }
+}
@@ -0,0 +1,198 @@
+package logging
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "runtime"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "testing"
+)
+
+// newQueueTestLogger возвращает логгер, записи которого остаются в очереди
+func newQueueTestLogger(opts ...Option) *ELKLogger {
+    l := &ELKLogger{
+        ctx:             context.Background(),
+        queue:           make(chan LogEntry, 8),
+        dispatcher:      &dispatcher{},
+        inflight:        &sync.WaitGroup{},
+        hooks:           &hookRegistry{},
+        level:           &atomic.Int32{},
+        componentLevels: &componentLevels{levels: make(map[string]int)},
+        callerDepth:     defaultCallerDepth,
+        consoleFormat:   ConsoleJSON,
+    }
+    for _, opt := range opts {
+        opt(l)
+    }
+    return l
+}
+
+func (l *ELKLogger) lastQueued(t *testing.T) LogEntry {
+    t.Helper()
+    select {
+    case entry := <-l.queue:
+        return entry
+    default:
+        t.Fatal("no entry was queued")
+        return LogEntry{}
+    }
+}
+
+// nextLine возвращает позицию строки, следующей за вызовом
+func nextLine() string {
+    _, file, line, _ := runtime.Caller(1)
+    return fmt.Sprintf("%s:%d", file, line+1)
+}
+
+// serviceLogError - обертка сервиса над логгером, см. WithCallerDepth
+func serviceLogError(l *ELKLogger, err error) {
+    l.LogError("ERROR", err, nil)
+}
+
+func TestLogError_Caller(t *testing.T) {
+    errBoom := errors.New("boom")
+    
+    tests := []struct {
+        name string
+        opts []Option
+        log  func(l *ELKLogger) string
+    }{
+        {
+            name: "LogError",
+            log: func(l *ELKLogger) string {
+                want := nextLine()
+                l.LogError("ERROR", errBoom, nil)
+                return want
+            },
+        },
+        {
+            name: "Error method",
+            log: func(l *ELKLogger) string {
+                want := nextLine()
+                l.Error("failed", map[string]interface{}{"error": errBoom})
+                return want
+            },
+        },
+        {
+            name: "global Error",
+            log: func(l *ELKLogger) string {
+                SetDefault(l.AsLogger())
+                defer SetDefault(nil)
+                
+                want := nextLine()
+                Error("failed", map[string]interface{}{"error": errBoom})
+                return want
+            },
+        },
+        {
+            name: "WithFields",
+            log: func(l *ELKLogger) string {
+                want := nextLine()
+                l.WithFields(map[string]interface{}{"order_id": 1}).Error("failed", map[string]interface{}{"error": errBoom})
+                return want
+            },
+        },
+        {
+            name: "wrapper with caller depth 4",
+            opts: []Option{WithCallerDepth(4)},
+            log: func(l *ELKLogger) string {
+                want := nextLine()
+                serviceLogError(l, errBoom)
+                return want
+            },
+        },
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l := newQueueTestLogger(tt.opts...)
+            want := tt.log(l)
+            entry := l.lastQueued(t)
+            
+            if got := entry.Fields["caller"]; got != want {
+                t.Errorf("caller = %v, want %s", got, want)
+            }
+            if entry.Error == nil || entry.Error.Message != "boom" || entry.Error.Type != "*errors.errorString" {
+                t.Fatalf("error = %+v, want boom of type *errors.errorString", entry.Error)
+            }
+            if len(entry.Error.Stack) == 0 || !strings.HasSuffix(entry.Error.Stack[0], want) {
+                t.Errorf("stack starts with %v, want the call site %s", entry.Error.Stack, want)
+            }
+            for _, frame := range entry.Error.Stack {
+                if strings.HasPrefix(frame, loggingPackage+"(*ELKLogger)") {
+                    t.Errorf("stack contains logger frame %s", frame)
+                }
+            }
+        })
+    }
+}
+
+// serviceInfo - обертка сервиса над Info
+func serviceInfo(l *ELKLogger, message string) {
+    l.Info(message, nil)
+}
+
+func TestCallerDepth(t *testing.T) {
+    tests := []struct {
+        name       string
+        opts       []Option
+        wrapped    bool
+        wantCaller bool
+    }{
+        {name: "direct call with default depth", wantCaller: true},
+        {name: "wrapper with depth 4", opts: []Option{WithCallerDepth(4)}, wrapped: true, wantCaller: true},
+        {name: "caller disabled", opts: []Option{WithDisableCaller()}, wantCaller: false},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l := newQueueTestLogger(tt.opts...)
+            
+            var want string
+            if tt.wrapped {
+                want = nextLine()
+                serviceInfo(l, "wrapped")
+            } else {
+                want = nextLine()
+                l.Info("direct", nil)
+            }
+            
+            got, ok := l.lastQueued(t).Fields["caller"]
+            if !tt.wantCaller {
+                if ok {
+                    t.Errorf("caller = %v, want no caller field", got)
+                }
+                return
+            }
+            if got != want {
+                t.Errorf("caller = %v, want %s", got, want)
+            }
+        })
+    }
+}
+
+func TestLogEntry_ErrorOmittedWithoutError(t *testing.T) {
+    l := newQueueTestLogger()
+    l.Info("ok", map[string]interface{}{"error": "plain string"})
+    entry := l.lastQueued(t)
+    
+    data, err := json.Marshal(entry)
+    if err != nil {
+        t.Fatalf("marshal: %v", err)
+    }
+    
+    var doc map[string]interface{}
+    if err := json.Unmarshal(data, &doc); err != nil {
+        t.Fatalf("unmarshal: %v", err)
+    }
+    if _, ok := doc["error"]; ok {
+        t.Errorf("entry without error value has error object: %s", data)
+    }
+    if got := entry.Fields["error"]; got != "plain string" {
+        t.Errorf("fields.error = %v, want the string kept as a field", got)
+    }
+}
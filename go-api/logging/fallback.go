@@ -0,0 +1,152 @@
+package logging
+
+import (
+    "bufio"
+    "bytes"
+    "fmt"
+    "os"
+    "strconv"
+    "sync"
+)
+
+// Сколько ротированных файлов хранить рядом с основным (.1 и .2)
+const fallbackRotations = 2
+
+// fallbackWriter сохраняет записи, не доставленные в Logstash, в локальный файл
+// JSON строками. При превышении maxBytes файл ротируется в .1, .1 - в .2.
+// Когда Logstash снова доступен, записи переотправляются и файлы удаляются.
+type fallbackWriter struct {
+    mu       sync.Mutex
+    path     string
+    maxBytes int64
+    file     *os.File
+    size     int64
+}
+
+func newFallbackWriter(path string, maxBytes int64) *fallbackWriter {
+    return &fallbackWriter{path: path, maxBytes: maxBytes}
+}
+
+// write добавляет запись в файл, при необходимости ротируя его
+func (f *fallbackWriter) write(data []byte) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    
+    line := append(append(make([]byte, 0, len(data)+1), data...), '\n')
+    
+    if f.file != nil && f.size > 0 && f.size+int64(len(line)) > f.maxBytes {
+        if err := f.rotate(); err != nil {
+            return err
+        }
+    }
+    
+    if f.file == nil {
+        if err := f.open(); err != nil {
+            return err
+        }
+    }
+    
+    n, err := f.file.Write(line)
+    f.size += int64(n)
+    return err
+}
+
+func (f *fallbackWriter) open() error {
+    file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+    if err != nil {
+        return fmt.Errorf("open fallback file: %w", err)
+    }
+    
+    info, err := file.Stat()
+    if err != nil {
+        file.Close()
+        return fmt.Errorf("stat fallback file: %w", err)
+    }
+    
+    f.file = file
+    f.size = info.Size()
+    return nil
+}
+
+// rotate сдвигает файлы: .1 -> .2, основной -> .1. Самый старый удаляется
+func (f *fallbackWriter) rotate() error {
+    f.file.Close()
+    f.file = nil
+    f.size = 0
+    
+    for i := fallbackRotations; i > 1; i-- {
+        os.Rename(f.rotatedPath(i-1), f.rotatedPath(i))
+    }
+    
+    if err := os.Rename(f.path, f.rotatedPath(1)); err != nil {
+        return fmt.Errorf("rotate fallback file: %w", err)
+    }
+    return f.open()
+}
+
+func (f *fallbackWriter) rotatedPath(n int) string {
+    return f.path + "." + strconv.Itoa(n)
+}
+
+// drain забирает все сохраненные записи от старых к новым и удаляет файлы
+func (f *fallbackWriter) drain() ([][]byte, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    
+    if f.file != nil {
+        f.file.Close()
+        f.file = nil
+        f.size = 0
+    }
+    
+    paths := make([]string, 0, fallbackRotations+1)
+    for i := fallbackRotations; i >= 1; i-- {
+        paths = append(paths, f.rotatedPath(i))
+    }
+    paths = append(paths, f.path)
+    
+    var lines [][]byte
+    for _, path := range paths {
+        data, err := os.ReadFile(path)
+        if os.IsNotExist(err) {
+            continue
+        }
+        if err != nil {
+            return lines, fmt.Errorf("read fallback file: %w", err)
+        }
+        
+        scanner := bufio.NewScanner(bytes.NewReader(data))
+        scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+        for scanner.Scan() {
+            if line := scanner.Bytes(); len(line) > 0 {
+                lines = append(lines, append([]byte(nil), line...))
+            }
+        }
+        
+        os.Remove(path)
+    }
+    
+    return lines, nil
+}
+
+// replayFallback переотправляет сохраненные записи. Записи, которые
+// снова не удалось доставить, возвращаются в файл
+func (l *ELKLogger) replayFallback() {
+    if l.fallback == nil {
+        return
+    }
+    
+    lines, err := l.fallback.drain()
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Failed to read log fallback: %v\n", err)
+    }
+    
+    for i, line := range lines {
+        if err := l.send(line); err != nil {
+            for _, rest := range lines[i:] {
+                l.fallback.write(rest)
+            }
+            return
+        }
+    }
+}
@@ -0,0 +1,188 @@
+package logging
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "reflect"
+    "sync"
+    "sync/atomic"
+    "testing"
+)
+
+// line9 - запись из 9 байт, с переводом строки в файле 10
+func line9(n int) []byte {
+    return []byte(fmt.Sprintf(`{"n":%03d}`, n))
+}
+
+func fileLines(t *testing.T, path string) int {
+    t.Helper()
+    data, err := os.ReadFile(path)
+    if os.IsNotExist(err) {
+        return -1
+    }
+    if err != nil {
+        t.Fatalf("read %s: %v", path, err)
+    }
+    return len(data) / 10
+}
+
+func TestFallbackWriter_RotationBoundaries(t *testing.T) {
+    tests := []struct {
+        name     string
+        maxBytes int64
+        writes   int
+        // Число строк в основном файле, .1 и .2; -1 - файла нет
+        want [3]int
+    }{
+        {name: "exactly at the limit", maxBytes: 20, writes: 2, want: [3]int{2, -1, -1}},
+        {name: "one byte over the limit", maxBytes: 19, writes: 2, want: [3]int{1, 1, -1}},
+        {name: "entry larger than the limit", maxBytes: 5, writes: 1, want: [3]int{1, -1, -1}},
+        {name: "two rotations", maxBytes: 20, writes: 6, want: [3]int{2, 2, 2}},
+        {name: "oldest file discarded", maxBytes: 20, writes: 8, want: [3]int{2, 2, 2}},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            path := filepath.Join(t.TempDir(), "fallback.log")
+            f := newFallbackWriter(path, tt.maxBytes)
+            for i := 0; i < tt.writes; i++ {
+                if err := f.write(line9(i)); err != nil {
+                    t.Fatalf("write %d: %v", i, err)
+                }
+            }
+            
+            got := [3]int{fileLines(t, path), fileLines(t, path+".1"), fileLines(t, path+".2")}
+            if got != tt.want {
+                t.Errorf("lines in main, .1, .2 = %v, want %v", got, tt.want)
+            }
+            if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+                t.Errorf("%s.3 exists, want at most %d rotated files", path, fallbackRotations)
+            }
+        })
+    }
+}
+
+func TestFallbackWriter_DrainOldestFirst(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "fallback.log")
+    f := newFallbackWriter(path, 20)
+    for i := 0; i < 8; i++ {
+        f.write(line9(i))
+    }
+    
+    lines, err := f.drain()
+    if err != nil {
+        t.Fatalf("drain: %v", err)
+    }
+    
+    // Записи 0 и 1 ушли вместе с самым старым файлом
+    var got []string
+    for _, line := range lines {
+        got = append(got, string(line))
+    }
+    want := []string{`{"n":002}`, `{"n":003}`, `{"n":004}`, `{"n":005}`, `{"n":006}`, `{"n":007}`}
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("drained %v, want %v", got, want)
+    }
+    
+    for _, p := range []string{path, path + ".1", path + ".2"} {
+        if _, err := os.Stat(p); !os.IsNotExist(err) {
+            t.Errorf("%s still exists after drain", filepath.Base(p))
+        }
+    }
+}
+
+func TestFallbackWriter_ConcurrentWrites(t *testing.T) {
+    const writers, perWriter = 10, 100
+    
+    tests := []struct {
+        name     string
+        maxBytes int64
+    }{
+        {name: "without rotation", maxBytes: 1 << 20},
+        {name: "with rotation", maxBytes: 4096},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            f := newFallbackWriter(filepath.Join(t.TempDir(), "fallback.log"), tt.maxBytes)
+            
+            var wg sync.WaitGroup
+            for w := 0; w < writers; w++ {
+                wg.Add(1)
+                go func(w int) {
+                    defer wg.Done()
+                    for i := 0; i < perWriter; i++ {
+                        f.write([]byte(fmt.Sprintf(`{"writer":%d,"seq":%d}`, w, i)))
+                    }
+                }(w)
+            }
+            wg.Wait()
+            
+            lines, err := f.drain()
+            if err != nil {
+                t.Fatalf("drain: %v", err)
+            }
+            if tt.maxBytes >= 1<<20 && len(lines) != writers*perWriter {
+                t.Errorf("drained %d lines, want %d", len(lines), writers*perWriter)
+            }
+            for _, line := range lines {
+                var doc map[string]int
+                if err := json.Unmarshal(line, &doc); err != nil {
+                    t.Fatalf("interleaved line %q: %v", line, err)
+                }
+            }
+        })
+    }
+}
+
+func TestReplayFallback(t *testing.T) {
+    tests := []struct {
+        name         string
+        acceptFirst  int64
+        wantReceived int64
+        wantLeft     int
+    }{
+        {name: "logstash recovered", acceptFirst: 100, wantReceived: 3, wantLeft: 0},
+        {name: "logstash fails again mid-replay", acceptFirst: 1, wantReceived: 1, wantLeft: 2},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var received atomic.Int64
+            srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                if received.Load() >= tt.acceptFirst {
+                    w.WriteHeader(http.StatusBadRequest)
+                    return
+                }
+                received.Add(1)
+            }))
+            defer srv.Close()
+            
+            path := filepath.Join(t.TempDir(), "fallback.log")
+            l := newQueueTestLogger()
+            l.httpClient = srv.Client()
+            l.logstashURL = srv.URL
+            l.fallback = newFallbackWriter(path, 1<<20)
+            for i := 0; i < 3; i++ {
+                l.fallback.write(line9(i))
+            }
+            
+            l.replayFallback()
+            
+            if got := received.Load(); got != tt.wantReceived {
+                t.Errorf("logstash received %d entries, want %d", got, tt.wantReceived)
+            }
+            left := fileLines(t, path)
+            if left == -1 {
+                left = 0
+            }
+            if left != tt.wantLeft {
+                t.Errorf("%d entries left in the fallback file, want %d", left, tt.wantLeft)
+            }
+        })
+    }
+}
@@ -0,0 +1,42 @@
+package logging
+
+// WithField возвращает дочерний логгер, добавляющий поле ко всем записям
+func (l *ELKLogger) WithField(key string, value interface{}) *ELKLogger {
+    return l.withFields(map[string]interface{}{key: value})
+}
+
+// WithFields возвращает дочерний логгер, добавляющий поля ко всем записям.
+// Дочерний логгер использует HTTP клиент и пул отправки родителя,
+// базовые поля копируются, поэтому родитель не меняется
+func (l *ELKLogger) WithFields(fields map[string]interface{}) *ELKLogger {
+    return l.withFields(fields)
+}
+
+func (l *ELKLogger) withFields(fields map[string]interface{}) *ELKLogger {
+    child := *l
+    child.fields = make(map[string]interface{}, len(l.fields)+len(fields))
+    for k, v := range l.fields {
+        child.fields[k] = v
+    }
+    for k, v := range fields {
+        child.fields[k] = v
+    }
+    return &child
+}
+
+// mergeFields объединяет базовые поля логгера с полями записи,
+// поля записи имеют приоритет
+func (l *ELKLogger) mergeFields(fields map[string]interface{}) map[string]interface{} {
+    if len(l.fields) == 0 {
+        return fields
+    }
+
+    merged := make(map[string]interface{}, len(l.fields)+len(fields))
+    for k, v := range l.fields {
+        merged[k] = v
+    }
+    for k, v := range fields {
+        merged[k] = v
+    }
+    return merged
+}
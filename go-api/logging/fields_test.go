@@ -0,0 +1,164 @@
+package logging
+
+import (
+    "encoding/json"
+    "reflect"
+    "sync"
+    "testing"
+)
+
+func TestFieldPrefix_Serialization(t *testing.T) {
+    tests := []struct {
+        name    string
+        prefix  string
+        wantKey string
+    }{
+        {name: "no prefix", prefix: "", wantKey: "user_id"},
+        {name: "app prefix", prefix: "app.", wantKey: "app.user_id"},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l := newQueueTestLogger(WithFieldPrefix(tt.prefix), WithDisableCaller(), WithTimestampPrecision("ms"))
+            l.Info("order created", map[string]interface{}{"user_id": 42})
+            
+            data, err := json.Marshal(l.lastQueued(t))
+            if err != nil {
+                t.Fatalf("marshal: %v", err)
+            }
+            
+            var doc struct {
+                Timestamp string                 `json:"@timestamp"`
+                Level     string                 `json:"level"`
+                Fields    map[string]interface{} `json:"fields"`
+            }
+            if err := json.Unmarshal(data, &doc); err != nil {
+                t.Fatalf("unmarshal: %v", err)
+            }
+            
+            if len(doc.Fields) != 1 || doc.Fields[tt.wantKey] != float64(42) {
+                t.Errorf("fields = %v, want {%q: 42}", doc.Fields, tt.wantKey)
+            }
+            if doc.Timestamp == "" || doc.Level != "INFO" {
+                t.Errorf("@timestamp = %q, level = %q, want reserved keys unprefixed: %s", doc.Timestamp, doc.Level, data)
+            }
+        })
+    }
+}
+
+func TestWithFields_ChildIsolation(t *testing.T) {
+    tests := []struct {
+        name       string
+        build      func(parent *ELKLogger) *ELKLogger
+        wantParent map[string]interface{}
+        wantChild  map[string]interface{}
+    }{
+        {
+            name: "WithFields",
+            build: func(parent *ELKLogger) *ELKLogger {
+                return parent.WithFields(map[string]interface{}{"request_id": "req-1", "user_id": 7})
+            },
+            wantParent: map[string]interface{}{"service_zone": "eu"},
+            wantChild:  map[string]interface{}{"service_zone": "eu", "request_id": "req-1", "user_id": 7},
+        },
+        {
+            name: "WithField",
+            build: func(parent *ELKLogger) *ELKLogger {
+                return parent.WithField("request_id", "req-1")
+            },
+            wantParent: map[string]interface{}{"service_zone": "eu"},
+            wantChild:  map[string]interface{}{"service_zone": "eu", "request_id": "req-1"},
+        },
+        {
+            name: "child overrides parent field",
+            build: func(parent *ELKLogger) *ELKLogger {
+                return parent.WithField("service_zone", "us")
+            },
+            wantParent: map[string]interface{}{"service_zone": "eu"},
+            wantChild:  map[string]interface{}{"service_zone": "us"},
+        },
+        {
+            name: "grandchild",
+            build: func(parent *ELKLogger) *ELKLogger {
+                return parent.WithField("request_id", "req-1").WithField("order_id", 3)
+            },
+            wantParent: map[string]interface{}{"service_zone": "eu"},
+            wantChild:  map[string]interface{}{"service_zone": "eu", "request_id": "req-1", "order_id": 3},
+        },
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            parent := newQueueTestLogger(WithDisableCaller()).WithField("service_zone", "eu")
+            child := tt.build(parent)
+            
+            if !reflect.DeepEqual(parent.fields, tt.wantParent) {
+                t.Errorf("parent fields = %v, want %v", parent.fields, tt.wantParent)
+            }
+            if !reflect.DeepEqual(child.fields, tt.wantChild) {
+                t.Errorf("child fields = %v, want %v", child.fields, tt.wantChild)
+            }
+            if child.queue != parent.queue || child.dispatcher != parent.dispatcher || child.httpClient != parent.httpClient {
+                t.Error("child does not share the parent's queue, worker pool and HTTP client")
+            }
+            
+            // Поля записи дополняют базовые и не попадают в логгер
+            child.Info("order created", map[string]interface{}{"amount": 10})
+            if got := child.lastQueued(t).Fields; got["amount"] != 10 || len(got) != len(tt.wantChild)+1 {
+                t.Errorf("entry fields = %v, want child fields and amount", got)
+            }
+            if _, ok := child.fields["amount"]; ok {
+                t.Error("entry fields leaked into the child logger")
+            }
+            
+            parent.Info("parent entry", nil)
+            if got := parent.lastQueued(t).Fields; !reflect.DeepEqual(got, tt.wantParent) {
+                t.Errorf("parent entry fields = %v, want %v", got, tt.wantParent)
+            }
+        })
+    }
+}
+
+// Родитель и дочерние логгеры пишут одновременно, проверяется под -race
+func TestWithFields_Concurrent(t *testing.T) {
+    const workers, perWorker = 8, 50
+    
+    l := newQueueTestLogger(WithDisableCaller())
+    l.queue = make(chan LogEntry, 2*workers*perWorker)
+    parent := l.WithField("service_zone", "eu")
+    
+    var wg sync.WaitGroup
+    for w := 0; w < workers; w++ {
+        wg.Add(2)
+        go func(w int) {
+            defer wg.Done()
+            child := parent.WithField("worker", w)
+            for i := 0; i < perWorker; i++ {
+                child.WithFields(map[string]interface{}{"i": i}).Info("child entry", nil)
+            }
+        }(w)
+        go func() {
+            defer wg.Done()
+            for i := 0; i < perWorker; i++ {
+                parent.Info("parent entry", map[string]interface{}{"i": i})
+            }
+        }()
+    }
+    wg.Wait()
+    close(l.queue)
+    
+    counts := make(map[string]int)
+    for entry := range l.queue {
+        counts[entry.Message]++
+        _, hasWorker := entry.Fields["worker"]
+        if entry.Fields["service_zone"] != "eu" || hasWorker != (entry.Message == "child entry") {
+            t.Errorf("%s fields = %v", entry.Message, entry.Fields)
+        }
+    }
+    if counts["child entry"] != workers*perWorker || counts["parent entry"] != workers*perWorker {
+        t.Errorf("entries = %v, want %d of each", counts, workers*perWorker)
+    }
+    if len(parent.fields) != 1 {
+        t.Errorf("parent fields = %v, want only service_zone", parent.fields)
+    }
+}
@@ -0,0 +1,240 @@
+package logging
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "strings"
+    "sync"
+    "time"
+)
+
+// EnrichmentHook дополняет запись лога перед отправкой
+type EnrichmentHook func(entry *LogEntry)
+
+type namedHook struct {
+    name string
+    hook EnrichmentHook
+}
+
+// Реестр хуков общий для логгера и его дочерних логгеров. Срезы хуков
+// не меняются после публикации: регистрация создает новый срез, поэтому
+// отправка перебирает снимок без блокировки
+type hookRegistry struct {
+    mu     sync.Mutex
+    hooks  []namedHook
+    staged map[HookStage][]Hook
+}
+
+// HookStage - момент жизненного цикла записи, в который вызывается Hook
+type HookStage int
+
+const (
+    // BeforeSend - перед записью в приемники и отправкой, ошибка отбрасывает запись
+    BeforeSend HookStage = iota
+    // AfterSend - после успешной доставки в Logstash
+    AfterSend
+    // OnError - когда запись не удалось доставить после всех повторов
+    OnError
+)
+
+// Hook реагирует на запись лога на заданной стадии и может ее изменить
+type Hook interface {
+    Fire(entry *LogEntry) error
+}
+
+// ErrEntryFiltered возвращается хуком BeforeSend, отбросившим запись
+var ErrEntryFiltered = errors.New("log entry filtered")
+
+// FilterHook отбрасывает записи, поля которых удовлетворяют Predicate
+type FilterHook struct {
+    Predicate func(fields map[string]interface{}) bool
+}
+
+func (h FilterHook) Fire(entry *LogEntry) error {
+    if h.Predicate != nil && h.Predicate(entry.Fields) {
+        return ErrEntryFiltered
+    }
+    return nil
+}
+
+// AddHook регистрирует хук стадии. Хуки стадии вызываются в порядке регистрации
+func (l *ELKLogger) AddHook(stage HookStage, hook Hook) {
+    r := l.hooks
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if r.staged == nil {
+        r.staged = make(map[HookStage][]Hook)
+    }
+    hooks := make([]Hook, 0, len(r.staged[stage])+1)
+    r.staged[stage] = append(append(hooks, r.staged[stage]...), hook)
+}
+
+// fireHooks вызывает хуки стадии по порядку. Для BeforeSend первая ошибка
+// прерывает цепочку, на остальных стадиях ошибки хуков только выводятся в stderr
+func (l *ELKLogger) fireHooks(stage HookStage, entry *LogEntry) error {
+    r := l.hooks
+    r.mu.Lock()
+    hooks := r.staged[stage]
+    r.mu.Unlock()
+
+    for _, h := range hooks {
+        if err := h.Fire(entry); err != nil {
+            if stage == BeforeSend {
+                return err
+            }
+            fmt.Fprintf(os.Stderr, "Log hook failed: %v\n", err)
+        }
+    }
+    return nil
+}
+
+// AddEnrichmentHook регистрирует хук. Хуки вызываются в порядке регистрации,
+// повторная регистрация с тем же именем заменяет хук на месте
+func (l *ELKLogger) AddEnrichmentHook(name string, hook EnrichmentHook) {
+    r := l.hooks
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    hooks := make([]namedHook, 0, len(r.hooks)+1)
+    replaced := false
+    for _, h := range r.hooks {
+        if h.name == name {
+            h.hook = hook
+            replaced = true
+        }
+        hooks = append(hooks, h)
+    }
+    if !replaced {
+        hooks = append(hooks, namedHook{name: name, hook: hook})
+    }
+    r.hooks = hooks
+}
+
+// RemoveEnrichmentHook удаляет хук по имени
+func (l *ELKLogger) RemoveEnrichmentHook(name string) {
+    r := l.hooks
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    for i := range r.hooks {
+        if r.hooks[i].name == name {
+            r.hooks = append(r.hooks[:i:i], r.hooks[i+1:]...)
+            return
+        }
+    }
+}
+
+// applyEnrichmentHooks вызывает хуки по порядку. Поля, добавленные хуками,
+// получают префикс логгера, как и поля из вызова
+func (l *ELKLogger) applyEnrichmentHooks(entry *LogEntry) {
+    r := l.hooks
+    r.mu.Lock()
+    hooks := r.hooks
+    r.mu.Unlock()
+
+    if len(hooks) == 0 {
+        return
+    }
+
+    var existing map[string]struct{}
+    if l.fieldPrefix != "" {
+        existing = make(map[string]struct{}, len(entry.Fields))
+        for k := range entry.Fields {
+            existing[k] = struct{}{}
+        }
+    }
+
+    for _, h := range hooks {
+        h.hook(entry)
+    }
+
+    if l.fieldPrefix == "" {
+        return
+    }
+    for k, v := range entry.Fields {
+        if _, ok := existing[k]; ok || strings.HasPrefix(k, l.fieldPrefix) {
+            continue
+        }
+        delete(entry.Fields, k)
+        entry.Fields[l.fieldPrefix+k] = v
+    }
+}
+
+// KubernetesHook добавляет имя пода и ноды из Downward API
+func KubernetesHook() EnrichmentHook {
+    podName := os.Getenv("MY_POD_NAME")
+    nodeName := os.Getenv("MY_NODE_NAME")
+
+    return func(entry *LogEntry) {
+        if podName != "" {
+            entry.Fields["pod_name"] = podName
+        }
+        if nodeName != "" {
+            entry.Fields["node_name"] = nodeName
+        }
+    }
+}
+
+const awsMetadataURL = "http://169.254.169.254/latest"
+
+// AWSMetadataHook однократно запрашивает метаданные EC2 инстанса
+// и добавляет их во все записи. Вне AWS хук ничего не делает
+func AWSMetadataHook(timeout time.Duration) EnrichmentHook {
+    client := &http.Client{Timeout: timeout}
+    ctx, cancel := context.WithTimeout(context.Background(), timeout)
+    defer cancel()
+
+    // IMDSv2 требует токен, при его отсутствии пробуем IMDSv1
+    token := fetchAWSMetadata(ctx, client, http.MethodPut, "/api/token", "")
+
+    instanceID := fetchAWSMetadata(ctx, client, http.MethodGet, "/meta-data/instance-id", token)
+    zone := fetchAWSMetadata(ctx, client, http.MethodGet, "/meta-data/placement/availability-zone", token)
+    region := fetchAWSMetadata(ctx, client, http.MethodGet, "/meta-data/placement/region", token)
+
+    return func(entry *LogEntry) {
+        if instanceID != "" {
+            entry.Fields["aws_instance_id"] = instanceID
+        }
+        if zone != "" {
+            entry.Fields["aws_availability_zone"] = zone
+        }
+        if region != "" {
+            entry.Fields["aws_region"] = region
+        }
+    }
+}
+
+func fetchAWSMetadata(ctx context.Context, client *http.Client, method, path, token string) string {
+    req, err := http.NewRequestWithContext(ctx, method, awsMetadataURL+path, nil)
+    if err != nil {
+        return ""
+    }
+
+    if method == http.MethodPut {
+        req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+    }
+    if token != "" {
+        req.Header.Set("X-aws-ec2-metadata-token", token)
+    }
+
+    resp, err := client.Do(req)
+    if err != nil {
+        return ""
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return ""
+    }
+
+    body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+    if err != nil {
+        return ""
+    }
+    return strings.TrimSpace(string(body))
+}
@@ -0,0 +1,263 @@
+package logging
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "sync"
+    "testing"
+)
+
+func newHookTestLogger(prefix string) *ELKLogger {
+    return &ELKLogger{hooks: &hookRegistry{}, fieldPrefix: prefix}
+}
+
+func newHookTestEntry(prefix string) *LogEntry {
+    return &LogEntry{Fields: map[string]interface{}{prefix + "user_id": 42}}
+}
+
+func TestEnrichmentHooks_RegistrationOrder(t *testing.T) {
+    l := newHookTestLogger("")
+
+    var calls []string
+    for _, name := range []string{"first", "second", "third"} {
+        name := name
+        l.AddEnrichmentHook(name, func(entry *LogEntry) {
+            calls = append(calls, name)
+            entry.Fields["last"] = name
+        })
+    }
+
+    // Замена сохраняет позицию хука
+    l.AddEnrichmentHook("second", func(entry *LogEntry) {
+        calls = append(calls, "second-replaced")
+    })
+
+    entry := newHookTestEntry("")
+    l.applyEnrichmentHooks(entry)
+
+    if want := []string{"first", "second-replaced", "third"}; !reflect.DeepEqual(calls, want) {
+        t.Errorf("hooks called in order %v, want %v", calls, want)
+    }
+    if entry.Fields["last"] != "third" {
+        t.Errorf("fields[last] = %v, want third", entry.Fields["last"])
+    }
+}
+
+func TestEnrichmentHooks_Remove(t *testing.T) {
+    l := newHookTestLogger("")
+    l.AddEnrichmentHook("region", func(entry *LogEntry) { entry.Fields["region"] = "eu-west-1" })
+    l.RemoveEnrichmentHook("region")
+    l.RemoveEnrichmentHook("missing")
+
+    entry := newHookTestEntry("")
+    l.applyEnrichmentHooks(entry)
+
+    if _, ok := entry.Fields["region"]; ok {
+        t.Error("removed hook was called")
+    }
+}
+
+func TestEnrichmentHooks_FieldPrefix(t *testing.T) {
+    tests := []struct {
+        name   string
+        prefix string
+        want   map[string]interface{}
+    }{
+        {
+            name:   "no prefix",
+            prefix: "",
+            want:   map[string]interface{}{"user_id": 42, "pod_name": "api-0"},
+        },
+        {
+            name:   "prefix applied to hook fields",
+            prefix: "dev.",
+            want:   map[string]interface{}{"dev.user_id": 42, "dev.pod_name": "api-0"},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l := newHookTestLogger(tt.prefix)
+            l.AddEnrichmentHook("kubernetes", func(entry *LogEntry) { entry.Fields["pod_name"] = "api-0" })
+
+            entry := newHookTestEntry(tt.prefix)
+            l.applyEnrichmentHooks(entry)
+
+            if !reflect.DeepEqual(entry.Fields, tt.want) {
+                t.Errorf("fields = %v, want %v", entry.Fields, tt.want)
+            }
+        })
+    }
+}
+
+func TestKubernetesHook(t *testing.T) {
+    t.Setenv("MY_POD_NAME", "api-7d9f")
+    t.Setenv("MY_NODE_NAME", "node-1")
+
+    entry := newHookTestEntry("")
+    KubernetesHook()(entry)
+
+    if entry.Fields["pod_name"] != "api-7d9f" || entry.Fields["node_name"] != "node-1" {
+        t.Errorf("fields = %v", entry.Fields)
+    }
+}
+
+// Регистрация и замена хуков во время отправки не должны вызывать гонок
+// (проверяется с -race)
+func TestEnrichmentHooks_ConcurrentRegistration(t *testing.T) {
+    l := newHookTestLogger("")
+    l.AddEnrichmentHook("replaced", func(entry *LogEntry) {})
+
+    var wg sync.WaitGroup
+    for i := 0; i < 4; i++ {
+        wg.Add(2)
+        go func() {
+            defer wg.Done()
+            for j := 0; j < 200; j++ {
+                l.applyEnrichmentHooks(newHookTestEntry(""))
+            }
+        }()
+        go func(i int) {
+            defer wg.Done()
+            for j := 0; j < 200; j++ {
+                l.AddEnrichmentHook("replaced", func(entry *LogEntry) { entry.Fields["n"] = j })
+                l.AddEnrichmentHook(fmt.Sprintf("hook-%d", i), func(entry *LogEntry) {})
+                l.RemoveEnrichmentHook(fmt.Sprintf("hook-%d", i))
+            }
+        }(i)
+    }
+    wg.Wait()
+}
+
+// hookFunc позволяет задать Hook функцией
+type hookFunc func(entry *LogEntry) error
+
+func (f hookFunc) Fire(entry *LogEntry) error { return f(entry) }
+
+// newStagedHookLogger отправляет записи в Logstash, отвечающий status
+func newStagedHookLogger(t *testing.T, status int) (*ELKLogger, *[]map[string]interface{}) {
+    t.Helper()
+
+    var mu sync.Mutex
+    var received []map[string]interface{}
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var doc map[string]interface{}
+        json.NewDecoder(r.Body).Decode(&doc)
+        mu.Lock()
+        received = append(received, doc)
+        mu.Unlock()
+        w.WriteHeader(status)
+    }))
+    t.Cleanup(srv.Close)
+
+    l := newQueueTestLogger(WithDisableCaller())
+    l.httpClient = srv.Client()
+    l.logstashURL = srv.URL
+    l.output = &logstashOutput{l: l}
+    return l, &received
+}
+
+func TestStagedHooks_OrderAndMutation(t *testing.T) {
+    l, received := newStagedHookLogger(t, http.StatusOK)
+
+    var calls []string
+    l.AddHook(AfterSend, hookFunc(func(entry *LogEntry) error {
+        calls = append(calls, "after:"+fmt.Sprint(entry.Fields["trace_id"]))
+        return nil
+    }))
+    l.AddHook(BeforeSend, hookFunc(func(entry *LogEntry) error {
+        calls = append(calls, "before-1")
+        entry.Fields["trace_id"] = "sidecar-trace"
+        return nil
+    }))
+    l.AddHook(BeforeSend, hookFunc(func(entry *LogEntry) error {
+        // Изменения предыдущего хука видны следующему
+        calls = append(calls, "before-2:"+fmt.Sprint(entry.Fields["trace_id"]))
+        return nil
+    }))
+    l.AddHook(OnError, hookFunc(func(entry *LogEntry) error {
+        calls = append(calls, "on-error")
+        return nil
+    }))
+
+    l.Info("checkout", map[string]interface{}{"order_id": 1})
+    l.sendLogAsync(l.lastQueued(t))
+
+    if want := []string{"before-1", "before-2:sidecar-trace", "after:sidecar-trace"}; !reflect.DeepEqual(calls, want) {
+        t.Errorf("hooks called %v, want %v", calls, want)
+    }
+    if len(*received) != 1 {
+        t.Fatalf("logstash received %d entries, want 1", len(*received))
+    }
+    if fields, _ := (*received)[0]["fields"].(map[string]interface{}); fields["trace_id"] != "sidecar-trace" {
+        t.Errorf("sent fields = %v, want trace_id from the hook", fields)
+    }
+}
+
+func TestStagedHooks_BeforeSendDrops(t *testing.T) {
+    tests := []struct {
+        name     string
+        fields   map[string]interface{}
+        wantSent bool
+    }{
+        {name: "matching entry dropped", fields: map[string]interface{}{"path": "/health"}},
+        {name: "other entry sent", fields: map[string]interface{}{"path": "/v1/orders"}, wantSent: true},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            sink := &memorySink{name: "test_filter"}
+            l := newQueueTestLogger(WithDisableCaller())
+            l.output = sink
+
+            var laterCalled bool
+            l.AddHook(BeforeSend, FilterHook{Predicate: func(fields map[string]interface{}) bool {
+                return fields["path"] == "/health"
+            }})
+            l.AddHook(BeforeSend, hookFunc(func(entry *LogEntry) error {
+                laterCalled = true
+                return nil
+            }))
+
+            l.Info("request", tt.fields)
+            l.sendLogAsync(l.lastQueued(t))
+
+            if sent := len(sink.written()) == 1; sent != tt.wantSent {
+                t.Errorf("sent = %v, want %v", sent, tt.wantSent)
+            }
+            if laterCalled != tt.wantSent {
+                t.Errorf("hook after the filter called = %v, want %v", laterCalled, tt.wantSent)
+            }
+        })
+    }
+}
+
+func TestStagedHooks_OnError(t *testing.T) {
+    l, _ := newStagedHookLogger(t, http.StatusBadRequest)
+
+    var calls []string
+    l.AddHook(AfterSend, hookFunc(func(entry *LogEntry) error {
+        calls = append(calls, "after")
+        return nil
+    }))
+    l.AddHook(OnError, hookFunc(func(entry *LogEntry) error {
+        calls = append(calls, "alert")
+        return errors.New("pager unavailable")
+    }))
+    l.AddHook(OnError, hookFunc(func(entry *LogEntry) error {
+        // Ошибка предыдущего хука не прерывает цепочку OnError
+        calls = append(calls, "metric:"+entry.Message)
+        return nil
+    }))
+
+    l.Error("payment failed", nil)
+    l.sendLogAsync(l.lastQueued(t))
+
+    if want := []string{"alert", "metric:payment failed"}; !reflect.DeepEqual(calls, want) {
+        t.Errorf("hooks called %v, want %v", calls, want)
+    }
+}
@@ -0,0 +1,126 @@
+//go:build integration
+
+package logging_test
+
+import (
+    "context"
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/crazy1997/go-api/config"
+    handlers "github.com/crazy1997/go-api/hadnlers"
+    "github.com/crazy1997/go-api/logging"
+    "github.com/crazy1997/go-api/router"
+)
+
+// logstashMock принимает записи, как Logstash с http input
+type logstashMock struct {
+    *httptest.Server
+
+    mu     sync.Mutex
+    bodies [][]byte
+}
+
+func newLogstashMock(t *testing.T) *logstashMock {
+    m := &logstashMock{}
+    m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != http.MethodPost {
+            t.Errorf("logstash mock got %s, want POST", r.Method)
+        }
+        body, _ := io.ReadAll(r.Body)
+
+        m.mu.Lock()
+        m.bodies = append(m.bodies, body)
+        m.mu.Unlock()
+    }))
+    t.Cleanup(m.Close)
+    return m
+}
+
+func (m *logstashMock) received() [][]byte {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return append([][]byte(nil), m.bodies...)
+}
+
+// neverFail отключает имитацию сбоев обработчиков
+type neverFail struct{}
+
+func (neverFail) Intn(n int) int { return n - 1 }
+
+// TestLogPipeline проверяет путь обработчик -> логгер -> Logstash
+func TestLogPipeline(t *testing.T) {
+    mock := newLogstashMock(t)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    logger := logging.InitLogger(ctx, &config.Config{
+        LogstashURL:    mock.URL,
+        Environment:    "test",
+        ServerIP:       "127.0.0.1",
+        ServiceVersion: "test",
+    })
+
+    api := httptest.NewServer(router.New(router.Options{
+        Handler: handlers.New(handlers.Config{Logger: logger.AsLogger(), Rand: neverFail{}}),
+    }))
+    defer api.Close()
+
+    resp, err := http.Get(api.URL + "/api/users")
+    if err != nil {
+        t.Fatalf("GET /api/users: %v", err)
+    }
+    io.Copy(io.Discard, resp.Body)
+    resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        t.Fatalf("GET /api/users status = %d, want 200", resp.StatusCode)
+    }
+
+    flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer flushCancel()
+    if err := logger.Flush(flushCtx); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+
+    bodies := mock.received()
+    if len(bodies) < 2 {
+        t.Fatalf("logstash mock received %d POST requests, want at least 2", len(bodies))
+    }
+
+    messages := make(map[string]string)
+    for _, body := range bodies {
+        var entry map[string]interface{}
+        if err := json.Unmarshal(body, &entry); err != nil {
+            t.Fatalf("body is not valid JSON: %v\n%s", err, body)
+        }
+
+        ts, _ := entry["@timestamp"].(string)
+        if _, err := time.Parse(time.RFC3339Nano, ts); err != nil {
+            t.Errorf("@timestamp %q is not RFC 3339: %v", ts, err)
+        }
+        if entry["service"] != "go-api" {
+            t.Errorf("service = %v, want go-api", entry["service"])
+        }
+
+        message, _ := entry["message"].(string)
+        level, _ := entry["level"].(string)
+        messages[message] = level
+    }
+
+    for message, level := range map[string]string{
+        "Processing users request": "INFO",
+        "HTTP request":             "INFO",
+    } {
+        if got, ok := messages[message]; !ok {
+            t.Errorf("entry %q was not sent to logstash", message)
+        } else if got != level {
+            t.Errorf("entry %q level = %s, want %s", message, got, level)
+        }
+    }
+}
@@ -0,0 +1,177 @@
+package logging
+
+import (
+    "context"
+    "sync"
+    "time"
+)
+
+// Logger - методы записи, через которые пишут логи обработчики и
+// глобальные функции пакета. ELKLogger подставляется через AsLogger, в тестах
+// подставляются NoopLogger или BufferedLogger
+type Logger interface {
+    Debug(message string, fields map[string]interface{})
+    Info(message string, fields map[string]interface{})
+    Warn(message string, fields map[string]interface{})
+    Error(message string, fields map[string]interface{})
+    
+    // WithFields возвращает дочерний логгер с полями во всех записях
+    WithFields(fields map[string]interface{}) Logger
+    
+    // Close отправляет накопленные записи, не дольше ctx
+    Close(ctx context.Context) error
+}
+
+var (
+    _ Logger = elkLogger{}
+    _ Logger = NoopLogger{}
+    _ Logger = (*BufferedLogger)(nil)
+)
+
+var (
+    defaultMu     sync.RWMutex
+    defaultLogger Logger
+)
+
+// SetDefault подменяет логгер глобальных функций Info, Error и т.д.
+// (например, на BufferedLogger в тестах), nil возвращает ELKLogger из InitLogger
+func SetDefault(l Logger) {
+    defaultMu.Lock()
+    defer defaultMu.Unlock()
+    
+    defaultLogger = l
+}
+
+// Default возвращает логгер глобальных функций
+func Default() Logger {
+    defaultMu.RLock()
+    l := defaultLogger
+    defaultMu.RUnlock()
+    
+    if l != nil {
+        return l
+    }
+    return GetLogger().AsLogger()
+}
+
+// elkLogger - ELKLogger как Logger. ELKLogger.WithFields возвращает
+// *ELKLogger для цепочек вызовов, поэтому интерфейс реализует обертка
+type elkLogger struct {
+    *ELKLogger
+}
+
+// AsLogger возвращает логгер как Logger, например для handlers.Config
+func (l *ELKLogger) AsLogger() Logger {
+    return elkLogger{l}
+}
+
+func (l elkLogger) WithFields(fields map[string]interface{}) Logger {
+    return elkLogger{l.ELKLogger.WithFields(fields)}
+}
+
+// NoopLogger отбрасывает все записи
+type NoopLogger struct{}
+
+func (NoopLogger) Debug(message string, fields map[string]interface{}) {}
+func (NoopLogger) Info(message string, fields map[string]interface{})  {}
+func (NoopLogger) Warn(message string, fields map[string]interface{})  {}
+func (NoopLogger) Error(message string, fields map[string]interface{}) {}
+
+func (n NoopLogger) WithFields(fields map[string]interface{}) Logger {
+    return n
+}
+
+func (NoopLogger) Close(ctx context.Context) error {
+    return nil
+}
+
+// BufferedLogger сохраняет записи в памяти в порядке вызовов,
+// чтобы тесты могли проверить, что и в какой последовательности записано.
+// Дочерние логгеры из WithFields пишут в общий буфер родителя
+type BufferedLogger struct {
+    buffer *logBuffer
+    fields map[string]interface{}
+}
+
+type logBuffer struct {
+    mu      sync.Mutex
+    entries []LogEntry
+}
+
+// NewBufferedLogger создает пустой BufferedLogger
+func NewBufferedLogger() *BufferedLogger {
+    return &BufferedLogger{buffer: &logBuffer{}}
+}
+
+func (b *BufferedLogger) Debug(message string, fields map[string]interface{}) {
+    b.record("DEBUG", message, fields)
+}
+
+func (b *BufferedLogger) Info(message string, fields map[string]interface{}) {
+    b.record("INFO", message, fields)
+}
+
+func (b *BufferedLogger) Warn(message string, fields map[string]interface{}) {
+    b.record("WARN", message, fields)
+}
+
+func (b *BufferedLogger) Error(message string, fields map[string]interface{}) {
+    b.record("ERROR", message, fields)
+}
+
+func (b *BufferedLogger) WithFields(fields map[string]interface{}) Logger {
+    merged := make(map[string]interface{}, len(b.fields)+len(fields))
+    for k, v := range b.fields {
+        merged[k] = v
+    }
+    for k, v := range fields {
+        merged[k] = v
+    }
+    return &BufferedLogger{buffer: b.buffer, fields: merged}
+}
+
+func (b *BufferedLogger) Close(ctx context.Context) error {
+    return nil
+}
+
+// Entries возвращает копию записанных записей
+func (b *BufferedLogger) Entries() []LogEntry {
+    b.buffer.mu.Lock()
+    defer b.buffer.mu.Unlock()
+    
+    entries := make([]LogEntry, len(b.buffer.entries))
+    copy(entries, b.buffer.entries)
+    return entries
+}
+
+// Reset очищает буфер
+func (b *BufferedLogger) Reset() {
+    b.buffer.mu.Lock()
+    defer b.buffer.mu.Unlock()
+    
+    b.buffer.entries = nil
+}
+
+func (b *BufferedLogger) record(level, message string, fields map[string]interface{}) {
+    entryFields := make(map[string]interface{}, len(b.fields)+len(fields))
+    for k, v := range b.fields {
+        entryFields[k] = v
+    }
+    for k, v := range fields {
+        entryFields[k] = v
+    }
+    
+    now := time.Now()
+    entry := LogEntry{
+        Timestamp: formatTimestamp(now, timestampFormats[defaultLogstashPrecision]),
+        Level:     level,
+        Message:   message,
+        Fields:    entryFields,
+        createdAt: now,
+    }
+    
+    b.buffer.mu.Lock()
+    defer b.buffer.mu.Unlock()
+    
+    b.buffer.entries = append(b.buffer.entries, entry)
+}
\ No newline at end of file
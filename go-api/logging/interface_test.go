@@ -0,0 +1,104 @@
+package logging
+
+import (
+    "context"
+    "testing"
+)
+
+func TestGlobalFunctions_RouteThroughDefault(t *testing.T) {
+    buffered := NewBufferedLogger()
+    SetDefault(buffered)
+    defer SetDefault(nil)
+
+    Info("started", nil)
+    Warn("slow", map[string]interface{}{"ms": 900})
+    Debug("details", nil)
+    Error("failed", map[string]interface{}{"code": 1})
+
+    want := []struct{ level, message string }{
+        {"INFO", "started"},
+        {"WARN", "slow"},
+        {"DEBUG", "details"},
+        {"ERROR", "failed"},
+    }
+    entries := buffered.Entries()
+    if len(entries) != len(want) {
+        t.Fatalf("recorded %d entries, want %d", len(entries), len(want))
+    }
+    for i, w := range want {
+        if entries[i].Level != w.level || entries[i].Message != w.message {
+            t.Errorf("entry %d = %s %q, want %s %q", i, entries[i].Level, entries[i].Message, w.level, w.message)
+        }
+    }
+    if entries[3].Fields["code"] != 1 {
+        t.Errorf("error fields = %v, want code=1", entries[3].Fields)
+    }
+}
+
+func TestLogger_WithFields(t *testing.T) {
+    tests := []struct {
+        name   string
+        logger Logger
+    }{
+        {name: "buffered", logger: NewBufferedLogger()},
+        {name: "elk", logger: newQueueTestLogger().AsLogger()},
+        {name: "noop", logger: NoopLogger{}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            child := tt.logger.WithFields(map[string]interface{}{"component": "db"})
+            if child == nil {
+                t.Fatal("WithFields returned nil")
+            }
+            child.Info("query", map[string]interface{}{"rows": 3})
+
+            switch l := tt.logger.(type) {
+            case *BufferedLogger:
+                entries := l.Entries()
+                if len(entries) != 1 || entries[0].Fields["component"] != "db" || entries[0].Fields["rows"] != 3 {
+                    t.Errorf("entries = %+v, want one with component and rows", entries)
+                }
+            case elkLogger:
+                entry := l.lastQueued(t)
+                if entry.Fields["component"] != "db" || entry.Fields["rows"] != 3 {
+                    t.Errorf("fields = %v, want component and rows", entry.Fields)
+                }
+            }
+        })
+    }
+}
+
+func TestContextFields(t *testing.T) {
+    tests := []struct {
+        name string
+        ctx  context.Context
+        want map[string]interface{}
+    }{
+        {name: "empty", ctx: context.Background()},
+        {
+            name: "request id",
+            ctx:  WithRequestID(context.Background(), "req-1"),
+            want: map[string]interface{}{"request_id": "req-1"},
+        },
+        {
+            name: "request id and trace",
+            ctx:  withSpanContext(t, WithRequestID(context.Background(), "req-2"), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"),
+            want: map[string]interface{}{"request_id": "req-2", "trace_id": "4bf92f3577b34da6a3ce929d0e0e4736", "span_id": "00f067aa0ba902b7"},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := ContextFields(tt.ctx)
+            if len(got) != len(tt.want) {
+                t.Fatalf("ContextFields = %v, want %v", got, tt.want)
+            }
+            for k, v := range tt.want {
+                if got[k] != v {
+                    t.Errorf("%s = %v, want %v", k, got[k], v)
+                }
+            }
+        })
+    }
+}
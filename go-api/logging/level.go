@@ -0,0 +1,105 @@
+package logging
+
+import (
+    "fmt"
+    "strings"
+    "sync"
+)
+
+// Уровни логирования в порядке возрастания важности
+var levelOrder = map[string]int{
+    "DEBUG": 0,
+    "INFO":  1,
+    "WARN":  2,
+    "ERROR": 3,
+    "FATAL": 4,
+}
+
+// SetLevel меняет глобальный уровень без перезапуска.
+// Действует на логгер и все его дочерние логгеры
+func (l *ELKLogger) SetLevel(level string) error {
+    value, ok := levelOrder[strings.ToUpper(level)]
+    if !ok {
+        return fmt.Errorf("unknown log level %q", level)
+    }
+    l.level.Store(int32(value))
+    return nil
+}
+
+// Level возвращает текущий глобальный уровень
+func (l *ELKLogger) Level() string {
+    value := int(l.level.Load())
+    for name, v := range levelOrder {
+        if v == value {
+            return name
+        }
+    }
+    return ""
+}
+
+// Уровни отдельных компонентов, общие для логгера и его дочерних логгеров
+type componentLevels struct {
+    mu     sync.RWMutex
+    levels map[string]int
+}
+
+func (c *componentLevels) get(component string) (int, bool) {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+
+    level, ok := c.levels[component]
+    return level, ok
+}
+
+// WithComponentLevels задает уровни для компонентов, например {"db": "DEBUG"}
+func WithComponentLevels(levels map[string]string) Option {
+    return func(l *ELKLogger) {
+        for component, level := range levels {
+            l.SetComponentLevel(component, level)
+        }
+    }
+}
+
+// SetComponentLevel задает уровень для компонента, переопределяющий глобальный.
+// Компонент записи берется из поля "component", см. WithField
+func (l *ELKLogger) SetComponentLevel(component, level string) {
+    value, ok := levelOrder[strings.ToUpper(level)]
+    if !ok {
+        return
+    }
+
+    c := l.componentLevels
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    c.levels[component] = value
+}
+
+// enabled проверяет, проходит ли запись уровня level для компонента
+func (l *ELKLogger) enabled(level string, fields map[string]interface{}) bool {
+    value, ok := levelOrder[level]
+    if !ok {
+        return true
+    }
+
+    threshold := int(l.level.Load())
+    if component, ok := fields["component"].(string); ok {
+        if componentLevel, ok := l.componentLevels.get(component); ok {
+            threshold = componentLevel
+        }
+    }
+
+    return value >= threshold
+}
+
+// defaultLevel возвращает уровень level (LOG_LEVEL); без него DEBUG
+// включен только в development
+func defaultLevel(environment, level string) int {
+    if level, ok := levelOrder[strings.ToUpper(level)]; ok {
+        return level
+    }
+    if environment == "development" {
+        return levelOrder["DEBUG"]
+    }
+    return levelOrder["INFO"]
+}
@@ -0,0 +1,162 @@
+package logging
+
+import (
+    "reflect"
+    "sync"
+    "testing"
+)
+
+// queuedMessages забирает из очереди все записи логгера
+func (l *ELKLogger) queuedMessages() []string {
+    var messages []string
+    for {
+        select {
+        case entry := <-l.queue:
+            messages = append(messages, entry.Message)
+        default:
+            return messages
+        }
+    }
+}
+
+func TestComponentLevels(t *testing.T) {
+    tests := []struct {
+        name   string
+        fields map[string]interface{}
+        level  string
+        want   bool
+    }{
+        {name: "component at DEBUG emits debug", fields: map[string]interface{}{"component": "db"}, level: "DEBUG", want: true},
+        {name: "component without level uses global", fields: map[string]interface{}{"component": "handlers"}, level: "INFO", want: false},
+        {name: "entry without component uses global", level: "DEBUG", want: false},
+        {name: "global level still passes WARN", level: "WARN", want: true},
+        {name: "component raised to ERROR drops WARN", fields: map[string]interface{}{"component": "cache"}, level: "WARN", want: false},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l := newQueueTestLogger(WithComponentLevels(map[string]string{"db": "debug", "cache": "ERROR"}))
+            if err := l.SetLevel("WARN"); err != nil {
+                t.Fatalf("SetLevel: %v", err)
+            }
+            
+            l.Log(tt.level, "message", tt.fields)
+            
+            if got := len(l.queuedMessages()) == 1; got != tt.want {
+                t.Errorf("%s entry queued = %v, want %v", tt.level, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestComponentLevels_WithField(t *testing.T) {
+    l := newQueueTestLogger()
+    if err := l.SetLevel("WARN"); err != nil {
+        t.Fatalf("SetLevel: %v", err)
+    }
+    db := l.WithField("component", "db")
+    
+    db.Debug("query before override", nil)
+    l.SetComponentLevel("db", "DEBUG")
+    db.Debug("query after override", nil)
+    l.Debug("handler debug", nil)
+    
+    // Уровни компонентов общие для логгера и дочерних логгеров
+    if got := l.queuedMessages(); len(got) != 1 || got[0] != "query after override" {
+        t.Errorf("queued %v, want only the db debug entry after the override", got)
+    }
+}
+
+func TestSetLevel_AppliesToAllMethods(t *testing.T) {
+    tests := []struct {
+        level string
+        want  []string
+    }{
+        {level: "DEBUG", want: []string{"debug", "info", "warn", "error"}},
+        {level: "info", want: []string{"info", "warn", "error"}},
+        {level: "WARN", want: []string{"warn", "error"}},
+        {level: "ERROR", want: []string{"error"}},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.level, func(t *testing.T) {
+            l := newQueueTestLogger(WithDisableCaller())
+            if err := l.SetLevel(tt.level); err != nil {
+                t.Fatalf("SetLevel: %v", err)
+            }
+            
+            // Дочерний логгер видит уровень родителя
+            child := l.WithField("component", "handlers")
+            child.Debug("debug", nil)
+            child.Info("info", nil)
+            child.Warn("warn", nil)
+            child.Error("error", nil)
+            
+            if got := l.queuedMessages(); !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("queued %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestSetLevel_UnknownLevel(t *testing.T) {
+    l := newQueueTestLogger()
+    l.SetLevel("WARN")
+    
+    if err := l.SetLevel("verbose"); err == nil {
+        t.Error("SetLevel(verbose) succeeded, want an error")
+    }
+    if got := l.Level(); got != "WARN" {
+        t.Errorf("Level = %s after a rejected change, want WARN", got)
+    }
+}
+
+func TestSetLevel_ConcurrentWithLogging(t *testing.T) {
+    l := newQueueTestLogger(WithDisableCaller(), WithComponentLevels(map[string]string{"db": "DEBUG"}))
+    
+    // Очередь разбирается параллельно, чтобы записи не отбрасывались
+    stop := make(chan struct{})
+    drained := make(chan struct{})
+    go func() {
+        defer close(drained)
+        for {
+            select {
+            case <-l.queue:
+            case <-stop:
+                return
+            }
+        }
+    }()
+    
+    var wg sync.WaitGroup
+    for i := 0; i < 8; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            db := l.WithField("component", "db")
+            for j := 0; j < 200; j++ {
+                l.Debug("debug", nil)
+                l.Info("info", nil)
+                db.Warn("warn", nil)
+            }
+        }()
+    }
+    for i := 0; i < 2; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for j := 0; j < 200; j++ {
+                l.SetLevel([]string{"DEBUG", "INFO", "WARN", "ERROR"}[j%4])
+                l.SetComponentLevel("db", "ERROR")
+                _ = l.Level()
+            }
+        }()
+    }
+    wg.Wait()
+    close(stop)
+    <-drained
+    
+    if got := l.Level(); got == "" {
+        t.Error("Level is empty after concurrent changes")
+    }
+}
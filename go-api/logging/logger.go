@@ -2,13 +2,18 @@ package logging
 
 import (
     "bytes"
-    "encoding/json"
+    "context"
     "fmt"
+    "io"
+    "math/rand"
     "net/http"
     "os"
     "runtime"
     "sync"
+    "sync/atomic"
     "time"
+
+    "github.com/crazy1997/go-api/config"
 )
 
 // ELKLogger отправляет логи напрямую в Logstash
@@ -19,7 +24,114 @@ type ELKLogger struct {
     environment string
     hostname    string
     serverIP    string
-    mu          sync.Mutex
+    fieldPrefix string
+    masker      *masker
+    sampler     Sampler
+    level       *atomic.Int32
+    fields      map[string]interface{}
+    tags        []string
+    // Основной приемник (Logstash), консоль и дополнительные приемники
+    output      Sink
+    console     Sink
+    sinks       []Sink
+    sinkFanOut  *FanOutSink
+    hooks       *hookRegistry
+    
+    componentLevels   *componentLevels
+    inflight          *sync.WaitGroup
+    disableKeepAlives bool
+    maxConnLifetime   time.Duration
+    poolStats         *poolStats
+    
+    // Авторизация в прокси перед Logstash и mutual TLS
+    authHeader     string
+    authToken      string
+    clientCertFile string
+    clientKeyFile  string
+    timestampFormat   string
+    
+    // Очередь и пул отправки в Logstash
+    ctx         context.Context
+    queue       chan LogEntry
+    dispatcher  *dispatcher
+    workerCount int
+    queueSize   int
+    blockOnFull bool
+    maxRetries  int
+    
+    // Лимит размера записи в байтах JSON, 0 - без лимита
+    maxEntryBytes int
+    
+    // Глубина стека для поля caller, см. WithCallerDepth
+    callerDepth   int
+    disableCaller bool
+    
+    // Транспорт доставки: http (по умолчанию), tcp или udp
+    transport  string
+    streamAddr string
+    stream     *streamWriter
+    fallback   *fallbackWriter
+    
+    // Вывод в консоль: формат и ANSI цвета (только для терминала)
+    consoleFormat string
+    consoleColor  bool
+    
+    // Ключ HMAC подписи записей, nil отключает подпись
+    signingKey []byte
+    
+    // Формат записей в Logstash: прежний или Elastic Common Schema
+    ecsMode        bool
+    serviceVersion string
+    
+    // Отправка пачками, включается при batchSize > 1
+    batchSize     int
+    batchInterval time.Duration
+    batch         *batcher
+    
+    // Размыкает отправку по HTTP при недоступности Logstash
+    breaker *circuitBreaker
+}
+
+// Option настраивает ELKLogger при инициализации
+type Option func(*ELKLogger)
+
+// WithFieldPrefix задает префикс для всех ключей Fields (например "dev."),
+// чтобы поля разных окружений не конфликтовали в одном Elasticsearch
+func WithFieldPrefix(prefix string) Option {
+    return func(l *ELKLogger) {
+        l.fieldPrefix = prefix
+    }
+}
+
+// Глубина стека вызова без внешних оберток
+const defaultCallerDepth = 3
+
+// WithCallerDepth задает глубину стека для поля caller и стека ошибки.
+// Кадры пакета logging пропускаются всегда, каждое значение сверх 3
+// пропускает еще один кадр внешней обертки над логгером
+// (например 4 для собственной функции-обертки сервиса)
+func WithCallerDepth(n int) Option {
+    return func(l *ELKLogger) {
+        if n > 0 {
+            l.callerDepth = n
+        }
+    }
+}
+
+// WithDisableCaller отключает поле caller, экономя вызов runtime.Caller
+func WithDisableCaller() Option {
+    return func(l *ELKLogger) {
+        l.disableCaller = true
+    }
+}
+
+// WithMaxRetries задает число повторов отправки в Logstash при сбоях
+func WithMaxRetries(n int) Option {
+    return func(l *ELKLogger) {
+        if n >= 0 {
+            l.maxRetries = n
+        }
+    }
 }
 
 var (
@@ -33,49 +145,124 @@ type LogEntry struct {
     Service     string                 `json:"service"`
     Message     string                 `json:"message"`
     Fields      map[string]interface{} `json:"fields,omitempty"`
+    Tags        []string               `json:"tags,omitempty"`
     Environment string                 `json:"environment"`
     Host        string                 `json:"host"`
     ServerIP    string                 `json:"server_ip"`
     GoVersion   string                 `json:"go_version"`
+    Error       *ErrorEntry            `json:"error,omitempty"`
+    Signature   string                 `json:"_sig,omitempty"`
+    
+    // Время создания записи для переформатирования @timestamp в приемниках
+    createdAt time.Time
 }
 
-func InitLogger() *ELKLogger {
+// InitLogger создает логгер при первом вызове. Адрес Logstash, окружение,
+// данные сервиса и тонкая настройка логгера (поля cfg.Log*) берутся из cfg,
+// opts применяются поверх них.
+// Пул отправки работает до отмены ctx, повторные вызовы возвращают тот же логгер
+func InitLogger(ctx context.Context, cfg *config.Config, opts ...Option) *ELKLogger {
     once.Do(func() {
         hostname, _ := os.Hostname()
         
-        // Получаем внешний IP сервера
-        serverIP := os.Getenv("SERVER_IP")
-        if serverIP == "" {
-            serverIP = "147.45.183.143" // Ваш IP сервера
-        }
-        
-        // Logstash URL - используем localhost внутри контейнера
-        logstashURL := "http://logstash:5000"
-        
         loggerInstance = &ELKLogger{
-            logstashURL: logstashURL,
-            httpClient: &http.Client{
-                Timeout: 5 * time.Second,
-                Transport: &http.Transport{
-                    MaxIdleConns:        100,
-                    MaxIdleConnsPerHost: 100,
-                    IdleConnTimeout:     90 * time.Second,
-                },
-            },
+            logstashURL: cfg.LogstashURL,
             serviceName: "go-api",
-            environment: os.Getenv("ENVIRONMENT"),
+            environment: cfg.Environment,
             hostname:    hostname,
-            serverIP:    serverIP,
+            serverIP:    cfg.ServerIP,
+            hooks:       &hookRegistry{},
+            
+            componentLevels: &componentLevels{levels: make(map[string]int)},
+            inflight:        &sync.WaitGroup{},
+            
+            timestampFormat: timestampFormats[defaultLogstashPrecision],
+            
+            workerCount: defaultWorkerCount,
+            queueSize:   defaultQueueSize,
+            callerDepth: defaultCallerDepth,
+            
+            transport:  TransportHTTP,
+            streamAddr: "logstash:5001",
+            
+            consoleFormat:  ConsoleText,
+            consoleColor:   isTerminal(os.Stdout),
+            serviceVersion: cfg.ServiceVersion,
         }
         
         if loggerInstance.environment == "" {
             loggerInstance.environment = "production"
         }
         
+        loggerInstance.level = &atomic.Int32{}
+        loggerInstance.level.Store(int32(defaultLevel(loggerInstance.environment, cfg.LogLevel)))
+        
+        for _, opt := range configOptions(cfg) {
+            opt(loggerInstance)
+        }
+        
+        if len(cfg.LogMaskFields) > 0 {
+            loggerInstance.masker = newMasker(cfg.LogMaskFields)
+        }
+        
+        // Архив записей с регулируемыми данными
+        if path := cfg.LogComplianceFile; path != "" {
+            sink, err := NewFileSink(path)
+            if err != nil {
+                fmt.Fprintf(os.Stderr, "Failed to open compliance log: %v\n", err)
+            } else {
+                loggerInstance.sinks = append(loggerInstance.sinks, TagFilter(sink, TagPII, TagPHI, TagFinancial))
+            }
+        }
+        
+        for _, opt := range opts {
+            opt(loggerInstance)
+        }
+        
+        tlsConfig, err := loggerInstance.clientTLSConfig()
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Logstash mutual TLS disabled: %v\n", err)
+        }
+        
+        loggerInstance.poolStats = &poolStats{}
+        loggerInstance.httpClient = newHTTPClient(loggerInstance.disableKeepAlives, loggerInstance.maxConnLifetime, tlsConfig, loggerInstance.poolStats)
+        if loggerInstance.authToken != "" {
+            loggerInstance.httpClient.Transport = newAuthTransport(loggerInstance.httpClient.Transport, loggerInstance.authHeader, loggerInstance.authToken)
+        }
+        if loggerInstance.transport == TransportTCP || loggerInstance.transport == TransportUDP {
+            loggerInstance.stream = newStreamWriter(loggerInstance.transport, loggerInstance.streamAddr)
+        }
+        
+        registerMetrics()
+        
+        loggerInstance.output = &logstashOutput{l: loggerInstance}
+        loggerInstance.console = &ConsoleSink{out: os.Stdout, format: loggerInstance.consoleFormat, color: loggerInstance.consoleColor}
+        if len(loggerInstance.sinks) > 0 {
+            loggerInstance.sinkFanOut = NewFanOutSink(defaultSinkTimeout, loggerInstance.queueSize, loggerInstance.sinks...)
+        }
+        
+        if cfg.LogFallbackFile != "" {
+            loggerInstance.fallback = newFallbackWriter(cfg.LogFallbackFile, int64(cfg.LogFallbackMaxMB)*1024*1024)
+        }
+        if loggerInstance.batchSize > 1 && loggerInstance.stream == nil {
+            if loggerInstance.batchInterval <= 0 {
+                loggerInstance.batchInterval = defaultBatchInterval
+            }
+            loggerInstance.batch = newBatcher(loggerInstance.batchSize, loggerInstance.batchInterval, loggerInstance.sendBatch)
+            go loggerInstance.batch.run(ctx)
+        }
+        loggerInstance.startWorkers(ctx)
+        
+        probeInterval := cfg.LogProbeInterval
+        if probeInterval <= 0 {
+            probeInterval = defaultProbeInterval
+        }
+        go loggerInstance.logstashProber(probeInterval)
+        
         // Тестовое сообщение при инициализации
         loggerInstance.Log("INFO", "Logger initialized on production server", map[string]interface{}{
-            "server_ip":     serverIP,
-            "logstash_url":  logstashURL,
+            "server_ip":     cfg.ServerIP,
+            "logstash_url":  cfg.LogstashURL,
             "environment":   loggerInstance.environment,
             "hostname":      hostname,
         })
@@ -84,6 +271,61 @@ func InitLogger() *ELKLogger {
     return loggerInstance
 }
 
+// configOptions переводит поля cfg.Log* в опции логгера
+func configOptions(cfg *config.Config) []Option {
+    opts := []Option{
+        WithFieldPrefix(cfg.LogFieldPrefix),
+        WithDisableKeepAlives(cfg.LogDisableKeepAlives),
+        WithMaxIdleConnLifetime(cfg.LogMaxConnLifetime),
+        WithWorkers(cfg.LogWorkerCount),
+        WithQueueSize(cfg.LogQueueSize),
+        WithBlockOnFull(cfg.LogQueueBlock),
+        WithMaxRetries(cfg.LogMaxRetries),
+        WithBatching(cfg.LogBatchSize, cfg.LogBatchInterval),
+        WithCircuitBreaker(cfg.LogCircuitFailures, cfg.LogCircuitCooldown),
+        WithSigningKey(cfg.LogSigningKey),
+        WithTimestampPrecision(cfg.LogTimestampPrecision),
+        WithMaxEntryBytes(cfg.LogMaxEntryBytes),
+        WithConsoleFormat(cfg.LogConsoleFormat),
+        WithECSMode(cfg.LogECSMode),
+        WithComponentLevels(cfg.LogComponentLevels),
+    }
+    
+    if cfg.LogTransport != "" {
+        opts = append(opts, WithTransport(cfg.LogTransport, cfg.LogstashAddr))
+    }
+    if cfg.LogAuthToken != "" {
+        opts = append(opts, WithAuth(cfg.LogAuthHeader, cfg.LogAuthToken))
+    }
+    if cfg.LogClientCertFile != "" && cfg.LogClientKeyFile != "" {
+        opts = append(opts, WithClientCert(cfg.LogClientCertFile, cfg.LogClientKeyFile))
+    }
+    if len(cfg.LogSampleRates) > 0 {
+        opts = append(opts, WithSampler(NewRateSampler(cfg.LogSampleRates)))
+    }
+    return opts
+}
+
+
+// Flush ждет завершения отправки логов или отмены ctx
+func (l *ELKLogger) Flush(ctx context.Context) error {
+    done := make(chan struct{})
+    go func() {
+        l.inflight.Wait()
+        // Неполная пачка отправляется синхронно, чтобы не потерять записи
+        if l.batch != nil {
+            l.batch.flush()
+        }
+        close(done)
+    }()
+    
+    select {
+    case <-done:
+        return nil
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
 
 func GetLogger() *ELKLogger {
     if loggerInstance == nil {
@@ -93,88 +335,252 @@ func GetLogger() *ELKLogger {
 }
 
 func (l *ELKLogger) Log(level, message string, fields map[string]interface{}) {
-    go l.sendLogAsync(level, message, fields)
+    fields = l.mergeFields(fields)
+    if l.accept(level, message, fields) {
+        l.enqueue(l.createLogEntry(level, message, fields))
+    }
+}
+
+// accept проверяет уровень, выводит запись в консоль и применяет выборку.
+// Возвращает true, если запись нужно отправить в Logstash
+func (l *ELKLogger) accept(level, message string, fields map[string]interface{}) bool {
+    if !l.enabled(level, fields) {
+        return false
+    }
     
     // Также выводим в консоль для отладки
     l.logToConsole(level, message, fields)
+    
+    // Выборка снижает объем отправки в ELK, консоль получает все записи
+    if l.sampler != nil && !l.sampler.ShouldLog(level, message) {
+        sampledOut.WithLabelValues(level).Inc()
+        return false
+    }
+    return true
 }
 
-func (l *ELKLogger) sendLogAsync(level, message string, fields map[string]interface{}) {
-    entry := l.createLogEntry(level, message, fields)
+// sendLogAsync выполняется в горутине пула и отправляет запись в Logstash
+func (l *ELKLogger) sendLogAsync(entry LogEntry) {
+    l.applyEnrichmentHooks(&entry)
     
-    jsonData, err := json.Marshal(entry)
-    if err != nil {
-        fmt.Fprintf(os.Stderr, "Failed to marshal log: %v\n", err)
+    if err := l.fireHooks(BeforeSend, &entry); err != nil {
         return
     }
     
-    req, err := http.NewRequest("POST", l.logstashURL, bytes.NewBuffer(jsonData))
-    if err != nil {
-        fmt.Fprintf(os.Stderr, "Failed to create log request: %v\n", err)
-        return
+    // Усечение до подписи, иначе подпись не совпадет с отправленной записью
+    l.enforceEntrySize(&entry)
+    
+    if l.signingKey != nil {
+        if err := l.signEntry(&entry); err != nil {
+            fmt.Fprintf(os.Stderr, "Failed to sign log: %v\n", err)
+        }
     }
     
-    req.Header.Set("Content-Type", "application/json")
+    for _, tag := range entry.Tags {
+        taggedEntries.WithLabelValues(tag).Inc()
+    }
     
-    resp, err := l.httpClient.Do(req)
+    // Дополнительные приемники (например, архив для compliance) пишут
+    // из своих очередей и не задерживают отправку в Logstash
+    if l.sinkFanOut != nil {
+        if err := l.sinkFanOut.Write(entry); err != nil {
+            fmt.Fprintf(os.Stderr, "Failed to write log to sink: %v\n", err)
+        }
+    }
+    
+    if err := l.output.Write(entry); err != nil {
+        fmt.Fprintf(os.Stderr, "Failed to send log to ELK over %s: %v\n", l.transport, err)
+    }
+}
+
+// logstashOutput - основной приемник логгера: Logstash выбранным транспортом,
+// пачками при LOG_BATCH_SIZE > 1 и с локальным резервом при недоступности.
+// Вызывается воркерами пула, очередь логгера служит его буфером
+type logstashOutput struct {
+    l *ELKLogger
+}
+
+func (o *logstashOutput) Name() string {
+    return "logstash"
+}
+
+func (o *logstashOutput) Write(entry LogEntry) error {
+    l := o.l
+    
+    jsonData, err := l.marshalEntry(entry)
     if err != nil {
-        // В случае ошибки пишем в stderr
-        fmt.Fprintf(os.Stderr, "Failed to send log to ELK: %v\n", err)
+        return fmt.Errorf("marshal log: %w", err)
+    }
+    
+    if l.batch != nil {
+        l.batch.add(entry, jsonData)
+        return nil
+    }
+    
+    if err := l.send(jsonData); err != nil {
+        sendFailures.Inc()
+        l.saveFallback(jsonData)
+        l.fireHooks(OnError, &entry)
+        return err
+    }
+    l.fireHooks(AfterSend, &entry)
+    return nil
+}
+
+// saveFallback сохраняет запись локально до восстановления Logstash
+func (l *ELKLogger) saveFallback(data []byte) {
+    if l.fallback == nil {
         return
     }
-    defer resp.Body.Close()
+    if err := l.fallback.write(data); err != nil {
+        fmt.Fprintf(os.Stderr, "Failed to write log fallback: %v\n", err)
+    }
+}
+
+// send доставляет сериализованную запись выбранным транспортом
+func (l *ELKLogger) send(data []byte) error {
+    if l.stream != nil {
+        // UDP - доставка без гарантий, TCP - с переподключением
+        return l.stream.write(data)
+    }
+    return l.postLogstash(data)
+}
+
+// Параметры экспоненциальной задержки между повторами
+const (
+    retryBaseDelay = 100 * time.Millisecond
+    retryMaxDelay  = 2 * time.Second
+)
+
+// retryablePost отправляет body в Logstash, повторяя попытку при сетевых
+// ошибках и ответах 5xx/429 не более maxRetries раз. Задержка - full jitter:
+// случайное значение от 0 до min(retryMaxDelay, retryBaseDelay*2^attempt)
+func retryablePost(client *http.Client, url string, body []byte, maxRetries int) error {
+    var lastErr error
     
-    if resp.StatusCode >= 400 {
-        fmt.Fprintf(os.Stderr, "Logstash returned error: %d\n", resp.StatusCode)
+    for attempt := 0; attempt <= maxRetries; attempt++ {
+        if attempt > 0 {
+            time.Sleep(backoffDelay(attempt - 1))
+        }
+        
+        req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+        if err != nil {
+            return fmt.Errorf("create log request: %w", err)
+        }
+        req.Header.Set("Content-Type", "application/json")
+        
+        resp, err := client.Do(req)
+        if err != nil {
+            lastErr = err
+            continue
+        }
+        
+        // Дочитываем тело, чтобы соединение вернулось в пул
+        io.Copy(io.Discard, resp.Body)
+        resp.Body.Close()
+        
+        switch {
+        case resp.StatusCode < 400:
+            return nil
+        case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+            lastErr = fmt.Errorf("logstash returned error: %d", resp.StatusCode)
+        default:
+            return fmt.Errorf("logstash returned error: %d", resp.StatusCode)
+        }
+    }
+    
+    return fmt.Errorf("after %d retries: %w", maxRetries, lastErr)
+}
+
+func backoffDelay(attempt int) time.Duration {
+    delay := retryMaxDelay
+    if attempt < 16 {
+        delay = min(retryBaseDelay<<attempt, retryMaxDelay)
     }
+    return time.Duration(rand.Int63n(int64(delay) + 1))
 }
 
 func (l *ELKLogger) createLogEntry(level, message string, fields map[string]interface{}) LogEntry {
-    if fields == nil {
-        fields = make(map[string]interface{})
+    // Копируем поля, чтобы не менять карту вызывающего кода,
+    // и маскируем PII до сериализации
+    entryFields := make(map[string]interface{}, len(fields)+1)
+    
+    // Значение error выносится в структурированное поле записи
+    var errEntry *ErrorEntry
+    var frames []runtime.Frame
+    if err, ok := fields["error"].(error); ok {
+        frames = l.callerFrames(maxErrorStackFrames)
+        errEntry = newErrorEntry(err, frames)
+    } else if !l.disableCaller {
+        frames = l.callerFrames(1)
+    }
+    
+    for k, v := range fields {
+        if errEntry != nil && k == "error" {
+            continue
+        }
+        if l.masker != nil && l.masker.match(k) {
+            v = maskedValue
+        }
+        entryFields[l.fieldPrefix+k] = v
     }
     
     // Добавляем информацию о вызове
-    _, file, line, ok := runtime.Caller(3)
-    if ok {
-        fields["caller"] = fmt.Sprintf("%s:%d", file, line)
+    if !l.disableCaller {
+        if len(frames) > 0 {
+            entryFields[l.fieldPrefix+"caller"] = fmt.Sprintf("%s:%d", frames[0].File, frames[0].Line)
+        }
     }
     
+    now := time.Now()
+    
     return LogEntry{
-        Timestamp:   time.Now().UTC().Format(time.RFC3339Nano),
+        Timestamp:   formatTimestamp(now, l.timestampFormat),
         Level:       level,
         Service:     l.serviceName,
         Message:     message,
-        Fields:      fields,
+        Fields:      entryFields,
+        Tags:        l.tags,
         Environment: l.environment,
         Host:        l.hostname,
+        ServerIP:    l.serverIP,
         GoVersion:   runtime.Version(),
+        Error:       errEntry,
+        createdAt:   now,
     }
 }
 
-func (l *ELKLogger) logToConsole(level, message string, fields map[string]interface{}) {
-    color := "\033[0m"
-    switch level {
-    case "ERROR":
-        color = "\033[31m" // Красный
-    case "WARN":
-        color = "\033[33m" // Желтый
-    case "INFO":
-        color = "\033[32m" // Зеленый
-    case "DEBUG":
-        color = "\033[36m" // Голубой
+// Запись проверки доступности, Logstash отбрасывает ее в фильтре
+var pingEntry = []byte(`{"level":"PING","message":"ping"}`)
+
+// Ping отправляет в Logstash запись проверки и ждет ответа 200 не дольше 2 с.
+// Для TCP проверяется соединение, для UDP проверка невозможна.
+// Используется при старте и в readiness-проверке
+func (l *ELKLogger) Ping() error {
+    if l.transport != TransportHTTP {
+        return l.probeLogstash(probeTimeout)
     }
     
-    timestamp := time.Now().Format("15:04:05.000")
-    fmt.Printf("%s[%s] %-5s %s\033[0m", color, timestamp, level, message)
+    ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+    defer cancel()
     
-    if len(fields) > 0 {
-        fmt.Print(" | ")
-        for k, v := range fields {
-            fmt.Printf("%s=%v ", k, v)
-        }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.logstashURL, bytes.NewReader(pingEntry))
+    if err != nil {
+        return fmt.Errorf("logstash ping: %w", err)
     }
-    fmt.Println()
+    req.Header.Set("Content-Type", "application/json")
+    
+    resp, err := l.httpClient.Do(req)
+    if err != nil {
+        return fmt.Errorf("logstash ping %s: %w", l.logstashURL, err)
+    }
+    io.Copy(io.Discard, resp.Body)
+    resp.Body.Close()
+    
+    if resp.StatusCode != http.StatusOK {
+        return fmt.Errorf("logstash ping %s: unexpected status %d", l.logstashURL, resp.StatusCode)
+    }
+    return nil
 }
 
 // Удобные методы
@@ -182,8 +588,10 @@ func (l *ELKLogger) Info(message string, fields map[string]interface{}) {
     l.Log("INFO", message, fields)
 }
 
+// Error пишет запись уровня ERROR, значение error из fields
+// попадает в структурированное поле error со стеком, как в LogError
 func (l *ELKLogger) Error(message string, fields map[string]interface{}) {
-    l.Log("ERROR", message, fields)
+    l.logError("ERROR", message, nil, fields)
 }
 
 func (l *ELKLogger) Warn(message string, fields map[string]interface{}) {
@@ -191,24 +599,59 @@ func (l *ELKLogger) Warn(message string, fields map[string]interface{}) {
 }
 
 func (l *ELKLogger) Debug(message string, fields map[string]interface{}) {
-    if l.environment == "development" {
-        l.Log("DEBUG", message, fields)
-    }
+    l.Log("DEBUG", message, fields)
+}
+
+// Fatal пишет запись, дожидается отправки логов и завершает процесс
+func (l *ELKLogger) Fatal(message string, fields map[string]interface{}) {
+    l.Log("FATAL", message, fields)
+    l.exit()
+}
+
+// exit отправляет накопленные записи и завершает процесс
+func (l *ELKLogger) exit() {
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    l.Flush(ctx)
+    
+    os.Exit(1)
 }
 
-// Глобальные функции для удобства
+// Глобальные функции для удобства, пишут через Default()
 func Info(message string, fields map[string]interface{}) {
-    GetLogger().Info(message, fields)
+    Default().Info(message, fields)
 }
 
 func Error(message string, fields map[string]interface{}) {
-    GetLogger().Error(message, fields)
+    Default().Error(message, fields)
 }
 
 func Warn(message string, fields map[string]interface{}) {
-    GetLogger().Warn(message, fields)
+    Default().Warn(message, fields)
 }
 
 func Debug(message string, fields map[string]interface{}) {
-    GetLogger().Debug(message, fields)
-}
\ No newline at end of file
+    Default().Debug(message, fields)
+}
+
+// fatalLogger - логгер с собственным уровнем FATAL, например ELKLogger
+type fatalLogger interface {
+    Fatal(message string, fields map[string]interface{})
+}
+
+// Fatal пишет запись, дожидается отправки логов и завершает процесс.
+// Логгер без уровня FATAL пишет запись как ERROR
+func Fatal(message string, fields map[string]interface{}) {
+    logger := Default()
+    if l, ok := logger.(fatalLogger); ok {
+        l.Fatal(message, fields)
+        return
+    }
+    
+    logger.Error(message, fields)
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    logger.Close(ctx)
+    
+    os.Exit(1)
+}
@@ -0,0 +1,106 @@
+package logging
+
+import (
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/crazy1997/go-api/config"
+)
+
+func TestConfigOptions(t *testing.T) {
+    l := newQueueTestLogger(configOptions(&config.Config{
+        LogFieldPrefix:        "dev.",
+        LogWorkerCount:        2,
+        LogQueueSize:          10,
+        LogMaxRetries:         1,
+        LogBatchSize:          20,
+        LogBatchInterval:      100 * time.Millisecond,
+        LogCircuitFailures:    3,
+        LogTimestampPrecision: "ns",
+        LogConsoleFormat:      ConsoleText,
+        LogECSMode:            true,
+        LogTransport:          TransportTCP,
+        LogstashAddr:          "logstash:6000",
+        LogAuthToken:          "secret",
+        LogComponentLevels:    map[string]string{"db": "ERROR"},
+        LogSampleRates:        map[string]float64{"DEBUG": 0},
+    })...)
+
+    if l.fieldPrefix != "dev." || l.workerCount != 2 || l.queueSize != 10 || l.maxRetries != 1 {
+        t.Errorf("prefix = %q, workers = %d, queue = %d, retries = %d", l.fieldPrefix, l.workerCount, l.queueSize, l.maxRetries)
+    }
+    if l.batchSize != 20 || l.batchInterval != 100*time.Millisecond {
+        t.Errorf("batch = %d %v, want 20 100ms", l.batchSize, l.batchInterval)
+    }
+    if l.breaker == nil {
+        t.Error("circuit breaker is disabled, want 3 failures")
+    }
+    if l.timestampFormat != timestampFormats["ns"] || l.consoleFormat != ConsoleText || !l.ecsMode {
+        t.Errorf("timestamp = %q, console = %q, ecs = %v", l.timestampFormat, l.consoleFormat, l.ecsMode)
+    }
+    if l.transport != TransportTCP || l.streamAddr != "logstash:6000" || l.authToken != "secret" {
+        t.Errorf("transport = %q %q, token = %q", l.transport, l.streamAddr, l.authToken)
+    }
+    if level, ok := l.componentLevels.get("db"); !ok || level != levelOrder["ERROR"] {
+        t.Errorf("db level = %d %v, want ERROR", level, ok)
+    }
+    if l.sampler == nil || l.sampler.ShouldLog("DEBUG", "x") {
+        t.Error("DEBUG entries pass the sampler, want them dropped")
+    }
+}
+
+func TestPing(t *testing.T) {
+    tests := []struct {
+        name    string
+        status  int
+        closed  bool
+        wantErr string
+    }{
+        {name: "ok", status: http.StatusOK},
+        {name: "server error", status: http.StatusInternalServerError, wantErr: "unexpected status 500"},
+        {name: "unavailable", status: http.StatusServiceUnavailable, wantErr: "unexpected status 503"},
+        {name: "connection refused", closed: true, wantErr: "logstash ping"},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var gotBody, gotType string
+            srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                body, _ := io.ReadAll(r.Body)
+                gotBody, gotType = string(body), r.Header.Get("Content-Type")
+                w.WriteHeader(tt.status)
+            }))
+            defer srv.Close()
+            if tt.closed {
+                srv.Close()
+            }
+            
+            l := newQueueTestLogger()
+            l.transport, l.httpClient, l.logstashURL = TransportHTTP, srv.Client(), srv.URL
+            
+            err := l.Ping()
+            if tt.wantErr == "" {
+                if err != nil {
+                    t.Fatalf("Ping() = %v, want nil", err)
+                }
+            } else if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), srv.URL) {
+                t.Fatalf("Ping() = %v, want error with %q and the Logstash URL", err, tt.wantErr)
+            }
+            
+            if tt.closed {
+                return
+            }
+            if gotBody != `{"level":"PING","message":"ping"}` || gotType != "application/json" {
+                t.Errorf("request = %s %q, want the ping entry as JSON", gotType, gotBody)
+            }
+            // Проверка не проходит через очередь отправки
+            if len(l.queue) != 0 {
+                t.Errorf("%d entries queued, want 0", len(l.queue))
+            }
+        })
+    }
+}
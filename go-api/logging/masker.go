@@ -0,0 +1,59 @@
+package logging
+
+import (
+    "regexp"
+    "strings"
+)
+
+// Значение, которым заменяются замаскированные поля
+const maskedValue = "***"
+
+// masker определяет, какие ключи Fields содержат PII
+type masker struct {
+    exact    map[string]struct{}
+    patterns []*regexp.Regexp
+}
+
+// MaskFields маскирует значения полей, ключи которых совпадают с glob
+// шаблонами (например "email", "*_token"). Карта вызывающего кода не меняется
+func MaskFields(patterns []string) Option {
+    return func(l *ELKLogger) {
+        l.masker = newMasker(patterns)
+    }
+}
+
+func newMasker(patterns []string) *masker {
+    m := &masker{exact: make(map[string]struct{})}
+
+    for _, pattern := range patterns {
+        pattern = strings.TrimSpace(pattern)
+        if pattern == "" {
+            continue
+        }
+
+        // Шаблоны без метасимволов проверяются поиском в карте
+        if !strings.ContainsAny(pattern, "*?") {
+            m.exact[pattern] = struct{}{}
+            continue
+        }
+
+        expr := regexp.QuoteMeta(pattern)
+        expr = strings.ReplaceAll(expr, `\*`, ".*")
+        expr = strings.ReplaceAll(expr, `\?`, ".")
+        m.patterns = append(m.patterns, regexp.MustCompile("^"+expr+"$"))
+    }
+
+    return m
+}
+
+func (m *masker) match(key string) bool {
+    if _, ok := m.exact[key]; ok {
+        return true
+    }
+    for _, re := range m.patterns {
+        if re.MatchString(key) {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestMasker_Match(t *testing.T) {
+    m := newMasker([]string{"email", " *_token ", "card_?", ""})
+    
+    tests := []struct {
+        key  string
+        want bool
+    }{
+        {key: "email", want: true},
+        {key: "user_email", want: false},
+        {key: "access_token", want: true},
+        {key: "_token", want: true},
+        {key: "token", want: false},
+        {key: "card_1", want: true},
+        {key: "card_12", want: false},
+        {key: "user_id", want: false},
+    }
+    
+    for _, tt := range tests {
+        if got := m.match(tt.key); got != tt.want {
+            t.Errorf("match(%q) = %v, want %v", tt.key, got, tt.want)
+        }
+    }
+}
+
+func TestMaskFields_DoesNotMutateCaller(t *testing.T) {
+    l := newQueueTestLogger(MaskFields([]string{"email", "*_token"}), WithDisableCaller())
+    
+    fields := map[string]interface{}{
+        "email":         "user@example.com",
+        "refresh_token": "secret",
+        "user_id":       42,
+    }
+    original := map[string]interface{}{"email": "user@example.com", "refresh_token": "secret", "user_id": 42}
+    
+    l.Info("user logged in", fields)
+    entry := l.lastQueued(t)
+    
+    want := map[string]interface{}{"email": maskedValue, "refresh_token": maskedValue, "user_id": 42}
+    if !reflect.DeepEqual(entry.Fields, want) {
+        t.Errorf("entry fields = %v, want %v", entry.Fields, want)
+    }
+    if !reflect.DeepEqual(fields, original) {
+        t.Errorf("caller fields changed to %v", fields)
+    }
+}
+
+// Накладные расходы маскирования: копия карты из пяти маскируемых
+// полей с проверкой ключей, как в createLogEntry. Цель - меньше 1 мкс
+func BenchmarkMasker_FiveKeys(b *testing.B) {
+    m := newMasker([]string{"email", "phone", "*_token", "password", "card_*"})
+    fields := map[string]interface{}{
+        "email":        "user@example.com",
+        "phone":        "+70000000000",
+        "access_token": "secret",
+        "password":     "hunter2",
+        "card_number":  "4111111111111111",
+    }
+    
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        masked := make(map[string]interface{}, len(fields))
+        for k, v := range fields {
+            if m.match(k) {
+                v = maskedValue
+            }
+            masked[k] = v
+        }
+    }
+}
@@ -0,0 +1,87 @@
+package logging
+
+import (
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+// Метрики самого логгера
+var (
+    taggedEntries = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Name: "log_tagged_entries_total",
+            Help: "Total number of log entries with compliance tags",
+        },
+        []string{"tag"},
+    )
+    
+    sendFailures = prometheus.NewCounter(
+        prometheus.CounterOpts{
+            Name: "logstash_send_failures_total",
+            Help: "Total number of log entries not delivered to Logstash after retries",
+        },
+    )
+    
+    sinkErrors = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Name: "log_sink_errors_total",
+            Help: "Total number of failed writes to additional log sinks",
+        },
+        []string{"sink"},
+    )
+    
+    sinkDropped = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Name: "log_sink_dropped_total",
+            Help: "Total number of log entries dropped because a sink queue was full or a write timed out",
+        },
+        []string{"sink"},
+    )
+    
+    sampledOut = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Name: "logs_sampled_out_total",
+            Help: "Total number of log entries skipped by sampling",
+        },
+        []string{"level"},
+    )
+    
+    circuitOpenDrops = prometheus.NewCounter(
+        prometheus.CounterOpts{
+            Name: "logstash_circuit_open_drops_total",
+            Help: "Total number of Logstash sends skipped while the circuit breaker was open",
+        },
+    )
+    
+    circuitState = prometheus.NewGauge(
+        prometheus.GaugeOpts{
+            Name: "logstash_circuit_state",
+            Help: "Logstash circuit breaker state: 0 closed, 1 open, 2 half-open",
+        },
+    )
+    
+    truncatedEntries = prometheus.NewCounter(
+        prometheus.CounterOpts{
+            Name: "log_entries_truncated_total",
+            Help: "Total number of log entries with fields truncated to the size limit",
+        },
+    )
+    
+    droppedLogs = prometheus.NewCounter(
+        prometheus.CounterOpts{
+            Name: "dropped_logs_total",
+            Help: "Total number of log entries dropped because the send queue was full",
+        },
+    )
+)
+
+func registerMetrics() {
+    prometheus.MustRegister(taggedEntries)
+    prometheus.MustRegister(droppedLogs)
+    prometheus.MustRegister(sendFailures)
+    prometheus.MustRegister(sampledOut)
+    prometheus.MustRegister(sinkErrors)
+    prometheus.MustRegister(sinkDropped)
+    prometheus.MustRegister(circuitOpenDrops)
+    prometheus.MustRegister(circuitState)
+    prometheus.MustRegister(truncatedEntries)
+}
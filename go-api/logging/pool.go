@@ -0,0 +1,96 @@
+package logging
+
+import (
+    "io"
+    "net/http"
+    "net/http/httptrace"
+    "sync/atomic"
+)
+
+// Лимит соединений с одним Logstash. Больше воркеров, чем соединений,
+// означает ожидание свободного соединения, а не новые подключения
+const maxConnsPerHost = 10
+
+// poolStats - счетчики пула соединений с Logstash. http.Transport
+// не отдает свое состояние, поэтому оно собирается при подключении
+// и на каждом запросе
+type poolStats struct {
+    dials    atomic.Int64
+    open     atomic.Int64
+    active   atomic.Int64
+    requests atomic.Int64
+    reused   atomic.Int64
+}
+
+// ConnectionPoolStats возвращает состояние пула соединений с Logstash:
+// dials - всего подключений, open - открыто сейчас, active - занято
+// запросами, idle - свободно, requests - всего запросов, reused - из них
+// на переиспользованном соединении
+func (l *ELKLogger) ConnectionPoolStats() map[string]int {
+    s := l.poolStats
+    if s == nil {
+        return map[string]int{}
+    }
+    
+    open, active := int(s.open.Load()), int(s.active.Load())
+    idle := open - active
+    if idle < 0 {
+        idle = 0
+    }
+    
+    return map[string]int{
+        "dials":    int(s.dials.Load()),
+        "open":     open,
+        "active":   active,
+        "idle":     idle,
+        "requests": int(s.requests.Load()),
+        "reused":   int(s.reused.Load()),
+    }
+}
+
+// statsTransport считает запросы и переиспользование соединений.
+// Запрос занимает соединение до закрытия тела ответа
+type statsTransport struct {
+    base  http.RoundTripper
+    stats *poolStats
+}
+
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+    t.stats.requests.Add(1)
+    trace := &httptrace.ClientTrace{
+        GotConn: func(info httptrace.GotConnInfo) {
+            t.stats.active.Add(1)
+            if info.Reused {
+                t.stats.reused.Add(1)
+            }
+        },
+    }
+    
+    resp, err := t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+    if err != nil {
+        t.stats.active.Add(-1)
+        return nil, err
+    }
+    resp.Body = &activeBody{ReadCloser: resp.Body, stats: t.stats}
+    return resp, nil
+}
+
+func (t *statsTransport) CloseIdleConnections() {
+    if c, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+        c.CloseIdleConnections()
+    }
+}
+
+// activeBody освобождает соединение в счетчиках при закрытии тела
+type activeBody struct {
+    io.ReadCloser
+    stats  *poolStats
+    closed atomic.Bool
+}
+
+func (b *activeBody) Close() error {
+    if b.closed.CompareAndSwap(false, true) {
+        b.stats.active.Add(-1)
+    }
+    return b.ReadCloser.Close()
+}
\ No newline at end of file
@@ -0,0 +1,125 @@
+package logging
+
+import (
+    "net/http"
+    "sync"
+    "testing"
+)
+
+// newPoolTestLogger отправляет в srv через клиент со счетчиками пула
+func newPoolTestLogger(url string) *ELKLogger {
+    l := newQueueTestLogger()
+    l.poolStats = &poolStats{}
+    l.httpClient = newHTTPClient(false, 0, nil, l.poolStats)
+    l.logstashURL = url
+    return l
+}
+
+func TestConnectionPool_Reuse(t *testing.T) {
+    sends := 10000
+    if testing.Short() {
+        sends = 1000
+    }
+    
+    tests := []struct {
+        name    string
+        workers int
+    }{
+        {name: "sequential", workers: 1},
+        {name: "4 workers", workers: 4},
+        {name: "pool limit", workers: maxConnsPerHost},
+        {name: "more workers than connections", workers: 3 * maxConnsPerHost},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            srv, conns := newConnCountingServer(t, func(w http.ResponseWriter, r *http.Request) {})
+            l := newPoolTestLogger(srv.URL)
+            t.Cleanup(l.httpClient.CloseIdleConnections)
+            
+            perWorker := sends / tt.workers
+            var wg sync.WaitGroup
+            for w := 0; w < tt.workers; w++ {
+                wg.Add(1)
+                go func() {
+                    defer wg.Done()
+                    for i := 0; i < perWorker; i++ {
+                        if err := l.postLogstash([]byte(`{"message":"pool"}`)); err != nil {
+                            t.Errorf("postLogstash: %v", err)
+                            return
+                        }
+                    }
+                }()
+            }
+            wg.Wait()
+            
+            total := perWorker * tt.workers
+            maxConns := int64(min(tt.workers, maxConnsPerHost))
+            if got := conns.Load(); got < 1 || got > maxConns {
+                t.Errorf("server saw %d connections for %d sends, want 1..%d", got, total, maxConns)
+            }
+            
+            stats := l.ConnectionPoolStats()
+            if stats["dials"] != int(conns.Load()) {
+                t.Errorf("dials = %d, server saw %d connections", stats["dials"], conns.Load())
+            }
+            // Соединение, набранное для ждущего запроса, может уйти в пул
+            // без запроса, если раньше освободилось другое
+            if stats["requests"] != total || stats["reused"] < total-stats["dials"] || stats["reused"] >= total {
+                t.Errorf("requests = %d, reused = %d, want %d requests and all but at most one per connection reused", stats["requests"], stats["reused"], total)
+            }
+            if stats["active"] != 0 || stats["idle"] != stats["open"] || stats["open"] != stats["dials"] {
+                t.Errorf("stats = %v, want every connection open and idle after the sends", stats)
+            }
+        })
+    }
+}
+
+func TestConnectionPoolStats(t *testing.T) {
+    if got := (&ELKLogger{}).ConnectionPoolStats(); len(got) != 0 {
+        t.Errorf("stats without a pool = %v, want empty", got)
+    }
+    
+    // Занятое соединение не считается свободным до закрытия тела ответа
+    srv, _ := newConnCountingServer(t, func(w http.ResponseWriter, r *http.Request) {})
+    l := newPoolTestLogger(srv.URL)
+    resp, err := l.httpClient.Get(srv.URL)
+    if err != nil {
+        t.Fatalf("GET: %v", err)
+    }
+    if stats := l.ConnectionPoolStats(); stats["active"] != 1 || stats["idle"] != 0 {
+        t.Errorf("stats with an open response = %v, want 1 active and 0 idle", stats)
+    }
+    resp.Body.Close()
+    resp.Body.Close()
+    if stats := l.ConnectionPoolStats(); stats["active"] != 0 || stats["idle"] != 1 {
+        t.Errorf("stats after closing the body twice = %v, want 0 active and 1 idle", stats)
+    }
+    
+    l.httpClient.CloseIdleConnections()
+    if stats := l.ConnectionPoolStats(); stats["open"] != 0 {
+        t.Errorf("stats after closing idle connections = %v, want 0 open", stats)
+    }
+}
+
+// BenchmarkPostLogstash_Sequential отправляет записи одним воркером
+// и сообщает число TCP соединений на все отправки
+func BenchmarkPostLogstash_Sequential(b *testing.B) {
+    srv, conns := newConnCountingServer(b, func(w http.ResponseWriter, r *http.Request) {})
+    l := newPoolTestLogger(srv.URL)
+    defer l.httpClient.CloseIdleConnections()
+    data := []byte(`{"message":"bench"}`)
+    
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        if err := l.postLogstash(data); err != nil {
+            b.Fatal(err)
+        }
+    }
+    b.StopTimer()
+    
+    b.ReportMetric(float64(conns.Load()), "conns")
+    if got := conns.Load(); got > 1 {
+        b.Errorf("%d sends used %d connections, want 1", b.N, got)
+    }
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+    "net"
+    "net/url"
+    "time"
+
+    "github.com/crazy1997/go-api/bus"
+)
+
+// Имя компонента Logstash в шине здоровья
+const LogstashComponent = "logstash"
+
+// Таймаут проверки и интервал проверок без LOG_PROBE_INTERVAL
+const (
+    probeTimeout         = 2 * time.Second
+    defaultProbeInterval = 15 * time.Second
+)
+
+// logstashProber периодически проверяет доступность Logstash
+// и публикует изменения состояния в шину здоровья
+func (l *ELKLogger) logstashProber(interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    lastStatus := ""
+    for {
+        event := bus.HealthEvent{Component: LogstashComponent, Status: bus.StatusUp}
+        if err := l.probeLogstash(probeTimeout); err != nil {
+            event.Status = bus.StatusDown
+            event.Error = err.Error()
+        } else {
+            // Logstash доступен - дослать записи, накопленные при сбое
+            l.replayFallback()
+        }
+
+        if event.Status != lastStatus {
+            bus.Publish(event)
+            lastStatus = event.Status
+        }
+
+        <-ticker.C
+    }
+}
+
+// probeLogstash проверяет доступность порта Logstash, не создавая записей в индексе
+func (l *ELKLogger) probeLogstash(timeout time.Duration) error {
+    switch l.transport {
+    case TransportTCP:
+        return dialProbe(l.streamAddr, timeout)
+    case TransportUDP:
+        // Для UDP доступность не проверить без ответа сервера
+        return nil
+    }
+
+    u, err := url.Parse(l.logstashURL)
+    if err != nil {
+        return err
+    }
+
+    host := u.Host
+    if u.Port() == "" {
+        host = net.JoinHostPort(u.Hostname(), "80")
+    }
+
+    return dialProbe(host, timeout)
+}
+
+func dialProbe(addr string, timeout time.Duration) error {
+    conn, err := net.DialTimeout("tcp", addr, timeout)
+    if err != nil {
+        return err
+    }
+    return conn.Close()
+}
+
@@ -0,0 +1,34 @@
+package logging
+
+// Значения по умолчанию для очереди отправки
+const (
+    defaultWorkerCount = 4
+    defaultQueueSize   = 1000
+)
+
+// WithWorkers задает число горутин, отправляющих логи в Logstash
+func WithWorkers(n int) Option {
+    return func(l *ELKLogger) {
+        if n > 0 {
+            l.workerCount = n
+        }
+    }
+}
+
+// WithQueueSize задает емкость очереди записей
+func WithQueueSize(n int) Option {
+    return func(l *ELKLogger) {
+        if n > 0 {
+            l.queueSize = n
+        }
+    }
+}
+
+// WithBlockOnFull выбирает поведение при заполненной очереди:
+// true - вызывающий ждет освобождения места, false - запись отбрасывается
+func WithBlockOnFull(block bool) Option {
+    return func(l *ELKLogger) {
+        l.blockOnFull = block
+    }
+}
+
@@ -0,0 +1,101 @@
+package logging
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync/atomic"
+    "testing"
+    
+    promtest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// newFlakyLogstash отвечает status на первые failures запросов, затем 200
+func newFlakyLogstash(t *testing.T, failures int64, status int) (*httptest.Server, *atomic.Int64) {
+    var calls atomic.Int64
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if calls.Add(1) <= failures {
+            w.WriteHeader(status)
+        }
+    }))
+    t.Cleanup(srv.Close)
+    return srv, &calls
+}
+
+func TestRetryablePost(t *testing.T) {
+    tests := []struct {
+        name       string
+        failures   int64
+        status     int
+        maxRetries int
+        wantCalls  int64
+        wantErr    string
+    }{
+        {name: "succeeds first time", failures: 0, status: http.StatusInternalServerError, maxRetries: 3, wantCalls: 1},
+        {name: "recovers after 5xx", failures: 2, status: http.StatusServiceUnavailable, maxRetries: 3, wantCalls: 3},
+        {name: "retries 429", failures: 1, status: http.StatusTooManyRequests, maxRetries: 3, wantCalls: 2},
+        {name: "retries exhausted", failures: 10, status: http.StatusBadGateway, maxRetries: 2, wantCalls: 3, wantErr: "after 2 retries: logstash returned error: 502"},
+        {name: "client error is not retried", failures: 10, status: http.StatusBadRequest, maxRetries: 3, wantCalls: 1, wantErr: "logstash returned error: 400"},
+        {name: "no retries", failures: 1, status: http.StatusInternalServerError, maxRetries: 0, wantCalls: 1, wantErr: "after 0 retries"},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            srv, calls := newFlakyLogstash(t, tt.failures, tt.status)
+            
+            err := retryablePost(srv.Client(), srv.URL, []byte(`{"message":"retry"}`), tt.maxRetries)
+            
+            if tt.wantErr == "" && err != nil {
+                t.Errorf("retryablePost: %v", err)
+            }
+            if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+                t.Errorf("error = %v, want %q", err, tt.wantErr)
+            }
+            if got := calls.Load(); got != tt.wantCalls {
+                t.Errorf("logstash received %d requests, want %d", got, tt.wantCalls)
+            }
+        })
+    }
+}
+
+func TestBackoffDelay_FullJitterBounds(t *testing.T) {
+    for attempt := 0; attempt < 40; attempt++ {
+        limit := retryMaxDelay
+        if attempt < 5 {
+            limit = retryBaseDelay << attempt
+        }
+        for i := 0; i < 100; i++ {
+            if d := backoffDelay(attempt); d < 0 || d > limit {
+                t.Fatalf("backoffDelay(%d) = %s, want within [0, %s]", attempt, d, limit)
+            }
+        }
+    }
+}
+
+func TestLogstashOutput_FailedSendGoesToFallback(t *testing.T) {
+    srv, _ := newFlakyLogstash(t, 10, http.StatusInternalServerError)
+    path := filepath.Join(t.TempDir(), "fallback.log")
+    
+    l := newQueueTestLogger(WithMaxRetries(0))
+    l.httpClient = srv.Client()
+    l.logstashURL = srv.URL
+    l.fallback = newFallbackWriter(path, 1024*1024)
+    failuresBefore := promtest.ToFloat64(sendFailures)
+    
+    if err := (&logstashOutput{l: l}).Write(LogEntry{Message: "undelivered"}); err == nil {
+        t.Fatal("Write succeeded while Logstash returns 500")
+    }
+    
+    if got := promtest.ToFloat64(sendFailures) - failuresBefore; got != 1 {
+        t.Errorf("logstash_send_failures_total increased by %v, want 1", got)
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatalf("read fallback: %v", err)
+    }
+    if !strings.Contains(string(data), `"message":"undelivered"`) {
+        t.Errorf("fallback = %q, want the undelivered entry", data)
+    }
+}
@@ -0,0 +1,49 @@
+package logging
+
+import (
+    "math/rand"
+)
+
+// Sampler решает, отправлять ли запись в Logstash.
+// Отброшенные записи все равно выводятся в консоль
+type Sampler interface {
+    ShouldLog(level, message string) bool
+}
+
+// WithSampler включает выборочную отправку записей
+func WithSampler(s Sampler) Option {
+    return func(l *ELKLogger) {
+        l.sampler = s
+    }
+}
+
+// RateSampler отправляет долю записей каждого уровня (0.0–1.0).
+// Уровни без заданной доли отправляются полностью.
+//
+// При выборке точное число событий в Kibana нужно восстанавливать делением
+// на долю уровня: count(INFO) / 0.1 при LOG_SAMPLE_INFO=0.1
+type RateSampler struct {
+    rates map[string]float64
+}
+
+// NewRateSampler создает сэмплер с долями по уровням, например {"INFO": 0.1}.
+// Карта копируется, поэтому сэмплер безопасен для конкурентного использования
+func NewRateSampler(rates map[string]float64) *RateSampler {
+    copied := make(map[string]float64, len(rates))
+    for level, rate := range rates {
+        copied[level] = rate
+    }
+    return &RateSampler{rates: copied}
+}
+
+func (s *RateSampler) ShouldLog(level, message string) bool {
+    rate, ok := s.rates[level]
+    if !ok || rate >= 1 {
+        return true
+    }
+    if rate <= 0 {
+        return false
+    }
+    // Функции пакета math/rand безопасны для конкурентного вызова
+    return rand.Float64() < rate
+}
@@ -0,0 +1,81 @@
+package logging
+
+import (
+    "bytes"
+    "strings"
+    "sync"
+    "testing"
+    
+    promtest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRateSampler_Rates(t *testing.T) {
+    const samples = 20000
+    
+    tests := []struct {
+        name     string
+        level    string
+        min, max int
+    }{
+        {name: "rate 0 drops all", level: "DEBUG", min: 0, max: 0},
+        {name: "rate 1 keeps all", level: "ERROR", min: samples, max: samples},
+        {name: "level without rate keeps all", level: "WARN", min: samples, max: samples},
+        {name: "rate 0.1 keeps about a tenth", level: "INFO", min: samples * 8 / 100, max: samples * 12 / 100},
+    }
+    
+    rates := map[string]float64{"DEBUG": 0, "INFO": 0.1, "ERROR": 1}
+    s := NewRateSampler(rates)
+    
+    // Сэмплер работает с копией карты
+    rates["INFO"] = 0
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            kept := 0
+            for i := 0; i < samples; i++ {
+                if s.ShouldLog(tt.level, "message") {
+                    kept++
+                }
+            }
+            if kept < tt.min || kept > tt.max {
+                t.Errorf("kept %d of %d %s entries, want %d..%d", kept, samples, tt.level, tt.min, tt.max)
+            }
+        })
+    }
+}
+
+func TestRateSampler_Concurrent(t *testing.T) {
+    s := NewRateSampler(map[string]float64{"INFO": 0.5})
+    
+    var wg sync.WaitGroup
+    for i := 0; i < 8; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for j := 0; j < 1000; j++ {
+                s.ShouldLog("INFO", "concurrent")
+            }
+        }()
+    }
+    wg.Wait()
+}
+
+func TestSampler_ConsoleKeepsSampledOutEntries(t *testing.T) {
+    var console bytes.Buffer
+    l := newQueueTestLogger(WithSampler(NewRateSampler(map[string]float64{"INFO": 0})), WithDisableCaller())
+    l.console = &ConsoleSink{out: &console, format: ConsoleJSON}
+    sampledBefore := promtest.ToFloat64(sampledOut.WithLabelValues("INFO"))
+    
+    l.Info("products listed", nil)
+    l.Warn("slow response", nil)
+    
+    if got := l.queuedMessages(); len(got) != 1 || got[0] != "slow response" {
+        t.Errorf("queued %v, want only the WARN entry", got)
+    }
+    if out := console.String(); !strings.Contains(out, "products listed") || !strings.Contains(out, "slow response") {
+        t.Errorf("console = %q, want both entries", out)
+    }
+    if got := promtest.ToFloat64(sampledOut.WithLabelValues("INFO")) - sampledBefore; got != 1 {
+        t.Errorf("logs_sampled_out_total{level=INFO} increased by %v, want 1", got)
+    }
+}
@@ -0,0 +1,98 @@
+package logging
+
+import (
+    "bytes"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+)
+
+// WithSigningKey включает подпись записей HMAC-SHA256 в поле _sig
+func WithSigningKey(key string) Option {
+    return func(l *ELKLogger) {
+        if key != "" {
+            l.signingKey = []byte(key)
+        }
+    }
+}
+
+// signEntry подписывает запись. Подпись ставится после хуков обогащения
+// и считается над документом, который marshalEntry отправит в Logstash
+// (в том числе в формате ECS), поэтому ее можно проверить по сохраненной записи
+func (l *ELKLogger) signEntry(entry *LogEntry) error {
+    entry.Signature = ""
+    
+    data, err := l.marshalEntry(*entry)
+    if err != nil {
+        return err
+    }
+    sig, err := documentSignature(data, l.signingKey)
+    if err != nil {
+        return err
+    }
+    entry.Signature = sig
+    return nil
+}
+
+// VerifyEntry проверяет подпись _sig записи ключом key.
+// Изменение любого поля после подписи делает подпись недействительной.
+// Для записей в формате ECS используйте VerifyDocument
+func VerifyEntry(entry LogEntry, key string) bool {
+    data, err := json.Marshal(entry)
+    if err != nil {
+        return false
+    }
+    return VerifyDocument(data, key)
+}
+
+// VerifyDocument проверяет подпись _sig JSON документа записи в том виде,
+// в котором он сохранен в Elasticsearch или получен из Logstash.
+// Порядок ключей документа на проверку не влияет
+func VerifyDocument(doc []byte, key string) bool {
+    var fields map[string]interface{}
+    if err := decodeJSON(doc, &fields); err != nil {
+        return false
+    }
+    
+    sig, _ := fields["_sig"].(string)
+    if sig == "" {
+        return false
+    }
+    delete(fields, "_sig")
+    
+    data, err := json.Marshal(fields)
+    if err != nil {
+        return false
+    }
+    expected, err := documentSignature(data, []byte(key))
+    if err != nil {
+        return false
+    }
+    return hmac.Equal([]byte(expected), []byte(sig))
+}
+
+// documentSignature считает HMAC над каноническим JSON документа без _sig:
+// ключи объектов на всех уровнях отсортированы, числа сохраняются как в исходном JSON
+func documentSignature(doc []byte, key []byte) (string, error) {
+    var value interface{}
+    if err := decodeJSON(doc, &value); err != nil {
+        return "", err
+    }
+    
+    canonical, err := json.Marshal(value)
+    if err != nil {
+        return "", err
+    }
+    
+    mac := hmac.New(sha256.New, key)
+    mac.Write(canonical)
+    return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// decodeJSON разбирает JSON, сохраняя числа в виде json.Number
+func decodeJSON(data []byte, v interface{}) error {
+    decoder := json.NewDecoder(bytes.NewReader(data))
+    decoder.UseNumber()
+    return decoder.Decode(v)
+}
@@ -0,0 +1,139 @@
+package logging
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "testing"
+    "time"
+)
+
+const testSigningKey = "compliance-secret"
+
+func newSignedEntry() LogEntry {
+    return LogEntry{
+        Timestamp:   "2026-10-16T10:00:00.123Z",
+        Level:       "INFO",
+        Service:     "go-api",
+        Message:     "Order created",
+        Fields:      map[string]interface{}{"order_id": 1234567890123, "amount": 19.99, "user": map[string]interface{}{"id": 7}},
+        Tags:        []string{TagFinancial},
+        Environment: "production",
+        Host:        "api-1",
+        ServerIP:    "10.0.0.1",
+        GoVersion:   "go1.25.1",
+        Error:       newErrorEntry(errors.New("card declined"), nil),
+        createdAt:   time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC),
+    }
+}
+
+// sentDocument подписывает запись и возвращает документ, уходящий в Logstash
+func sentDocument(t *testing.T, l *ELKLogger, entry LogEntry) []byte {
+    t.Helper()
+    if err := l.signEntry(&entry); err != nil {
+        t.Fatalf("signEntry: %v", err)
+    }
+    data, err := l.marshalEntry(entry)
+    if err != nil {
+        t.Fatalf("marshalEntry: %v", err)
+    }
+    return data
+}
+
+func TestSigning_SentDocumentVerifies(t *testing.T) {
+    for _, ecsMode := range []bool{false, true} {
+        name := "legacy"
+        if ecsMode {
+            name = "ecs"
+        }
+        
+        t.Run(name, func(t *testing.T) {
+            l := &ELKLogger{signingKey: []byte(testSigningKey), ecsMode: ecsMode, serviceVersion: "1.2.3"}
+            doc := sentDocument(t, l, newSignedEntry())
+            
+            if !VerifyDocument(doc, testSigningKey) {
+                t.Fatalf("sent document does not verify: %s", doc)
+            }
+            if VerifyDocument(doc, "other-key") {
+                t.Error("document verifies with a wrong key")
+            }
+            
+            // Elasticsearch и Logstash не сохраняют порядок ключей
+            var stored map[string]interface{}
+            if err := decodeJSON(doc, &stored); err != nil {
+                t.Fatalf("decode: %v", err)
+            }
+            reordered, _ := json.Marshal(stored)
+            if !VerifyDocument(reordered, testSigningKey) {
+                t.Error("stored document with reordered keys does not verify")
+            }
+        })
+    }
+}
+
+func TestSigning_TamperedFieldFailsVerification(t *testing.T) {
+    l := &ELKLogger{signingKey: []byte(testSigningKey)}
+    doc := sentDocument(t, l, newSignedEntry())
+    
+    tests := []struct {
+        name   string
+        tamper func(doc map[string]interface{})
+    }{
+        {"message", func(doc map[string]interface{}) { doc["message"] = "Order cancelled" }},
+        {"level", func(doc map[string]interface{}) { doc["level"] = "DEBUG" }},
+        {"timestamp", func(doc map[string]interface{}) { doc["@timestamp"] = "2026-10-16T11:00:00.123Z" }},
+        {"nested field", func(doc map[string]interface{}) {
+            doc["fields"].(map[string]interface{})["amount"] = json.Number("0.01")
+        }},
+        {"removed tag", func(doc map[string]interface{}) { delete(doc, "tags") }},
+        {"added field", func(doc map[string]interface{}) { doc["admin"] = true }},
+        {"error type", func(doc map[string]interface{}) { delete(doc["error"].(map[string]interface{}), "type") }},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var stored map[string]interface{}
+            if err := decodeJSON(doc, &stored); err != nil {
+                t.Fatalf("decode: %v", err)
+            }
+            tt.tamper(stored)
+            tampered, _ := json.Marshal(stored)
+            
+            if VerifyDocument(tampered, testSigningKey) {
+                t.Errorf("tampered document still verifies: %s", tampered)
+            }
+        })
+    }
+}
+
+func TestVerifyEntry_StoredLegacyEntry(t *testing.T) {
+    l := &ELKLogger{signingKey: []byte(testSigningKey)}
+    doc := sentDocument(t, l, newSignedEntry())
+    
+    var stored LogEntry
+    if err := json.Unmarshal(doc, &stored); err != nil {
+        t.Fatalf("unmarshal: %v", err)
+    }
+    if !VerifyEntry(stored, testSigningKey) {
+        t.Fatal("entry decoded from the sent document does not verify")
+    }
+    
+    stored.Message = "changed"
+    if VerifyEntry(stored, testSigningKey) {
+        t.Error("modified entry still verifies")
+    }
+}
+
+func TestSigning_NoKeyNoSignature(t *testing.T) {
+    l := &ELKLogger{}
+    data, err := l.marshalEntry(newSignedEntry())
+    if err != nil {
+        t.Fatalf("marshalEntry: %v", err)
+    }
+    if bytes.Contains(data, []byte(`"_sig"`)) {
+        t.Errorf("entry without signing key has _sig: %s", data)
+    }
+    if VerifyDocument(data, testSigningKey) {
+        t.Error("unsigned document verifies")
+    }
+}
@@ -0,0 +1,306 @@
+package logging
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "sync"
+    "time"
+)
+
+// Sink - дополнительный приемник записей лога
+type Sink interface {
+    Write(entry LogEntry) error
+}
+
+// WithSink добавляет приемник, получающий все записи помимо Logstash
+func WithSink(s Sink) Option {
+    return func(l *ELKLogger) {
+        l.sinks = append(l.sinks, s)
+    }
+}
+
+// FileSink пишет записи в файл в формате JSON lines
+type FileSink struct {
+    mu              sync.Mutex
+    file            *os.File
+    timestampFormat string
+}
+
+// NewFileSink открывает файл на дозапись
+func NewFileSink(path string) (*FileSink, error) {
+    file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+    if err != nil {
+        return nil, fmt.Errorf("open log file %s: %w", path, err)
+    }
+    return &FileSink{file: file, timestampFormat: timestampFormats[defaultFilePrecision]}, nil
+}
+
+// SetTimestampPrecision задает точность @timestamp в файле: "ns", "ms" или "s"
+func (s *FileSink) SetTimestampPrecision(precision string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if format, ok := timestampFormats[precision]; ok {
+        s.timestampFormat = format
+    }
+}
+
+func (s *FileSink) Write(entry LogEntry) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if !entry.createdAt.IsZero() {
+        entry.Timestamp = formatTimestamp(entry.createdAt, s.timestampFormat)
+    }
+
+    data, err := json.Marshal(entry)
+    if err != nil {
+        return err
+    }
+    data = append(data, '\n')
+
+    _, err = s.file.Write(data)
+    return err
+}
+
+func (s *FileSink) Close() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    return s.file.Close()
+}
+
+// TagFilter пропускает в sink только записи с одним из указанных тегов
+func TagFilter(sink Sink, tags ...string) Sink {
+    allowed := make(map[string]struct{}, len(tags))
+    for _, tag := range tags {
+        allowed[tag] = struct{}{}
+    }
+    return &tagFilterSink{sink: sink, tags: allowed}
+}
+
+type tagFilterSink struct {
+    sink Sink
+    tags map[string]struct{}
+}
+
+func (f *tagFilterSink) Write(entry LogEntry) error {
+    for _, tag := range entry.Tags {
+        if _, ok := f.tags[tag]; ok {
+            return f.sink.Write(entry)
+        }
+    }
+    return nil
+}
+
+
+// Параметры FanOutSink по умолчанию: предельное время записи
+// и емкость очереди каждого приемника
+const (
+    defaultSinkTimeout   = 2 * time.Second
+    defaultSinkQueueSize = 1000
+)
+
+// FanOutSink передает запись всем приемникам асинхронно. У каждого приемника
+// своя ограниченная очередь и горутина записи, поэтому медленный или зависший
+// приемник не задерживает вызывающий код и остальные приемники. При
+// переполненной очереди или записи дольше timeout запись для этого
+// приемника отбрасывается и учитывается в log_sink_dropped_total.
+// Ошибки учитываются в log_sink_errors_total по имени приемника
+type FanOutSink struct {
+    outputs []*sinkQueue
+    timeout time.Duration
+    
+    mu     sync.RWMutex
+    closed bool
+    wg     sync.WaitGroup
+}
+
+// sinkQueue - очередь и имя одного приемника FanOutSink
+type sinkQueue struct {
+    sink  Sink
+    name  string
+    queue chan LogEntry
+}
+
+// NewFanOutSink создает FanOutSink и запускает горутины приемников,
+// остановить их можно через Close. Имя приемника для метрик берется
+// из метода Name() string, если он есть, иначе из типа
+func NewFanOutSink(timeout time.Duration, queueSize int, sinks ...Sink) *FanOutSink {
+    if timeout <= 0 {
+        timeout = defaultSinkTimeout
+    }
+    if queueSize <= 0 {
+        queueSize = defaultSinkQueueSize
+    }
+    
+    f := &FanOutSink{timeout: timeout}
+    for _, sink := range sinks {
+        q := &sinkQueue{sink: sink, name: sinkName(sink), queue: make(chan LogEntry, queueSize)}
+        f.outputs = append(f.outputs, q)
+        
+        f.wg.Add(1)
+        go f.run(q)
+    }
+    return f
+}
+
+// Write ставит запись в очередь каждого приемника и не ждет записи.
+// Возвращает ошибку с именами приемников, для которых запись отброшена
+func (f *FanOutSink) Write(entry LogEntry) error {
+    f.mu.RLock()
+    defer f.mu.RUnlock()
+    
+    if f.closed {
+        return errors.New("fan-out sink is closed")
+    }
+    
+    var errs []error
+    for _, q := range f.outputs {
+        select {
+        case q.queue <- entry:
+        default:
+            sinkDropped.WithLabelValues(q.name).Inc()
+            errs = append(errs, fmt.Errorf("sink %s: queue is full, entry dropped", q.name))
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// run записывает очередь одного приемника до закрытия FanOutSink.
+// Запись, не уложившаяся в timeout, считается отброшенной. Пока она
+// висит, следующие записи приемника отбрасываются сразу
+func (f *FanOutSink) run(q *sinkQueue) {
+    defer f.wg.Done()
+    
+    var pending chan error
+    for entry := range q.queue {
+        if pending != nil {
+            select {
+            case <-pending:
+                pending = nil
+            default:
+                sinkDropped.WithLabelValues(q.name).Inc()
+                continue
+            }
+        }
+        
+        done := make(chan error, 1)
+        go func() {
+            done <- q.sink.Write(entry)
+        }()
+        
+        timer := time.NewTimer(f.timeout)
+        select {
+        case err := <-done:
+            timer.Stop()
+            if err != nil {
+                sinkErrors.WithLabelValues(q.name).Inc()
+                fmt.Fprintf(os.Stderr, "Failed to write log to sink %s: %v\n", q.name, err)
+            }
+        case <-timer.C:
+            pending = done
+            sinkDropped.WithLabelValues(q.name).Inc()
+            fmt.Fprintf(os.Stderr, "Log sink %s write timed out after %s, entry dropped\n", q.name, f.timeout)
+        }
+    }
+}
+
+// Close прекращает прием записей и ждет, пока приемники запишут очереди,
+// не дольше ctx. Записи, не успевшие до отмены ctx, отбрасываются
+// горутинами приемников в фоне
+func (f *FanOutSink) Close(ctx context.Context) error {
+    f.mu.Lock()
+    if f.closed {
+        f.mu.Unlock()
+        return nil
+    }
+    f.closed = true
+    for _, q := range f.outputs {
+        close(q.queue)
+    }
+    f.mu.Unlock()
+    
+    done := make(chan struct{})
+    go func() {
+        f.wg.Wait()
+        close(done)
+    }()
+    
+    select {
+    case <-done:
+        return nil
+    case <-ctx.Done():
+        return fmt.Errorf("log sinks not drained: %w", ctx.Err())
+    }
+}
+
+func sinkName(sink Sink) string {
+    if named, ok := sink.(interface{ Name() string }); ok {
+        return named.Name()
+    }
+    return fmt.Sprintf("%T", sink)
+}
+
+// LogstashSink отправляет записи в дополнительный экземпляр Logstash по HTTP
+type LogstashSink struct {
+    url        string
+    client     *http.Client
+    maxRetries int
+}
+
+// NewLogstashSink создает приемник для Logstash HTTP input по адресу url
+func NewLogstashSink(url string) *LogstashSink {
+    return &LogstashSink{
+        url:        url,
+        client:     newHTTPClient(false, 0, nil, nil),
+        maxRetries: 3,
+    }
+}
+
+func (s *LogstashSink) Name() string {
+    return "logstash"
+}
+
+func (s *LogstashSink) Write(entry LogEntry) error {
+    data, err := json.Marshal(entry)
+    if err != nil {
+        return err
+    }
+    return retryablePost(s.client, s.url, data, s.maxRetries)
+}
+
+// ConsoleSink пишет записи в stdout в формате text или json
+type ConsoleSink struct {
+    mu     sync.Mutex
+    out    io.Writer
+    format string
+    color  bool
+}
+
+// NewConsoleSink создает приемник для stdout, format - ConsoleText или ConsoleJSON.
+// ANSI цвета в формате text включаются, только если stdout - терминал
+func NewConsoleSink(format string) *ConsoleSink {
+    return &ConsoleSink{out: os.Stdout, format: format, color: isTerminal(os.Stdout)}
+}
+
+func (s *ConsoleSink) Name() string {
+    return "console"
+}
+
+func (s *ConsoleSink) Write(entry LogEntry) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if s.format == ConsoleJSON {
+        writeConsoleJSON(s.out, entry.Level, entry.Message, entry.Fields)
+        return nil
+    }
+    writeConsoleText(s.out, entry.Level, entry.Message, entry.Fields, s.color)
+    return nil
+}
@@ -0,0 +1,183 @@
+package logging
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "testing"
+    "time"
+    
+    promtest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// memorySink запоминает записанные сообщения
+type memorySink struct {
+    name string
+    
+    mu       sync.Mutex
+    messages []string
+}
+
+func (s *memorySink) Name() string { return s.name }
+
+func (s *memorySink) Write(entry LogEntry) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    
+    s.messages = append(s.messages, entry.Message)
+    return nil
+}
+
+func (s *memorySink) written() []string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return append([]string(nil), s.messages...)
+}
+
+// failingSink всегда возвращает ошибку
+type failingSink struct{ name string }
+
+func (s failingSink) Name() string { return s.name }
+
+func (s failingSink) Write(entry LogEntry) error { return errors.New("sink unavailable") }
+
+// blockingSink не завершает запись до закрытия release
+type blockingSink struct {
+    name    string
+    release chan struct{}
+}
+
+func (s blockingSink) Name() string { return s.name }
+
+func (s blockingSink) Write(entry LogEntry) error {
+    <-s.release
+    return nil
+}
+
+func TestFanOutSink_FailingSinkDoesNotBlockOthers(t *testing.T) {
+    healthy := &memorySink{name: "test_healthy"}
+    blocked := blockingSink{name: "test_blocked", release: make(chan struct{})}
+    defer close(blocked.release)
+    
+    fanOut := NewFanOutSink(time.Second, 10, failingSink{name: "test_failing"}, blocked, healthy)
+    failedBefore := promtest.ToFloat64(sinkErrors.WithLabelValues("test_failing"))
+    
+    start := time.Now()
+    for _, message := range []string{"one", "two", "three"} {
+        if err := fanOut.Write(LogEntry{Message: message}); err != nil {
+            t.Fatalf("Write: %v", err)
+        }
+    }
+    if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+        t.Errorf("Write waited %s for sinks, want it to return immediately", elapsed)
+    }
+    
+    deadline := time.Now().Add(2 * time.Second)
+    for len(healthy.written()) < 3 && time.Now().Before(deadline) {
+        time.Sleep(5 * time.Millisecond)
+    }
+    if got := healthy.written(); len(got) != 3 || got[0] != "one" || got[2] != "three" {
+        t.Errorf("healthy sink got %v, want [one two three]", got)
+    }
+    
+    for time.Now().Before(deadline) && promtest.ToFloat64(sinkErrors.WithLabelValues("test_failing"))-failedBefore < 3 {
+        time.Sleep(5 * time.Millisecond)
+    }
+    if got := promtest.ToFloat64(sinkErrors.WithLabelValues("test_failing")) - failedBefore; got != 3 {
+        t.Errorf("log_sink_errors_total{sink=test_failing} increased by %v, want 3", got)
+    }
+    if got := promtest.ToFloat64(sinkErrors.WithLabelValues("test_healthy")); got != 0 {
+        t.Errorf("log_sink_errors_total{sink=test_healthy} = %v, want 0", got)
+    }
+}
+
+func TestFanOutSink_FullQueueDropsPerSink(t *testing.T) {
+    healthy := &memorySink{name: "test_queue_healthy"}
+    blocked := blockingSink{name: "test_queue_blocked", release: make(chan struct{})}
+    
+    fanOut := NewFanOutSink(time.Second, 1, blocked, healthy)
+    droppedBefore := promtest.ToFloat64(sinkDropped.WithLabelValues("test_queue_blocked"))
+    
+    // Первую запись горутина забирает и зависает, вторая ждет в очереди,
+    // остальные не помещаются
+    var dropErrs int
+    for i := 0; i < 5; i++ {
+        if err := fanOut.Write(LogEntry{Message: "entry"}); err != nil {
+            dropErrs++
+        }
+        time.Sleep(5 * time.Millisecond)
+    }
+    
+    if dropErrs == 0 {
+        t.Error("Write reported no dropped entries for the blocked sink")
+    }
+    if got := promtest.ToFloat64(sinkDropped.WithLabelValues("test_queue_blocked")) - droppedBefore; got != float64(dropErrs) {
+        t.Errorf("log_sink_dropped_total{sink=test_queue_blocked} increased by %v, want %d", got, dropErrs)
+    }
+    if got := promtest.ToFloat64(sinkDropped.WithLabelValues("test_queue_healthy")); got != 0 {
+        t.Errorf("healthy sink dropped %v entries, want 0", got)
+    }
+    
+    close(blocked.release)
+    if err := fanOut.Close(context.Background()); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    if got := len(healthy.written()); got != 5 {
+        t.Errorf("healthy sink got %d entries, want 5", got)
+    }
+}
+
+func TestFanOutSink_CloseStopsWaitingAtDeadline(t *testing.T) {
+    blocked := blockingSink{name: "test_close_blocked", release: make(chan struct{})}
+    defer close(blocked.release)
+    
+    fanOut := NewFanOutSink(time.Second, 10, blocked)
+    fanOut.Write(LogEntry{Message: "stuck"})
+    
+    ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+    defer cancel()
+    if err := fanOut.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("Close = %v, want context.DeadlineExceeded", err)
+    }
+    if err := fanOut.Write(LogEntry{Message: "late"}); err == nil {
+        t.Error("Write after Close succeeded")
+    }
+}
+
+// Зависший приемник не блокирует свою очередь дольше timeout
+func TestFanOutSink_WriteTimeout(t *testing.T) {
+    blocked := blockingSink{name: "test_timeout_blocked", release: make(chan struct{})}
+    defer close(blocked.release)
+    healthy := &memorySink{name: "test_timeout_healthy"}
+    
+    fanOut := NewFanOutSink(20*time.Millisecond, 10, blocked, healthy)
+    droppedBefore := promtest.ToFloat64(sinkDropped.WithLabelValues("test_timeout_blocked"))
+    
+    // Первая запись зависает и отбрасывается по timeout, остальные -
+    // сразу, пока она висит
+    for _, message := range []string{"one", "two", "three"} {
+        if err := fanOut.Write(LogEntry{Message: message}); err != nil {
+            t.Fatalf("Write: %v", err)
+        }
+    }
+    
+    ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+    defer cancel()
+    start := time.Now()
+    if err := fanOut.Close(ctx); err != nil {
+        t.Fatalf("Close = %v, want the hung sink queue drained by timeouts", err)
+    }
+    if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+        t.Errorf("Close took %s, want about one write timeout", elapsed)
+    }
+    
+    if got := promtest.ToFloat64(sinkDropped.WithLabelValues("test_timeout_blocked")) - droppedBefore; got != 3 {
+        t.Errorf("log_sink_dropped_total{sink=test_timeout_blocked} increased by %v, want 3", got)
+    }
+    if got := promtest.ToFloat64(sinkErrors.WithLabelValues("test_timeout_blocked")); got != 0 {
+        t.Errorf("log_sink_errors_total{sink=test_timeout_blocked} = %v, want 0", got)
+    }
+    if got := healthy.written(); len(got) != 3 {
+        t.Errorf("healthy sink got %v, want all 3 entries", got)
+    }
+}
@@ -0,0 +1,71 @@
+package logging
+
+import (
+    "encoding/json"
+    "sort"
+)
+
+// Запас под подпись ,"_sig":"<64 hex>", она добавляется после усечения
+const signatureReserve = 74
+
+// WithMaxEntryBytes задает лимит размера записи в байтах JSON.
+// Записи больше лимита отправляются с усеченными Fields, 0 отключает лимит
+func WithMaxEntryBytes(n int) Option {
+    return func(l *ELKLogger) {
+        if n >= 0 {
+            l.maxEntryBytes = n
+        }
+    }
+}
+
+// enforceEntrySize усекает Fields записи, не уложившейся в maxEntryBytes.
+// Поля добавляются в порядке ключей, пока запись с пометкой _truncated
+// помещается в лимит. Message и метаданные записи не усекаются
+func (l *ELKLogger) enforceEntrySize(entry *LogEntry) {
+    if l.maxEntryBytes <= 0 || len(entry.Fields) == 0 {
+        return
+    }
+    
+    limit := l.maxEntryBytes
+    if l.signingKey != nil {
+        limit -= signatureReserve
+    }
+    
+    data, err := l.marshalEntry(*entry)
+    if err != nil || len(data) <= limit {
+        return
+    }
+    
+    fields := entry.Fields
+    kept := map[string]interface{}{l.fieldPrefix + "_truncated": true}
+    entry.Fields = kept
+    
+    base, err := l.marshalEntry(*entry)
+    if err != nil {
+        return
+    }
+    size := len(base)
+    
+    keys := make([]string, 0, len(fields))
+    for k := range fields {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    
+    for _, k := range keys {
+        pair, err := json.Marshal(map[string]interface{}{k: fields[k]})
+        if err != nil {
+            continue
+        }
+        
+        // "k":v без фигурных скобок плюс запятая-разделитель
+        n := len(pair) - 2 + 1
+        if size+n > limit {
+            break
+        }
+        kept[k] = fields[k]
+        size += n
+    }
+    
+    truncatedEntries.Inc()
+}
\ No newline at end of file
@@ -0,0 +1,167 @@
+package logging
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    
+    promtest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const testMaxEntryBytes = 64 * 1024
+
+// sendToMockLogstash отправляет запись через пул логгера в тестовый
+// Logstash и возвращает тело запроса
+func sendToMockLogstash(t *testing.T, l *ELKLogger, message string, fields map[string]interface{}) []byte {
+    t.Helper()
+    
+    var sent []byte
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        sent, _ = io.ReadAll(r.Body)
+    }))
+    defer srv.Close()
+    
+    l.serviceName = "go-api"
+    l.transport, l.httpClient, l.logstashURL = TransportHTTP, srv.Client(), srv.URL
+    l.output = &logstashOutput{l: l}
+    
+    l.Info(message, fields)
+    l.sendLogAsync(l.lastQueued(t))
+    
+    if sent == nil {
+        t.Fatal("nothing was sent to Logstash")
+    }
+    return sent
+}
+
+// manyFields возвращает count полей field-NNNN по size байт
+func manyFields(count, size int) map[string]interface{} {
+    fields := make(map[string]interface{}, count)
+    for i := 0; i < count; i++ {
+        fields[fmt.Sprintf("field-%04d", i)] = strings.Repeat("x", size)
+    }
+    return fields
+}
+
+func TestEnforceEntrySize(t *testing.T) {
+    megabyte := strings.Repeat("a", 1024*1024)
+    longMessage := strings.Repeat("m", 2*testMaxEntryBytes)
+    
+    tests := []struct {
+        name          string
+        opts          []Option
+        message       string
+        fields        map[string]interface{}
+        wantTruncated bool
+        wantUnder     bool
+        wantKept      []string
+        wantDropped   []string
+    }{
+        {
+            name:          "1 MB field",
+            message:       "Request body dump",
+            fields:        map[string]interface{}{"a_request_id": "req-1", "body": megabyte, "z_status": 200},
+            wantTruncated: true,
+            wantUnder:     true,
+            wantKept:      []string{"a_request_id"},
+            wantDropped:   []string{"body", "z_status"},
+        },
+        {
+            name:          "1 MB in many fields",
+            message:       "Request body dump",
+            fields:        manyFields(1024, 1024),
+            wantTruncated: true,
+            wantUnder:     true,
+            wantKept:      []string{"field-0000", "field-0050"},
+            wantDropped:   []string{"field-0100", "field-1023"},
+        },
+        {
+            name:          "signed entry",
+            opts:          []Option{WithSigningKey(testSigningKey)},
+            message:       "Request body dump",
+            fields:        manyFields(1024, 1024),
+            wantTruncated: true,
+            wantUnder:     true,
+            wantKept:      []string{"field-0000"},
+        },
+        {
+            name:      "under the limit",
+            message:   "Order created",
+            fields:    map[string]interface{}{"order_id": 1, "body": strings.Repeat("a", 1024)},
+            wantUnder: true,
+            wantKept:  []string{"order_id", "body"},
+        },
+        {
+            name:          "message is never truncated",
+            message:       longMessage,
+            fields:        map[string]interface{}{"order_id": 1},
+            wantTruncated: true,
+            wantDropped:   []string{"order_id"},
+        },
+        {
+            name:     "limit disabled",
+            opts:     []Option{WithMaxEntryBytes(0)},
+            message:  "Request body dump",
+            fields:   map[string]interface{}{"body": megabyte},
+            wantKept: []string{"body"},
+        },
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            opts := append([]Option{WithDisableCaller(), WithTimestampPrecision("ms"), WithMaxEntryBytes(testMaxEntryBytes)}, tt.opts...)
+            l := newQueueTestLogger(opts...)
+            truncatedBefore := promtest.ToFloat64(truncatedEntries)
+            
+            sent := sendToMockLogstash(t, l, tt.message, tt.fields)
+            
+            if tt.wantUnder && len(sent) > testMaxEntryBytes {
+                t.Errorf("sent %d bytes, want at most %d", len(sent), testMaxEntryBytes)
+            }
+            
+            if l.signingKey != nil && !VerifyDocument(sent, testSigningKey) {
+                t.Error("truncated entry does not verify")
+            }
+            
+            var doc struct {
+                Timestamp string                 `json:"@timestamp"`
+                Message   string                 `json:"message"`
+                Level     string                 `json:"level"`
+                Service   string                 `json:"service"`
+                Fields    map[string]interface{} `json:"fields"`
+            }
+            if err := json.Unmarshal(sent, &doc); err != nil {
+                t.Fatalf("sent document is not valid JSON: %v", err)
+            }
+            if doc.Message != tt.message || doc.Level != "INFO" || doc.Service != "go-api" || doc.Timestamp == "" {
+                t.Errorf("message or metadata changed: @timestamp %q, level %q, service %q, message of %d bytes", doc.Timestamp, doc.Level, doc.Service, len(doc.Message))
+            }
+            
+            if got := doc.Fields["_truncated"] == true; got != tt.wantTruncated {
+                t.Errorf("_truncated = %v, want %v", doc.Fields["_truncated"], tt.wantTruncated)
+            }
+            wantCount := 0.0
+            if tt.wantTruncated {
+                wantCount = 1
+            }
+            if got := promtest.ToFloat64(truncatedEntries) - truncatedBefore; got != wantCount {
+                t.Errorf("log_entries_truncated_total increased by %v, want %v", got, wantCount)
+            }
+            
+            for _, key := range tt.wantKept {
+                if _, ok := doc.Fields[key]; !ok {
+                    t.Errorf("field %s was dropped", key)
+                }
+            }
+            for _, key := range tt.wantDropped {
+                if _, ok := doc.Fields[key]; ok {
+                    t.Errorf("field %s was kept", key)
+                }
+            }
+        })
+    }
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+    "fmt"
+    "net"
+    "sync"
+    "time"
+)
+
+// Транспорты доставки логов в Logstash
+const (
+    TransportHTTP = "http"
+    TransportTCP  = "tcp"
+    TransportUDP  = "udp"
+)
+
+const streamTimeout = 5 * time.Second
+
+// WithTransport выбирает транспорт ("http", "tcp", "udp") и адрес host:port
+// для потоковых транспортов. HTTP использует LOGSTASH_URL
+func WithTransport(transport, addr string) Option {
+    return func(l *ELKLogger) {
+        l.transport = transport
+        if addr != "" {
+            l.streamAddr = addr
+        }
+    }
+}
+
+// streamWriter пишет записи в Logstash по TCP или UDP, по одной JSON строке.
+// Соединение постоянное и переустанавливается после ошибки записи
+type streamWriter struct {
+    network string
+    addr    string
+
+    mu   sync.Mutex
+    conn net.Conn
+}
+
+func newStreamWriter(network, addr string) *streamWriter {
+    return &streamWriter{network: network, addr: addr}
+}
+
+// write отправляет строку с переводом строки в конце. Для TCP при обрыве
+// соединения делается одна попытка переподключения
+func (w *streamWriter) write(data []byte) error {
+    line := make([]byte, 0, len(data)+1)
+    line = append(line, data...)
+    line = append(line, '\n')
+
+    w.mu.Lock()
+    defer w.mu.Unlock()
+
+    err := w.writeLocked(line)
+    if err != nil && w.network == TransportTCP {
+        err = w.writeLocked(line)
+    }
+    return err
+}
+
+func (w *streamWriter) writeLocked(line []byte) error {
+    if w.conn == nil {
+        conn, err := net.DialTimeout(w.network, w.addr, streamTimeout)
+        if err != nil {
+            return fmt.Errorf("dial logstash %s %s: %w", w.network, w.addr, err)
+        }
+        w.conn = conn
+    }
+
+    w.conn.SetWriteDeadline(time.Now().Add(streamTimeout))
+    if _, err := w.conn.Write(line); err != nil {
+        w.conn.Close()
+        w.conn = nil
+        return err
+    }
+    return nil
+}
+
+func (w *streamWriter) Close() error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+
+    if w.conn == nil {
+        return nil
+    }
+    err := w.conn.Close()
+    w.conn = nil
+    return err
+}
@@ -0,0 +1,138 @@
+package logging
+
+import (
+    "bufio"
+    "encoding/json"
+    "net"
+    "strings"
+    "testing"
+    "time"
+)
+
+// streamLine - строка, полученная тестовым сервером, и номер соединения
+type streamLine struct {
+    conn int
+    text string
+}
+
+// newLineServer принимает TCP соединения и передает полученные строки в канал.
+// Первое соединение закрывается сервером после первой строки
+func newLineServer(t *testing.T) (string, <-chan streamLine) {
+    ln, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatalf("listen: %v", err)
+    }
+    t.Cleanup(func() { ln.Close() })
+    
+    lines := make(chan streamLine, 100)
+    go func() {
+        for n := 1; ; n++ {
+            conn, err := ln.Accept()
+            if err != nil {
+                return
+            }
+            go func(n int, conn net.Conn) {
+                defer conn.Close()
+                reader := bufio.NewReader(conn)
+                for {
+                    text, err := reader.ReadString('\n')
+                    if err != nil {
+                        return
+                    }
+                    lines <- streamLine{conn: n, text: text}
+                    if n == 1 {
+                        return
+                    }
+                }
+            }(n, conn)
+        }
+    }()
+    return ln.Addr().String(), lines
+}
+
+func marshalStreamEntry(t *testing.T, message string) []byte {
+    t.Helper()
+    data, err := json.Marshal(LogEntry{Level: "INFO", Message: message})
+    if err != nil {
+        t.Fatalf("marshal: %v", err)
+    }
+    return data
+}
+
+func TestStreamWriter_TCPNewlineTerminated(t *testing.T) {
+    addr, lines := newLineServer(t)
+    w := newStreamWriter(TransportTCP, addr)
+    defer w.Close()
+    
+    if err := w.write(marshalStreamEntry(t, "first")); err != nil {
+        t.Fatalf("write: %v", err)
+    }
+    
+    select {
+    case line := <-lines:
+        if !strings.HasSuffix(line.text, "\n") || strings.Count(line.text, "\n") != 1 {
+            t.Errorf("line = %q, want one JSON document ending with a newline", line.text)
+        }
+        var entry LogEntry
+        if err := json.Unmarshal([]byte(line.text), &entry); err != nil || entry.Message != "first" {
+            t.Errorf("line = %q, decode error %v, want the first entry", line.text, err)
+        }
+    case <-time.After(time.Second):
+        t.Fatal("server received nothing")
+    }
+}
+
+func TestStreamWriter_TCPReconnectsAfterServerClose(t *testing.T) {
+    addr, lines := newLineServer(t)
+    w := newStreamWriter(TransportTCP, addr)
+    defer w.Close()
+    
+    if err := w.write(marshalStreamEntry(t, "before close")); err != nil {
+        t.Fatalf("write: %v", err)
+    }
+    if line := <-lines; line.conn != 1 {
+        t.Fatalf("first line arrived on connection %d, want 1", line.conn)
+    }
+    
+    // Сервер закрыл соединение. Запись в полузакрытый сокет может пройти
+    // без ошибки, поэтому пишем до появления строки на новом соединении
+    deadline := time.After(2 * time.Second)
+    for {
+        w.write(marshalStreamEntry(t, "after close"))
+        
+        select {
+        case line := <-lines:
+            if line.conn != 2 || !strings.Contains(line.text, `"after close"`) {
+                t.Fatalf("got %q on connection %d, want the next entry on connection 2", line.text, line.conn)
+            }
+            return
+        case <-deadline:
+            t.Fatal("writer did not reconnect after the server closed the connection")
+        case <-time.After(10 * time.Millisecond):
+        }
+    }
+}
+
+func TestStreamWriter_UDP(t *testing.T) {
+    pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatalf("listen: %v", err)
+    }
+    defer pc.Close()
+    
+    w := newStreamWriter(TransportUDP, pc.LocalAddr().String())
+    defer w.Close()
+    if err := w.write(marshalStreamEntry(t, "datagram")); err != nil {
+        t.Fatalf("write: %v", err)
+    }
+    
+    buf := make([]byte, 64*1024)
+    pc.SetReadDeadline(time.Now().Add(time.Second))
+    n, _, err := pc.ReadFrom(buf)
+    if err != nil {
+        t.Fatalf("read datagram: %v", err)
+    }
+    if got := string(buf[:n]); !strings.HasSuffix(got, "}\n") || !strings.Contains(got, `"datagram"`) {
+        t.Errorf("datagram = %q, want the JSON entry ending with a newline", got)
+    }
+}
@@ -0,0 +1,15 @@
+package logging
+
+// Теги для записей, содержащих регулируемые данные (GDPR, HIPAA)
+const (
+    TagPII       = "pii"
+    TagPHI       = "phi"
+    TagFinancial = "financial"
+)
+
+// WithTags возвращает дочерний логгер, добавляющий теги ко всем записям
+func (l *ELKLogger) WithTags(tags ...string) *ELKLogger {
+    child := *l
+    child.tags = append(append([]string(nil), l.tags...), tags...)
+    return &child
+}
@@ -0,0 +1,81 @@
+package logging
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+    "time"
+    
+    promtest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTags_ComplianceSink(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "compliance.log")
+    archive, err := NewFileSink(path)
+    if err != nil {
+        t.Fatalf("NewFileSink: %v", err)
+    }
+    defer archive.Close()
+    
+    logstash := &memorySink{name: "test_tags_logstash"}
+    l := newQueueTestLogger(WithDisableCaller())
+    l.output = logstash
+    l.sinkFanOut = NewFanOutSink(time.Second, 10, TagFilter(archive, TagPII, TagPHI, TagFinancial))
+    
+    financialBefore := promtest.ToFloat64(taggedEntries.WithLabelValues(TagFinancial))
+    
+    l.WithTags(TagFinancial).Info("order processed", nil)
+    l.sendLogAsync(l.lastQueued(t))
+    l.Info("health checked", nil)
+    l.sendLogAsync(l.lastQueued(t))
+    l.WithTags("internal").Info("cache flushed", nil)
+    l.sendLogAsync(l.lastQueued(t))
+    
+    if err := l.sinkFanOut.Close(context.Background()); err != nil {
+        t.Fatalf("close fan-out: %v", err)
+    }
+    
+    if got, want := logstash.written(), []string{"order processed", "health checked", "cache flushed"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("logstash got %v, want %v", got, want)
+    }
+    
+    file, err := os.Open(path)
+    if err != nil {
+        t.Fatalf("open archive: %v", err)
+    }
+    defer file.Close()
+    
+    var archived []LogEntry
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        var entry LogEntry
+        if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+            t.Fatalf("archive line %q: %v", scanner.Text(), err)
+        }
+        archived = append(archived, entry)
+    }
+    if len(archived) != 1 || archived[0].Message != "order processed" || !reflect.DeepEqual(archived[0].Tags, []string{TagFinancial}) {
+        t.Errorf("archive = %+v, want only the financial entry", archived)
+    }
+    
+    if got := promtest.ToFloat64(taggedEntries.WithLabelValues(TagFinancial)) - financialBefore; got != 1 {
+        t.Errorf("log_tagged_entries_total{tag=financial} increased by %v, want 1", got)
+    }
+}
+
+func TestWithTags_DoesNotShareParentSlice(t *testing.T) {
+    parent := newQueueTestLogger().WithTags(TagPII)
+    first := parent.WithTags(TagPHI)
+    second := parent.WithTags(TagFinancial)
+    
+    if !reflect.DeepEqual(parent.tags, []string{TagPII}) {
+        t.Errorf("parent tags = %v, want [pii]", parent.tags)
+    }
+    if !reflect.DeepEqual(first.tags, []string{TagPII, TagPHI}) || !reflect.DeepEqual(second.tags, []string{TagPII, TagFinancial}) {
+        t.Errorf("child tags = %v and %v, want [pii phi] and [pii financial]", first.tags, second.tags)
+    }
+}
@@ -0,0 +1,32 @@
+package logging
+
+import (
+    "time"
+)
+
+// Форматы @timestamp по точности. В отличие от RFC3339Nano
+// дробная часть имеет фиксированную длину
+var timestampFormats = map[string]string{
+    "ns": "2006-01-02T15:04:05.000000000Z07:00",
+    "ms": "2006-01-02T15:04:05.000Z07:00",
+    "s":  "2006-01-02T15:04:05Z07:00",
+}
+
+// Точность по умолчанию для Logstash и файлового приемника
+const (
+    defaultLogstashPrecision = "ms"
+    defaultFilePrecision     = "ns"
+)
+
+// WithTimestampPrecision задает точность @timestamp: "ns", "ms" или "s"
+func WithTimestampPrecision(precision string) Option {
+    return func(l *ELKLogger) {
+        if _, ok := timestampFormats[precision]; ok {
+            l.timestampFormat = timestampFormats[precision]
+        }
+    }
+}
+
+func formatTimestamp(t time.Time, format string) string {
+    return t.UTC().Format(format)
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+    "regexp"
+    "testing"
+    "time"
+)
+
+func TestTimestampPrecision(t *testing.T) {
+    at := time.Date(2024, 1, 15, 10, 30, 0, 123456789, time.FixedZone("MSK", 3*60*60))
+    
+    tests := []struct {
+        precision string
+        want      string
+        pattern   string
+    }{
+        {precision: "ns", want: "2024-01-15T07:30:00.123456789Z", pattern: `\.\d{9}Z$`},
+        {precision: "ms", want: "2024-01-15T07:30:00.123Z", pattern: `\.\d{3}Z$`},
+        {precision: "s", want: "2024-01-15T07:30:00Z", pattern: `:\d{2}Z$`},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.precision, func(t *testing.T) {
+            l := newQueueTestLogger(WithTimestampPrecision(tt.precision))
+            if got := formatTimestamp(at, l.timestampFormat); got != tt.want {
+                t.Errorf("timestamp = %s, want %s", got, tt.want)
+            }
+            
+            // Дробная часть фиксированной длины, даже если оканчивается нулями
+            round := time.Date(2024, 1, 15, 10, 30, 0, 100000000, time.UTC)
+            if got := formatTimestamp(round, l.timestampFormat); !regexp.MustCompile(tt.pattern).MatchString(got) {
+                t.Errorf("timestamp %s does not match %s", got, tt.pattern)
+            }
+        })
+    }
+}
+
+func TestTimestampPrecision_Defaults(t *testing.T) {
+    l := newQueueTestLogger(WithTimestampPrecision("ms"), WithTimestampPrecision("us"))
+    if l.timestampFormat != timestampFormats["ms"] {
+        t.Errorf("unknown precision changed the format to %q", l.timestampFormat)
+    }
+    
+    sink, err := NewFileSink(t.TempDir() + "/app.log")
+    if err != nil {
+        t.Fatalf("NewFileSink: %v", err)
+    }
+    defer sink.Close()
+    if sink.timestampFormat != timestampFormats[defaultFilePrecision] || defaultFilePrecision != "ns" {
+        t.Errorf("file sink format = %q, want nanoseconds", sink.timestampFormat)
+    }
+    if defaultLogstashPrecision != "ms" {
+        t.Errorf("logstash precision = %q, want ms", defaultLogstashPrecision)
+    }
+}
@@ -0,0 +1,214 @@
+package logging
+
+import (
+    "context"
+    "crypto/tls"
+    "io"
+    "net"
+    "net/http"
+    "net/http/httptrace"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// WithDisableKeepAlives отключает переиспользование соединений с Logstash
+func WithDisableKeepAlives(disable bool) Option {
+    return func(l *ELKLogger) {
+        l.disableKeepAlives = disable
+    }
+}
+
+// WithMaxIdleConnLifetime ограничивает время жизни соединения с Logstash.
+// Нужно, когда сетевые экраны молча рвут долгоживущие соединения
+func WithMaxIdleConnLifetime(d time.Duration) Option {
+    return func(l *ELKLogger) {
+        l.maxConnLifetime = d
+    }
+}
+
+// newHTTPClient создает клиент Logstash. tlsConfig с клиентским
+// сертификатом включает mutual TLS, nil - настройки по умолчанию.
+// Соединения переиспользуются между воркерами (keep-alive), stats,
+// если задан, получает счетчики пула
+func newHTTPClient(disableKeepAlives bool, maxConnLifetime time.Duration, tlsConfig *tls.Config, stats *poolStats) *http.Client {
+    dialer := &net.Dialer{
+        Timeout:   5 * time.Second,
+        KeepAlive: 30 * time.Second,
+    }
+
+    // Время жизни отслеживается только для переиспользуемых соединений,
+    // простаивающие соединения закрываются не позже истечения срока
+    lifetime := maxConnLifetime
+    if disableKeepAlives {
+        lifetime = 0
+    }
+    idleTimeout := 90 * time.Second
+    if lifetime > 0 {
+        idleTimeout = min(idleTimeout, lifetime)
+    }
+    
+    transport := &http.Transport{
+        DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+            conn, err := dialer.DialContext(ctx, network, addr)
+            if err != nil {
+                return nil, err
+            }
+            if stats != nil {
+                stats.dials.Add(1)
+                stats.open.Add(1)
+            }
+            tracked := &trackedConn{Conn: conn, stats: stats}
+            if lifetime > 0 {
+                tracked.expireAfter(lifetime)
+            }
+            return tracked, nil
+        },
+        MaxIdleConns:        100,
+        MaxIdleConnsPerHost: maxConnsPerHost,
+        MaxConnsPerHost:     maxConnsPerHost,
+        IdleConnTimeout:     idleTimeout,
+        DisableKeepAlives:   disableKeepAlives,
+        ForceAttemptHTTP2:   true,
+        TLSClientConfig:     tlsConfig,
+    }
+
+    var rt http.RoundTripper = transport
+    if lifetime > 0 {
+        rt = &lifetimeTransport{base: transport}
+    }
+    if stats != nil {
+        rt = &statsTransport{base: rt, stats: stats}
+    }
+
+    return &http.Client{
+        Timeout:   5 * time.Second,
+        Transport: rt,
+    }
+}
+
+// trackedConn учитывает закрытие соединения в счетчиках пула. С ограничением времени жизни соединение
+// закрывается таймером: свободное - сразу, занятое запросом - когда
+// lifetimeTransport вернет его в пул
+type trackedConn struct {
+    net.Conn
+    stats  *poolStats
+    closed atomic.Bool
+    
+    // Число запросов на соединении: по HTTP/2 их может быть несколько
+    mu      sync.Mutex
+    timer   *time.Timer
+    active  int
+    expired bool
+}
+
+func (c *trackedConn) Close() error {
+    if c.closed.CompareAndSwap(false, true) {
+        if c.stats != nil {
+            c.stats.open.Add(-1)
+        }
+        c.mu.Lock()
+        if c.timer != nil {
+            c.timer.Stop()
+        }
+        c.mu.Unlock()
+    }
+    return c.Conn.Close()
+}
+
+// expireAfter закрывает соединение через lifetime после установки
+func (c *trackedConn) expireAfter(lifetime time.Duration) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    
+    c.timer = time.AfterFunc(lifetime, c.expire)
+}
+
+func (c *trackedConn) expire() {
+    c.mu.Lock()
+    c.expired = true
+    idle := c.active == 0
+    c.mu.Unlock()
+    
+    if idle {
+        c.Close()
+    }
+}
+
+// acquire отмечает соединение занятым запросом
+func (c *trackedConn) acquire() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    
+    c.active++
+}
+
+// release освобождает соединение и закрывает его, если срок уже истек,
+// чтобы оно не вернулось в пул
+func (c *trackedConn) release() {
+    c.mu.Lock()
+    c.active--
+    expired := c.expired && c.active == 0
+    c.mu.Unlock()
+    
+    if expired {
+        c.Close()
+    }
+}
+
+// lifetimeTransport не дает соединению с истекшим сроком закрыться
+// посреди запроса: таймер trackedConn ждет конца ответа
+type lifetimeTransport struct {
+    base *http.Transport
+}
+
+func (t *lifetimeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+    var conn *trackedConn
+    trace := &httptrace.ClientTrace{
+        GotConn: func(info httptrace.GotConnInfo) {
+            conn = unwrapTrackedConn(info.Conn)
+            if conn != nil {
+                conn.acquire()
+            }
+        },
+    }
+    
+    resp, err := t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+    if err != nil {
+        if conn != nil {
+            conn.release()
+        }
+        return nil, err
+    }
+    
+    if conn != nil {
+        resp.Body = &releasingBody{ReadCloser: resp.Body, conn: conn}
+    }
+    return resp, nil
+}
+
+func (t *lifetimeTransport) CloseIdleConnections() {
+    t.base.CloseIdleConnections()
+}
+
+// unwrapTrackedConn достает trackedConn, в том числе из-под TLS
+func unwrapTrackedConn(conn net.Conn) *trackedConn {
+    if tlsConn, ok := conn.(*tls.Conn); ok {
+        conn = tlsConn.NetConn()
+    }
+    tracked, _ := conn.(*trackedConn)
+    return tracked
+}
+
+// releasingBody освобождает соединение при закрытии тела ответа
+type releasingBody struct {
+    io.ReadCloser
+    conn *trackedConn
+    once sync.Once
+}
+
+func (b *releasingBody) Close() error {
+    err := b.ReadCloser.Close()
+    b.once.Do(b.conn.release)
+    return err
+}
\ No newline at end of file
@@ -0,0 +1,100 @@
+package logging
+
+import (
+    "bytes"
+    "io"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+// newConnCountingServer считает новые TCP соединения к серверу
+func newConnCountingServer(t testing.TB, handler http.HandlerFunc) (*httptest.Server, *atomic.Int64) {
+    var conns atomic.Int64
+    srv := httptest.NewUnstartedServer(handler)
+    srv.Config.ConnState = func(c net.Conn, state http.ConnState) {
+        if state == http.StateNew {
+            conns.Add(1)
+        }
+    }
+    srv.Start()
+    t.Cleanup(srv.Close)
+    return srv, &conns
+}
+
+func post(t *testing.T, client *http.Client, url string) {
+    t.Helper()
+    resp, err := client.Post(url, "application/json", bytes.NewReader([]byte(`{}`)))
+    if err != nil {
+        t.Fatalf("POST: %v", err)
+    }
+    io.Copy(io.Discard, resp.Body)
+    resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        t.Fatalf("status = %d, want 200", resp.StatusCode)
+    }
+}
+
+func TestHTTPClient_MaxConnLifetime(t *testing.T) {
+    tests := []struct {
+        name              string
+        lifetime          time.Duration
+        disableKeepAlives bool
+        wantConns         int64
+    }{
+        {name: "reused without lifetime", wantConns: 1},
+        {name: "expired connection not reused", lifetime: 50 * time.Millisecond, wantConns: 2},
+        {name: "keep-alives disabled", disableKeepAlives: true, wantConns: 2},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            srv, conns := newConnCountingServer(t, func(w http.ResponseWriter, r *http.Request) {})
+            client := newHTTPClient(tt.disableKeepAlives, tt.lifetime, nil, &poolStats{})
+
+            post(t, client, srv.URL)
+            time.Sleep(100 * time.Millisecond)
+            post(t, client, srv.URL)
+
+            if got := conns.Load(); got != tt.wantConns {
+                t.Errorf("server saw %d connections, want %d", got, tt.wantConns)
+            }
+        })
+    }
+}
+
+// Соединение с истекшим сроком закрывается само, не дожидаясь следующего запроса
+func TestHTTPClient_ExpiredIdleConnectionClosed(t *testing.T) {
+    srv, _ := newConnCountingServer(t, func(w http.ResponseWriter, r *http.Request) {})
+    l := &ELKLogger{poolStats: &poolStats{}}
+    client := newHTTPClient(false, 50*time.Millisecond, nil, l.poolStats)
+
+    post(t, client, srv.URL)
+    if open := l.ConnectionPoolStats()["open"]; open != 1 {
+        t.Fatalf("open connections after request = %d, want 1", open)
+    }
+
+    time.Sleep(150 * time.Millisecond)
+    if open := l.ConnectionPoolStats()["open"]; open != 0 {
+        t.Errorf("open connections after lifetime = %d, want 0", open)
+    }
+}
+
+// Срок, истекший во время запроса, не обрывает ответ
+func TestHTTPClient_LifetimeExpiresDuringRequest(t *testing.T) {
+    srv, conns := newConnCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+        time.Sleep(100 * time.Millisecond)
+        w.Write([]byte("ok"))
+    })
+    client := newHTTPClient(false, 30*time.Millisecond, nil, &poolStats{})
+
+    post(t, client, srv.URL)
+    post(t, client, srv.URL)
+
+    if got := conns.Load(); got != 2 {
+        t.Errorf("server saw %d connections, want 2", got)
+    }
+}
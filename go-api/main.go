@@ -3,89 +3,229 @@ package main
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/crazy1997/go-api/bus"
+	"github.com/crazy1997/go-api/config"
 	handlers "github.com/crazy1997/go-api/hadnlers"
 	"github.com/crazy1997/go-api/logging"
 	"github.com/crazy1997/go-api/metrics"
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/openapi"
+	"github.com/crazy1997/go-api/router"
+	"github.com/crazy1997/go-api/tracing"
+	"github.com/getsentry/sentry-go"
 	"github.com/gorilla/mux"
 )
 
+// Версия сборки, задается через -ldflags "-X main.version=..." (см. Makefile)
+var (
+	version   = ""
+	commit    = "unknown"
+	buildDate = "unknown"
+)
+
 func main() {
-	// Инициализация логгера
-	logger := logging.InitLogger()
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Без версии из сборки используем SERVICE_VERSION
+	if version == "" {
+		version = cfg.ServiceVersion
+	}
+	buildInfo := metrics.BuildInfo{Version: version, Commit: commit, BuildDate: buildDate}
+	handlers.SetBuildInfo(buildInfo)
+
+	// Инициализация логгера, пул отправки работает до завершения main
+	logCtx, stopLogging := context.WithCancel(context.Background())
+	defer stopLogging()
+
+	logger := logging.InitLogger(logCtx, cfg)
+	logger.AddEnrichmentHook("kubernetes", logging.KubernetesHook())
+
+	// До проверки Logstash /api/health отвечает 503 starting, см. awaitStartup
+	var started atomic.Bool
+
+	// Readiness-проверка пингует Logstash и следит за остановкой сервера через шину здоровья
+	handlers.AddReadinessCheck(logging.LogstashComponent, logger)
+	handlers.WatchHealth(bus.Default, serverComponent)
+
+	// Ошибки и паники со стеком уходят в Sentry
+	if err := sentry.Init(sentry.ClientOptions{
+		Dsn:              cfg.SentryDSN,
+		Environment:      cfg.Environment,
+		Release:          version,
+		ServerName:       cfg.ServerIP,
+		AttachStacktrace: true,
+	}); err != nil {
+		logger.Error("Failed to initialize Sentry", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	// Трассировка, спаны отправляются в OpenTelemetry Collector
+	if _, err := tracing.Init(cfg.OTELServiceName, cfg.OTELEndpoint); err != nil {
+		logger.Error("Failed to initialize tracing", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else if cfg.OTELEndpoint != "" {
+		logger.Info("Tracing enabled", map[string]interface{}{
+			"collector_endpoint": cfg.OTELEndpoint,
+			"service_name":       cfg.OTELServiceName,
+		})
+	}
 
 	// Инициализация метрик
-	metrics.Init()
+	metrics.Init(logCtx, metrics.MetricsConfig{
+		Namespace:       cfg.MetricsNamespace,
+		Subsystem:       cfg.MetricsSubsystem,
+		MaxPaths:        cfg.MetricsMaxPaths,
+		GCPollInterval:  cfg.MetricsGCPollInterval,
+		ProductViewsTTL: cfg.ProductViewsTTL,
+		BuildInfo:       buildInfo,
+
+		SLOTarget:         cfg.SLOTarget,
+		BurnRateThreshold: cfg.BurnRateThreshold,
+	})
 
 	// Создаем роутер
-	r := mux.NewRouter()
+	routerOpts := router.Options{
+		Handler: handlers.New(handlers.Config{
+			Logger:            logger.AsLogger(),
+			Metrics:           metrics.Recorder{},
+			UserCacheTTL:      cfg.UserCacheTTL,
+			LowStockThreshold: cfg.LowStockThreshold,
+			CursorSecret:      cfg.CursorSecret,
+			Rand:              rand.New(rand.NewSource(time.Now().UnixNano())),
+			Started:           &started,
+		}),
+		MirrorURL:          cfg.MirrorURL,
+		MirrorPercentage:   cfg.MirrorPercentage,
+		AdminToken:         cfg.AdminToken,
+		AdminAllowedCIDRs:  cfg.AdminAllowedCIDRs,
+		TrustedProxyCIDRs:  cfg.TrustedProxyCIDRs,
+		JWTSecret:          cfg.JWTSecret,
+		CORSAllowedOrigins: cfg.CORSAllowedOrigins,
+		MaxBodyBytes:       cfg.MaxBodyBytes,
+		RateLimitRPS:       cfg.RateLimitRPS,
+		RateLimitBurst:     cfg.RateLimitBurst,
+		MaxActiveRequests:  cfg.MaxActiveRequests,
+	}
+	if cfg.SlowRequestDetail {
+		routerOpts.SlowRequestThreshold = 500 * time.Millisecond
+	}
 
-	// Глобальный middleware для метрик
-	r.Use(metrics.MetricsMiddleware)
+	if len(cfg.APIKeys) > 0 {
+		routerOpts.APIKeys = middleware.NewMemoryAPIKeyStore(cfg.APIKeys)
+	}
 
-	// API эндпоинты
-	r.HandleFunc("/api/health", handlers.HealthHandler).Methods("GET")
-	r.HandleFunc("/api/users", handlers.UsersHandler).Methods("GET")
-	r.HandleFunc("/api/orders", handlers.OrdersHandler).Methods("POST")
-	r.HandleFunc("/api/products", handlers.ProductsHandler).Methods("GET")
-	r.HandleFunc("/api/metrics/info", handlers.MetricsHandler).Methods("GET")
+	r := router.New(routerOpts)
 
-	// Prometheus метрики
-	r.Handle("/metrics", metrics.Handler())
+	// В development сверяем маршруты с OpenAPI спецификацией
+	if cfg.Environment == "development" {
+		validateSpec(logger, r, cfg.OpenAPISpecPath)
+	}
 
-	// Статика
-	r.PathPrefix("/").Handler(http.FileServer(http.Dir("./static/")))
+	// Настройка сервера, HTTP/2 push ассетов статики вместе с HTML страницами
+	server := newServer(cfg, middleware.PushMiddleware(cfg.PushManifestPath)(r))
+	logServerConfig(logger, server)
+	logTLSMode(logger, cfg)
 
-	// Настройка сервера
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Сертификаты Let's Encrypt для ACME_DOMAINS
+	certManager := newCertManager(cfg)
+	if certManager != nil {
+		server.TLSConfig = certManager.TLSConfig()
 	}
 
-	server := &http.Server{
-		Addr:         "0.0.0.0:" + port,
-		Handler:      r,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	// При включенном TLS обычный HTTP только перенаправляет на HTTPS
+	var redirectServer *http.Server
+	if cfg.TLSEnabled() {
+		redirectServer = newRedirectServer(cfg, certManager)
+		go func() {
+			if err := redirectServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("HTTP redirect server failed", map[string]interface{}{
+					"error": err,
+				})
+				sentry.CaptureMessage("HTTP redirect server failed: " + err.Error())
+			}
+		}()
 	}
 
 	// Graceful shutdown
+	shutdownConfig := newShutdownConfig(logger.AsLogger(), cfg)
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
-		logger.Info(fmt.Sprintf("Starting server on %s:%s", "0.0.0.0", port), map[string]interface{}{
-			"environment": os.Getenv("ENVIRONMENT"),
-			"server_ip":   "147.45.183.143",
+		logger.Info(fmt.Sprintf("Starting server on %s:%s", "0.0.0.0", cfg.Port), map[string]interface{}{
+			"environment": cfg.Environment,
+			"server_ip":   cfg.ServerIP,
 		})
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := listenAndServe(server, cfg); err != nil && err != http.ErrServerClosed {
 			logger.Error("Server failed to start", map[string]interface{}{
-				"error": err.Error(),
+				"error": err,
 			})
+			sentry.CaptureMessage("Server failed to start: " + err.Error())
 		}
 	}()
 
+	// Логгер и метрики готовы, ждем Logstash не дольше STARTUP_TIMEOUT_SECONDS
+	go awaitStartup(logger, &started, cfg.StartupTimeout)
+
 	// Ожидаем сигнал остановки
 	<-stop
 
 	logger.Info("Shutting down server...", nil)
 
-	// Даем время на завершение обработки запросов
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	// Редирект отвечает мгновенно, ждать его запросы не нужно
+	if redirectServer != nil {
+		redirectServer.Close()
+	}
 
-	if err := server.Shutdown(ctx); err != nil {
-		logger.Error("Server shutdown failed", map[string]interface{}{
-			"error": err.Error(),
+	// Даем время на завершение запросов, заказов и отправку логов
+	gracefulShutdown(server, logger.AsLogger(), shutdownConfig)
+}
+
+// validateSpec предупреждает о расхождениях маршрутов со спецификацией
+// и останавливает запуск, если для описанного маршрута нет обработчика
+func validateSpec(logger *logging.ELKLogger, r *mux.Router, specPath string) {
+	spec, err := openapi.LoadSpec(specPath)
+	if err != nil {
+		logger.Warn("OpenAPI spec validation skipped", map[string]interface{}{
+			"spec_path": specPath,
+			"error":     err.Error(),
 		})
+		return
 	}
 
-	logger.Info("Server stopped gracefully", nil)
+	missingHandlers := false
+	for _, mismatch := range openapi.ValidateSpecAgainstRoutes(spec, r) {
+		logger.Warn("Route does not match OpenAPI spec", map[string]interface{}{
+			"type":   mismatch.Type,
+			"method": mismatch.Method,
+			"path":   mismatch.Path,
+		})
+
+		if mismatch.Type == openapi.MismatchMissingHandler {
+			missingHandlers = true
+		}
+	}
+
+	if missingHandlers {
+		logger.Error("OpenAPI spec contains routes without handlers", nil)
+		sentry.CaptureMessage("OpenAPI spec contains routes without handlers")
+		sentry.Flush(2 * time.Second)
+		logger.Flush(context.Background())
+		os.Exit(1)
+	}
 }
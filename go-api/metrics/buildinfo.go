@@ -0,0 +1,31 @@
+package metrics
+
+import (
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+// BuildInfo - версия бинарника, передается через -ldflags при сборке
+type BuildInfo struct {
+    Version   string `json:"version"`
+    Commit    string `json:"commit"`
+    BuildDate string `json:"build_date"`
+}
+
+// newBuildInfoGauge создает build_info со значением 1 и версией в метках,
+// как у стандартных *_build_info метрик Prometheus
+func newBuildInfoGauge(cfg MetricsConfig) prometheus.Gauge {
+    gauge := prometheus.NewGauge(
+        prometheus.GaugeOpts{
+            Namespace: cfg.Namespace,
+            Name:      "build_info",
+            Help:      "Build information of the running binary, value is always 1",
+            ConstLabels: prometheus.Labels{
+                "version":    cfg.BuildInfo.Version,
+                "commit":     cfg.BuildInfo.Commit,
+                "build_date": cfg.BuildInfo.BuildDate,
+            },
+        },
+    )
+    gauge.Set(1)
+    return gauge
+}
\ No newline at end of file
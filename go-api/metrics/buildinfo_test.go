@@ -0,0 +1,49 @@
+package metrics
+
+import (
+    "strings"
+    "testing"
+    
+    promtest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInit_BuildInfo(t *testing.T) {
+    tests := []struct {
+        name     string
+        cfg      MetricsConfig
+        expected string
+    }{
+        {
+            name: "injected version",
+            cfg:  MetricsConfig{BuildInfo: BuildInfo{Version: "v1.4.2", Commit: "3f2c1ab", BuildDate: "2026-10-01T12:00:00Z"}},
+            expected: `
+# HELP build_info Build information of the running binary, value is always 1
+# TYPE build_info gauge
+build_info{build_date="2026-10-01T12:00:00Z",commit="3f2c1ab",version="v1.4.2"} 1
+`,
+        },
+        {
+            name: "namespaced",
+            cfg:  MetricsConfig{Namespace: "goapi", BuildInfo: BuildInfo{Version: "dev", Commit: "unknown", BuildDate: "unknown"}},
+            expected: `
+# HELP goapi_build_info Build information of the running binary, value is always 1
+# TYPE goapi_build_info gauge
+goapi_build_info{build_date="unknown",commit="unknown",version="dev"} 1
+`,
+        },
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            reg := initTestMetrics(t, tt.cfg)
+            
+            name := "build_info"
+            if tt.cfg.Namespace != "" {
+                name = tt.cfg.Namespace + "_" + name
+            }
+            if err := promtest.GatherAndCompare(reg, strings.NewReader(tt.expected), name); err != nil {
+                t.Error(err)
+            }
+        })
+    }
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+    "strings"
+    "sync"
+    "time"
+)
+
+// labelDeleter - вектор метрик, из которого можно удалить серию (CounterVec, HistogramVec...)
+type labelDeleter interface {
+    DeleteLabelValues(lvs ...string) bool
+}
+
+// Минимальный период проверки серий: ttl/2 от очень малого ttl дал бы
+// нулевой период тикера (паника) или холостой цикл
+const minExpireInterval = 10 * time.Millisecond
+
+// CardinalityGuard следит за значениями меток векторной метрики
+// и ограничивает рост числа серий
+type CardinalityGuard struct {
+    name string
+    vec  labelDeleter
+    ttl  time.Duration
+
+    mu         sync.Mutex
+    lastUpdate map[string]time.Time
+    stop       chan struct{}
+    done       chan struct{}
+}
+
+// GuardOption настраивает CardinalityGuard
+type GuardOption func(*CardinalityGuard)
+
+// WithAutoExpire удаляет серии, не обновлявшиеся дольше ttl. Серии
+// проверяются каждые ttl/2, но не чаще раза в minExpireInterval.
+// Новое наблюдение после удаления создает серию заново с нуля.
+// ttl <= 0 отключает удаление
+func WithAutoExpire(ttl time.Duration) GuardOption {
+    return func(g *CardinalityGuard) {
+        g.ttl = ttl
+    }
+}
+
+// NewCardinalityGuard создает guard для метрики name
+func NewCardinalityGuard(name string, vec labelDeleter, opts ...GuardOption) *CardinalityGuard {
+    g := &CardinalityGuard{
+        name:       name,
+        vec:        vec,
+        lastUpdate: make(map[string]time.Time),
+        stop:       make(chan struct{}),
+        done:       make(chan struct{}),
+    }
+    for _, opt := range opts {
+        opt(g)
+    }
+
+    if g.ttl > 0 {
+        go g.expireLoop()
+    } else {
+        close(g.done)
+    }
+    return g
+}
+
+// Touch отмечает обновление серии с указанными значениями меток
+func (g *CardinalityGuard) Touch(lvs ...string) {
+    if g.ttl <= 0 {
+        return
+    }
+
+    g.mu.Lock()
+    defer g.mu.Unlock()
+
+    g.lastUpdate[strings.Join(lvs, "\xff")] = time.Now()
+}
+
+// Stop останавливает фоновую очистку и ждет завершения текущего прохода
+func (g *CardinalityGuard) Stop() {
+    close(g.stop)
+    <-g.done
+}
+
+func (g *CardinalityGuard) expireLoop() {
+    defer close(g.done)
+
+    ticker := time.NewTicker(max(g.ttl/2, minExpireInterval))
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ticker.C:
+            g.expire(time.Now())
+        case <-g.stop:
+            return
+        }
+    }
+}
+
+func (g *CardinalityGuard) expire(now time.Time) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+
+    for key, updated := range g.lastUpdate {
+        if now.Sub(updated) < g.ttl {
+            continue
+        }
+
+        delete(g.lastUpdate, key)
+        if g.vec.DeleteLabelValues(strings.Split(key, "\xff")...) {
+            expiredSeries.WithLabelValues(g.name).Inc()
+        }
+    }
+}
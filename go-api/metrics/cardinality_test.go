@@ -0,0 +1,83 @@
+package metrics
+
+import (
+    "testing"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newGuardTestVec(name string) *prometheus.CounterVec {
+    return prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: "test"}, []string{"product_id"})
+}
+
+// observe увеличивает серию и отмечает ее обновление, как RecordProductView
+func observe(vec *prometheus.CounterVec, guard *CardinalityGuard, productID string) {
+    vec.WithLabelValues(productID).Inc()
+    guard.Touch(productID)
+}
+
+func TestCardinalityGuard_AutoExpire(t *testing.T) {
+    const name = "test_guard_expire_total"
+    vec := newGuardTestVec(name)
+    guard := NewCardinalityGuard(name, vec, WithAutoExpire(50*time.Millisecond))
+    defer guard.Stop()
+
+    expiredBefore := testutil.ToFloat64(expiredSeries.WithLabelValues(name))
+
+    observe(vec, guard, "1")
+    observe(vec, guard, "1")
+    observe(vec, guard, "2")
+
+    // Серия 2 обновляется, серия 1 - нет
+    deadline := time.Now().Add(time.Second)
+    for testutil.CollectAndCount(vec) != 1 && time.Now().Before(deadline) {
+        observe(vec, guard, "2")
+        time.Sleep(10 * time.Millisecond)
+    }
+
+    if n := testutil.CollectAndCount(vec); n != 1 {
+        t.Fatalf("series count = %d, want 1 after the stale series expired", n)
+    }
+    if got := testutil.ToFloat64(expiredSeries.WithLabelValues(name)) - expiredBefore; got != 1 {
+        t.Errorf("metric_expired_series_total{metric=%q} increased by %v, want 1", name, got)
+    }
+
+    // Новое наблюдение создает серию заново с нуля
+    observe(vec, guard, "1")
+    if got := testutil.ToFloat64(vec.WithLabelValues("1")); got != 1 {
+        t.Errorf("recreated series = %v, want 1", got)
+    }
+}
+
+func TestCardinalityGuard_TTLBounds(t *testing.T) {
+    tests := []struct {
+        name string
+        ttl  time.Duration
+    }{
+        {name: "zero disables expiry", ttl: 0},
+        {name: "negative disables expiry", ttl: -time.Second},
+        {name: "one nanosecond", ttl: time.Nanosecond},
+        {name: "below minimum interval", ttl: minExpireInterval},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            vec := newGuardTestVec("test_guard_bounds_total")
+            guard := NewCardinalityGuard("test_guard_bounds_total", vec, WithAutoExpire(tt.ttl))
+            defer guard.Stop()
+
+            observe(vec, guard, "1")
+            time.Sleep(3 * minExpireInterval)
+
+            want := 1
+            if tt.ttl > 0 {
+                want = 0
+            }
+            if n := testutil.CollectAndCount(vec); n != want {
+                t.Errorf("series count = %d, want %d", n, want)
+            }
+        })
+    }
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+    "fmt"
+    "sync"
+
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+    // Registerer, на котором регистрируются пользовательские метрики
+    registerer prometheus.Registerer = prometheus.DefaultRegisterer
+
+    customMu         sync.RWMutex
+    customCollectors = make(map[string]prometheus.Collector)
+)
+
+// SetRegisterer подменяет Registerer для пользовательских метрик (например, в тестах)
+func SetRegisterer(reg prometheus.Registerer) {
+    customMu.Lock()
+    defer customMu.Unlock()
+
+    registerer = reg
+}
+
+// RegisterCounter регистрирует бизнес-счетчик без изменения этого пакета.
+// К имени применяется префикс из MetricsConfig
+func RegisterCounter(name, help string, labels []string) (*prometheus.CounterVec, error) {
+    counter := prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Namespace: config.Namespace,
+            Subsystem: config.Subsystem,
+            Name:      name,
+            Help:      help,
+        },
+        labels,
+    )
+
+    if err := registerCustom(name, counter); err != nil {
+        return nil, err
+    }
+    return counter, nil
+}
+
+// RegisterHistogram регистрирует бизнес-гистограмму без изменения этого пакета
+func RegisterHistogram(name, help string, labels []string, buckets []float64) (*prometheus.HistogramVec, error) {
+    if buckets == nil {
+        buckets = prometheus.DefBuckets
+    }
+
+    histogram := prometheus.NewHistogramVec(
+        prometheus.HistogramOpts{
+            Namespace: config.Namespace,
+            Subsystem: config.Subsystem,
+            Name:      name,
+            Help:      help,
+            Buckets:   buckets,
+        },
+        labels,
+    )
+
+    if err := registerCustom(name, histogram); err != nil {
+        return nil, err
+    }
+    return histogram, nil
+}
+
+// GetCounter возвращает ранее зарегистрированный счетчик
+func GetCounter(name string) (*prometheus.CounterVec, bool) {
+    customMu.RLock()
+    defer customMu.RUnlock()
+
+    counter, ok := customCollectors[name].(*prometheus.CounterVec)
+    return counter, ok
+}
+
+// GetHistogram возвращает ранее зарегистрированную гистограмму
+func GetHistogram(name string) (*prometheus.HistogramVec, bool) {
+    customMu.RLock()
+    defer customMu.RUnlock()
+
+    histogram, ok := customCollectors[name].(*prometheus.HistogramVec)
+    return histogram, ok
+}
+
+func registerCustom(name string, collector prometheus.Collector) error {
+    customMu.Lock()
+    defer customMu.Unlock()
+
+    if _, exists := customCollectors[name]; exists {
+        return fmt.Errorf("metric %q already registered", name)
+    }
+
+    if err := registerer.Register(collector); err != nil {
+        return fmt.Errorf("register metric %q: %w", name, err)
+    }
+
+    customCollectors[name] = collector
+    return nil
+}
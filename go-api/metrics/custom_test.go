@@ -0,0 +1,126 @@
+package metrics
+
+import (
+    "strings"
+    "testing"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// useTestRegisterer регистрирует пользовательские метрики на отдельном реестре
+func useTestRegisterer(t *testing.T) *prometheus.Registry {
+    t.Helper()
+
+    customMu.Lock()
+    prevRegisterer, prevCollectors := registerer, customCollectors
+    customCollectors = make(map[string]prometheus.Collector)
+    customMu.Unlock()
+    t.Cleanup(func() {
+        customMu.Lock()
+        registerer, customCollectors = prevRegisterer, prevCollectors
+        customMu.Unlock()
+    })
+
+    reg := prometheus.NewRegistry()
+    SetRegisterer(reg)
+    return reg
+}
+
+func TestRegisterCounter(t *testing.T) {
+    reg := useTestRegisterer(t)
+
+    counter, err := RegisterCounter("signups_total", "Signups by plan", []string{"plan"})
+    if err != nil {
+        t.Fatalf("RegisterCounter: %v", err)
+    }
+    counter.WithLabelValues("pro").Add(2)
+
+    got, ok := GetCounter("signups_total")
+    if !ok || got != counter {
+        t.Fatalf("GetCounter = %v %v, want the registered counter", got, ok)
+    }
+
+    expected := `
+# HELP signups_total Signups by plan
+# TYPE signups_total counter
+signups_total{plan="pro"} 2
+`
+    if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "signups_total"); err != nil {
+        t.Error(err)
+    }
+}
+
+func TestRegisterHistogram(t *testing.T) {
+    reg := useTestRegisterer(t)
+
+    histogram, err := RegisterHistogram("basket_items", "Items per basket", nil, []float64{1, 5})
+    if err != nil {
+        t.Fatalf("RegisterHistogram: %v", err)
+    }
+    histogram.WithLabelValues().Observe(3)
+
+    if got, ok := GetHistogram("basket_items"); !ok || got != histogram {
+        t.Fatalf("GetHistogram = %v %v, want the registered histogram", got, ok)
+    }
+    if _, ok := GetCounter("basket_items"); ok {
+        t.Error("GetCounter returned a histogram")
+    }
+
+    expected := `
+# HELP basket_items Items per basket
+# TYPE basket_items histogram
+basket_items_bucket{le="1"} 0
+basket_items_bucket{le="5"} 1
+basket_items_bucket{le="+Inf"} 1
+basket_items_sum 3
+basket_items_count 1
+`
+    if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "basket_items"); err != nil {
+        t.Error(err)
+    }
+}
+
+func TestRegisterCustom_Errors(t *testing.T) {
+    reg := useTestRegisterer(t)
+    reg.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "taken_total", Help: "test"}))
+
+    if _, err := RegisterCounter("orders_refunded_total", "test", nil); err != nil {
+        t.Fatalf("RegisterCounter: %v", err)
+    }
+
+    tests := []struct {
+        name     string
+        register func() error
+        wantErr  string
+    }{
+        {
+            name: "duplicate name",
+            register: func() error {
+                _, err := RegisterHistogram("orders_refunded_total", "test", nil, nil)
+                return err
+            },
+            wantErr: `metric "orders_refunded_total" already registered`,
+        },
+        {
+            name: "registry conflict",
+            register: func() error {
+                _, err := RegisterCounter("taken_total", "test", nil)
+                return err
+            },
+            wantErr: `register metric "taken_total"`,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if err := tt.register(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+                t.Errorf("error = %v, want %q", err, tt.wantErr)
+            }
+        })
+    }
+
+    if _, ok := GetCounter("taken_total"); ok {
+        t.Error("failed registration was stored")
+    }
+}
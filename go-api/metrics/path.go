@@ -0,0 +1,185 @@
+package metrics
+
+import (
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+
+    "github.com/gorilla/mux"
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+// Значение метки path для путей сверх лимита
+const overflowPath = "other"
+
+const defaultMaxPaths = 100
+
+// pathLimiter ограничивает число различных значений метки path
+type pathLimiter struct {
+    mu    sync.Mutex
+    max   int
+    paths map[string]struct{}
+}
+
+func newPathLimiter(max int) *pathLimiter {
+    return &pathLimiter{max: max, paths: make(map[string]struct{})}
+}
+
+// limit возвращает path, пока лимит не исчерпан, и "other" для новых путей сверх него
+func (p *pathLimiter) limit(path string) string {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    
+    if _, ok := p.paths[path]; ok {
+        return path
+    }
+    if len(p.paths) >= p.max {
+        cardinalityOverflow.Inc()
+        return overflowPath
+    }
+    p.paths[path] = struct{}{}
+    return path
+}
+
+// Лимит путей задается MetricsConfig.MaxPaths в Init
+var pathLabels = newPathLimiter(defaultMaxPaths)
+
+// pathLabel возвращает шаблон маршрута (/api/users/{id}) вместо пути запроса.
+// Маршруты PathPrefix сводятся к шаблону вида /static/*, чтобы каждый файл
+// статики не создавал свою серию. Для запросов без маршрута используется
+// путь с замененными числовыми сегментами
+func pathLabel(r *http.Request) string {
+    if route := mux.CurrentRoute(r); route != nil {
+        if tmpl, err := route.GetPathTemplate(); err == nil {
+            if isPrefixRoute(route) {
+                tmpl = strings.TrimSuffix(tmpl, "/") + "/*"
+            }
+            return pathLabels.limit(tmpl)
+        }
+    }
+    return pathLabels.limit(sanitizePath(r.URL.Path))
+}
+
+// sanitizePath заменяет числовые сегменты пути на {id}
+func sanitizePath(path string) string {
+    segments := strings.Split(path, "/")
+    for i, segment := range segments {
+        if segment == "" {
+            continue
+        }
+        if _, err := strconv.ParseUint(segment, 10, 64); err == nil {
+            segments[i] = "{id}"
+        }
+    }
+    return strings.Join(segments, "/")
+}
+
+// isPrefixRoute определяет маршрут PathPrefix: в отличие от Path
+// его регулярное выражение не привязано к концу строки
+func isPrefixRoute(route *mux.Route) bool {
+    re, err := route.GetPathRegexp()
+    return err == nil && !strings.HasSuffix(re, "$")
+}
+// durationHistograms - http_request_duration_seconds с границами гистограммы,
+// настраиваемыми по шаблону пути через WithPathBuckets. Серии всех шаблонов
+// отдаются под одним именем метрики, пути без настройки используют DefBuckets
+type durationHistograms struct {
+    opts     prometheus.HistogramOpts
+    desc     *prometheus.Desc
+    defaults *prometheus.HistogramVec
+    
+    mu    sync.RWMutex
+    rules []pathBucketsRule
+}
+
+// pathBucketsRule - границы гистограммы для точного шаблона или префикса
+type pathBucketsRule struct {
+    pattern string
+    prefix  bool
+    vec     *prometheus.HistogramVec
+}
+
+var durationLabels = []string{"method", "path"}
+
+func newDurationHistograms(opts prometheus.HistogramOpts) *durationHistograms {
+    opts.Buckets = prometheus.DefBuckets
+    return &durationHistograms{
+        opts:     opts,
+        desc:     prometheus.NewDesc(prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name), opts.Help, durationLabels, nil),
+        defaults: prometheus.NewHistogramVec(opts, durationLabels),
+    }
+}
+
+// WithPathBuckets задает границы гистограммы длительности запросов для
+// шаблона пути: точного значения метки path (/api/users/{id}) или префикса
+// с /* на конце (/static/*), которому соответствуют все метки с этим
+// префиксом, в том числе метки маршрутов PathPrefix. Правила проверяются
+// в порядке добавления. Вызывается после Init и до начала обработки запросов
+func WithPathBuckets(pattern string, buckets []float64) error {
+    return httpRequestDuration.addRule(pattern, buckets)
+}
+
+func (d *durationHistograms) addRule(pattern string, buckets []float64) error {
+    if !strings.HasPrefix(pattern, "/") {
+        return fmt.Errorf("path buckets pattern %q must start with /", pattern)
+    }
+    if len(buckets) == 0 {
+        return fmt.Errorf("path buckets for %q are empty", pattern)
+    }
+    for i := 1; i < len(buckets); i++ {
+        if buckets[i] <= buckets[i-1] {
+            return fmt.Errorf("path buckets for %q must be sorted in increasing order", pattern)
+        }
+    }
+    
+    rule := pathBucketsRule{pattern: pattern}
+    if strings.HasSuffix(pattern, "/*") {
+        rule.prefix = true
+        rule.pattern = strings.TrimSuffix(pattern, "*")
+    }
+    
+    opts := d.opts
+    opts.Buckets = buckets
+    rule.vec = prometheus.NewHistogramVec(opts, durationLabels)
+    
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    
+    for _, existing := range d.rules {
+        if existing.pattern == rule.pattern && existing.prefix == rule.prefix {
+            return fmt.Errorf("path buckets for %q already configured", pattern)
+        }
+    }
+    d.rules = append(d.rules, rule)
+    return nil
+}
+
+// WithLabelValues возвращает гистограмму серии с границами по шаблону path
+func (d *durationHistograms) WithLabelValues(method, path string) prometheus.Observer {
+    d.mu.RLock()
+    defer d.mu.RUnlock()
+    
+    for _, rule := range d.rules {
+        if path == rule.pattern || (rule.prefix && strings.HasPrefix(path, rule.pattern)) {
+            return rule.vec.WithLabelValues(method, path)
+        }
+    }
+    return d.defaults.WithLabelValues(method, path)
+}
+
+func (d *durationHistograms) Describe(ch chan<- *prometheus.Desc) {
+    ch <- d.desc
+}
+
+func (d *durationHistograms) Collect(ch chan<- prometheus.Metric) {
+    d.defaults.Collect(ch)
+    
+    d.mu.RLock()
+    defer d.mu.RUnlock()
+    
+    for _, rule := range d.rules {
+        rule.vec.Collect(ch)
+    }
+}
@@ -0,0 +1,225 @@
+package metrics
+
+import (
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gorilla/mux"
+    "github.com/prometheus/client_golang/prometheus"
+    promtest "github.com/prometheus/client_golang/prometheus/testutil"
+    dto "github.com/prometheus/client_model/go"
+)
+
+// useTestHTTPMetrics подменяет HTTP метрики новыми на отдельном реестре
+func useTestHTTPMetrics(t *testing.T) *prometheus.Registry {
+    t.Helper()
+
+    total, duration, limiter := httpRequestsTotal, httpRequestDuration, pathLabels
+    t.Cleanup(func() {
+        httpRequestsTotal, httpRequestDuration, pathLabels = total, duration, limiter
+    })
+
+    httpRequestsTotal = prometheus.NewCounterVec(
+        prometheus.CounterOpts{Name: "http_requests_total", Help: "test"},
+        []string{"method", "path", "status", "outcome"},
+    )
+    httpRequestDuration = newDurationHistograms(prometheus.HistogramOpts{Name: "http_request_duration_seconds", Help: "test"})
+    pathLabels = newPathLimiter(defaultMaxPaths)
+
+    reg := prometheus.NewRegistry()
+    reg.MustRegister(httpRequestsTotal, httpRequestDuration)
+    return reg
+}
+
+func newPathTestRouter() *mux.Router {
+    r := mux.NewRouter()
+    r.Use(MetricsMiddleware)
+    ok := func(w http.ResponseWriter, r *http.Request) {}
+    r.HandleFunc("/api/users/{id}", ok)
+    r.HandleFunc("/api/health", ok)
+    r.PathPrefix("/static/").HandlerFunc(ok)
+    return r
+}
+
+func serve(r http.Handler, path string) {
+    r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+}
+
+// durationSeries возвращает гистограммы длительности по значению метки path
+func durationSeries(t *testing.T, reg *prometheus.Registry) map[string]*dto.Histogram {
+    t.Helper()
+
+    families, err := reg.Gather()
+    if err != nil {
+        t.Fatalf("gather: %v", err)
+    }
+
+    series := make(map[string]*dto.Histogram)
+    for _, family := range families {
+        if family.GetName() != "http_request_duration_seconds" {
+            continue
+        }
+        for _, m := range family.GetMetric() {
+            for _, label := range m.GetLabel() {
+                if label.GetName() == "path" {
+                    series[label.GetValue()] = m.GetHistogram()
+                }
+            }
+        }
+    }
+    return series
+}
+
+func TestMetricsMiddleware_StaticPathsShareOneSeries(t *testing.T) {
+    reg := useTestHTTPMetrics(t)
+    r := newPathTestRouter()
+
+    for i := 0; i < 50; i++ {
+        serve(r, fmt.Sprintf("/static/assets/file-%d.js", i))
+    }
+    serve(r, "/api/users/1")
+    serve(r, "/api/users/2")
+
+    series := durationSeries(t, reg)
+    if len(series) != 2 {
+        t.Fatalf("got series %v, want /static/* and /api/users/{id}", keys(series))
+    }
+    if got := series["/static/*"].GetSampleCount(); got != 50 {
+        t.Errorf("/static/* count = %d, want 50", got)
+    }
+    if got := series["/api/users/{id}"].GetSampleCount(); got != 2 {
+        t.Errorf("/api/users/{id} count = %d, want 2", got)
+    }
+}
+
+func TestPathLabel(t *testing.T) {
+    // Без маршрутизатора mux.CurrentRoute пуст и метка берется из пути
+    unrouted := MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+    tests := []struct {
+        name    string
+        handler http.Handler
+        paths   []string
+        want    string
+    }{
+        {name: "route template", handler: newPathTestRouter(), paths: []string{"/api/users/12345", "/api/users/9999"}, want: "/api/users/{id}"},
+        {name: "prefix route", handler: newPathTestRouter(), paths: []string{"/static/a.css", "/static/img/b.png"}, want: "/static/*"},
+        {name: "unmatched path sanitized", handler: unrouted, paths: []string{"/api/orders/17", "/api/orders/42"}, want: "/api/orders/{id}"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            reg := useTestHTTPMetrics(t)
+            for _, path := range tt.paths {
+                serve(tt.handler, path)
+            }
+
+            series := durationSeries(t, reg)
+            if len(series) != 1 || series[tt.want].GetSampleCount() != uint64(len(tt.paths)) {
+                t.Errorf("got series %v, want one %s series with %d samples", keys(series), tt.want, len(tt.paths))
+            }
+        })
+    }
+}
+
+func TestMetricsMiddleware_CardinalityOverflow(t *testing.T) {
+    reg := initTestMetrics(t, MetricsConfig{MaxPaths: 2})
+    r := newPathTestRouter()
+    unrouted := MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+    serve(r, "/api/users/1")
+    serve(r, "/api/health")
+    serve(unrouted, "/unknown/a")
+    serve(unrouted, "/unknown/b")
+    serve(r, "/api/users/2")
+
+    if got := promtest.ToFloat64(cardinalityOverflow); got != 2 {
+        t.Errorf("metrics_cardinality_overflow_total = %v, want 2", got)
+    }
+    series := durationSeries(t, reg)
+    want := map[string]uint64{"/api/users/{id}": 2, "/api/health": 1, overflowPath: 2}
+    if len(series) != len(want) {
+        t.Fatalf("got series %v, want %v", keys(series), want)
+    }
+    for path, count := range want {
+        if got := series[path].GetSampleCount(); got != count {
+            t.Errorf("%s count = %d, want %d", path, got, count)
+        }
+    }
+}
+
+func TestWithPathBuckets(t *testing.T) {
+    reg := useTestHTTPMetrics(t)
+    if err := WithPathBuckets("/static/*", []float64{0.001, 0.01}); err != nil {
+        t.Fatalf("WithPathBuckets prefix: %v", err)
+    }
+    if err := WithPathBuckets("/api/health", []float64{0.0005, 0.001, 0.002}); err != nil {
+        t.Fatalf("WithPathBuckets exact: %v", err)
+    }
+
+    r := newPathTestRouter()
+    serve(r, "/static/app.css")
+    serve(r, "/static/img/logo.png")
+    serve(r, "/api/health")
+    serve(r, "/api/users/1")
+
+    tests := []struct {
+        path    string
+        buckets int
+        count   uint64
+    }{
+        {"/static/*", 2, 2},
+        {"/api/health", 3, 1},
+        {"/api/users/{id}", len(prometheus.DefBuckets), 1},
+    }
+
+    series := durationSeries(t, reg)
+    for _, tt := range tests {
+        h, ok := series[tt.path]
+        if !ok {
+            t.Errorf("no series for %s in %v", tt.path, keys(series))
+            continue
+        }
+        if got := len(h.GetBucket()); got != tt.buckets {
+            t.Errorf("%s has %d buckets, want %d", tt.path, got, tt.buckets)
+        }
+        if got := h.GetSampleCount(); got != tt.count {
+            t.Errorf("%s count = %d, want %d", tt.path, got, tt.count)
+        }
+    }
+}
+
+func TestWithPathBuckets_InvalidRules(t *testing.T) {
+    useTestHTTPMetrics(t)
+    if err := WithPathBuckets("/static/*", []float64{1}); err != nil {
+        t.Fatalf("WithPathBuckets: %v", err)
+    }
+
+    tests := []struct {
+        name    string
+        pattern string
+        buckets []float64
+    }{
+        {"relative pattern", "static/*", []float64{1}},
+        {"empty buckets", "/api/health", nil},
+        {"unsorted buckets", "/api/health", []float64{1, 0.5}},
+        {"duplicate pattern", "/static/*", []float64{2}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if err := WithPathBuckets(tt.pattern, tt.buckets); err == nil {
+                t.Errorf("WithPathBuckets(%q, %v) succeeded, want error", tt.pattern, tt.buckets)
+            }
+        })
+    }
+}
+
+func keys(m map[string]*dto.Histogram) []string {
+    result := make([]string, 0, len(m))
+    for k := range m {
+        result = append(result, k)
+    }
+    return result
+}
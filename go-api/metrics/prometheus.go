@@ -1,6 +1,8 @@
 package metrics
 
 import (
+    "context"
+    "github.com/crazy1997/go-api/logging"
     "github.com/prometheus/client_golang/prometheus"
     "github.com/prometheus/client_golang/prometheus/promhttp"
     "net/http"
@@ -8,93 +10,433 @@ import (
     "time"
 )
 
+// MetricsConfig задает общий префикс имен метрик и параметры сбора.
+// Нулевые значения заменяются значениями по умолчанию
+type MetricsConfig struct {
+    Namespace string
+    Subsystem string
+    
+    // Лимит значений метки path
+    MaxPaths int
+    
+    // Период опроса пауз GC
+    GCPollInterval time.Duration
+    
+    // Время жизни серий products_viewed_total без обновлений
+    ProductViewsTTL time.Duration
+    
+    // Версия бинарника для build_info
+    BuildInfo BuildInfo
+    
+    // Цель SLO по доле успешных запросов (0.999) и порог burn rate
+    // для предупреждения в логе
+    SLOTarget         float64
+    BurnRateThreshold float64
+}
+
+const defaultProductViewsTTL = 24 * time.Hour
+
+// Текущая конфигурация, применяется и к пользовательским метрикам
+var config MetricsConfig
+
 var (
+    httpRequestsTotal       *prometheus.CounterVec
+    httpRequestDuration     *durationHistograms
+    httpRequestSize         *prometheus.HistogramVec
+    httpResponseSize        *prometheus.HistogramVec
+    ordersProcessed         prometheus.Counter
+    ordersCancelledByClient prometheus.Counter
+    ordersRevenueTotal      prometheus.Counter
+    orderValueHistogram     prometheus.Histogram
+    orderStatusTransitions  *prometheus.CounterVec
+    usersRegistered         prometheus.Counter
+    usersBulkCreated        prometheus.Counter
+    usersDeleted            prometheus.Counter
+    productsViewed          *prometheus.CounterVec
+    productStockLevel       *prometheus.GaugeVec
+    errorCounter            *prometheus.CounterVec
+    activeRequests          prometheus.Gauge
+    responseTime95          prometheus.Gauge
+    shutdownStageDuration   *prometheus.HistogramVec
+    expiredSeries           *prometheus.CounterVec
+    mirrorErrors            *prometheus.CounterVec
+    outboundRequests        *prometheus.CounterVec
+    outboundDuration        *prometheus.HistogramVec
+    rateLimitRejections     *prometheus.CounterVec
+    http2Pushes             prometheus.Counter
+    shedRequests            prometheus.Counter
+    apiKeyAuthFailures      prometheus.Counter
+    apiKeyAuthSuccess       *prometheus.CounterVec
+    cardinalityOverflow     prometheus.Counter
+
+    // Ограничение серий products_viewed_total по product_id
+    productsViewedGuard *CardinalityGuard
+)
+
+// Метрики создаются сразу, чтобы Record* работали и до Init
+func init() {
+    newCollectors(MetricsConfig{})
+}
+
+// newCollectors создает метрики с префиксом из cfg
+func newCollectors(cfg MetricsConfig) {
     // HTTP метрики
     httpRequestsTotal = prometheus.NewCounterVec(
         prometheus.CounterOpts{
-            Name: "http_requests_total",
-            Help: "Total number of HTTP requests",
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "http_requests_total",
+            Help:      "Total number of HTTP requests",
         },
-        []string{"method", "path", "status"},
+        []string{"method", "path", "status", "outcome"},
     )
-    
-    httpRequestDuration = prometheus.NewHistogramVec(
+
+    // Границы гистограммы настраиваются по шаблону пути, см. WithPathBuckets
+    httpRequestDuration = newDurationHistograms(
         prometheus.HistogramOpts{
-            Name:    "http_request_duration_seconds",
-            Help:    "Duration of HTTP requests in seconds",
-            Buckets: prometheus.DefBuckets,
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "http_request_duration_seconds",
+            Help:      "Duration of HTTP requests in seconds",
         },
-        []string{"method", "path"},
     )
-    
+
     httpRequestSize = prometheus.NewHistogramVec(
         prometheus.HistogramOpts{
-            Name:    "http_request_size_bytes",
-            Help:    "Size of HTTP requests in bytes",
-            Buckets: []float64{100, 500, 1000, 5000, 10000, 50000, 100000},
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "http_request_size_bytes",
+            Help:      "Size of HTTP requests in bytes",
+            Buckets:   []float64{100, 500, 1000, 5000, 10000, 50000, 100000},
         },
         []string{"method", "path"},
     )
-    
+
+    httpResponseSize = prometheus.NewHistogramVec(
+        prometheus.HistogramOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "http_response_size_bytes",
+            Help:      "Size of HTTP responses in bytes",
+            Buckets:   []float64{100, 500, 1000, 5000, 10000, 50000, 100000, 500000, 1000000},
+        },
+        []string{"method", "path"},
+    )
+
     // Бизнес метрики
     ordersProcessed = prometheus.NewCounter(
         prometheus.CounterOpts{
-            Name: "orders_processed_total",
-            Help: "Total number of orders processed",
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "orders_processed_total",
+            Help:      "Total number of orders processed",
         },
     )
-    
+
+    ordersCancelledByClient = prometheus.NewCounter(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "orders_cancelled_by_client_total",
+            Help:      "Total number of orders cancelled by client disconnect",
+        },
+    )
+
+    ordersRevenueTotal = prometheus.NewCounter(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "orders_revenue_total",
+            Help:      "Total revenue of processed orders",
+        },
+    )
+
+    orderValueHistogram = prometheus.NewHistogram(
+        prometheus.HistogramOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "order_value",
+            Help:      "Distribution of processed order values",
+            Buckets:   []float64{0, 10, 50, 100, 500, 1000, 5000},
+        },
+    )
+
+    orderStatusTransitions = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "order_status_transitions_total",
+            Help:      "Total number of order status transitions",
+        },
+        []string{"from", "to"},
+    )
+
     usersRegistered = prometheus.NewCounter(
         prometheus.CounterOpts{
-            Name: "users_registered_total",
-            Help: "Total number of users registered",
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "users_registered_total",
+            Help:      "Total number of users registered",
         },
     )
-    
+
+    usersBulkCreated = prometheus.NewCounter(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "users_bulk_created_total",
+            Help:      "Total number of users created by bulk import",
+        },
+    )
+
+    usersDeleted = prometheus.NewCounter(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "users_deleted_total",
+            Help:      "Total number of users deleted",
+        },
+    )
+
     productsViewed = prometheus.NewCounterVec(
         prometheus.CounterOpts{
-            Name: "products_viewed_total",
-            Help: "Total number of product views",
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "products_viewed_total",
+            Help:      "Total number of product views",
         },
         []string{"product_id"},
     )
-    
+
+    // Остатки на складе, обновляются при каждом изменении
+    productStockLevel = prometheus.NewGaugeVec(
+        prometheus.GaugeOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "product_stock_level",
+            Help:      "Current stock level of a product",
+        },
+        []string{"product_id"},
+    )
+
     // Ошибки
     errorCounter = prometheus.NewCounterVec(
         prometheus.CounterOpts{
-            Name: "errors_total",
-            Help: "Total number of errors",
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "errors_total",
+            Help:      "Total number of errors",
         },
         []string{"type", "endpoint"},
     )
-    
+
     // Системные метрики приложения
     activeRequests = prometheus.NewGauge(
         prometheus.GaugeOpts{
-            Name: "active_requests",
-            Help: "Number of active requests",
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "active_requests",
+            Help:      "Number of active requests",
         },
     )
-    
+
     responseTime95 = prometheus.NewGauge(
         prometheus.GaugeOpts{
-            Name: "response_time_95_percentile",
-            Help: "95th percentile of response time",
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "response_time_95_percentile",
+            Help:      "95th percentile of response time",
+        },
+    )
+
+    // Остановка сервера
+    shutdownStageDuration = prometheus.NewHistogramVec(
+        prometheus.HistogramOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "shutdown_stage_duration_seconds",
+            Help:      "Duration of graceful shutdown stages in seconds",
+            Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30},
+        },
+        []string{"stage"},
+    )
+
+    // Серии, удаленные по истечении TTL
+    expiredSeries = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "metric_expired_series_total",
+            Help:      "Total number of metric series removed after TTL expiration",
+        },
+        []string{"metric"},
+    )
+
+    // Значения метки path сверх лимита, замененные на "other"
+    cardinalityOverflow = prometheus.NewCounter(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "metrics_cardinality_overflow_total",
+            Help:      "Total number of observations with path label replaced by \"other\"",
         },
     )
-)
 
-func Init() {
+    // Зеркалирование трафика
+    mirrorErrors = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "mirror_errors_total",
+            Help:      "Total number of failed mirrored requests",
+        },
+        []string{"reason"},
+    )
+
+    // Исходящие запросы InstrumentedClient, target - имя внешнего сервиса
+    outboundRequests = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "outbound_requests_total",
+            Help:      "Total number of outbound HTTP requests",
+        },
+        []string{"target", "method", "status"},
+    )
+
+    outboundDuration = prometheus.NewHistogramVec(
+        prometheus.HistogramOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "outbound_request_duration_seconds",
+            Help:      "Duration of outbound HTTP requests in seconds",
+            Buckets:   prometheus.DefBuckets,
+        },
+        []string{"target", "method"},
+    )
+
+    // Запросы, отклоненные ограничением частоты, ip - хеш адреса клиента
+    rateLimitRejections = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "rate_limit_rejections_total",
+            Help:      "Total number of requests rejected by rate limiting",
+        },
+        []string{"ip"},
+    )
+
+    // Ассеты, отправленные HTTP/2 server push
+    http2Pushes = prometheus.NewCounter(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "http2_push_total",
+            Help:      "Total number of static assets sent via HTTP/2 server push",
+        },
+    )
+
+    // Запросы, отклоненные при перегрузке
+    shedRequests = prometheus.NewCounter(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "requests_shed_total",
+            Help:      "Total number of requests rejected by load shedding",
+        },
+    )
+
+    // Проверки API ключей, key_name - имя интеграции из API_KEYS
+    apiKeyAuthFailures = prometheus.NewCounter(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "api_key_auth_failures_total",
+            Help:      "Total number of requests with a missing or invalid API key",
+        },
+    )
+
+    apiKeyAuthSuccess = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "api_key_auth_success_total",
+            Help:      "Total number of requests authenticated by API key",
+        },
+        []string{"key_name"},
+    )
+}
+
+// Init регистрирует метрики. Фоновый сбор пауз GC работает до отмены ctx
+func Init(ctx context.Context, cfg MetricsConfig) {
+    config = cfg
+    newCollectors(cfg)
+    
     // Регистрируем все метрики
     prometheus.MustRegister(httpRequestsTotal)
     prometheus.MustRegister(httpRequestDuration)
     prometheus.MustRegister(httpRequestSize)
+    prometheus.MustRegister(httpResponseSize)
     prometheus.MustRegister(ordersProcessed)
+    prometheus.MustRegister(ordersCancelledByClient)
+    prometheus.MustRegister(ordersRevenueTotal)
+    prometheus.MustRegister(orderValueHistogram)
+    prometheus.MustRegister(orderStatusTransitions)
     prometheus.MustRegister(usersRegistered)
+    prometheus.MustRegister(usersBulkCreated)
+    prometheus.MustRegister(usersDeleted)
     prometheus.MustRegister(productsViewed)
+    prometheus.MustRegister(productStockLevel)
     prometheus.MustRegister(errorCounter)
     prometheus.MustRegister(activeRequests)
     prometheus.MustRegister(responseTime95)
+    prometheus.MustRegister(shutdownStageDuration)
+    prometheus.MustRegister(mirrorErrors)
+    prometheus.MustRegister(outboundRequests)
+    prometheus.MustRegister(outboundDuration)
+    prometheus.MustRegister(rateLimitRejections)
+    prometheus.MustRegister(http2Pushes)
+    prometheus.MustRegister(shedRequests)
+    prometheus.MustRegister(apiKeyAuthFailures)
+    prometheus.MustRegister(apiKeyAuthSuccess)
+    prometheus.MustRegister(expiredSeries)
+    prometheus.MustRegister(cardinalityOverflow)
+    
+    startTime = time.Now()
+    prometheus.MustRegister(newUptimeGauge(cfg))
+    prometheus.MustRegister(newBuildInfoGauge(cfg))
+    RegisterRuntimeCollector()
+    prometheus.MustRegister(gcPauses)
+    
+    gcInterval := cfg.GCPollInterval
+    if gcInterval <= 0 {
+        gcInterval = defaultGCPollInterval
+    }
+    go watchGCPauses(ctx, gcInterval)
+    
+    sloTarget := cfg.SLOTarget
+    if sloTarget <= 0 || sloTarget >= 1 {
+        sloTarget = defaultSLOTarget
+    }
+    burnThreshold := cfg.BurnRateThreshold
+    if burnThreshold <= 0 {
+        burnThreshold = defaultBurnRateThreshold
+    }
+    prometheus.MustRegister(sloBurnRate)
+    go watchSLOBurnRate(ctx, sloTarget, burnThreshold)
+    
+    maxPaths := cfg.MaxPaths
+    if maxPaths <= 0 {
+        maxPaths = defaultMaxPaths
+    }
+    pathLabels = newPathLimiter(maxPaths)
+    
+    // Просмотры снятых с продажи продуктов не должны храниться вечно
+    ttl := cfg.ProductViewsTTL
+    if ttl <= 0 {
+        ttl = defaultProductViewsTTL
+    }
+    productsViewedGuard = NewCardinalityGuard("products_viewed_total", productsViewed, WithAutoExpire(ttl))
 }
 
 func Handler() http.Handler {
@@ -118,11 +460,12 @@ func MetricsMiddleware(next http.Handler) http.Handler {
         
         // Собираем метрики
         duration := time.Since(start).Seconds()
-        path := r.URL.Path
+        path := pathLabel(r)
         method := r.Method
         status := strconv.Itoa(rw.statusCode)
         
-        httpRequestsTotal.WithLabelValues(method, path, status).Inc()
+        httpRequestsTotal.WithLabelValues(method, path, status, OutcomeFromStatus(rw.statusCode)).Inc()
+        sloRequests.Add(1)
         httpRequestDuration.WithLabelValues(method, path).Observe(duration)
         
         // Размер запроса (приблизительно)
@@ -130,36 +473,160 @@ func MetricsMiddleware(next http.Handler) http.Handler {
         if contentLength > 0 {
             httpRequestSize.WithLabelValues(method, path).Observe(float64(contentLength))
         }
+        
+        // Размер ответа - сумма всех Write, включая ответы http.FileServer
+        httpResponseSize.WithLabelValues(method, path).Observe(float64(rw.bytesWritten))
     })
 }
 
+// Исходы запроса для SLO и фильтрации в Kibana
+const (
+    OutcomeSuccess     = "success"
+    OutcomeClientError = "client_error"
+    OutcomeServerError = "server_error"
+)
+
+// OutcomeFromStatus сводит код ответа к исходу запроса
+func OutcomeFromStatus(statusCode int) string {
+    switch {
+    case statusCode >= 500:
+        return OutcomeServerError
+    case statusCode >= 400:
+        return OutcomeClientError
+    default:
+        return OutcomeSuccess
+    }
+}
+
 type responseWriter struct {
     http.ResponseWriter
-    statusCode int
+    statusCode    int
+    headerWritten bool
+    bytesWritten  int64
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
-    rw.statusCode = code
+    if !rw.headerWritten {
+        rw.statusCode = code
+        rw.headerWritten = true
+    }
     rw.ResponseWriter.WriteHeader(code)
 }
 
+func (rw *responseWriter) Write(b []byte) (int, error) {
+    rw.headerWritten = true
+    n, err := rw.ResponseWriter.Write(b)
+    rw.bytesWritten += int64(n)
+    return n, err
+}
+
+// Header после отправки заголовков возвращает копию,
+// изменения которой уже не влияют на соединение
+func (rw *responseWriter) Header() http.Header {
+    if rw.headerWritten {
+        return rw.ResponseWriter.Header().Clone()
+    }
+    return rw.ResponseWriter.Header()
+}
+
+// SetHeader устанавливает заголовок, если ответ еще не начат,
+// иначе пишет предупреждение вместо молчаливой потери заголовка
+func (rw *responseWriter) SetHeader(key, value string) {
+    if rw.headerWritten {
+        logging.Warn("Response header set after headers were written", map[string]interface{}{
+            "header": key,
+            "status": rw.statusCode,
+        })
+        return
+    }
+    rw.ResponseWriter.Header().Set(key, value)
+}
+
 // Бизнес метрики
 func RecordOrder() {
     ordersProcessed.Inc()
 }
 
+// RecordOrderRevenue учитывает сумму заказа в выручке и распределении стоимости
+func RecordOrderRevenue(amount float64) {
+    if amount < 0 {
+        return
+    }
+    ordersRevenueTotal.Add(amount)
+    orderValueHistogram.Observe(amount)
+}
+
+func RecordOrderStatusTransition(from, to string) {
+    orderStatusTransitions.WithLabelValues(from, to).Inc()
+}
+
+func RecordOrderCancelledByClient() {
+    ordersCancelledByClient.Inc()
+}
+
 func RecordUserRegistration() {
     usersRegistered.Inc()
 }
 
+func RecordUsersBulkCreated(count int) {
+    usersBulkCreated.Add(float64(count))
+}
+
+func RecordUserDeletion() {
+    usersDeleted.Inc()
+}
+
 func RecordProductView(productID string) {
+    if productsViewedGuard != nil {
+        productsViewedGuard.Touch(productID)
+    }
     productsViewed.WithLabelValues(productID).Inc()
 }
 
+func SetProductStock(productID string, level int) {
+    productStockLevel.WithLabelValues(productID).Set(float64(level))
+}
+
 func RecordError(errorType, endpoint string) {
     errorCounter.WithLabelValues(errorType, endpoint).Inc()
+    sloErrors.Add(1)
 }
 
 func SetResponseTime95(value float64) {
     responseTime95.Set(value)
+}
+
+func RecordShutdownStage(stage string, duration time.Duration) {
+    shutdownStageDuration.WithLabelValues(stage).Observe(duration.Seconds())
+}
+
+func RecordMirrorError(reason string) {
+    mirrorErrors.WithLabelValues(reason).Inc()
+}
+
+// RecordOutboundRequest учитывает исходящий запрос. status - код ответа
+// или "error", если ответа не было
+func RecordOutboundRequest(target, method, status string, duration time.Duration) {
+    outboundRequests.WithLabelValues(target, method, status).Inc()
+    outboundDuration.WithLabelValues(target, method).Observe(duration.Seconds())
+}
+
+func RecordRateLimitRejection(ipHash string) {
+    rateLimitRejections.WithLabelValues(ipHash).Inc()
+}
+
+func RecordHTTP2Push() {
+    http2Pushes.Inc()
+}
+
+func RecordShedRequest() {
+    shedRequests.Inc()
+}
+
+func RecordAPIKeyAuthFailure() {
+    apiKeyAuthFailures.Inc()
+}
+
+func RecordAPIKeyAuthSuccess(keyName string) {
+    apiKeyAuthSuccess.WithLabelValues(keyName).Inc()
 }
\ No newline at end of file
@@ -0,0 +1,271 @@
+package metrics
+
+import (
+    "bytes"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    
+    "github.com/crazy1997/go-api/logging"
+    "github.com/gorilla/mux"
+    "github.com/prometheus/client_golang/prometheus"
+    promtest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// initTestMetrics выполняет Init на отдельном реестре вместо реестра по умолчанию.
+// Фоновые задачи останавливаются вместе с тестом
+func initTestMetrics(t *testing.T, cfg MetricsConfig) *prometheus.Registry {
+    t.Helper()
+    
+    registerer, gatherer, prevConfig, paths := prometheus.DefaultRegisterer, prometheus.DefaultGatherer, config, pathLabels
+    t.Cleanup(func() {
+        prometheus.DefaultRegisterer, prometheus.DefaultGatherer, config, pathLabels = registerer, gatherer, prevConfig, paths
+    })
+    
+    reg := prometheus.NewRegistry()
+    prometheus.DefaultRegisterer, prometheus.DefaultGatherer = reg, reg
+    Init(t.Context(), cfg)
+    return reg
+}
+
+// gatheredNames возвращает имена всех семейств метрик реестра
+func gatheredNames(t *testing.T, reg *prometheus.Registry) map[string]bool {
+    t.Helper()
+    
+    families, err := reg.Gather()
+    if err != nil {
+        t.Fatalf("gather: %v", err)
+    }
+    names := make(map[string]bool, len(families))
+    for _, family := range families {
+        names[family.GetName()] = true
+    }
+    return names
+}
+
+func TestInit_NamespaceAndSubsystem(t *testing.T) {
+    reg := initTestMetrics(t, MetricsConfig{Namespace: "goapi", Subsystem: "http"})
+    
+    RecordOrder()
+    RecordError("payment", "/api/orders")
+    httpRequestsTotal.WithLabelValues("GET", "/api/health", "200", OutcomeSuccess).Inc()
+    
+    names := gatheredNames(t, reg)
+    for _, want := range []string{"goapi_http_http_requests_total", "goapi_http_orders_processed_total", "goapi_http_errors_total"} {
+        if !names[want] {
+            t.Errorf("metric %s is not registered", want)
+        }
+    }
+    for name := range names {
+        if strings.HasPrefix(name, "http_") || strings.HasPrefix(name, "orders_") {
+            t.Errorf("metric %s has no namespace", name)
+        }
+    }
+}
+
+func TestOutcomeFromStatus(t *testing.T) {
+    tests := []struct {
+        status int
+        want   string
+    }{
+        {status: 200, want: OutcomeSuccess},
+        {status: 204, want: OutcomeSuccess},
+        {status: 304, want: OutcomeSuccess},
+        {status: 399, want: OutcomeSuccess},
+        {status: 400, want: OutcomeClientError},
+        {status: 429, want: OutcomeClientError},
+        {status: 499, want: OutcomeClientError},
+        {status: 500, want: OutcomeServerError},
+        {status: 503, want: OutcomeServerError},
+    }
+    
+    for _, tt := range tests {
+        if got := OutcomeFromStatus(tt.status); got != tt.want {
+            t.Errorf("OutcomeFromStatus(%d) = %q, want %q", tt.status, got, tt.want)
+        }
+    }
+}
+
+func TestResponseWriter_Headers(t *testing.T) {
+    tests := []struct {
+        name      string
+        handle    func(t *testing.T, rw *responseWriter)
+        wantValue string
+        wantWarn  bool
+    }{
+        {
+            name: "set before write",
+            handle: func(t *testing.T, rw *responseWriter) {
+                rw.SetHeader("X-Cache", "HIT")
+                rw.WriteHeader(http.StatusOK)
+            },
+            wantValue: "HIT",
+        },
+        {
+            name: "set after write warns",
+            handle: func(t *testing.T, rw *responseWriter) {
+                rw.Write([]byte("body"))
+                rw.SetHeader("X-Cache", "HIT")
+            },
+            wantWarn: true,
+        },
+        {
+            name: "override before write",
+            handle: func(t *testing.T, rw *responseWriter) {
+                rw.Header().Set("X-Cache", "MISS")
+                rw.SetHeader("X-Cache", "HIT")
+                rw.WriteHeader(http.StatusOK)
+            },
+            wantValue: "HIT",
+        },
+        {
+            name: "header changes after write are discarded",
+            handle: func(t *testing.T, rw *responseWriter) {
+                rw.SetHeader("X-Cache", "MISS")
+                rw.WriteHeader(http.StatusAccepted)
+                rw.Header().Set("X-Cache", "HIT")
+                if got := rw.Header().Get("X-Cache"); got != "MISS" {
+                    t.Errorf("X-Cache after write = %q, want the sent MISS", got)
+                }
+            },
+            wantValue: "MISS",
+        },
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            logger := logging.NewBufferedLogger()
+            logging.SetDefault(logger)
+            defer logging.SetDefault(nil)
+            
+            rec := httptest.NewRecorder()
+            tt.handle(t, &responseWriter{ResponseWriter: rec, statusCode: http.StatusOK})
+            
+            if got := rec.Result().Header.Get("X-Cache"); got != tt.wantValue {
+                t.Errorf("sent X-Cache = %q, want %q", got, tt.wantValue)
+            }
+            
+            warned := false
+            for _, entry := range logger.Entries() {
+                if entry.Level == "WARN" && entry.Message == "Response header set after headers were written" && entry.Fields["header"] == "X-Cache" {
+                    warned = true
+                }
+            }
+            if warned != tt.wantWarn {
+                t.Errorf("late header warning = %v, want %v", warned, tt.wantWarn)
+            }
+        })
+    }
+}
+
+// histogramSeries возвращает серии гистограммы name по значению метки path
+func histogramSeries(t *testing.T, reg *prometheus.Registry, name string) map[string][2]float64 {
+    t.Helper()
+    
+    families, err := reg.Gather()
+    if err != nil {
+        t.Fatalf("gather: %v", err)
+    }
+    series := make(map[string][2]float64)
+    for _, family := range families {
+        if family.GetName() != name {
+            continue
+        }
+        for _, m := range family.GetMetric() {
+            for _, label := range m.GetLabel() {
+                if label.GetName() == "path" {
+                    series[label.GetValue()] = [2]float64{float64(m.GetHistogram().GetSampleCount()), m.GetHistogram().GetSampleSum()}
+                }
+            }
+        }
+    }
+    return series
+}
+
+func TestMetricsMiddleware_ResponseSize(t *testing.T) {
+    dir := t.TempDir()
+    asset := bytes.Repeat([]byte("a"), 3000)
+    if err := os.WriteFile(filepath.Join(dir, "app.js"), asset, 0o600); err != nil {
+        t.Fatal(err)
+    }
+    
+    tests := []struct {
+        name     string
+        path     string
+        wantPath string
+        wantSize float64
+    }{
+        {name: "single write", path: "/hello", wantPath: "/hello", wantSize: 5},
+        {name: "multiple writes", path: "/chunks", wantPath: "/chunks", wantSize: 3 * 1024},
+        {name: "file server", path: "/static/app.js", wantPath: "/static/*", wantSize: float64(len(asset))},
+        {name: "empty body", path: "/empty", wantPath: "/empty", wantSize: 0},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            reg := initTestMetrics(t, MetricsConfig{})
+            
+            r := mux.NewRouter()
+            r.Use(MetricsMiddleware)
+            r.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+                w.Write([]byte("hello"))
+            })
+            r.HandleFunc("/chunks", func(w http.ResponseWriter, r *http.Request) {
+                for i := 0; i < 3; i++ {
+                    w.Write(bytes.Repeat([]byte("x"), 1024))
+                }
+            })
+            r.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+                w.WriteHeader(http.StatusNoContent)
+            })
+            r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir(dir))))
+            
+            rec := httptest.NewRecorder()
+            r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+            
+            got, ok := histogramSeries(t, reg, "http_response_size_bytes")[tt.wantPath]
+            if !ok {
+                t.Fatalf("no http_response_size_bytes series for path %q", tt.wantPath)
+            }
+            if got[0] != 1 || got[1] != tt.wantSize {
+                t.Errorf("count = %v, sum = %v, want 1 sample of %v", got[0], got[1], tt.wantSize)
+            }
+            if float64(rec.Body.Len()) != tt.wantSize {
+                t.Errorf("body is %d bytes, histogram recorded %v", rec.Body.Len(), tt.wantSize)
+            }
+        })
+    }
+}
+
+func TestRecordOrderRevenue(t *testing.T) {
+    reg := initTestMetrics(t, MetricsConfig{})
+    
+    // Отрицательная сумма (возврат) не учитывается
+    for _, amount := range []float64{5, 75, 1200, -10} {
+        RecordOrderRevenue(amount)
+    }
+    
+    expected := `
+# HELP orders_revenue_total Total revenue of processed orders
+# TYPE orders_revenue_total counter
+orders_revenue_total 1280
+# HELP order_value Distribution of processed order values
+# TYPE order_value histogram
+order_value_bucket{le="0"} 0
+order_value_bucket{le="10"} 1
+order_value_bucket{le="50"} 1
+order_value_bucket{le="100"} 2
+order_value_bucket{le="500"} 2
+order_value_bucket{le="1000"} 2
+order_value_bucket{le="5000"} 3
+order_value_bucket{le="+Inf"} 3
+order_value_sum 1280
+order_value_count 3
+`
+    if err := promtest.GatherAndCompare(reg, strings.NewReader(expected), "orders_revenue_total", "order_value"); err != nil {
+        t.Error(err)
+    }
+}
@@ -0,0 +1,45 @@
+package metrics
+
+// Recorder записывает бизнес-метрики в коллекторы пакета. Нулевое значение
+// готово к использованию, его получают обработчики через внедрение зависимостей
+type Recorder struct{}
+
+func (Recorder) RecordOrder() {
+    RecordOrder()
+}
+
+func (Recorder) RecordOrderRevenue(amount float64) {
+    RecordOrderRevenue(amount)
+}
+
+func (Recorder) RecordOrderStatusTransition(from, to string) {
+    RecordOrderStatusTransition(from, to)
+}
+
+func (Recorder) RecordOrderCancelledByClient() {
+    RecordOrderCancelledByClient()
+}
+
+func (Recorder) RecordUserRegistration() {
+    RecordUserRegistration()
+}
+
+func (Recorder) RecordUsersBulkCreated(count int) {
+    RecordUsersBulkCreated(count)
+}
+
+func (Recorder) RecordUserDeletion() {
+    RecordUserDeletion()
+}
+
+func (Recorder) RecordProductView(productID string) {
+    RecordProductView(productID)
+}
+
+func (Recorder) SetProductStock(productID string, level int) {
+    SetProductStock(productID, level)
+}
+
+func (Recorder) RecordError(errorType, endpoint string) {
+    RecordError(errorType, endpoint)
+}
\ No newline at end of file
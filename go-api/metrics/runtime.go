@@ -0,0 +1,97 @@
+package metrics
+
+import (
+    "context"
+    "runtime"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+// runtimeCollector читает состояние рантайма Go при каждом scrape.
+// Имена отличаются от метрик стандартного Go collector, чтобы не конфликтовать с ними
+type runtimeCollector struct {
+    goroutines  *prometheus.Desc
+    heapAlloc   *prometheus.Desc
+    heapObjects *prometheus.Desc
+    gcSys       *prometheus.Desc
+}
+
+func newRuntimeCollector() *runtimeCollector {
+    return &runtimeCollector{
+        goroutines:  prometheus.NewDesc("go_goroutines_custom", "Number of goroutines that currently exist", nil, nil),
+        heapAlloc:   prometheus.NewDesc("go_heap_alloc_bytes", "Bytes of allocated heap objects", nil, nil),
+        heapObjects: prometheus.NewDesc("go_heap_objects", "Number of allocated heap objects", nil, nil),
+        gcSys:       prometheus.NewDesc("go_gc_sys_bytes", "Bytes of memory in garbage collection metadata", nil, nil),
+    }
+}
+
+func (c *runtimeCollector) Describe(ch chan<- *prometheus.Desc) {
+    ch <- c.goroutines
+    ch <- c.heapAlloc
+    ch <- c.heapObjects
+    ch <- c.gcSys
+}
+
+func (c *runtimeCollector) Collect(ch chan<- prometheus.Metric) {
+    var stats runtime.MemStats
+    runtime.ReadMemStats(&stats)
+    
+    ch <- prometheus.MustNewConstMetric(c.goroutines, prometheus.GaugeValue, float64(runtime.NumGoroutine()))
+    ch <- prometheus.MustNewConstMetric(c.heapAlloc, prometheus.GaugeValue, float64(stats.HeapAlloc))
+    ch <- prometheus.MustNewConstMetric(c.heapObjects, prometheus.GaugeValue, float64(stats.HeapObjects))
+    ch <- prometheus.MustNewConstMetric(c.gcSys, prometheus.GaugeValue, float64(stats.GCSys))
+}
+
+// RegisterRuntimeCollector регистрирует метрики горутин и памяти
+func RegisterRuntimeCollector() {
+    prometheus.MustRegister(newRuntimeCollector())
+}
+
+// Паузы GC в наносекундах, метка gen всегда "gc_pause"
+var gcPauses = prometheus.NewHistogramVec(
+    prometheus.HistogramOpts{
+        Name:    "go_gc_pause_ns",
+        Help:    "Duration of garbage collection stop-the-world pauses in nanoseconds",
+        Buckets: prometheus.ExponentialBuckets(10000, 4, 9), // 10µs .. ~650ms
+    },
+    []string{"gen"},
+)
+
+const defaultGCPollInterval = 5 * time.Second
+
+// watchGCPauses раз в interval читает кольцевой буфер MemStats.PauseNs
+// и записывает паузы циклов GC, завершившихся после предыдущего опроса
+func watchGCPauses(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    
+    var stats runtime.MemStats
+    runtime.ReadMemStats(&stats)
+    lastGC := stats.NumGC
+    
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+        
+        runtime.ReadMemStats(&stats)
+        lastGC = observeGCPauses(&stats, lastGC)
+    }
+}
+
+// observeGCPauses записывает паузы с номера lastGC+1 по stats.NumGC.
+// Буфер хранит 256 последних пауз, более старые уже потеряны
+func observeGCPauses(stats *runtime.MemStats, lastGC uint32) uint32 {
+    bufSize := uint32(len(stats.PauseNs))
+    if stats.NumGC-lastGC > bufSize {
+        lastGC = stats.NumGC - bufSize
+    }
+    
+    for gc := lastGC + 1; gc <= stats.NumGC; gc++ {
+        gcPauses.WithLabelValues("gc_pause").Observe(float64(stats.PauseNs[(gc+bufSize-1)%bufSize]))
+    }
+    return stats.NumGC
+}
\ No newline at end of file
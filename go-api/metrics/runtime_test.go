@@ -0,0 +1,122 @@
+package metrics
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "runtime"
+    "testing"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    promtest "github.com/prometheus/client_golang/prometheus/testutil"
+    dto "github.com/prometheus/client_model/go"
+)
+
+func TestRuntimeCollector_GaugesAfterRequest(t *testing.T) {
+    reg := initTestMetrics(t, MetricsConfig{})
+    
+    handler := MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Write([]byte("ok"))
+    }))
+    handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/health", nil))
+    
+    families, err := reg.Gather()
+    if err != nil {
+        t.Fatalf("gather: %v", err)
+    }
+    values := make(map[string]float64)
+    for _, family := range families {
+        for _, m := range family.GetMetric() {
+            values[family.GetName()] = m.GetGauge().GetValue()
+        }
+    }
+    
+    for _, name := range []string{"go_goroutines_custom", "go_heap_alloc_bytes", "go_heap_objects", "go_gc_sys_bytes"} {
+        got, ok := values[name]
+        if !ok {
+            t.Errorf("%s is not registered", name)
+            continue
+        }
+        if got <= 0 {
+            t.Errorf("%s = %v, want a positive value", name, got)
+        }
+    }
+}
+
+// gcPauseCount возвращает число записанных пауз GC
+func gcPauseCount(t *testing.T) uint64 {
+    t.Helper()
+    
+    var m dto.Metric
+    if err := gcPauses.WithLabelValues("gc_pause").(prometheus.Metric).Write(&m); err != nil {
+        t.Fatalf("read go_gc_pause_ns: %v", err)
+    }
+    return m.GetHistogram().GetSampleCount()
+}
+
+func TestWatchGCPauses_RecordsAfterGC(t *testing.T) {
+    initTestMetrics(t, MetricsConfig{GCPollInterval: 10 * time.Millisecond})
+    
+    before := gcPauseCount(t)
+    runtime.GC()
+    
+    deadline := time.Now().Add(time.Second)
+    for gcPauseCount(t) == before {
+        if time.Now().After(deadline) {
+            t.Fatal("go_gc_pause_ns was not updated after runtime.GC")
+        }
+        time.Sleep(5 * time.Millisecond)
+    }
+    if n := promtest.CollectAndCount(gcPauses); n != 1 {
+        t.Errorf("go_gc_pause_ns has %d series, want only gen=gc_pause", n)
+    }
+}
+
+func TestWatchGCPauses_StopsWithContext(t *testing.T) {
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan struct{})
+    go func() {
+        watchGCPauses(ctx, time.Millisecond)
+        close(done)
+    }()
+    
+    cancel()
+    select {
+    case <-done:
+    case <-time.After(time.Second):
+        t.Fatal("watchGCPauses still running after the context was cancelled")
+    }
+}
+
+func TestObserveGCPauses(t *testing.T) {
+    var stats runtime.MemStats
+    for i := range stats.PauseNs {
+        stats.PauseNs[i] = uint64(i+1) * 1000
+    }
+    
+    tests := []struct {
+        name    string
+        numGC   uint32
+        lastGC  uint32
+        wantObs uint64
+    }{
+        {name: "no new cycles", numGC: 10, lastGC: 10, wantObs: 0},
+        {name: "three new cycles", numGC: 10, lastGC: 7, wantObs: 3},
+        {name: "more cycles than the ring buffer", numGC: 1000, lastGC: 1, wantObs: uint64(len(stats.PauseNs))},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            stats.NumGC = tt.numGC
+            before := gcPauseCount(t)
+            
+            if got := observeGCPauses(&stats, tt.lastGC); got != tt.numGC {
+                t.Errorf("observeGCPauses returned %d, want %d", got, tt.numGC)
+            }
+            if got := gcPauseCount(t) - before; got != tt.wantObs {
+                t.Errorf("recorded %d pauses, want %d", got, tt.wantObs)
+            }
+        })
+    }
+}
@@ -0,0 +1,135 @@
+package metrics
+
+import (
+    "context"
+    "sync/atomic"
+    "time"
+
+    "github.com/crazy1997/go-api/logging"
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+// Параметры расчета burn rate по умолчанию. Порог 14.4 - быстрое сжигание
+// по Google SRE Workbook: при нем месячный бюджет 99.9% уходит за двое суток
+const (
+    defaultSLOTarget         = 0.999
+    defaultBurnRateThreshold = 14.4
+    sloSampleInterval        = 30 * time.Second
+    sloWindow                = 5 * time.Minute
+)
+
+// Счетчики для SLO, дублируют суммы errors_total и http_requests_total
+// без обхода всех серий с метками
+var (
+    sloRequests atomic.Int64
+    sloErrors   atomic.Int64
+)
+
+// Скорость расходования бюджета ошибок: доля ошибок за окно,
+// деленная на допустимую долю ошибок (1 - цель SLO)
+var sloBurnRate = prometheus.NewGaugeVec(
+    prometheus.GaugeOpts{
+        Name: "slo_error_budget_burn_rate",
+        Help: "Error budget burn rate: error ratio over the window divided by the allowed error ratio",
+    },
+    []string{"window"},
+)
+
+// sloSample - накопленные счетчики на момент замера
+type sloSample struct {
+    at     time.Time
+    errors int64
+    total  int64
+}
+
+// sloRing - кольцевой буфер замеров за скользящее окно
+type sloRing struct {
+    samples []sloSample
+    next    int
+    count   int
+}
+
+// newSLORing создает буфер на window при замерах раз в interval,
+// с одним дополнительным замером на начало окна
+func newSLORing(window, interval time.Duration) *sloRing {
+    return &sloRing{samples: make([]sloSample, int(window/interval)+1)}
+}
+
+func (r *sloRing) add(s sloSample) {
+    r.samples[r.next] = s
+    r.next = (r.next + 1) % len(r.samples)
+    if r.count < len(r.samples) {
+        r.count++
+    }
+}
+
+// errorRate - доля ошибок между самым старым и последним замером.
+// ok=false, пока замеров меньше двух или за окно не было запросов
+func (r *sloRing) errorRate() (rate float64, ok bool) {
+    if r.count < 2 {
+        return 0, false
+    }
+    
+    newest := r.samples[(r.next-1+len(r.samples))%len(r.samples)]
+    oldest := r.samples[(r.next-r.count+len(r.samples))%len(r.samples)]
+    
+    total := newest.total - oldest.total
+    if total <= 0 {
+        return 0, false
+    }
+    return float64(newest.errors-oldest.errors) / float64(total), true
+}
+
+// burnRate переводит долю ошибок в скорость расходования бюджета
+func burnRate(errorRate, target float64) float64 {
+    budget := 1 - target
+    if budget <= 0 {
+        return 0
+    }
+    return errorRate / budget
+}
+
+// watchSLOBurnRate раз в sloSampleInterval обновляет burn rate за sloWindow
+// и пишет WARN, пока он выше threshold
+func watchSLOBurnRate(ctx context.Context, target, threshold float64) {
+    ticker := time.NewTicker(sloSampleInterval)
+    defer ticker.Stop()
+    
+    sloBurnRate.WithLabelValues("5m").Set(0)
+    ring := newSLORing(sloWindow, sloSampleInterval)
+    ring.add(sloSample{at: time.Now(), errors: sloErrors.Load(), total: sloRequests.Load()})
+    
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case now := <-ticker.C:
+            ring.add(sloSample{at: now, errors: sloErrors.Load(), total: sloRequests.Load()})
+        }
+        
+        updateSLOBurnRate(ring, target, threshold)
+    }
+}
+
+// updateSLOBurnRate выставляет burn rate по замерам ring
+// и пишет WARN, если он выше threshold
+func updateSLOBurnRate(ring *sloRing, target, threshold float64) {
+    errorRate, ok := ring.errorRate()
+    if !ok {
+        sloBurnRate.WithLabelValues("5m").Set(0)
+        return
+    }
+    
+    rate := burnRate(errorRate, target)
+    sloBurnRate.WithLabelValues("5m").Set(rate)
+    
+    if rate > threshold {
+        logging.Warn("SLO error budget burn rate above threshold", map[string]interface{}{
+            "window":     "5m",
+            "burn_rate":  rate,
+            "threshold":  threshold,
+            "error_rate": errorRate,
+            "slo_target": target,
+        })
+    }
+}
\ No newline at end of file
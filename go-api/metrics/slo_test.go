@@ -0,0 +1,148 @@
+package metrics
+
+import (
+    "math"
+    "testing"
+    "time"
+    
+    "github.com/crazy1997/go-api/logging"
+    promtest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// newTestSLORing заполняет буфер на 5 минут замерами раз в 30 с,
+// samples - пары (ошибки, запросы) накопленных счетчиков
+func newTestSLORing(samples ...[2]int64) *sloRing {
+    ring := newSLORing(sloWindow, sloSampleInterval)
+    start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+    for i, s := range samples {
+        ring.add(sloSample{at: start.Add(time.Duration(i) * sloSampleInterval), errors: s[0], total: s[1]})
+    }
+    return ring
+}
+
+func TestSLORing_ErrorRate(t *testing.T) {
+    // Окно целиком: 11 замеров, 1% ошибок
+    fullWindow := make([][2]int64, 11)
+    for i := range fullWindow {
+        fullWindow[i] = [2]int64{int64(10 * i), int64(1000 * i)}
+    }
+    
+    // 15 замеров: первые 4 интервала с 50% ошибок выходят из окна
+    wrapped := make([][2]int64, 15)
+    for i := range wrapped {
+        if i <= 4 {
+            wrapped[i] = [2]int64{int64(500 * i), int64(1000 * i)}
+        } else {
+            wrapped[i] = [2]int64{int64(2000 + 10*(i-4)), int64(1000 * i)}
+        }
+    }
+    
+    tests := []struct {
+        name    string
+        samples [][2]int64
+        want    float64
+        wantOK  bool
+    }{
+        {name: "empty"},
+        {name: "single sample", samples: [][2]int64{{0, 0}}},
+        {name: "no requests in window", samples: [][2]int64{{5, 100}, {5, 100}}},
+        {name: "1% errors", samples: [][2]int64{{0, 0}, {10, 1000}}, want: 0.01, wantOK: true},
+        {name: "counters before window ignored", samples: [][2]int64{{50, 100}, {60, 1100}}, want: 0.01, wantOK: true},
+        {name: "all errors", samples: [][2]int64{{0, 0}, {40, 60}, {100, 100}}, want: 1, wantOK: true},
+        {name: "no errors", samples: [][2]int64{{7, 100}, {7, 200}, {7, 300}}, want: 0, wantOK: true},
+        {name: "full window", samples: fullWindow, want: 0.01, wantOK: true},
+        {name: "oldest samples overwritten", samples: wrapped, want: 0.01, wantOK: true},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            ring := newTestSLORing(tt.samples...)
+            
+            got, ok := ring.errorRate()
+            if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+                t.Errorf("errorRate() = %v %v, want %v %v", got, ok, tt.want, tt.wantOK)
+            }
+        })
+    }
+}
+
+func TestSLORing_Capacity(t *testing.T) {
+    ring := newSLORing(sloWindow, sloSampleInterval)
+    if got := len(ring.samples); got != 11 {
+        t.Fatalf("capacity = %d, want 10 intervals plus the window start", got)
+    }
+    
+    for i := 0; i < 25; i++ {
+        ring.add(sloSample{total: int64(i)})
+    }
+    if ring.count != 11 {
+        t.Errorf("count = %d, want 11", ring.count)
+    }
+}
+
+func TestBurnRate(t *testing.T) {
+    tests := []struct {
+        name      string
+        errorRate float64
+        target    float64
+        want      float64
+    }{
+        {name: "within budget", errorRate: 0.0005, target: 0.999, want: 0.5},
+        {name: "exactly the budget", errorRate: 0.001, target: 0.999, want: 1},
+        {name: "fast burn threshold", errorRate: 0.0144, target: 0.999, want: 14.4},
+        {name: "no errors", errorRate: 0, target: 0.999, want: 0},
+        {name: "lower target", errorRate: 0.5, target: 0.9, want: 5},
+        {name: "target without budget", errorRate: 0.5, target: 1, want: 0},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := burnRate(tt.errorRate, tt.target); math.Abs(got-tt.want) > 1e-9 {
+                t.Errorf("burnRate(%v, %v) = %v, want %v", tt.errorRate, tt.target, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestUpdateSLOBurnRate(t *testing.T) {
+    tests := []struct {
+        name     string
+        samples  [][2]int64
+        want     float64
+        wantWarn bool
+    }{
+        {name: "above threshold", samples: [][2]int64{{0, 0}, {20, 1000}}, want: 20, wantWarn: true},
+        {name: "below threshold", samples: [][2]int64{{0, 0}, {10, 1000}}, want: 10},
+        {name: "at threshold", samples: [][2]int64{{0, 0}, {144, 10000}}, want: 14.4},
+        {name: "not enough samples", samples: [][2]int64{{20, 1000}}, want: 0},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            logger := logging.NewBufferedLogger()
+            logging.SetDefault(logger)
+            defer logging.SetDefault(nil)
+            
+            // Прежнее значение не должно оставаться в gauge
+            sloBurnRate.WithLabelValues("5m").Set(99)
+            updateSLOBurnRate(newTestSLORing(tt.samples...), defaultSLOTarget, defaultBurnRateThreshold)
+            
+            if got := promtest.ToFloat64(sloBurnRate.WithLabelValues("5m")); math.Abs(got-tt.want) > 1e-9 {
+                t.Errorf("slo_error_budget_burn_rate{window=\"5m\"} = %v, want %v", got, tt.want)
+            }
+            
+            warned := false
+            for _, entry := range logger.Entries() {
+                if entry.Level == "WARN" && entry.Message == "SLO error budget burn rate above threshold" {
+                    warned = true
+                    if entry.Fields["window"] != "5m" || entry.Fields["threshold"] != defaultBurnRateThreshold {
+                        t.Errorf("warning fields = %v", entry.Fields)
+                    }
+                }
+            }
+            if warned != tt.wantWarn {
+                t.Errorf("warning logged = %v, want %v", warned, tt.wantWarn)
+            }
+        })
+    }
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+    "math"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+// Время запуска для uptime_seconds, задается в Init
+var startTime = time.Now()
+
+// newUptimeGauge создает uptime_seconds, вычисляемый при каждом scrape
+func newUptimeGauge(cfg MetricsConfig) prometheus.GaugeFunc {
+    return prometheus.NewGaugeFunc(
+        prometheus.GaugeOpts{
+            Namespace: cfg.Namespace,
+            Subsystem: cfg.Subsystem,
+            Name:      "uptime_seconds",
+            Help:      "Time since application start in seconds",
+        },
+        func() float64 {
+            return time.Since(startTime).Seconds()
+        },
+    )
+}
+
+// Summary - сводка основных метрик приложения для /api/metrics/info
+type Summary struct {
+    OrdersTotal   float64 `json:"orders_total"`
+    RequestsTotal float64 `json:"requests_total"`
+    ErrorsTotal   float64 `json:"errors_total"`
+    ErrorRatePct  float64 `json:"error_rate_pct"`
+    UptimeSeconds float64 `json:"uptime_seconds"`
+}
+
+// GetSummary собирает сводку из gatherer. Значения счетчиков суммируются
+// по всем сериям, имена ищутся с префиксом из MetricsConfig
+func GetSummary(gatherer prometheus.Gatherer) (Summary, error) {
+    families, err := gatherer.Gather()
+    if err != nil {
+        return Summary{}, err
+    }
+    
+    totals := make(map[string]float64, len(families))
+    for _, family := range families {
+        for _, metric := range family.GetMetric() {
+            switch {
+            case metric.GetCounter() != nil:
+                totals[family.GetName()] += metric.GetCounter().GetValue()
+            case metric.GetGauge() != nil:
+                totals[family.GetName()] += metric.GetGauge().GetValue()
+            }
+        }
+    }
+    
+    name := func(short string) string {
+        return prometheus.BuildFQName(config.Namespace, config.Subsystem, short)
+    }
+    
+    summary := Summary{
+        OrdersTotal:   totals[name("orders_processed_total")],
+        RequestsTotal: totals[name("http_requests_total")],
+        ErrorsTotal:   totals[name("errors_total")],
+        UptimeSeconds: math.Round(time.Since(startTime).Seconds()),
+    }
+    if summary.RequestsTotal > 0 {
+        rate := summary.ErrorsTotal / summary.RequestsTotal * 100
+        summary.ErrorRatePct = math.Round(rate*100) / 100
+    }
+    
+    return summary, nil
+}
\ No newline at end of file
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+    "testing"
+)
+
+func TestGetSummary(t *testing.T) {
+    tests := []struct {
+        name        string
+        cfg         MetricsConfig
+        requests    int
+        errors      int
+        orders      int
+        wantRatePct float64
+    }{
+        {name: "no traffic", wantRatePct: 0},
+        {name: "error rate", requests: 200, errors: 3, orders: 42, wantRatePct: 1.5},
+        {name: "rounded to hundredths", requests: 3, errors: 1, wantRatePct: 33.33},
+        {name: "namespaced metrics", cfg: MetricsConfig{Namespace: "goapi"}, requests: 50, errors: 5, orders: 1, wantRatePct: 10},
+    }
+    
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            reg := initTestMetrics(t, tt.cfg)
+            
+            // Счетчики запросов суммируются по всем сериям
+            for i := 0; i < tt.requests; i++ {
+                path := "/api/users"
+                if i%2 == 1 {
+                    path = "/api/orders"
+                }
+                httpRequestsTotal.WithLabelValues("GET", path, "200", OutcomeSuccess).Inc()
+            }
+            for i := 0; i < tt.errors; i++ {
+                RecordError("database", "/api/users")
+            }
+            for i := 0; i < tt.orders; i++ {
+                RecordOrder()
+            }
+            
+            summary, err := GetSummary(reg)
+            if err != nil {
+                t.Fatalf("GetSummary: %v", err)
+            }
+            if summary.RequestsTotal != float64(tt.requests) || summary.ErrorsTotal != float64(tt.errors) || summary.OrdersTotal != float64(tt.orders) {
+                t.Errorf("summary = %+v, want %d requests, %d errors, %d orders", summary, tt.requests, tt.errors, tt.orders)
+            }
+            if summary.ErrorRatePct != tt.wantRatePct {
+                t.Errorf("error_rate_pct = %v, want %v", summary.ErrorRatePct, tt.wantRatePct)
+            }
+            if summary.UptimeSeconds < 0 {
+                t.Errorf("uptime_seconds = %v, want non-negative", summary.UptimeSeconds)
+            }
+        })
+    }
+}
+
+func TestUptimeGauge(t *testing.T) {
+    reg := initTestMetrics(t, MetricsConfig{})
+    
+    if !gatheredNames(t, reg)["uptime_seconds"] {
+        t.Error("uptime_seconds is not registered")
+    }
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/metrics"
+)
+
+// statusWriter запоминает код ответа
+type statusWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.statusCode = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// AccessLogMiddleware пишет запись о каждом обработанном запросе
+func AccessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		sw := &statusWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		logging.FromContext(r.Context()).Info("HTTP request", map[string]interface{}{
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"status":      sw.statusCode,
+			"outcome":     metrics.OutcomeFromStatus(sw.statusCode),
+			"duration_ms": time.Since(start).Milliseconds(),
+			"client_ip":   r.RemoteAddr,
+			"user_agent":  r.UserAgent(),
+		})
+	})
+}
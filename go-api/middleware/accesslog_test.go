@@ -0,0 +1,65 @@
+package middleware_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/crazy1997/go-api/router"
+	"github.com/crazy1997/go-api/testutil"
+	"github.com/gorilla/mux"
+)
+
+func TestAccessLog_Outcome(t *testing.T) {
+	opts := router.Options{}
+	opts.Routes = func(r *mux.Router) {
+		r.HandleFunc("/test/outcome/{status}", func(w http.ResponseWriter, r *http.Request) {
+			switch mux.Vars(r)["status"] {
+			case "not-found":
+				w.WriteHeader(http.StatusNotFound)
+			case "unavailable":
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		})
+	}
+	srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{Router: opts})
+
+	tests := []struct {
+		path        string
+		wantStatus  int
+		wantOutcome string
+	}{
+		{path: "/test/outcome/ok", wantStatus: http.StatusOK, wantOutcome: "success"},
+		{path: "/test/outcome/not-found", wantStatus: http.StatusNotFound, wantOutcome: "client_error"},
+		{path: "/test/outcome/unavailable", wantStatus: http.StatusServiceUnavailable, wantOutcome: "server_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantOutcome, func(t *testing.T) {
+			labels := map[string]string{"path": "/test/outcome/{status}", "outcome": tt.wantOutcome}
+			before := testutil.MetricValue(t, "http_requests_total", labels)
+			srv.Logger().Reset()
+
+			resp, err := srv.Client().Get(srv.URL + tt.path)
+			if err != nil {
+				t.Fatalf("GET %s: %v", tt.path, err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			var outcome interface{}
+			for _, entry := range srv.Logger().Entries() {
+				if entry.Message == "HTTP request" && entry.Fields["path"] == tt.path {
+					outcome = entry.Fields["outcome"]
+				}
+			}
+			if outcome != tt.wantOutcome {
+				t.Errorf("access log outcome = %v, want %s", outcome, tt.wantOutcome)
+			}
+			if got := testutil.MetricValue(t, "http_requests_total", labels) - before; got != 1 {
+				t.Errorf("http_requests_total{outcome=%s} increased by %v, want 1", tt.wantOutcome, got)
+			}
+		})
+	}
+}
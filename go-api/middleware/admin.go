@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/gorilla/mux"
+)
+
+// AdminToken пропускает только запросы с заголовком X-Admin-Token, равным token.
+// Пустой token закрывает доступ полностью
+func AdminToken(token string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided := r.Header.Get("X-Admin-Token")
+			if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				logging.Warn("Admin request rejected", map[string]interface{}{
+					"path":      r.URL.Path,
+					"client_ip": r.RemoteAddr,
+				})
+
+				handlers.WriteError(w, http.StatusForbidden, handlers.ErrTypeForbidden, "forbidden")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/router"
+	"github.com/crazy1997/go-api/testutil"
+)
+
+func TestAdminLogLevel(t *testing.T) {
+	srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{
+		Router: router.Options{AdminToken: "admin-secret", AdminAllowedCIDRs: []string{"127.0.0.0/8", "::1/128"}},
+	})
+
+	logger := logging.GetLogger()
+	initial := logger.Level()
+	t.Cleanup(func() { logger.SetLevel(initial) })
+
+	tests := []struct {
+		name       string
+		token      string
+		body       string
+		wantStatus int
+		wantType   string
+		wantLevel  string
+	}{
+		{name: "missing token", body: `{"level":"DEBUG"}`, wantStatus: http.StatusForbidden, wantType: "forbidden", wantLevel: initial},
+		{name: "wrong token", token: "guess", body: `{"level":"DEBUG"}`, wantStatus: http.StatusForbidden, wantType: "forbidden", wantLevel: initial},
+		{name: "unknown level", token: "admin-secret", body: `{"level":"LOUD"}`, wantStatus: http.StatusBadRequest, wantType: "validation_error", wantLevel: initial},
+		{name: "invalid JSON", token: "admin-secret", body: `{"level":`, wantStatus: http.StatusBadRequest, wantType: "invalid_json", wantLevel: initial},
+		{name: "raise to ERROR", token: "admin-secret", body: `{"level":"error"}`, wantStatus: http.StatusOK, wantLevel: "ERROR"},
+		{name: "lower to DEBUG", token: "admin-secret", body: `{"level":"DEBUG"}`, wantStatus: http.StatusOK, wantLevel: "DEBUG"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := logger.Level()
+
+			req, _ := http.NewRequest(http.MethodPut, srv.URL+"/admin/loglevel", strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("X-Admin-Token", tt.token)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("PUT /admin/loglevel: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if tt.wantType != "" {
+				assertJSONError(t, resp, tt.wantStatus, tt.wantType)
+			} else {
+				var body struct {
+					Level    string `json:"level"`
+					Previous string `json:"previous"`
+				}
+				if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+					t.Fatalf("decode body: %v", err)
+				}
+				if resp.StatusCode != tt.wantStatus || body.Level != tt.wantLevel || body.Previous != previous {
+					t.Errorf("status = %d, body = %+v, want %d with level %s and previous %s", resp.StatusCode, body, tt.wantStatus, tt.wantLevel, previous)
+				}
+			}
+
+			if got := logger.Level(); got != tt.wantLevel {
+				t.Errorf("logger level = %s, want %s", got, tt.wantLevel)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+
+	"github.com/crazy1997/go-api/metrics"
+	"github.com/gorilla/mux"
+)
+
+// APIKeyHeader - заголовок запроса с API ключом
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyStore находит имя интеграции по API ключу
+type APIKeyStore interface {
+	Lookup(key string) (name string, ok bool)
+}
+
+// MemoryAPIKeyStore - ключи в памяти. Хранятся хеши ключей, поэтому
+// время поиска не зависит от совпадения префикса ключа
+type MemoryAPIKeyStore struct {
+	names map[[sha256.Size]byte]string
+}
+
+// NewMemoryAPIKeyStore создает хранилище из пар ключ -> имя,
+// например из API_KEYS (см. config)
+func NewMemoryAPIKeyStore(keys map[string]string) *MemoryAPIKeyStore {
+	s := &MemoryAPIKeyStore{names: make(map[[sha256.Size]byte]string, len(keys))}
+	for key, name := range keys {
+		s.names[sha256.Sum256([]byte(key))] = name
+	}
+	return s
+}
+
+func (s *MemoryAPIKeyStore) Lookup(key string) (string, bool) {
+	name, ok := s.names[sha256.Sum256([]byte(key))]
+	return name, ok
+}
+
+type apiKeyNameKey struct{}
+
+// APIKeyNameFromContext возвращает имя ключа, сохраненное APIKeyAuth
+func APIKeyNameFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(apiKeyNameKey{}).(string)
+	return name, ok
+}
+
+// APIKeyAuth пропускает только запросы с известным ключом в X-API-Key.
+// Имя ключа сохраняется в контексте, без ключа или с неизвестным - 401
+func APIKeyAuth(store APIKeyStore) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" {
+				metrics.RecordAPIKeyAuthFailure()
+				rejectUnauthorized(w, r, errors.New("missing API key"))
+				return
+			}
+
+			name, ok := store.Lookup(key)
+			if !ok {
+				metrics.RecordAPIKeyAuthFailure()
+				rejectUnauthorized(w, r, errors.New("invalid API key"))
+				return
+			}
+
+			metrics.RecordAPIKeyAuthSuccess(name)
+			ctx := context.WithValue(r.Context(), apiKeyNameKey{}, name)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// APIKeyOr проверяет запросы с X-API-Key через APIKeyAuth, остальные -
+// через fallback (например JWTAuth). Интеграции без поддержки JWT
+// авторизуются ключом на тех же маршрутах. nil store оставляет только fallback
+func APIKeyOr(store APIKeyStore, fallback mux.MiddlewareFunc) mux.MiddlewareFunc {
+	if store == nil {
+		return fallback
+	}
+
+	apiKey := APIKeyAuth(store)
+	return func(next http.Handler) http.Handler {
+		viaKey, viaFallback := apiKey(next), fallback(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(APIKeyHeader) != "" {
+				viaKey.ServeHTTP(w, r)
+				return
+			}
+			viaFallback.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/router"
+	"github.com/crazy1997/go-api/testutil"
+)
+
+var testAPIKeys = map[string]string{
+	"key-billing-0001": "billing",
+	"key-reports-0002": "reports",
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	testutil.NewTestServer(t)
+	store := middleware.NewMemoryAPIKeyStore(testAPIKeys)
+
+	tests := []struct {
+		name       string
+		key        string
+		wantStatus int
+		wantName   string
+	}{
+		{name: "missing key", wantStatus: http.StatusUnauthorized},
+		{name: "invalid key", key: "key-unknown", wantStatus: http.StatusUnauthorized},
+		{name: "key prefix", key: "key-billing", wantStatus: http.StatusUnauthorized},
+		{name: "key name instead of key", key: "billing", wantStatus: http.StatusUnauthorized},
+		{name: "valid key", key: "key-billing-0001", wantStatus: http.StatusOK, wantName: "billing"},
+		{name: "second valid key", key: "key-reports-0002", wantStatus: http.StatusOK, wantName: "reports"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failuresBefore := testutil.MetricValue(t, "api_key_auth_failures_total", nil)
+			successBefore := testutil.MetricValue(t, "api_key_auth_success_total", nil)
+
+			var gotName string
+			reached := false
+			handler := middleware.APIKeyAuth(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reached = true
+				gotName, _ = middleware.APIKeyNameFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+			if tt.key != "" {
+				req.Header.Set(middleware.APIKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			wantFailures, wantSuccess := 1.0, 0.0
+			if tt.wantStatus == http.StatusOK {
+				wantFailures, wantSuccess = 0, 1
+				if rec.Code != http.StatusOK || !reached {
+					t.Fatalf("status = %d, handler reached = %v, want 200", rec.Code, reached)
+				}
+				if gotName != tt.wantName {
+					t.Errorf("key name in context = %q, want %q", gotName, tt.wantName)
+				}
+				if got := testutil.MetricValue(t, "api_key_auth_success_total", map[string]string{"key_name": tt.wantName}); got < 1 {
+					t.Errorf("api_key_auth_success_total{key_name=%q} = %v, want at least 1", tt.wantName, got)
+				}
+			} else {
+				assertJSONError(t, rec.Result(), tt.wantStatus, "unauthorized")
+				if reached {
+					t.Error("handler reached without a valid key")
+				}
+			}
+
+			if got := testutil.MetricValue(t, "api_key_auth_failures_total", nil) - failuresBefore; got != wantFailures {
+				t.Errorf("api_key_auth_failures_total increased by %v, want %v", got, wantFailures)
+			}
+			if got := testutil.MetricValue(t, "api_key_auth_success_total", nil) - successBefore; got != wantSuccess {
+				t.Errorf("api_key_auth_success_total increased by %v, want %v", got, wantSuccess)
+			}
+		})
+	}
+}
+
+func TestAPIKeyOr(t *testing.T) {
+	testutil.NewTestServer(t)
+	token := "Bearer " + signJWT(t, "HS256", testJWTSecret, map[string]interface{}{"sub": "42", "exp": time.Now().Add(time.Hour).Unix()})
+
+	tests := []struct {
+		name       string
+		store      middleware.APIKeyStore
+		key        string
+		auth       string
+		wantStatus int
+	}{
+		{name: "valid key", store: middleware.NewMemoryAPIKeyStore(testAPIKeys), key: "key-billing-0001", wantStatus: http.StatusOK},
+		{name: "invalid key with valid token", store: middleware.NewMemoryAPIKeyStore(testAPIKeys), key: "key-unknown", auth: token, wantStatus: http.StatusUnauthorized},
+		{name: "token without key", store: middleware.NewMemoryAPIKeyStore(testAPIKeys), auth: token, wantStatus: http.StatusOK},
+		{name: "neither key nor token", store: middleware.NewMemoryAPIKeyStore(testAPIKeys), wantStatus: http.StatusUnauthorized},
+		{name: "no store ignores key", key: "key-billing-0001", wantStatus: http.StatusUnauthorized},
+		{name: "no store accepts token", auth: token, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.APIKeyOr(tt.store, middleware.JWTAuth(testJWTSecret))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+			if tt.key != "" {
+				req.Header.Set(middleware.APIKeyHeader, tt.key)
+			}
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuth_ProtectedRoutes(t *testing.T) {
+	srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{
+		Router: router.Options{
+			Handler:   handlers.New(handlers.Config{Logger: logging.NoopLogger{}, Rand: constRand(100)}),
+			JWTSecret: testJWTSecret,
+			APIKeys:   middleware.NewMemoryAPIKeyStore(testAPIKeys),
+		},
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		key    string
+		status int
+	}{
+		{name: "valid key", path: "/v1/users", key: "key-reports-0002", status: http.StatusOK},
+		{name: "valid key on legacy path", path: "/api/products", key: "key-reports-0002", status: http.StatusOK},
+		{name: "invalid key", path: "/v1/users", key: "key-unknown", status: http.StatusUnauthorized},
+		{name: "missing key", path: "/v1/users", status: http.StatusUnauthorized},
+		{name: "health needs no key", path: "/api/health", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set(middleware.APIKeyHeader, tt.key)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+// Без JWT_SECRET запрос без заголовка X-API-Key не должен обходить проверку ключа
+func TestAPIKeyAuth_WithoutJWTSecret(t *testing.T) {
+	srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{
+		Router: router.Options{
+			Handler: handlers.New(handlers.Config{Logger: logging.NoopLogger{}, Rand: constRand(100)}),
+			APIKeys: middleware.NewMemoryAPIKeyStore(testAPIKeys),
+		},
+	})
+
+	tests := []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{name: "no credentials", status: http.StatusUnauthorized},
+		{name: "valid key", key: "key-reports-0002", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/v1/users", nil)
+			if tt.key != "" {
+				req.Header.Set(middleware.APIKeyHeader, tt.key)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+
+			defer resp.Body.Close()
+
+			if tt.status == http.StatusUnauthorized {
+				assertJSONError(t, resp, tt.status, "unauthorized")
+				return
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// APIVersionHeader - заголовок ответа с версией API
+const APIVersionHeader = "X-API-Version"
+
+// APIVersion выставляет X-API-Version во всех ответах маршрутов версии
+func APIVersion(version string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(APIVersionHeader, version)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
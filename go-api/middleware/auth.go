@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+)
+
+// Claims - данные пользователя из проверенного JWT
+type Claims struct {
+	Subject string
+	Roles   []string
+}
+
+type claimsKey struct{}
+
+// ClaimsFromContext возвращает claims, сохраненные JWTAuth
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(Claims)
+	return claims, ok
+}
+
+// JWTAuth пропускает только запросы с валидным токеном HS256 в заголовке
+// Authorization: Bearer <token>. Claims sub и roles сохраняются в контексте.
+// Claim exp обязателен. С пустым secret отклоняется любой токен
+func JWTAuth(secret string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				rejectUnauthorized(w, r, errors.New("missing bearer token"))
+				return
+			}
+
+			claims, err := parseJWT(strings.TrimSpace(token), secret)
+			if err != nil {
+				rejectUnauthorized(w, r, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), claimsKey{}, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func rejectUnauthorized(w http.ResponseWriter, r *http.Request, reason error) {
+	logging.FromContext(r.Context()).Warn("Unauthorized request rejected", map[string]interface{}{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"reason": reason.Error(),
+	})
+
+	w.Header().Set("WWW-Authenticate", `Bearer realm="go-api"`)
+	handlers.WriteError(w, http.StatusUnauthorized, handlers.ErrTypeUnauthorized, "unauthorized")
+}
+
+// jwtClaims - claims токена. sub допускаем и строкой, и числом,
+// поэтому он разбирается отдельно от RegisteredClaims.Subject
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	Sub   json.RawMessage `json:"sub"`
+	Roles []string        `json:"roles"`
+}
+
+// parseJWT проверяет подпись HS256 и сроки действия токена
+func parseJWT(token, secret string) (Claims, error) {
+	var claims jwtClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		// Подпись пустым ключом может подделать кто угодно
+		if secret == "" {
+			return nil, errors.New("JWT secret is not configured")
+		}
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithExpirationRequired())
+	if err != nil {
+		return Claims{}, err
+	}
+
+	subject := strings.Trim(string(claims.Sub), `"`)
+	if subject == "" || subject == "null" {
+		return Claims{}, errors.New("missing sub claim")
+	}
+
+	return Claims{Subject: subject, Roles: claims.Roles}, nil
+}
@@ -0,0 +1,185 @@
+package middleware_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/router"
+	"github.com/crazy1997/go-api/testutil"
+)
+
+const testJWTSecret = "test-secret"
+
+// signJWT собирает токен с заголовком alg и подписью HS256 ключом secret
+func signJWT(t *testing.T, alg, secret string, claims map[string]interface{}) string {
+	t.Helper()
+
+	segment := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("marshal token segment: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	unsigned := segment(map[string]string{"alg": alg, "typ": "JWT"}) + "." + segment(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestJWTAuth(t *testing.T) {
+	testutil.NewTestServer(t)
+
+	now := time.Now()
+	valid := map[string]interface{}{"sub": "42", "roles": []string{"admin", "ops"}, "exp": now.Add(time.Hour).Unix()}
+
+	tests := []struct {
+		name        string
+		header      string
+		wantStatus  int
+		wantSubject string
+		wantRoles   []string
+	}{
+		{name: "missing header", wantStatus: http.StatusUnauthorized},
+		{name: "not a bearer token", header: "Basic dXNlcjpwYXNz", wantStatus: http.StatusUnauthorized},
+		{name: "malformed token", header: "Bearer abc.def", wantStatus: http.StatusUnauthorized},
+		{
+			name:       "expired token",
+			header:     "Bearer " + signJWT(t, "HS256", testJWTSecret, map[string]interface{}{"sub": "42", "exp": now.Add(-time.Minute).Unix()}),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "token without exp",
+			header:     "Bearer " + signJWT(t, "HS256", testJWTSecret, map[string]interface{}{"sub": "42"}),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "not valid yet",
+			header:     "Bearer " + signJWT(t, "HS256", testJWTSecret, map[string]interface{}{"sub": "42", "exp": now.Add(time.Hour).Unix(), "nbf": now.Add(time.Minute).Unix()}),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid signature",
+			header:     "Bearer " + signJWT(t, "HS256", "other-secret", valid),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "unexpected algorithm",
+			header:     "Bearer " + signJWT(t, "none", testJWTSecret, valid),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing sub",
+			header:     "Bearer " + signJWT(t, "HS256", testJWTSecret, map[string]interface{}{"exp": now.Add(time.Hour).Unix()}),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:        "valid token",
+			header:      "Bearer " + signJWT(t, "HS256", testJWTSecret, valid),
+			wantStatus:  http.StatusOK,
+			wantSubject: "42",
+			wantRoles:   []string{"admin", "ops"},
+		},
+		{
+			name:        "numeric sub",
+			header:      "Bearer " + signJWT(t, "HS256", testJWTSecret, map[string]interface{}{"sub": 7, "exp": now.Add(time.Hour).Unix()}),
+			wantStatus:  http.StatusOK,
+			wantSubject: "7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claims middleware.Claims
+			handler := middleware.JWTAuth(testJWTSecret)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				claims, _ = middleware.ClaimsFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if tt.wantStatus != http.StatusOK {
+				assertJSONError(t, rec.Result(), tt.wantStatus, "unauthorized")
+				if !strings.HasPrefix(rec.Header().Get("WWW-Authenticate"), "Bearer") {
+					t.Errorf("WWW-Authenticate = %q, want a Bearer challenge", rec.Header().Get("WWW-Authenticate"))
+				}
+				return
+			}
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			if claims.Subject != tt.wantSubject || strings.Join(claims.Roles, ",") != strings.Join(tt.wantRoles, ",") {
+				t.Errorf("claims = %+v, want sub %s roles %v", claims, tt.wantSubject, tt.wantRoles)
+			}
+		})
+	}
+}
+
+// Токен, подписанный пустым ключом, может выпустить кто угодно
+func TestJWTAuth_EmptySecret(t *testing.T) {
+	testutil.NewTestServer(t)
+
+	called := false
+	handler := middleware.JWTAuth("")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	claims := map[string]interface{}{"sub": "42", "exp": time.Now().Add(time.Hour).Unix()}
+	for _, header := range []string{"", "Bearer " + signJWT(t, "HS256", "", claims)} {
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assertJSONError(t, rec.Result(), http.StatusUnauthorized, "unauthorized")
+	}
+	if called {
+		t.Error("handler called with an empty JWT secret")
+	}
+}
+
+func TestJWTAuth_ProtectedRoutes(t *testing.T) {
+	srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{
+		Router: router.Options{JWTSecret: testJWTSecret},
+	})
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/api/users", http.StatusUnauthorized},
+		{"/v1/users", http.StatusUnauthorized},
+		{"/api/products", http.StatusUnauthorized},
+		{"/api/orders/1", http.StatusUnauthorized},
+		{"/api/health", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := srv.Client().Get(srv.URL + tt.path)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
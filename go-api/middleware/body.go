@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/gorilla/mux"
+)
+
+// MaxBodyBytes ограничивает размер тела запроса limit байтами.
+// Запросы с заведомо большим Content-Length отклоняются сразу с 413,
+// тело без длины (chunked) обрезается http.MaxBytesReader, и чтение сверх
+// лимита возвращает *http.MaxBytesError. Значение limit <= 0 отключает проверку
+func MaxBodyBytes(limit int64) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				logging.FromContext(r.Context()).Warn("Request body too large", map[string]interface{}{
+					"method":         r.Method,
+					"path":           r.URL.Path,
+					"content_length": r.ContentLength,
+					"limit":          limit,
+				})
+
+				// Остаток тела не вычитываем, поэтому соединение закрываем
+				w.Header().Set("Connection", "close")
+				handlers.WriteError(w, http.StatusRequestEntityTooLarge, handlers.ErrTypeBodyTooLarge, "request body too large")
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
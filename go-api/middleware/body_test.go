@@ -0,0 +1,190 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/router"
+	"github.com/crazy1997/go-api/testutil"
+	"github.com/gorilla/mux"
+)
+
+// assertJSONError проверяет статус и тело ошибки {"error": {"type", "message", "code"}}
+func assertJSONError(t *testing.T, resp *http.Response, status int, errType string) {
+	t.Helper()
+
+	if resp.StatusCode != status {
+		t.Errorf("status = %d, want %d", resp.StatusCode, status)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+
+	var body struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+			Code    int    `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode error body: %v", err)
+	}
+	if body.Error.Type != errType || body.Error.Message == "" || body.Error.Code == 0 {
+		t.Errorf("error = %+v, want type %s with message and code", body.Error, errType)
+	}
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	const limit = 16
+
+	srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{
+		Router: router.Options{
+			MaxBodyBytes: limit,
+			Routes: func(r *mux.Router) {
+				r.HandleFunc("/test/echo", func(w http.ResponseWriter, r *http.Request) {
+					body, err := io.ReadAll(r.Body)
+					if err != nil {
+						w.WriteHeader(http.StatusRequestEntityTooLarge)
+						return
+					}
+					w.Write(body)
+				}).Methods(http.MethodPost)
+			},
+		},
+	})
+
+	tests := []struct {
+		name   string
+		size   int
+		status int
+	}{
+		{"below limit", limit - 1, http.StatusOK},
+		{"exactly at limit", limit, http.StatusOK},
+		{"one byte over", limit + 1, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.Client().Post(srv.URL+"/test/echo", "text/plain", strings.NewReader(strings.Repeat("a", tt.size)))
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if tt.status != http.StatusOK {
+				assertJSONError(t, resp, tt.status, "body_too_large")
+				if !resp.Close {
+					t.Error("connection is kept open after rejecting the body")
+				}
+				return
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("status = %d, want 200", resp.StatusCode)
+			}
+		})
+	}
+
+	t.Run("chunked body over limit", func(t *testing.T) {
+		// Без Content-Length лимит срабатывает при чтении тела
+		body := io.MultiReader(strings.NewReader(strings.Repeat("a", limit)), strings.NewReader("overflow"))
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/test/echo", body)
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Errorf("status = %d, want 413", resp.StatusCode)
+		}
+	})
+}
+
+// Ответы middleware с ошибкой используют общий формат ошибок API
+func TestMiddlewareErrors_JSONEnvelope(t *testing.T) {
+	release := make(chan struct{})
+	blocking := func(w http.ResponseWriter, r *http.Request) { <-release }
+
+	defer close(release)
+
+	// Middleware пишут в лог приложения
+	testutil.NewTestServer(t)
+
+	tests := []struct {
+		name    string
+		handler func(t *testing.T) http.Handler
+		status  int
+		errType string
+	}{
+		{
+			name: "admin token",
+			handler: func(t *testing.T) http.Handler {
+				return middleware.AdminToken("secret")(http.NotFoundHandler())
+			},
+			status:  http.StatusForbidden,
+			errType: "forbidden",
+		},
+		{
+			name: "fault injection",
+			handler: func(t *testing.T) http.Handler {
+				t.Setenv("FAULT_INJECTION_ENABLED", "true")
+				return middleware.FaultInjection()(http.NotFoundHandler())
+			},
+			status:  http.StatusBadGateway,
+			errType: "injected_fault",
+		},
+		{
+			name: "timeout",
+			handler: func(t *testing.T) http.Handler {
+				return middleware.Timeout(20 * time.Millisecond)(http.HandlerFunc(blocking))
+			},
+			status:  http.StatusServiceUnavailable,
+			errType: "timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(middleware.FaultStatusHeader, "502")
+			tt.handler(t).ServeHTTP(rec, req)
+
+			assertJSONError(t, rec.Result(), tt.status, tt.errType)
+		})
+	}
+}
+
+func TestLoadShedding_JSONError(t *testing.T) {
+	testutil.NewTestServer(t)
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	handler := middleware.LoadShedding(1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	<-entered
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	close(release)
+	<-done
+
+	assertJSONError(t, rec.Result(), http.StatusServiceUnavailable, "service_unavailable")
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("shed response has no Retry-After")
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/gorilla/mux"
+)
+
+// Chain объединяет middleware в одну. Порядок слева направо:
+// первая middleware - внешняя и первой получает запрос
+func Chain(middlewares ...mux.MiddlewareFunc) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// Named дает middleware имя для отладочного лога сборки маршрутов.
+// Имя пишется один раз при оборачивании обработчика, на запросы
+// обертка не влияет
+func Named(name string, mw mux.MiddlewareFunc) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		logging.Debug("Middleware applied", map[string]interface{}{
+			"middleware": name,
+		})
+		return mw(next)
+	}
+}
+
+// ConditionalChain применяет chain только к запросам, для которых
+// predicate возвращает true, остальные идут сразу в обработчик
+func ConditionalChain(predicate func(*http.Request) bool, chain mux.MiddlewareFunc) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		wrapped := chain(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if predicate(r) {
+				wrapped.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
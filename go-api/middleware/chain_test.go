@@ -0,0 +1,175 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/gorilla/mux"
+)
+
+// traceMiddleware записывает в trace вход и выход из middleware
+func traceMiddleware(name string, trace *[]string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*trace = append(*trace, name+" in")
+			next.ServeHTTP(w, r)
+			*trace = append(*trace, name+" out")
+		})
+	}
+}
+
+// headerMiddleware помечает ответ заголовком X-Chain
+func headerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Chain", "applied")
+		next.ServeHTTP(w, r)
+	})
+}
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestChain_Order(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  []string
+	}{
+		{name: "empty", want: []string{"handler"}},
+		{name: "single", names: []string{"a"}, want: []string{"a in", "handler", "a out"}},
+		{
+			name:  "left to right",
+			names: []string{"a", "b", "c"},
+			want:  []string{"a in", "b in", "c in", "handler", "c out", "b out", "a out"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var trace []string
+			mws := make([]mux.MiddlewareFunc, 0, len(tt.names))
+			for _, name := range tt.names {
+				mws = append(mws, traceMiddleware(name, &trace))
+			}
+			handler := middleware.Chain(mws...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				trace = append(trace, "handler")
+			}))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if !reflect.DeepEqual(trace, tt.want) {
+				t.Errorf("trace = %v, want %v", trace, tt.want)
+			}
+		})
+	}
+}
+
+func TestChain_Nested(t *testing.T) {
+	var trace []string
+	inner := middleware.Chain(traceMiddleware("b", &trace), traceMiddleware("c", &trace))
+	handler := middleware.Chain(traceMiddleware("a", &trace), inner)(okHandler)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := []string{"a in", "b in", "c in", "c out", "b out", "a out"}
+	if !reflect.DeepEqual(trace, want) {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+}
+
+func TestNamed(t *testing.T) {
+	logger := logging.NewBufferedLogger()
+	logging.SetDefault(logger)
+	t.Cleanup(func() { logging.SetDefault(nil) })
+
+	handler := middleware.Named("header", headerMiddleware)(okHandler)
+
+	// Имя пишется в лог один раз при сборке маршрута
+	var applied []logging.LogEntry
+	for _, entry := range logger.Entries() {
+		if entry.Message == "Middleware applied" {
+			applied = append(applied, entry)
+		}
+	}
+	if len(applied) != 1 || applied[0].Fields["middleware"] != "header" {
+		t.Fatalf("entries = %+v, want one \"Middleware applied\" entry for header", applied)
+	}
+
+	for i := 0; i < 10; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Header().Get("X-Chain") != "applied" {
+			t.Fatal("named middleware was not applied")
+		}
+	}
+	if got := len(logger.Entries()); got != 1 {
+		t.Errorf("%d log entries after serving requests, want 1", got)
+	}
+}
+
+// Named не добавляет работы на запрос: обработчик тот же, что у самой middleware
+func TestNamed_NoOverhead(t *testing.T) {
+	logging.SetDefault(logging.NoopLogger{})
+	t.Cleanup(func() { logging.SetDefault(nil) })
+
+	plain := headerMiddleware(okHandler)
+	named := middleware.Named("header", headerMiddleware)(okHandler)
+
+	if reflect.TypeOf(named) != reflect.TypeOf(plain) {
+		t.Errorf("named handler is %T, want %T", named, plain)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	allocs := func(h http.Handler) float64 {
+		return testing.AllocsPerRun(100, func() {
+			h.ServeHTTP(rec, req)
+		})
+	}
+	if plainAllocs, namedAllocs := allocs(plain), allocs(named); namedAllocs != plainAllocs {
+		t.Errorf("named middleware allocates %v per request, plain %v", namedAllocs, plainAllocs)
+	}
+}
+
+func TestConditionalChain(t *testing.T) {
+	onlyAPI := func(r *http.Request) bool { return strings.HasPrefix(r.URL.Path, "/api/") }
+
+	tests := []struct {
+		name        string
+		path        string
+		wantApplied bool
+	}{
+		{name: "predicate true", path: "/api/users", wantApplied: true},
+		{name: "predicate false", path: "/static/app.js", wantApplied: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var trace []string
+			chain := middleware.Chain(traceMiddleware("a", &trace), headerMiddleware)
+			handler := middleware.ConditionalChain(onlyAPI, chain)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				trace = append(trace, "handler")
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			want := []string{"handler"}
+			if tt.wantApplied {
+				want = []string{"a in", "handler", "a out"}
+			}
+			if !reflect.DeepEqual(trace, want) {
+				t.Errorf("trace = %v, want %v", trace, want)
+			}
+			if applied := rec.Header().Get("X-Chain") == "applied"; applied != tt.wantApplied {
+				t.Errorf("chain applied = %v, want %v", applied, tt.wantApplied)
+			}
+		})
+	}
+}
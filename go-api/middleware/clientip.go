@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type clientIPKey struct{}
+
+// TrustedProxies - подсети обратных прокси (nginx, балансировщик), которым
+// разрешено сообщать адрес клиента в X-Forwarded-For. Заголовок от
+// остальных адресов игнорируется, так как клиент может подставить его сам
+type TrustedProxies []*net.IPNet
+
+// NewTrustedProxies разбирает подсети прокси. Подсети проверяются в
+// config.Load, поэтому неверная подсеть вызывает панику при сборке роутера
+func NewTrustedProxies(cidrs []string) TrustedProxies {
+	proxies := make(TrustedProxies, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			panic(fmt.Sprintf("trusted proxies: %v", err))
+		}
+		proxies = append(proxies, ipNet)
+	}
+	return proxies
+}
+
+// ClientIPMiddleware определяет адрес клиента через proxies и сохраняет его
+// в контексте для следующих middleware (RateLimit)
+func ClientIPMiddleware(proxies TrustedProxies) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), clientIPKey{}, proxies.ClientIP(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// clientIP возвращает адрес из ClientIPMiddleware. Без него X-Forwarded-For
+// не учитывается и клиентом считается адрес соединения
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return TrustedProxies(nil).ClientIP(r)
+}
+
+// ClientIP возвращает адрес клиента. Если соединение пришло от доверенного
+// прокси, X-Forwarded-For разбирается справа налево: адреса доверенных прокси
+// пропускаются, первый недоверенный адрес считается клиентом. Левые значения
+// заголовка может подделать клиент, поэтому первому значению не доверяем.
+// Результат - нормализованный IP, непарсируемые значения не возвращаются
+func (p TrustedProxies) ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := parseIP(host)
+	if ip == nil {
+		return host
+	}
+
+	if !p.contains(ip) {
+		return ip.String()
+	}
+
+	hops := forwardedHops(r.Header)
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseIP(hops[i])
+		if hop == nil {
+			// Мусор в заголовке: ближайший известный адрес надежнее
+			break
+		}
+		ip = hop
+		if !p.contains(hop) {
+			break
+		}
+	}
+	return ip.String()
+}
+
+// contains сообщает, принадлежит ли адрес одной из подсетей
+func (p TrustedProxies) contains(ip net.IP) bool {
+	for _, n := range p {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedHops возвращает адреса всех заголовков X-Forwarded-For по порядку
+func forwardedHops(header http.Header) []string {
+	var hops []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	return hops
+}
+
+// parseIP разбирает адрес без зоны IPv6 (fe80::1%eth0)
+func parseIP(raw string) net.IP {
+	if i := strings.IndexByte(raw, '%'); i >= 0 {
+		raw = raw[:i]
+	}
+	return net.ParseIP(raw)
+}
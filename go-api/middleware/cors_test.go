@@ -0,0 +1,161 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/crazy1997/go-api/middleware"
+)
+
+func TestCORS(t *testing.T) {
+	origins := []string{"https://shop.example.com", " https://admin.example.com "}
+
+	tests := []struct {
+		name        string
+		origins     []string
+		method      string
+		origin      string
+		preflight   bool
+		wantStatus  int
+		wantOrigin  string
+		wantHeaders bool
+		wantVary    bool
+		wantNext    bool
+	}{
+		{
+			name:        "allowed origin",
+			origins:     origins,
+			method:      http.MethodGet,
+			origin:      "https://shop.example.com",
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "https://shop.example.com",
+			wantHeaders: true,
+			wantVary:    true,
+			wantNext:    true,
+		},
+		{
+			name:        "allowed origin trimmed from config",
+			origins:     origins,
+			method:      http.MethodGet,
+			origin:      "https://admin.example.com",
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "https://admin.example.com",
+			wantHeaders: true,
+			wantVary:    true,
+			wantNext:    true,
+		},
+		{
+			name:       "foreign origin gets no headers",
+			origins:    origins,
+			method:     http.MethodGet,
+			origin:     "https://evil.example.com",
+			wantStatus: http.StatusOK,
+			wantVary:   true,
+			wantNext:   true,
+		},
+		{
+			name:       "no origin header",
+			origins:    origins,
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantNext:   true,
+		},
+		{
+			name:        "preflight",
+			origins:     origins,
+			method:      http.MethodOptions,
+			origin:      "https://shop.example.com",
+			preflight:   true,
+			wantStatus:  http.StatusNoContent,
+			wantOrigin:  "https://shop.example.com",
+			wantHeaders: true,
+			wantVary:    true,
+		},
+		{
+			name:       "preflight from foreign origin",
+			origins:    origins,
+			method:     http.MethodOptions,
+			origin:     "https://evil.example.com",
+			preflight:  true,
+			wantStatus: http.StatusNoContent,
+			wantVary:   true,
+		},
+		{
+			name:        "options without request method is not preflight",
+			origins:     origins,
+			method:      http.MethodOptions,
+			origin:      "https://shop.example.com",
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "https://shop.example.com",
+			wantHeaders: true,
+			wantVary:    true,
+			wantNext:    true,
+		},
+		{
+			name:        "wildcard",
+			origins:     []string{"*"},
+			method:      http.MethodGet,
+			origin:      "https://any.example.com",
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "*",
+			wantHeaders: true,
+			wantVary:    true,
+			wantNext:    true,
+		},
+		{
+			name:       "disabled",
+			method:     http.MethodGet,
+			origin:     "https://shop.example.com",
+			wantStatus: http.StatusOK,
+			wantNext:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			handler := middleware.CORS(tt.origins)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reached = true
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(tt.method, "/api/products", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if reached != tt.wantNext {
+				t.Errorf("handler reached = %v, want %v", reached, tt.wantNext)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+
+			methods := rec.Header().Get("Access-Control-Allow-Methods")
+			headers := rec.Header().Get("Access-Control-Allow-Headers")
+			if tt.wantHeaders {
+				if methods != "GET, POST, PUT, PATCH, DELETE, OPTIONS" {
+					t.Errorf("Access-Control-Allow-Methods = %q", methods)
+				}
+				if headers != "Content-Type, Authorization, X-Request-ID" {
+					t.Errorf("Access-Control-Allow-Headers = %q", headers)
+				}
+			} else if methods != "" || headers != "" {
+				t.Errorf("CORS headers set for a request that should get none: methods %q, headers %q", methods, headers)
+			}
+
+			if got := rec.Header().Get("Vary") == "Origin"; got != tt.wantVary {
+				t.Errorf("Vary: Origin = %v, want %v", got, tt.wantVary)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/gorilla/mux"
+)
+
+// Заголовки запроса, управляющие внедрением сбоев
+const (
+	FaultDelayHeader       = "X-Fault-Delay-Ms"
+	FaultStatusHeader      = "X-Fault-Status"
+	FaultProbabilityHeader = "X-Fault-Probability"
+)
+
+// Верхняя граница внедряемой задержки
+const maxFaultDelay = time.Minute
+
+// FaultInjection внедряет сбои по заголовкам запроса для интеграционных
+// тестов: X-Fault-Delay-Ms задерживает запрос, X-Fault-Status отвечает
+// указанным кодом ошибки, X-Fault-Probability (0..1, по умолчанию 1)
+// задает вероятность сбоя. Работает только при FAULT_INJECTION_ENABLED=true,
+// иначе пропускает запросы без изменений
+func FaultInjection() mux.MiddlewareFunc {
+	if os.Getenv("FAULT_INJECTION_ENABLED") != "true" {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			delay, status, probability, ok := parseFault(r)
+			if !ok || rand.Float64() >= probability {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			logging.FromContext(r.Context()).Warn("Injecting fault", map[string]interface{}{
+				"path":     r.URL.Path,
+				"delay_ms": delay.Milliseconds(),
+				"status":   status,
+			})
+
+			if delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			if status != 0 {
+				handlers.WriteError(w, status, handlers.ErrTypeInjectedFault, fmt.Sprintf("injected fault %d", status))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// parseFault разбирает заголовки сбоя. Неверные значения игнорируются,
+// ok=false - запрос не просит сбоя
+func parseFault(r *http.Request) (delay time.Duration, status int, probability float64, ok bool) {
+	if ms, err := strconv.Atoi(r.Header.Get(FaultDelayHeader)); err == nil && ms > 0 {
+		delay = min(time.Duration(ms)*time.Millisecond, maxFaultDelay)
+	}
+	if code, err := strconv.Atoi(r.Header.Get(FaultStatusHeader)); err == nil && code >= 400 && code <= 599 {
+		status = code
+	}
+	if delay == 0 && status == 0 {
+		return 0, 0, 0, false
+	}
+
+	probability = 1
+	if p, err := strconv.ParseFloat(r.Header.Get(FaultProbabilityHeader), 64); err == nil && p >= 0 && p <= 1 {
+		probability = p
+	}
+	return delay, status, probability, true
+}
@@ -0,0 +1,213 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/testutil"
+)
+
+// faultServer - тестовый сервер с маршрутом /test/fault, считающий
+// запросы, дошедшие до обработчика. FAULT_INJECTION_ENABLED читается
+// при сборке маршрутизатора
+type faultServer struct {
+	*testutil.TestServer
+	reached atomic.Int64
+}
+
+func newFaultServer(t *testing.T) *faultServer {
+	t.Helper()
+
+	s := &faultServer{}
+	s.TestServer = testutil.NewTestServerWith(t, testutil.TestServerOptions{
+		Router: routerWithRoute("/test/fault", func(w http.ResponseWriter, r *http.Request) {
+			s.reached.Add(1)
+			w.WriteHeader(http.StatusOK)
+		}),
+	})
+	return s
+}
+
+// get выполняет запрос с заголовками сбоя и сообщает, дошел ли он до обработчика
+func (s *faultServer) get(t *testing.T, headers map[string]string) (*http.Response, bool) {
+	t.Helper()
+
+	before := s.reached.Load()
+	req, _ := http.NewRequest(http.MethodGet, s.URL+"/test/fault", nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET /test/fault: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, s.reached.Load() > before
+}
+
+func TestFaultInjection(t *testing.T) {
+	t.Setenv("FAULT_INJECTION_ENABLED", "true")
+	srv := newFaultServer(t)
+
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		wantStatus  int
+		wantReached bool
+		wantDelay   time.Duration
+	}{
+		{name: "no fault headers", wantStatus: http.StatusOK, wantReached: true},
+		{
+			name:       "status",
+			headers:    map[string]string{middleware.FaultStatusHeader: "503"},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "status with probability 1",
+			headers:    map[string]string{middleware.FaultStatusHeader: "500", middleware.FaultProbabilityHeader: "1"},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:        "probability 0",
+			headers:     map[string]string{middleware.FaultStatusHeader: "503", middleware.FaultProbabilityHeader: "0"},
+			wantStatus:  http.StatusOK,
+			wantReached: true,
+		},
+		{
+			name:       "invalid probability means always",
+			headers:    map[string]string{middleware.FaultStatusHeader: "503", middleware.FaultProbabilityHeader: "1.5"},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:        "non-error status ignored",
+			headers:     map[string]string{middleware.FaultStatusHeader: "200"},
+			wantStatus:  http.StatusOK,
+			wantReached: true,
+		},
+		{
+			name:        "invalid status ignored",
+			headers:     map[string]string{middleware.FaultStatusHeader: "boom"},
+			wantStatus:  http.StatusOK,
+			wantReached: true,
+		},
+		{
+			name:        "delay then pass through",
+			headers:     map[string]string{middleware.FaultDelayHeader: "50"},
+			wantStatus:  http.StatusOK,
+			wantReached: true,
+			wantDelay:   50 * time.Millisecond,
+		},
+		{
+			name:       "delay then status",
+			headers:    map[string]string{middleware.FaultDelayHeader: "50", middleware.FaultStatusHeader: "504"},
+			wantStatus: http.StatusGatewayTimeout,
+			wantDelay:  50 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			resp, reached := srv.get(t, tt.headers)
+			elapsed := time.Since(start)
+
+			if reached != tt.wantReached {
+				t.Errorf("handler reached = %v, want %v", reached, tt.wantReached)
+			}
+			if elapsed < tt.wantDelay || elapsed > tt.wantDelay+time.Second {
+				t.Errorf("request took %v, want about %v", elapsed, tt.wantDelay)
+			}
+			if tt.wantReached {
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+				return
+			}
+			assertJSONError(t, resp, tt.wantStatus, handlers.ErrTypeInjectedFault)
+		})
+	}
+}
+
+func TestFaultInjection_Probability(t *testing.T) {
+	t.Setenv("FAULT_INJECTION_ENABLED", "true")
+	srv := newFaultServer(t)
+	const requests = 1000
+
+	for _, p := range []float64{0.1, 0.5, 0.9} {
+		probability := strconv.FormatFloat(p, 'f', -1, 64)
+		t.Run(probability, func(t *testing.T) {
+			faults := 0
+			for i := 0; i < requests; i++ {
+				resp, _ := srv.get(t, map[string]string{
+					middleware.FaultStatusHeader:      "503",
+					middleware.FaultProbabilityHeader: probability,
+				})
+				io.Copy(io.Discard, resp.Body)
+				if resp.StatusCode == http.StatusServiceUnavailable {
+					faults++
+				}
+			}
+
+			// Пять стандартных отклонений биномиального распределения
+			mean := requests * p
+			spread := 5 * math.Sqrt(requests*p*(1-p))
+			if got := float64(faults); got < mean-spread || got > mean+spread {
+				t.Errorf("%d of %d requests failed, want %.0f ± %.0f", faults, requests, mean, spread)
+			}
+		})
+	}
+}
+
+func TestFaultInjection_Disabled(t *testing.T) {
+	headers := map[string]string{
+		middleware.FaultStatusHeader: "503",
+		middleware.FaultDelayHeader:  "2000",
+	}
+
+	for _, value := range []string{"", "false", "1"} {
+		t.Run("FAULT_INJECTION_ENABLED="+value, func(t *testing.T) {
+			t.Setenv("FAULT_INJECTION_ENABLED", value)
+			srv := newFaultServer(t)
+
+			start := time.Now()
+			resp, reached := srv.get(t, headers)
+
+			if !reached || resp.StatusCode != http.StatusOK {
+				t.Errorf("status = %d, handler reached = %v, want the request passed through", resp.StatusCode, reached)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("request took %v, want no injected delay", elapsed)
+			}
+		})
+	}
+}
+
+// Клиент, отключившийся во время задержки, не доходит до обработчика
+func TestFaultInjection_DelayCancelled(t *testing.T) {
+	t.Setenv("FAULT_INJECTION_ENABLED", "true")
+	srv := newFaultServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/test/fault", nil)
+	req.Header.Set(middleware.FaultDelayHeader, "500")
+
+	if resp, err := srv.Client().Do(req); err == nil {
+		resp.Body.Close()
+		t.Fatal("request completed, want the client to give up during the delay")
+	}
+
+	// Обработчик дошел бы до ответа через 500 мс
+	time.Sleep(time.Second)
+	if n := srv.reached.Load(); n != 0 {
+		t.Errorf("handler reached %d times after the client disconnected", n)
+	}
+}
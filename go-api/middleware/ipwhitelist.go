@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/gorilla/mux"
+)
+
+// IPWhitelist пропускает только клиентов из подсетей cidrs (IPv4 и IPv6),
+// остальным отвечает JSON 403. Адрес клиента определяется через proxies:
+// X-Forwarded-For учитывается только от доверенных прокси. Пустой список
+// закрывает доступ всем. Неверная подсеть - ошибка конфигурации,
+// поэтому вызывает панику при сборке роутера
+func IPWhitelist(cidrs []string, proxies TrustedProxies) mux.MiddlewareFunc {
+	allowed := make(TrustedProxies, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			panic(fmt.Sprintf("ip whitelist: %v", err))
+		}
+		allowed = append(allowed, ipNet)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := proxies.ClientIP(r)
+			if parsed := parseIP(ip); parsed == nil || !allowed.contains(parsed) {
+				logging.FromContext(r.Context()).Warn("Request from IP outside whitelist rejected", map[string]interface{}{
+					"path":      r.URL.Path,
+					"client_ip": ip,
+				})
+
+				handlers.WriteError(w, http.StatusForbidden, handlers.ErrTypeForbidden, "forbidden")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
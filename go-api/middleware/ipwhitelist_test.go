@@ -0,0 +1,127 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/router"
+	"github.com/crazy1997/go-api/testutil"
+)
+
+func TestIPWhitelist(t *testing.T) {
+	testutil.NewTestServer(t)
+
+	cidrs := []string{"10.0.0.0/8", "2001:db8::/32"}
+	proxies := middleware.NewTrustedProxies([]string{"192.168.1.0/24"})
+
+	tests := []struct {
+		name       string
+		cidrs      []string
+		remoteAddr string
+		xff        string
+		wantStatus int
+	}{
+		{name: "ipv4 in range", cidrs: cidrs, remoteAddr: "10.1.2.3:5000", wantStatus: http.StatusOK},
+		{name: "ipv4 out of range", cidrs: cidrs, remoteAddr: "203.0.113.7:5000", wantStatus: http.StatusForbidden},
+		{name: "ipv6 in range", cidrs: cidrs, remoteAddr: "[2001:db8::1]:5000", wantStatus: http.StatusOK},
+		{name: "ipv6 out of range", cidrs: cidrs, remoteAddr: "[2001:dead::1]:5000", wantStatus: http.StatusForbidden},
+		{name: "ipv6 with zone", cidrs: cidrs, remoteAddr: "[2001:db8::1%eth0]:5000", wantStatus: http.StatusOK},
+		{name: "empty list denies all", remoteAddr: "10.1.2.3:5000", wantStatus: http.StatusForbidden},
+		{
+			name:       "client behind trusted proxy",
+			cidrs:      cidrs,
+			remoteAddr: "192.168.1.10:5000",
+			xff:        "10.9.9.9",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "spoofed left hop behind trusted proxy",
+			cidrs:      cidrs,
+			remoteAddr: "192.168.1.10:5000",
+			xff:        "10.9.9.9, 203.0.113.7",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "xff from untrusted peer ignored",
+			cidrs:      cidrs,
+			remoteAddr: "203.0.113.7:5000",
+			xff:        "10.9.9.9",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "trusted proxy itself outside whitelist",
+			cidrs:      cidrs,
+			remoteAddr: "192.168.1.10:5000",
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.IPWhitelist(tt.cidrs, proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if tt.wantStatus == http.StatusForbidden {
+				assertJSONError(t, rec.Result(), http.StatusForbidden, "forbidden")
+				return
+			}
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestIPWhitelist_AdminRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		cidrs      []string
+		wantStatus int
+	}{
+		{name: "loopback allowed", cidrs: []string{"127.0.0.0/8", "::1/128"}, wantStatus: http.StatusOK},
+		{name: "loopback outside list", cidrs: []string{"10.0.0.0/8"}, wantStatus: http.StatusForbidden},
+		{name: "no list configured", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{
+				Router: router.Options{AdminAllowedCIDRs: tt.cidrs},
+			})
+
+			resp, err := srv.Client().Get(srv.URL + "/metrics")
+			if err != nil {
+				t.Fatalf("GET /metrics: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if tt.wantStatus == http.StatusForbidden {
+				assertJSONError(t, resp, http.StatusForbidden, "forbidden")
+				return
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestIPWhitelist_InvalidCIDRPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("IPWhitelist accepted an invalid CIDR")
+		}
+	}()
+	middleware.IPWhitelist([]string{"10.0.0.0/33"}, nil)
+}
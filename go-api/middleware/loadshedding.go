@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/metrics"
+	"github.com/gorilla/mux"
+)
+
+// LoadShedding отклоняет запросы, пока одновременно обрабатывается больше
+// maxActive запросов: клиент сразу получает 503 с Retry-After вместо
+// ожидания в очереди до таймаута. maxActive <= 0 отключает ограничение
+func LoadShedding(maxActive int) mux.MiddlewareFunc {
+	// Счетчик общий для всех маршрутов: mux оборачивает обработчик
+	// middleware заново на каждый запрос
+	var active atomic.Int64
+
+	return func(next http.Handler) http.Handler {
+		if maxActive <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := active.Add(1)
+			defer active.Add(-1)
+
+			if current > int64(maxActive) {
+				metrics.RecordShedRequest()
+
+				logging.FromContext(r.Context()).Warn("Request shed under load", map[string]interface{}{
+					"method":          r.Method,
+					"path":            r.URL.Path,
+					"active_requests": current - 1,
+					"max_active":      maxActive,
+				})
+
+				w.Header().Set("Retry-After", "1")
+				handlers.WriteError(w, http.StatusServiceUnavailable, handlers.ErrTypeUnavailable, "server overloaded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
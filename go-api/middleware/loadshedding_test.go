@@ -0,0 +1,115 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/testutil"
+)
+
+func TestLoadShedding(t *testing.T) {
+	const clients = 50
+
+	tests := []struct {
+		name       string
+		maxActive  int
+		wantServed int
+	}{
+		{name: "threshold", maxActive: 10, wantServed: 10},
+		{name: "single slot", maxActive: 1, wantServed: 1},
+		{name: "above client count", maxActive: 100, wantServed: clients},
+		{name: "disabled", maxActive: 0, wantServed: clients},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entered atomic.Int64
+			release := make(chan struct{})
+			opts := routerWithRoute("/test/slow", func(w http.ResponseWriter, r *http.Request) {
+				entered.Add(1)
+				<-release
+			})
+			opts.MaxActiveRequests = tt.maxActive
+			srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{Router: opts})
+			shedBefore := testutil.MetricValue(t, "requests_shed_total", nil)
+
+			var (
+				wg   sync.WaitGroup
+				shed atomic.Int64
+				ok   atomic.Int64
+			)
+			for i := 0; i < clients; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					resp, err := srv.Client().Get(srv.URL + "/test/slow")
+					if err != nil {
+						t.Errorf("GET: %v", err)
+						return
+					}
+					defer resp.Body.Close()
+
+					switch resp.StatusCode {
+					case http.StatusOK:
+						io.Copy(io.Discard, resp.Body)
+						ok.Add(1)
+					case http.StatusServiceUnavailable:
+						if got := resp.Header.Get("Retry-After"); got != "1" {
+							t.Errorf("Retry-After = %q, want 1", got)
+						}
+						assertJSONError(t, resp, http.StatusServiceUnavailable, handlers.ErrTypeUnavailable)
+						shed.Add(1)
+					default:
+						t.Errorf("status = %d, want 200 or 503", resp.StatusCode)
+					}
+				}()
+			}
+
+			// Обработчики держат запросы, пока остальные не получат ответ
+			wantShed := int64(clients - tt.wantServed)
+			admitted := waitFor(t, func() bool {
+				return entered.Load() == int64(tt.wantServed) && shed.Load() == wantShed
+			})
+			close(release)
+			if !admitted {
+				t.Errorf("%d requests admitted and %d shed before release, want %d and %d", entered.Load(), shed.Load(), tt.wantServed, wantShed)
+			}
+			wg.Wait()
+
+			if ok.Load() != int64(tt.wantServed) || shed.Load() != wantShed {
+				t.Errorf("served %d and shed %d, want %d and %d", ok.Load(), shed.Load(), tt.wantServed, wantShed)
+			}
+			if got := testutil.MetricValue(t, "requests_shed_total", nil) - shedBefore; got != float64(wantShed) {
+				t.Errorf("requests_shed_total increased by %v, want %d", got, wantShed)
+			}
+
+			logged := 0
+			for _, entry := range srv.Logger().Entries() {
+				if entry.Message != "Request shed under load" {
+					continue
+				}
+				logged++
+				if entry.Level != "WARN" || entry.Fields["active_requests"] != float64(tt.maxActive) {
+					t.Errorf("entry = %s %v, want WARN with active_requests %d", entry.Level, entry.Fields, tt.maxActive)
+				}
+			}
+			if logged != int(wantShed) {
+				t.Errorf("%d \"Request shed under load\" entries, want %d", logged, wantShed)
+			}
+
+			// Освободившиеся места снова принимают запросы
+			resp, err := srv.Client().Get(srv.URL + "/test/slow")
+			if err != nil {
+				t.Fatalf("GET after load: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("status after load = %d, want 200", resp.StatusCode)
+			}
+		})
+	}
+}
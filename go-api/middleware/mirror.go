@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/crazy1997/go-api/internal/httpclient"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/metrics"
+	"github.com/gorilla/mux"
+)
+
+// Клиент для зеркальных запросов, ответы нас не интересуют
+var mirrorClient = httpclient.InstrumentedClient("mirror", 5*time.Second)
+
+// Учетные данные клиента не уходят на staging
+var mirrorStrippedHeaders = []string{"Authorization", "Cookie", APIKeyHeader}
+
+// MirrorMiddleware асинхронно копирует часть запросов на mirrorURL.
+// percentage (0.0–1.0) задает долю зеркалируемых запросов.
+// Ответ клиенту не зависит от результата зеркалирования.
+func MirrorMiddleware(mirrorURL string, percentage float64) mux.MiddlewareFunc {
+	mirrorURL = strings.TrimRight(mirrorURL, "/")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if percentage <= 0 || rand.Float64() >= percentage {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Копируем тело по мере чтения его обработчиком
+			var body bytes.Buffer
+			if r.Body != nil {
+				original := r.Body
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.TeeReader(original, &body), original}
+			}
+
+			next.ServeHTTP(w, r)
+
+			header := r.Header.Clone()
+			for _, name := range mirrorStrippedHeaders {
+				header.Del(name)
+			}
+
+			// Контекст без отмены: зеркало уходит после ответа клиенту,
+			// но остается в трассе исходного запроса
+			ctx, method, uri, tee := context.WithoutCancel(r.Context()), r.Method, r.URL.RequestURI(), r.Body
+			go func() {
+				// Дочитываем остаток, если обработчик прочитал тело не полностью.
+				// Сервер может успеть закрыть тело: обрезанный запрос не отправляем
+				if tee != nil {
+					if _, err := io.Copy(io.Discard, tee); err != nil {
+						logging.Debug("Failed to read request body for mirror", map[string]interface{}{
+							"mirror_url": mirrorURL,
+							"error":      err.Error(),
+						})
+						metrics.RecordMirrorError("body")
+						return
+					}
+				}
+				sendMirror(ctx, mirrorURL, method, uri, header, body.Bytes())
+			}()
+		})
+	}
+}
+
+func sendMirror(ctx context.Context, mirrorURL, method, uri string, header http.Header, body []byte) {
+	req, err := http.NewRequestWithContext(ctx, method, mirrorURL+uri, bytes.NewReader(body))
+	if err != nil {
+		logging.Debug("Failed to build mirror request", map[string]interface{}{
+			"mirror_url": mirrorURL,
+			"error":      err.Error(),
+		})
+		metrics.RecordMirrorError("request")
+		return
+	}
+
+	req.Header = header
+	req.Header.Set("X-Mirrored-Request", "true")
+
+	resp, err := mirrorClient.Do(req)
+	if err != nil {
+		logging.Debug("Failed to send mirror request", map[string]interface{}{
+			"mirror_url": mirrorURL,
+			"error":      err.Error(),
+		})
+		metrics.RecordMirrorError("send")
+		return
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 500 {
+		logging.Debug("Mirror returned error", map[string]interface{}{
+			"mirror_url": mirrorURL,
+			"status":     resp.StatusCode,
+		})
+		metrics.RecordMirrorError("status")
+	}
+}
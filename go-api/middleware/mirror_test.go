@@ -0,0 +1,191 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/testutil"
+)
+
+// mirrorTarget - staging сервер, запоминающий зеркальные запросы
+type mirrorTarget struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []mirroredRequest
+}
+
+type mirroredRequest struct {
+	method, uri, body string
+	header            http.Header
+}
+
+func newMirrorTarget(t *testing.T) *mirrorTarget {
+	// Метрики инициализируются до первого зеркала: отправка продолжается
+	// в фоне и после конца теста
+	testutil.MetricValue(t, "mirror_errors_total", nil)
+
+	m := &mirrorTarget{}
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		m.mu.Lock()
+		m.requests = append(m.requests, mirroredRequest{method: r.Method, uri: r.RequestURI, body: string(body), header: r.Header})
+		m.mu.Unlock()
+	}))
+	t.Cleanup(m.Close)
+	return m
+}
+
+func (m *mirrorTarget) received() []mirroredRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mirroredRequest(nil), m.requests...)
+}
+
+// waitFor опрашивает cond, пока она не выполнится или не выйдет время
+func waitFor(t *testing.T, cond func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return cond()
+}
+
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Write(body)
+}
+
+func TestMirrorMiddleware_Percentage(t *testing.T) {
+	tests := []struct {
+		name       string
+		percentage float64
+		want       int
+	}{
+		{name: "disabled", percentage: 0, want: 0},
+		{name: "all", percentage: 1, want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newMirrorTarget(t)
+			handler := middleware.MirrorMiddleware(target.URL, tt.percentage)(http.HandlerFunc(echoHandler))
+
+			for i := 0; i < 10; i++ {
+				req := httptest.NewRequest(http.MethodPost, "/api/orders?source=test", strings.NewReader(`{"user_id":1}`))
+				req.Header.Set("X-Custom", "value")
+				req.Header.Set("Authorization", "Bearer secret-token")
+				req.Header.Set("Cookie", "session=secret")
+				req.Header.Set(middleware.APIKeyHeader, "key-secret")
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				if rec.Body.String() != `{"user_id":1}` {
+					t.Fatalf("handler body = %q, mirroring must not consume it", rec.Body.String())
+				}
+			}
+
+			if tt.want == 0 {
+				time.Sleep(100 * time.Millisecond)
+			} else {
+				waitFor(t, func() bool { return len(target.received()) >= tt.want })
+			}
+
+			got := target.received()
+			if len(got) != tt.want {
+				t.Fatalf("mirrored %d requests, want %d", len(got), tt.want)
+			}
+			for _, req := range got {
+				if req.method != http.MethodPost || req.uri != "/api/orders?source=test" || req.body != `{"user_id":1}` {
+					t.Errorf("mirrored request = %s %s %q", req.method, req.uri, req.body)
+				}
+				if req.header.Get("X-Custom") != "value" || req.header.Get("X-Mirrored-Request") != "true" {
+					t.Errorf("mirrored headers = %v", req.header)
+				}
+				for _, name := range []string{"Authorization", "Cookie", middleware.APIKeyHeader} {
+					if v := req.header.Get(name); v != "" {
+						t.Errorf("mirrored %s = %q, want the credential stripped", name, v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMirrorMiddleware_MirrorDown(t *testing.T) {
+	target := newMirrorTarget(t)
+	target.Close()
+
+	sendErrors := testutil.MetricValue(t, "mirror_errors_total", map[string]string{"reason": "send"})
+	outbound := testutil.MetricValue(t, "outbound_requests_total", map[string]string{"target": "mirror", "status": "error"})
+
+	handler := middleware.MirrorMiddleware(target.URL, 1)(http.HandlerFunc(echoHandler))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/orders", strings.NewReader("payload")))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "payload" {
+		t.Fatalf("response = %d %q, want 200 from the real handler", rec.Code, rec.Body.String())
+	}
+
+	ok := waitFor(t, func() bool {
+		return testutil.MetricValue(t, "mirror_errors_total", map[string]string{"reason": "send"}) == sendErrors+1
+	})
+	if !ok {
+		t.Fatal("mirror_errors_total{reason=\"send\"} was not incremented")
+	}
+	if got := testutil.MetricValue(t, "outbound_requests_total", map[string]string{"target": "mirror", "status": "error"}); got != outbound+1 {
+		t.Errorf("outbound_requests_total{target=\"mirror\",status=\"error\"} = %v, want %v", got, outbound+1)
+	}
+}
+
+// Непрочитанное обработчиком тело дочитывается после ответа клиенту
+func TestMirrorMiddleware_UnreadBodyDoesNotDelay(t *testing.T) {
+	target := newMirrorTarget(t)
+	srv := httptest.NewServer(middleware.MirrorMiddleware(target.URL, 1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("accepted"))
+	})))
+	t.Cleanup(srv.Close)
+
+	// Клиент отправляет начало большого тела и держит запрос открытым.
+	// Такое тело сервер не дочитывает сам, а закрывает соединение
+	body, writer := io.Pipe()
+	t.Cleanup(func() { writer.CloseWithError(io.ErrUnexpectedEOF) })
+	go writer.Write([]byte("first part"))
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/orders", body)
+	req.ContentLength = 1 << 20
+	done := make(chan *http.Response, 1)
+	go func() {
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Errorf("POST: %v", err)
+			close(done)
+			return
+		}
+		done <- resp
+	}()
+
+	select {
+	case resp, ok := <-done:
+		if !ok {
+			return
+		}
+		defer resp.Body.Close()
+		if got, _ := io.ReadAll(resp.Body); string(got) != "accepted" {
+			t.Errorf("response body = %q, want accepted", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("response waited for the rest of the request body")
+	}
+}
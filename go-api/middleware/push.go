@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/metrics"
+	"github.com/gorilla/mux"
+)
+
+// loadPushManifest читает манифест статики - JSON массив путей ассетов,
+// например ["/css/app.css", "/js/app.js"]
+func loadPushManifest(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []string
+	if err := json.Unmarshal(data, &assets); err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// PushMiddleware вместе с HTML ответом отправляет HTTP/2 server push ассетов
+// из манифеста manifestPath. Push работает только по HTTP/2 (HTTPS), в
+// остальных случаях и без манифеста запрос обрабатывается как обычно.
+// Должен оборачивать роутер снаружи, чтобы видеть исходный http.Pusher
+func PushMiddleware(manifestPath string) mux.MiddlewareFunc {
+	assets, err := loadPushManifest(manifestPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logging.Warn("Failed to load push manifest", map[string]interface{}{
+				"manifest": manifestPath,
+				"error":    err,
+			})
+		}
+		assets = nil
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(assets) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pusher, ok := w.(http.Pusher)
+			if !ok || r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(&pushWriter{
+				ResponseWriter: w,
+				pusher:         pusher,
+				request:        r,
+				assets:         assets,
+			}, r)
+		})
+	}
+}
+
+// pushWriter выполняет push перед отправкой заголовков HTML ответа
+type pushWriter struct {
+	http.ResponseWriter
+	pusher  http.Pusher
+	request *http.Request
+	assets  []string
+	done    bool
+}
+
+func (w *pushWriter) WriteHeader(code int) {
+	w.push(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *pushWriter) Write(b []byte) (int, error) {
+	w.push(http.StatusOK)
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap открывает исходный writer для http.ResponseController
+func (w *pushWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *pushWriter) push(code int) {
+	if w.done {
+		return
+	}
+	w.done = true
+
+	if code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		return
+	}
+
+	for _, asset := range w.assets {
+		if asset == w.request.URL.Path {
+			continue
+		}
+
+		if err := w.pusher.Push(asset, nil); err != nil {
+			// Клиент отключил push - остальные ассеты тоже не уйдут
+			if errors.Is(err, http.ErrNotSupported) {
+				return
+			}
+
+			logging.FromContext(w.request.Context()).Debug("HTTP/2 push failed", map[string]interface{}{
+				"asset": asset,
+				"error": err,
+			})
+			continue
+		}
+
+		metrics.RecordHTTP2Push()
+	}
+}
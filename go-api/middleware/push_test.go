@@ -0,0 +1,198 @@
+package middleware_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/testutil"
+)
+
+// mockPusher - ResponseRecorder с поддержкой HTTP/2 push
+type mockPusher struct {
+	*httptest.ResponseRecorder
+	pushed []string
+	err    error
+}
+
+func (p *mockPusher) Push(target string, opts *http.PushOptions) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.pushed = append(p.pushed, target)
+	return nil
+}
+
+// writePushManifest создает манифест статики во временном каталоге
+func writePushManifest(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// htmlHandler отвечает страницей с заданными Content-Type и статусом
+func htmlHandler(contentType string, status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		io.WriteString(w, "<html></html>")
+	}
+}
+
+func TestPushMiddleware(t *testing.T) {
+	testutil.NewTestServer(t)
+	manifest := writePushManifest(t, `["/css/app.css", "/js/app.js", "/index.html"]`)
+
+	tests := []struct {
+		name        string
+		manifest    string
+		method      string
+		path        string
+		handler     http.HandlerFunc
+		pushErr     error
+		wantPushed  []string
+		wantCounted float64
+	}{
+		{
+			name:        "html response pushes assets",
+			manifest:    manifest,
+			method:      http.MethodGet,
+			path:        "/",
+			handler:     htmlHandler("text/html; charset=utf-8", http.StatusOK),
+			wantPushed:  []string{"/css/app.css", "/js/app.js", "/index.html"},
+			wantCounted: 3,
+		},
+		{
+			name:        "requested asset is not pushed",
+			manifest:    manifest,
+			method:      http.MethodGet,
+			path:        "/index.html",
+			handler:     htmlHandler("text/html", http.StatusOK),
+			wantPushed:  []string{"/css/app.css", "/js/app.js"},
+			wantCounted: 2,
+		},
+		{
+			name:     "json response",
+			manifest: manifest,
+			method:   http.MethodGet,
+			path:     "/api/products",
+			handler:  htmlHandler("application/json", http.StatusOK),
+		},
+		{
+			name:     "error response",
+			manifest: manifest,
+			method:   http.MethodGet,
+			path:     "/",
+			handler:  htmlHandler("text/html", http.StatusNotFound),
+		},
+		{
+			name:     "post request",
+			manifest: manifest,
+			method:   http.MethodPost,
+			path:     "/",
+			handler:  htmlHandler("text/html", http.StatusOK),
+		},
+		{
+			name:     "push disabled by client",
+			manifest: manifest,
+			method:   http.MethodGet,
+			path:     "/",
+			handler:  htmlHandler("text/html", http.StatusOK),
+			pushErr:  http.ErrNotSupported,
+		},
+		{
+			name:     "push fails",
+			manifest: manifest,
+			method:   http.MethodGet,
+			path:     "/",
+			handler:  htmlHandler("text/html", http.StatusOK),
+			pushErr:  errors.New("stream closed"),
+		},
+		{
+			name:     "missing manifest",
+			manifest: filepath.Join(t.TempDir(), "missing.json"),
+			method:   http.MethodGet,
+			path:     "/",
+			handler:  htmlHandler("text/html", http.StatusOK),
+		},
+		{
+			name:     "invalid manifest",
+			manifest: writePushManifest(t, `{"app": "/js/app.js"}`),
+			method:   http.MethodGet,
+			path:     "/",
+			handler:  htmlHandler("text/html", http.StatusOK),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pushesBefore := testutil.MetricValue(t, "http2_push_total", nil)
+
+			w := &mockPusher{ResponseRecorder: httptest.NewRecorder(), err: tt.pushErr}
+			middleware.PushMiddleware(tt.manifest)(tt.handler).ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if !reflect.DeepEqual(w.pushed, tt.wantPushed) {
+				t.Errorf("pushed %v, want %v", w.pushed, tt.wantPushed)
+			}
+			if got := testutil.MetricValue(t, "http2_push_total", nil) - pushesBefore; got != tt.wantCounted {
+				t.Errorf("http2_push_total increased by %v, want %v", got, tt.wantCounted)
+			}
+			if w.Body.String() != "<html></html>" {
+				t.Errorf("body = %q, want the handler response", w.Body)
+			}
+		})
+	}
+}
+
+// Без поддержки push со стороны клиента ответ отдается как обычно
+func TestPushMiddleware_TLSServer(t *testing.T) {
+	testutil.NewTestServer(t)
+	manifest := writePushManifest(t, `["/css/app.css", "/js/app.js"]`)
+
+	srv := httptest.NewUnstartedServer(middleware.PushMiddleware(manifest)(htmlHandler("text/html", http.StatusOK)))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	pushesBefore := testutil.MetricValue(t, "http2_push_total", nil)
+	resp, err := srv.Client().Get(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("protocol = %s, want HTTP/2", resp.Proto)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "<html></html>" {
+		t.Errorf("response = %d %q, want 200 with the page", resp.StatusCode, body)
+	}
+	// Go клиент отключает push в SETTINGS_ENABLE_PUSH
+	if got := testutil.MetricValue(t, "http2_push_total", nil) - pushesBefore; got != 0 {
+		t.Errorf("http2_push_total increased by %v for a client without push", got)
+	}
+}
+
+func TestPushMiddleware_PlainHTTP(t *testing.T) {
+	testutil.NewTestServer(t)
+	manifest := writePushManifest(t, `["/css/app.css"]`)
+
+	// ResponseRecorder не реализует http.Pusher, как и HTTP/1.1
+	rec := httptest.NewRecorder()
+	middleware.PushMiddleware(manifest)(htmlHandler("text/html", http.StatusOK)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "<html></html>" {
+		t.Errorf("response = %d %q, want 200 with the page", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/metrics"
+	"github.com/gorilla/mux"
+	"golang.org/x/time/rate"
+)
+
+// Лимитеры клиентов без запросов дольше limiterIdleTTL удаляются
+const (
+	limiterIdleTTL       = 10 * time.Minute
+	limiterEvictInterval = time.Minute
+)
+
+// clientLimiter - лимитер клиента и время его последнего запроса
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen atomic.Int64
+}
+
+// RateLimit ограничивает частоту запросов с одного IP: rps запросов в
+// секунду с всплеском до burst. Сверх лимита отвечает 429 с Retry-After.
+// Адрес клиента берется из ClientIPMiddleware, без него - адрес соединения.
+// Лимитеры неактивных клиентов удаляются в фоне. rps <= 0 отключает ограничение
+func RateLimit(rps float64, burst int) mux.MiddlewareFunc {
+	if rps <= 0 {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	// Лимитеры общие для всех маршрутов: mux оборачивает обработчик
+	// middleware заново на каждый запрос
+	var limiters sync.Map
+	go evictLimiters(&limiters)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := clientIP(r)
+
+			v, ok := limiters.Load(ip)
+			if !ok {
+				v, _ = limiters.LoadOrStore(ip, &clientLimiter{limiter: rate.NewLimiter(rate.Limit(rps), burst)})
+			}
+			cl := v.(*clientLimiter)
+			cl.lastSeen.Store(time.Now().UnixNano())
+
+			reservation := cl.limiter.Reserve()
+			if wait := reservation.Delay(); wait > 0 {
+				// Отказ не должен занимать будущий токен
+				reservation.Cancel()
+
+				ipHash := hashIP(ip)
+				metrics.RecordRateLimitRejection(ipHash)
+
+				logging.FromContext(r.Context()).Warn("Rate limit exceeded", map[string]interface{}{
+					"method":  r.Method,
+					"path":    r.URL.Path,
+					"ip_hash": ipHash,
+				})
+
+				retryAfter := int(math.Ceil(wait.Seconds()))
+				if retryAfter < 1 {
+					retryAfter = 1
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				handlers.WriteError(w, http.StatusTooManyRequests, handlers.ErrTypeRateLimited, "too many requests")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// evictLimiters периодически удаляет лимитеры неактивных клиентов
+func evictLimiters(limiters *sync.Map) {
+	ticker := time.NewTicker(limiterEvictInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		limiters.Range(func(key, value interface{}) bool {
+			if now.Sub(time.Unix(0, value.(*clientLimiter).lastSeen.Load())) > limiterIdleTTL {
+				limiters.Delete(key)
+			}
+			return true
+		})
+	}
+}
+
+// hashIP сворачивает IP в один из 256 бакетов, чтобы метка ip
+// не раздувала число серий и не раскрывала адрес клиента
+func hashIP(ip string) string {
+	sum := sha256.Sum256([]byte(ip))
+	return hex.EncodeToString(sum[:1])
+}
@@ -0,0 +1,242 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/router"
+	"github.com/crazy1997/go-api/testutil"
+)
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	proxies := middleware.NewTrustedProxies([]string{"10.0.0.0/8", "fd00::/8"})
+
+	tests := []struct {
+		name       string
+		proxies    middleware.TrustedProxies
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{
+			name:       "no proxies ignore spoofed header",
+			remoteAddr: "203.0.113.7:5000",
+			forwarded:  []string{"1.2.3.4"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "untrusted peer ignores header",
+			proxies:    proxies,
+			remoteAddr: "203.0.113.7:5000",
+			forwarded:  []string{"1.2.3.4"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy without header",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.2:5000",
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "single trusted hop",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.2:5000",
+			forwarded:  []string{"198.51.100.10"},
+			want:       "198.51.100.10",
+		},
+		{
+			name:       "spoofed leftmost value is skipped",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.2:5000",
+			forwarded:  []string{"1.2.3.4, 198.51.100.10"},
+			want:       "198.51.100.10",
+		},
+		{
+			name:       "chain of trusted proxies",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.2:5000",
+			forwarded:  []string{"198.51.100.10, 10.1.1.1, 10.2.2.2"},
+			want:       "198.51.100.10",
+		},
+		{
+			name:       "multiple header lines",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.2:5000",
+			forwarded:  []string{"1.2.3.4", "198.51.100.10, 10.1.1.1"},
+			want:       "198.51.100.10",
+		},
+		{
+			name:       "garbage stops at the nearest trusted hop",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.2:5000",
+			forwarded:  []string{"not-an-ip, 10.1.1.1"},
+			want:       "10.1.1.1",
+		},
+		{
+			name:       "all hops trusted",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.2:5000",
+			forwarded:  []string{"10.3.3.3, 10.1.1.1"},
+			want:       "10.3.3.3",
+		},
+		{
+			name:       "ipv6 trusted proxy",
+			proxies:    proxies,
+			remoteAddr: "[fd00::1]:5000",
+			forwarded:  []string{"2001:db8::1"},
+			want:       "2001:db8::1",
+		},
+		{
+			name:       "ipv6 client is normalized",
+			remoteAddr: "[2001:DB8:0:0::1]:5000",
+			want:       "2001:db8::1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+
+			if got := tt.proxies.ClientIP(req); got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimit_SharedAcrossRequests(t *testing.T) {
+	// Лимитеры должны переживать повторную обертку обработчика mux на каждый запрос
+	srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{
+		Router: router.Options{RateLimitRPS: 0.001, RateLimitBurst: 2},
+	})
+	rejectedBefore := testutil.MetricValue(t, "rate_limit_rejections_total", nil)
+
+	var statuses []int
+	for i := 0; i < 3; i++ {
+		resp, err := srv.Client().Get(srv.URL + "/api/health")
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if resp.Header.Get("Retry-After") == "" {
+				t.Error("429 without Retry-After")
+			}
+			assertJSONError(t, resp, http.StatusTooManyRequests, "rate_limited")
+		}
+		resp.Body.Close()
+		statuses = append(statuses, resp.StatusCode)
+	}
+
+	if statuses[0] != http.StatusOK || statuses[1] != http.StatusOK || statuses[2] != http.StatusTooManyRequests {
+		t.Errorf("statuses = %v, want [200 200 429]", statuses)
+	}
+	if got := testutil.MetricValue(t, "rate_limit_rejections_total", nil) - rejectedBefore; got != 1 {
+		t.Errorf("rate_limit_rejections_total increased by %v, want 1", got)
+	}
+}
+
+func TestRateLimit_SpoofedForwardedForDoesNotBypass(t *testing.T) {
+	testutil.NewTestServer(t)
+
+	handler := middleware.RateLimit(0.001, 1)(http.NotFoundHandler())
+
+	var limited int
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "203.0.113.7:5000"
+		req.Header.Set("X-Forwarded-For", "198.51.100."+string(rune('1'+i)))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code == http.StatusTooManyRequests {
+			limited++
+		}
+	}
+	if limited != 4 {
+		t.Errorf("%d of 5 requests limited, want 4: X-Forwarded-For from an untrusted peer must be ignored", limited)
+	}
+}
+
+func TestRateLimit_DistinctClients(t *testing.T) {
+	testutil.NewTestServer(t)
+
+	proxies := middleware.NewTrustedProxies([]string{"10.0.0.0/8"})
+	handler := middleware.ClientIPMiddleware(proxies)(middleware.RateLimit(0.001, 1)(http.NotFoundHandler()))
+
+	for _, client := range []string{"198.51.100.1", "198.51.100.2", "2001:db8::1"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.2:5000"
+		req.Header.Set("X-Forwarded-For", client)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code == http.StatusTooManyRequests {
+			t.Errorf("first request of %s was limited", client)
+		}
+	}
+}
+
+func TestRateLimit_RetryAfter(t *testing.T) {
+	testutil.NewTestServer(t)
+
+	tests := []struct {
+		name string
+		rps  float64
+		want string
+	}{
+		{name: "token every 2s", rps: 0.5, want: "2"},
+		{name: "token every 10s", rps: 0.1, want: "10"},
+		{name: "sub-second wait rounds up", rps: 5, want: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.RateLimit(tt.rps, 1)(http.NotFoundHandler())
+
+			var rec *httptest.ResponseRecorder
+			for i := 0; i < 2; i++ {
+				rec = httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			}
+			if rec.Code != http.StatusTooManyRequests {
+				t.Fatalf("second request status = %d, want 429", rec.Code)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.want {
+				t.Errorf("Retry-After = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Отклоненные запросы не занимают будущие токены
+func TestRateLimit_RejectionsDoNotReserve(t *testing.T) {
+	testutil.NewTestServer(t)
+	handler := middleware.RateLimit(10, 1)(http.NotFoundHandler())
+
+	get := func() int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Code
+	}
+
+	if code := get(); code == http.StatusTooManyRequests {
+		t.Fatal("first request limited")
+	}
+	for i := 0; i < 5; i++ {
+		if code := get(); code != http.StatusTooManyRequests {
+			t.Fatalf("request %d status = %d, want 429 within the burst window", i+2, code)
+		}
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if code := get(); code == http.StatusTooManyRequests {
+		t.Error("request after the refill limited: rejected requests reserved tokens")
+	}
+}
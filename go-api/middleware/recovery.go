@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/metrics"
+	"github.com/getsentry/sentry-go"
+	"github.com/gorilla/mux"
+)
+
+// Заголовки, которые не попадают в лог при панике
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Cookie":              true,
+	"Proxy-Authorization": true,
+	"X-Api-Key":           true,
+}
+
+var goroutineIDPattern = regexp.MustCompile(`^goroutine (\d+) `)
+
+// RequestSnapshot - данные запроса, при обработке которого произошла паника
+type RequestSnapshot struct {
+	Method  string              `json:"method"`
+	Path    string              `json:"path"`
+	Headers map[string][]string `json:"headers"`
+}
+
+// PanicDetail - подробности перехваченной паники для записи в лог
+type PanicDetail struct {
+	RecoveredValue  interface{}     `json:"recovered_value"`
+	StackTrace      string          `json:"stack_trace"`
+	GoroutineID     int64           `json:"goroutine_id"`
+	RequestSnapshot RequestSnapshot `json:"request"`
+}
+
+// NewPanicDetail собирает PanicDetail для значения, полученного из recover()
+func NewPanicDetail(recovered interface{}, r *http.Request) PanicDetail {
+	stack := string(debug.Stack())
+
+	return PanicDetail{
+		RecoveredValue: recovered,
+		StackTrace:     stack,
+		GoroutineID:    parseGoroutineID(stack),
+		RequestSnapshot: RequestSnapshot{
+			Method:  r.Method,
+			Path:    r.URL.Path,
+			Headers: safeHeaders(r.Header),
+		},
+	}
+}
+
+// parseGoroutineID извлекает ID горутины из первой строки стека
+// вида "goroutine 42 [running]:"
+func parseGoroutineID(stack string) int64 {
+	match := goroutineIDPattern.FindStringSubmatch(stack)
+	if match == nil {
+		return 0
+	}
+	id, _ := strconv.ParseInt(match[1], 10, 64)
+	return id
+}
+
+// RecoveryMiddleware перехватывает панику в обработчике, пишет ее в лог
+// со стеком, отправляет в Sentry, учитывает в errors_total{type="panic"}
+// и отвечает JSON 500. Должен идти первым после sentryhttp, чтобы
+// покрывать все остальные middleware
+func RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				// Плоские поля для поиска в Kibana, полная картина - в под-объекте panic
+				detail := NewPanicDetail(recovered, r)
+				fields := map[string]interface{}{
+					"panic":        detail,
+					"panic_value":  fmt.Sprint(detail.RecoveredValue),
+					"stack_trace":  detail.StackTrace,
+					"goroutine_id": detail.GoroutineID,
+					"request":      detail.RequestSnapshot,
+				}
+				// Идентификатор уже выставлен RequestIDMiddleware в заголовке ответа
+				if id := w.Header().Get(RequestIDHeader); id != "" {
+					fields["request_id"] = id
+				}
+				logging.Error("Panic recovered in handler", fields)
+				captureHub(r).CaptureException(panicError(recovered))
+
+				metrics.RecordError("panic", routeTemplate(r))
+
+				handlers.WriteError(w, http.StatusInternalServerError, handlers.ErrTypeInternal, "internal server error")
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// captureHub возвращает хаб Sentry запроса, созданный sentryhttp, чтобы
+// событие получило данные запроса. Без него используется общий хаб
+func captureHub(r *http.Request) *sentry.Hub {
+	if hub := sentry.GetHubFromContext(r.Context()); hub != nil {
+		return hub
+	}
+	return sentry.CurrentHub()
+}
+
+// panicError приводит значение из recover() к error
+func panicError(recovered interface{}) error {
+	if err, ok := recovered.(error); ok {
+		return err
+	}
+	return fmt.Errorf("panic: %v", recovered)
+}
+
+// routeTemplate возвращает шаблон маршрута вместо пути,
+// чтобы не раздувать число серий метрики
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			return tmpl
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,163 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/crazy1997/go-api/router"
+	"github.com/crazy1997/go-api/testutil"
+	"github.com/getsentry/sentry-go"
+	"github.com/gorilla/mux"
+)
+
+func TestRecoveryMiddleware_Panic(t *testing.T) {
+	srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{
+		Router: routerWithRoute("/test/panic", func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}),
+	})
+	panicsBefore := testutil.MetricValue(t, "errors_total", map[string]string{"type": "panic"})
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/test/panic", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Trace", "kept")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("request failed instead of a 500 response: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var body struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Error.Type != "internal_error" || body.Error.Message != "internal server error" {
+		t.Errorf("error = %+v, want internal_error / internal server error", body.Error)
+	}
+
+	if got := testutil.MetricValue(t, "errors_total", map[string]string{"type": "panic"}) - panicsBefore; got != 1 {
+		t.Errorf("errors_total{type=panic} increased by %v, want 1", got)
+	}
+
+	var fields map[string]interface{}
+	for _, entry := range srv.Logger().Entries() {
+		if entry.Message == "Panic recovered in handler" {
+			if entry.Level != "ERROR" {
+				t.Errorf("panic logged at %s, want ERROR", entry.Level)
+			}
+			fields = entry.Fields
+		}
+	}
+	if fields == nil {
+		t.Fatal("panic was not logged")
+	}
+	if fields["panic_value"] != "boom" || fields["stack_trace"] == "" {
+		t.Errorf("panic_value = %v, stack_trace empty = %v", fields["panic_value"], fields["stack_trace"] == "")
+	}
+
+	detail, ok := fields["panic"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("fields.panic = %#v, want a PanicDetail object", fields["panic"])
+	}
+	if detail["recovered_value"] != "boom" {
+		t.Errorf("panic.recovered_value = %v, want boom", detail["recovered_value"])
+	}
+	if id, _ := detail["goroutine_id"].(float64); id <= 0 {
+		t.Errorf("panic.goroutine_id = %v, want a positive ID", detail["goroutine_id"])
+	}
+	if stack, _ := detail["stack_trace"].(string); stack == "" {
+		t.Error("panic.stack_trace is empty")
+	}
+
+	request, _ := detail["request"].(map[string]interface{})
+	if request["method"] != http.MethodGet || request["path"] != "/test/panic" {
+		t.Errorf("panic.request = %v, want GET /test/panic", request)
+	}
+	headers, _ := request["headers"].(map[string]interface{})
+	if _, ok := headers["Authorization"]; ok {
+		t.Error("panic.request.headers contains Authorization")
+	}
+	if _, ok := headers["X-Trace"]; !ok {
+		t.Error("panic.request.headers lost X-Trace")
+	}
+}
+
+// panickingHandler - отдельная функция, чтобы найти ее кадр в стеке события
+func panickingHandler(w http.ResponseWriter, r *http.Request) {
+	panic(errors.New("sentry boom"))
+}
+
+func TestRecoveryMiddleware_SentryEvent(t *testing.T) {
+	transport := &sentry.MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{
+		Dsn:       "https://public@sentry.example.com/1",
+		Transport: transport,
+	}); err != nil {
+		t.Fatalf("sentry.Init: %v", err)
+	}
+	t.Cleanup(func() { sentry.CurrentHub().BindClient(nil) })
+
+	srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{
+		Router: routerWithRoute("/test/sentry-panic", panickingHandler),
+	})
+
+	resp, err := srv.Client().Get(srv.URL + "/test/sentry-panic")
+	if err != nil {
+		t.Fatalf("request failed instead of a 500 response: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", resp.StatusCode)
+	}
+
+	events := transport.Events()
+	if len(events) != 1 {
+		t.Fatalf("captured %d events, want 1", len(events))
+	}
+	event := events[0]
+
+	if len(event.Exception) == 0 || event.Exception[0].Value != "sentry boom" {
+		t.Fatalf("exception = %+v, want value %q", event.Exception, "sentry boom")
+	}
+	if event.Request == nil || !strings.HasSuffix(event.Request.URL, "/test/sentry-panic") {
+		t.Errorf("request = %+v, want the panicking request", event.Request)
+	}
+
+	stack := event.Exception[len(event.Exception)-1].Stacktrace
+	if stack == nil {
+		t.Fatal("exception has no stack trace")
+	}
+	found := false
+	for _, frame := range stack.Frames {
+		if frame.Function == "panickingHandler" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("stack trace has no panickingHandler frame: %+v", stack.Frames)
+	}
+}
+
+// routerWithRoute добавляет тестовый маршрут в цепочку middleware роутера
+func routerWithRoute(path string, handler http.HandlerFunc) router.Options {
+	opts := router.Options{}
+	opts.Routes = func(r *mux.Router) {
+		r.HandleFunc(path, handler)
+	}
+	return opts
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/crazy1997/go-api/internal/requestid"
+	"github.com/crazy1997/go-api/logging"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader - заголовок запроса и ответа с идентификатором запроса
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware присваивает запросу UUID v4, сохраняет его в контексте
+// для logging.FromContext и возвращает клиенту в X-Request-ID. Валидный
+// UUID v4 из входящего X-Request-ID (от балансировщика или шлюза)
+// переиспользуется, чтобы запрос сквозным образом находился в логах сервисов.
+// Контекст трассировки из заголовка traceparent тоже попадает в логи
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		ctx := logging.WithRequestID(r.Context(), id)
+
+		// Спан Tracing, если он уже есть, главнее заголовка
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			if sc, ok := remoteSpanContext(r.Header.Get("traceparent")); ok {
+				ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
+			}
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// remoteSpanContext строит контекст удаленного спана из заголовка traceparent
+func remoteSpanContext(header string) (trace.SpanContext, bool) {
+	traceID, spanID, ok := logging.ParseTraceparent(header)
+	if !ok {
+		return trace.SpanContext{}, false
+	}
+	tid, err := trace.TraceIDFromHex(traceID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	sid, err := trace.SpanIDFromHex(spanID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid, Remote: true}), true
+}
@@ -0,0 +1,118 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/crazy1997/go-api/internal/requestid"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/testutil"
+)
+
+const upstreamRequestID = "3f2c1ab0-9d4e-4c7a-8b1f-0e6d5a4c3b2a"
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		wantReuse bool
+	}{
+		{name: "valid id reused", header: upstreamRequestID, wantReuse: true},
+		{name: "upper case reused", header: "3F2C1AB0-9D4E-4C7A-8B1F-0E6D5A4C3B2A", wantReuse: true},
+		{name: "missing id generated"},
+		{name: "not a uuid", header: "req-12345"},
+		{name: "uuid v1", header: "3f2c1ab0-9d4e-1c7a-8b1f-0e6d5a4c3b2a"},
+		{name: "wrong variant", header: "3f2c1ab0-9d4e-4c7a-cb1f-0e6d5a4c3b2a"},
+		{name: "without dashes", header: "3f2c1ab09d4e4c7a8b1f0e6d5a4c3b2a"},
+		{name: "log injection", header: upstreamRequestID + "\nlevel=ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctxID interface{}
+			handler := middleware.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxID = logging.ContextFields(r.Context())["request_id"]
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+			if tt.header != "" {
+				req.Header.Set(middleware.RequestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(middleware.RequestIDHeader)
+			if !requestid.Valid(got) {
+				t.Fatalf("%s = %q, want a UUID v4", middleware.RequestIDHeader, got)
+			}
+			if reused := got == tt.header; reused != tt.wantReuse {
+				t.Errorf("%s = %q for incoming %q, reused = %v, want %v", middleware.RequestIDHeader, got, tt.header, reused, tt.wantReuse)
+			}
+			if ctxID != got {
+				t.Errorf("request_id in context = %v, want %s", ctxID, got)
+			}
+		})
+	}
+}
+
+// Сгенерированные идентификаторы разные для каждого запроса
+func TestRequestIDMiddleware_Unique(t *testing.T) {
+	handler := middleware.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+
+		id := rec.Header().Get(middleware.RequestIDHeader)
+		if seen[id] {
+			t.Fatalf("request id %s generated twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestRequestIDMiddleware_AccessLog(t *testing.T) {
+	srv := testutil.NewTestServer(t)
+
+	tests := []struct {
+		name      string
+		header    string
+		wantReuse bool
+	}{
+		{name: "reused", header: upstreamRequestID, wantReuse: true},
+		{name: "replaced", header: "not-a-uuid"},
+		{name: "generated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/health", nil)
+			if tt.header != "" {
+				req.Header.Set(middleware.RequestIDHeader, tt.header)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("GET /api/health: %v", err)
+			}
+			resp.Body.Close()
+
+			id := resp.Header.Get(middleware.RequestIDHeader)
+			if (id == tt.header) != tt.wantReuse || !requestid.Valid(id) {
+				t.Errorf("%s = %q for incoming %q, want reuse %v", middleware.RequestIDHeader, id, tt.header, tt.wantReuse)
+			}
+
+			logged := false
+			for _, entry := range srv.Logger().Entries() {
+				if entry.Message == "HTTP request" && entry.Fields["request_id"] == id {
+					logged = true
+				}
+			}
+			if !logged {
+				t.Errorf("no access log entry with request_id %s", id)
+			}
+		})
+	}
+}
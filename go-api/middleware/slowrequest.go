@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/gorilla/mux"
+)
+
+// Сколько байт тела запроса и ответа попадает в лог
+const bodyPreviewBytes = 2048
+
+// previewBuffer сохраняет только первые bodyPreviewBytes байт
+type previewBuffer struct {
+	bytes.Buffer
+}
+
+func (b *previewBuffer) Write(p []byte) (int, error) {
+	if room := bodyPreviewBytes - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// capturingWriter запоминает статус и начало тела ответа
+type capturingWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       previewBuffer
+}
+
+func (w *capturingWriter) WriteHeader(code int) {
+	w.statusCode = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// SlowRequestDetailMiddleware логирует полные детали запросов,
+// обработка которых заняла больше threshold
+func SlowRequestDetailMiddleware(threshold time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			var requestBody previewBuffer
+			if r.Body != nil {
+				original := r.Body
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.TeeReader(original, &requestBody), original}
+			}
+
+			cw := &capturingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(cw, r)
+
+			duration := time.Since(start)
+			if duration <= threshold {
+				return
+			}
+
+			logging.FromContext(r.Context()).Warn("Slow request details", map[string]interface{}{
+				"method":                r.Method,
+				"path":                  r.URL.Path,
+				"query":                 r.URL.RawQuery,
+				"status":                cw.statusCode,
+				"duration_ms":           duration.Milliseconds(),
+				"threshold_ms":          threshold.Milliseconds(),
+				"request_headers":       safeHeaders(r.Header),
+				"response_headers":      safeHeaders(cw.Header()),
+				"request_body_preview":  requestBody.String(),
+				"response_body_preview": cw.body.String(),
+			})
+		})
+	}
+}
+
+// safeHeaders копирует заголовки без чувствительных значений
+func safeHeaders(header http.Header) map[string][]string {
+	result := make(map[string][]string, len(header))
+	for name, values := range header {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			continue
+		}
+		result[name] = values
+	}
+	return result
+}
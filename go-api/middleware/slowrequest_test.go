@@ -0,0 +1,84 @@
+package middleware_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/router"
+	"github.com/crazy1997/go-api/testutil"
+)
+
+// constRand возвращает одно и то же число: 0 включает все имитации сбоев,
+// большое значение отключает их
+type constRand int
+
+func (r constRand) Intn(n int) int {
+	return min(int(r), n-1)
+}
+
+func TestSlowRequestDetail_SlowProductsPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		rand     constRand
+		wantLogs int
+	}{
+		{name: "fast products response", rand: 100, wantLogs: 0},
+		{name: "simulated slow products response", rand: 0, wantLogs: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Обработчикам нужен общий логгер, его создает тестовый сервер
+			testutil.NewTestServer(t)
+			srv := testutil.NewTestServerWith(t, testutil.TestServerOptions{
+				Router: router.Options{
+					SlowRequestThreshold: 500 * time.Millisecond,
+					Handler:              handlers.New(handlers.Config{Rand: tt.rand}),
+					APIKeys:              middleware.NewMemoryAPIKeyStore(testAPIKeys),
+				},
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/v1/products?category=electronics", nil)
+			req.Header.Set(middleware.APIKeyHeader, "key-billing-0001")
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("GET /v1/products: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want 200", resp.StatusCode)
+			}
+
+			var details []logging.LogEntry
+			for _, entry := range srv.Logger().Entries() {
+				if entry.Message == "Slow request details" {
+					details = append(details, entry)
+				}
+			}
+			if len(details) != tt.wantLogs {
+				t.Fatalf("slow request details logged %d times, want %d", len(details), tt.wantLogs)
+			}
+			if tt.wantLogs == 0 {
+				return
+			}
+
+			fields := details[0].Fields
+			if fields["path"] != "/v1/products" || fields["query"] != "category=electronics" || fields["status"] != float64(http.StatusOK) {
+				t.Errorf("path = %v, query = %v, status = %v", fields["path"], fields["query"], fields["status"])
+			}
+			if ms, _ := fields["duration_ms"].(float64); ms < 500 {
+				t.Errorf("duration_ms = %v, want above the 500 ms threshold", fields["duration_ms"])
+			}
+			if preview, _ := fields["response_body_preview"].(string); preview == "" {
+				t.Error("response_body_preview is empty")
+			}
+			if headers, _ := fields["response_headers"].(map[string]interface{}); headers["Content-Type"] == nil {
+				t.Errorf("response_headers = %v, want Content-Type", fields["response_headers"])
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/testutil"
+)
+
+func TestTimeout(t *testing.T) {
+	const deadline = 20 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "handler finishes in time",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Handler", "done")
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte("created"))
+			},
+			wantCode: http.StatusCreated,
+			wantBody: "created",
+		},
+		{
+			name: "handler sleeps past the deadline",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(5 * deadline)
+				w.Write([]byte("too late"))
+			},
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			name: "handler honours the context",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+					t.Error("request context has no deadline")
+				}
+				w.Write([]byte("cancelled"))
+			},
+			wantCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := testutil.NewTestServer(t)
+
+			rec := httptest.NewRecorder()
+			middleware.Timeout(deadline)(tt.handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/orders", nil))
+
+			if tt.wantCode == http.StatusServiceUnavailable {
+				assertJSONError(t, rec.Result(), http.StatusServiceUnavailable, "timeout")
+				if rec.Header().Get("X-Handler") != "" {
+					t.Error("handler headers leaked into the timeout response")
+				}
+
+				logged := false
+				for _, entry := range srv.Logger().Entries() {
+					if entry.Message == "Request timed out" && entry.Fields["timeout_ms"] == float64(deadline.Milliseconds()) {
+						logged = true
+					}
+				}
+				if !logged {
+					t.Error("timeout was not logged with timeout_ms")
+				}
+				return
+			}
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if got := rec.Header().Get("X-Handler"); got != "done" {
+				t.Errorf("X-Handler = %q, want handler headers copied", got)
+			}
+		})
+	}
+}
+
+func TestTimeout_PanicReachesRecovery(t *testing.T) {
+	testutil.NewTestServer(t)
+
+	handler := middleware.RecoveryMiddleware(middleware.Timeout(time.Second)(http.HandlerFunc(panickingHandler)))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/orders", nil))
+
+	assertJSONError(t, rec.Result(), http.StatusInternalServerError, "internal_error")
+}
@@ -0,0 +1,171 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/middleware"
+	"github.com/crazy1997/go-api/tracing"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useInMemoryTracing включает трассировку с экспортом в память на время теста
+func useInMemoryTracing(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	tracing.SetProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { tracing.SetProvider(nil) })
+	return exporter
+}
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTracing_Spans(t *testing.T) {
+	const parentTrace = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const parentSpan = "00f067aa0ba902b7"
+
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		wantName    string
+		wantRoute   string
+		wantStatus  int64
+		wantError   bool
+		wantEvent   string
+	}{
+		{name: "ok route", path: "/items/42", wantName: "GET /items/{id}", wantRoute: "/items/{id}", wantStatus: 200, wantEvent: "item.loaded"},
+		{name: "server error", path: "/fail", wantName: "GET /fail", wantRoute: "/fail", wantStatus: 500, wantError: true},
+		{
+			name:        "remote parent",
+			path:        "/items/7",
+			traceparent: "00-" + parentTrace + "-" + parentSpan + "-01",
+			wantName:    "GET /items/{id}",
+			wantRoute:   "/items/{id}",
+			wantStatus:  200,
+			wantEvent:   "item.loaded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := useInMemoryTracing(t)
+
+			r := mux.NewRouter()
+			r.Use(middleware.Tracing())
+			r.HandleFunc("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+				trace.SpanFromContext(r.Context()).AddEvent("item.loaded", trace.WithAttributes(
+					attribute.String("id", mux.Vars(r)["id"]),
+				))
+			})
+			r.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("exported %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+
+			if span.Name != tt.wantName {
+				t.Errorf("span name = %q, want %q", span.Name, tt.wantName)
+			}
+			if span.SpanKind != trace.SpanKindServer {
+				t.Errorf("span kind = %v, want server", span.SpanKind)
+			}
+
+			attrs := spanAttributes(span)
+			if got := attrs["http.method"].AsString(); got != http.MethodGet {
+				t.Errorf("http.method = %q, want GET", got)
+			}
+			if got := attrs["http.route"].AsString(); got != tt.wantRoute {
+				t.Errorf("http.route = %q, want %q", got, tt.wantRoute)
+			}
+			if got := attrs["http.status_code"].AsInt64(); got != tt.wantStatus {
+				t.Errorf("http.status_code = %d, want %d", got, tt.wantStatus)
+			}
+			if gotError := span.Status.Code == codes.Error; gotError != tt.wantError {
+				t.Errorf("status = %v, want error %v", span.Status.Code, tt.wantError)
+			}
+
+			if tt.wantEvent != "" && (len(span.Events) != 1 || span.Events[0].Name != tt.wantEvent) {
+				t.Errorf("events = %+v, want one %q", span.Events, tt.wantEvent)
+			}
+
+			if tt.traceparent != "" {
+				if got := span.SpanContext.TraceID().String(); got != parentTrace {
+					t.Errorf("trace id = %s, want the remote %s", got, parentTrace)
+				}
+				if got := span.Parent.SpanID().String(); got != parentSpan {
+					t.Errorf("parent span id = %s, want %s", got, parentSpan)
+				}
+			}
+		})
+	}
+}
+
+func TestTracing_DisabledPassesThrough(t *testing.T) {
+	tracing.SetProvider(nil)
+
+	called := false
+	handler := middleware.Tracing()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if trace.SpanFromContext(r.Context()).IsRecording() {
+			t.Error("span is recording without a tracer provider")
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !called {
+		t.Error("handler was not called")
+	}
+}
+
+// Логи обработчика содержат trace_id и span_id серверного спана
+func TestTracing_LogFieldsMatchSpan(t *testing.T) {
+	exporter := useInMemoryTracing(t)
+	logger := logging.NewBufferedLogger()
+	logging.SetDefault(logger)
+	t.Cleanup(func() { logging.SetDefault(nil) })
+
+	handler := middleware.RequestIDMiddleware(middleware.Tracing()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logging.Default().WithFields(logging.ContextFields(r.Context())).Info("handled", nil)
+	})))
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	entries := logger.Entries()
+	if len(spans) != 1 || len(entries) != 1 {
+		t.Fatalf("got %d spans and %d log entries, want 1 and 1", len(spans), len(entries))
+	}
+	sc := spans[0].SpanContext
+	fields := entries[0].Fields
+	if fields["trace_id"] != sc.TraceID().String() || fields["span_id"] != sc.SpanID().String() {
+		t.Errorf("log fields trace_id=%v span_id=%v, want span %s %s", fields["trace_id"], fields["span_id"], sc.TraceID(), sc.SpanID())
+	}
+	if fields["request_id"] == nil {
+		t.Error("log entry has no request_id")
+	}
+}
@@ -0,0 +1,28 @@
+package openapi_test
+
+import (
+	"testing"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/openapi"
+	"github.com/crazy1997/go-api/router"
+)
+
+// Спецификация репозитория должна описывать все маршруты роутера
+func TestRepositorySpecMatchesRouter(t *testing.T) {
+	spec, err := openapi.LoadSpec("../openapi.json")
+	if err != nil {
+		t.Fatalf("LoadSpec: %v", err)
+	}
+
+	logging.SetDefault(logging.NoopLogger{})
+	defer logging.SetDefault(nil)
+
+	r := router.New(router.Options{
+		Handler: handlers.New(handlers.Config{Logger: logging.NoopLogger{}}),
+	})
+	for _, mismatch := range openapi.ValidateSpecAgainstRoutes(spec, r) {
+		t.Errorf("route does not match openapi.json: %s", mismatch)
+	}
+}
@@ -0,0 +1,123 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Типы расхождений между спецификацией и роутером
+const (
+	// Маршрут описан в спецификации, но не зарегистрирован
+	MismatchMissingHandler = "missing_handler"
+	// Маршрут зарегистрирован, но не описан в спецификации
+	MismatchUndocumented = "undocumented_route"
+)
+
+// OpenAPISpec - минимальная часть OpenAPI 3 документа, нужная для сверки маршрутов
+type OpenAPISpec struct {
+	OpenAPI string                          `json:"openapi"`
+	Info    map[string]interface{}          `json:"info"`
+	Paths   map[string]map[string]Operation `json:"paths"`
+}
+
+// Operation описывает операцию над путем
+type Operation struct {
+	Summary     string `json:"summary,omitempty"`
+	OperationID string `json:"operationId,omitempty"`
+}
+
+// SpecMismatch описывает маршрут, который есть только с одной стороны
+type SpecMismatch struct {
+	Type   string `json:"type"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+func (m SpecMismatch) String() string {
+	return fmt.Sprintf("%s: %s %s", m.Type, m.Method, m.Path)
+}
+
+// LoadSpec читает спецификацию из JSON файла
+func LoadSpec(path string) (*OpenAPISpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read spec: %w", err)
+	}
+
+	var spec OpenAPISpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("parse spec: %w", err)
+	}
+	return &spec, nil
+}
+
+// Регулярные выражения в переменных пути mux: {id:[0-9]+} -> {id}
+var muxVarPattern = regexp.MustCompile(`\{([^}:]+):[^}]+\}`)
+
+// Ключи Paths, которые не являются HTTP методами
+var nonMethodKeys = map[string]bool{
+	"parameters":  true,
+	"summary":     true,
+	"description": true,
+	"servers":     true,
+}
+
+// ValidateSpecAgainstRoutes сверяет маршруты спецификации с роутером.
+// Учитываются только маршруты с явно заданными методами
+func ValidateSpecAgainstRoutes(spec *OpenAPISpec, router *mux.Router) []SpecMismatch {
+	documented := make(map[string]bool)
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if nonMethodKeys[method] {
+				continue
+			}
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	registered := make(map[string]bool)
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		path = muxVarPattern.ReplaceAllString(path, "{$1}")
+		for _, method := range methods {
+			registered[method+" "+path] = true
+		}
+		return nil
+	})
+
+	var mismatches []SpecMismatch
+	for key := range documented {
+		if !registered[key] {
+			mismatches = append(mismatches, newMismatch(MismatchMissingHandler, key))
+		}
+	}
+	for key := range registered {
+		if !documented[key] {
+			mismatches = append(mismatches, newMismatch(MismatchUndocumented, key))
+		}
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool {
+		return mismatches[i].String() < mismatches[j].String()
+	})
+	return mismatches
+}
+
+func newMismatch(kind, key string) SpecMismatch {
+	method, path, _ := strings.Cut(key, " ")
+	return SpecMismatch{Type: kind, Method: method, Path: path}
+}
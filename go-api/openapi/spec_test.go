@@ -0,0 +1,88 @@
+package openapi
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func newSpecTestRouter() *mux.Router {
+	r := mux.NewRouter()
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	r.HandleFunc("/api/health", ok).Methods("GET")
+	r.HandleFunc("/api/users/{id:[0-9]+}", ok).Methods("GET", "DELETE")
+	r.PathPrefix("/static/").HandlerFunc(ok)
+	return r
+}
+
+func TestValidateSpecAgainstRoutes(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths map[string]map[string]Operation
+		want  []SpecMismatch
+	}{
+		{
+			name: "spec matches routes",
+			paths: map[string]map[string]Operation{
+				"/api/health":     {"get": {}},
+				"/api/users/{id}": {"get": {}, "delete": {}, "parameters": {}},
+			},
+		},
+		{
+			name: "spec route without handler",
+			paths: map[string]map[string]Operation{
+				"/api/health":     {"get": {}},
+				"/api/users/{id}": {"get": {}, "delete": {}},
+				"/api/orders":     {"post": {}},
+			},
+			want: []SpecMismatch{{Type: MismatchMissingHandler, Method: "POST", Path: "/api/orders"}},
+		},
+		{
+			name: "route missing from spec",
+			paths: map[string]map[string]Operation{
+				"/api/health":     {"get": {}},
+				"/api/users/{id}": {"get": {}},
+			},
+			want: []SpecMismatch{{Type: MismatchUndocumented, Method: "DELETE", Path: "/api/users/{id}"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateSpecAgainstRoutes(&OpenAPISpec{Paths: tt.paths}, newSpecTestRouter())
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mismatches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSpec(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "openapi.json")
+	if err := os.WriteFile(valid, []byte(`{"openapi": "3.0.3", "paths": {"/api/health": {"get": {"operationId": "health"}}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "broken.json")
+	if err := os.WriteFile(invalid, []byte(`{"paths": [`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := LoadSpec(valid)
+	if err != nil {
+		t.Fatalf("LoadSpec: %v", err)
+	}
+	if spec.Paths["/api/health"]["get"].OperationID != "health" {
+		t.Errorf("paths = %v, want GET /api/health with operationId health", spec.Paths)
+	}
+
+	for _, path := range []string{invalid, filepath.Join(dir, "missing.json")} {
+		if _, err := LoadSpec(path); err == nil {
+			t.Errorf("LoadSpec(%s) succeeded, want an error", filepath.Base(path))
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package router
+
+import (
+	"net/http"
+	"time"
+
+	v1 "github.com/crazy1997/go-api/api/v1"
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/metrics"
+	"github.com/crazy1997/go-api/middleware"
+	sentryhttp "github.com/getsentry/sentry-go/http"
+	"github.com/gorilla/mux"
+)
+
+// Таймаут health-проверки
+const healthRouteTimeout = time.Second
+
+// Options настраивает опциональные части роутера
+type Options struct {
+	// Зеркалирование трафика, пустой MirrorURL отключает его
+	MirrorURL        string
+	MirrorPercentage float64
+
+	// Детальный лог запросов дольше порога, 0 отключает его
+	SlowRequestThreshold time.Duration
+
+	// Origin, которым разрешены кросс-доменные запросы, "*" разрешает всем,
+	// пустой список отключает CORS
+	CORSAllowedOrigins []string
+
+	// Ограничение частоты запросов с одного IP, 0 отключает его
+	RateLimitRPS   float64
+	RateLimitBurst int
+
+	// Подсети обратных прокси, которым доверяется X-Forwarded-For.
+	// Пустой список - адрес клиента берется только из соединения
+	TrustedProxyCIDRs []string
+
+	// Лимит одновременно обрабатываемых запросов, сверх него - 503,
+	// 0 отключает его
+	MaxActiveRequests int
+
+	// Лимит размера тела запроса в байтах, 0 отключает его
+	MaxBodyBytes int64
+
+	// Секрет HS256 для JWT на /v1/users, /v1/orders и /v1/products,
+	// пустой отключает проверку
+	JWTSecret string
+
+	// API ключи интеграций, принимаются на тех же маршрутах вместо JWT.
+	// nil отключает авторизацию по ключу
+	APIKeys middleware.APIKeyStore
+
+	// Токен для /admin эндпоинтов, пустой закрывает доступ
+	AdminToken string
+
+	// Подсети, которым доступны /admin и /metrics, пустой список
+	// закрывает доступ всем
+	AdminAllowedCIDRs []string
+
+	// Каталог статики, по умолчанию ./static/
+	StaticDir string
+
+	// Обработчики API, по умолчанию handlers.New с общим логгером и метриками
+	Handler *handlers.Handler
+
+	// Routes регистрирует дополнительные маршруты до catch-all статики
+	Routes func(r *mux.Router)
+}
+
+// New собирает роутер приложения со всеми middleware и маршрутами.
+// Используется в main и в testutil, чтобы тесты проверяли ту же цепочку
+func New(opts Options) *mux.Router {
+	r := mux.NewRouter()
+
+	h := opts.Handler
+	if h == nil {
+		h = handlers.New(handlers.Config{})
+	}
+
+	proxies := middleware.NewTrustedProxies(opts.TrustedProxyCIDRs)
+
+	// Хаб Sentry на запрос: события паник получают данные запроса
+	r.Use(sentryhttp.New(sentryhttp.Options{}).Handle)
+
+	// Перехват паник в обработчиках
+	r.Use(middleware.RecoveryMiddleware)
+
+	// Идентификатор запроса для логов и заголовка X-Request-ID
+	r.Use(middleware.RequestIDMiddleware)
+
+	// Корневой спан трассировки запроса
+	r.Use(middleware.Tracing())
+
+	// CORS заголовки и ответ на preflight
+	r.Use(middleware.CORS(opts.CORSAllowedOrigins))
+
+	// Сброс нагрузки при перегрузке сервера
+	r.Use(middleware.LoadShedding(opts.MaxActiveRequests))
+
+	// Адрес клиента с учетом доверенных прокси
+	r.Use(middleware.ClientIPMiddleware(proxies))
+
+	// Ограничение частоты запросов по IP клиента
+	r.Use(middleware.RateLimit(opts.RateLimitRPS, opts.RateLimitBurst))
+
+	// Ограничение размера тела запроса
+	r.Use(middleware.MaxBodyBytes(opts.MaxBodyBytes))
+
+	// Глобальный middleware для метрик
+	r.Use(metrics.MetricsMiddleware)
+
+	// Access log с исходом запроса
+	r.Use(middleware.AccessLogMiddleware)
+
+	// Внедрение сбоев по заголовкам X-Fault-* (FAULT_INJECTION_ENABLED=true)
+	r.Use(middleware.FaultInjection())
+
+	// Зеркалирование трафика на staging (опционально)
+	if opts.MirrorURL != "" {
+		r.Use(middleware.MirrorMiddleware(opts.MirrorURL, opts.MirrorPercentage))
+	}
+
+	// Подробности медленных запросов для отладки
+	if opts.SlowRequestThreshold > 0 {
+		r.Use(middleware.SlowRequestDetailMiddleware(opts.SlowRequestThreshold))
+	}
+
+	// SLA по времени ответа health-проверки
+	healthTimeout := middleware.Timeout(healthRouteTimeout)
+
+	// Служебные эндпоинты вне версий API
+	r.Handle("/api/health", healthTimeout(http.HandlerFunc(h.HealthHandler))).Methods("GET")
+	r.HandleFunc("/api/ready", h.ReadinessHandler).Methods("GET")
+	r.HandleFunc("/api/metrics/info", h.MetricsHandler).Methods("GET")
+
+	// Версионированное API. Прежние пути /api/... обслуживаются той же
+	// версией v1, пока клиенты не перейдут на /v1
+	v1Opts := v1.Options{JWTSecret: opts.JWTSecret, APIKeys: opts.APIKeys, AdminToken: opts.AdminToken}
+	v1.RegisterRoutes(r.PathPrefix("/v1").Subrouter(), h, v1Opts)
+	v1.RegisterRoutes(r.PathPrefix("/api").Subrouter(), h, v1Opts)
+
+	// Административные эндпоинты и метрики доступны только из внутренних
+	// сетей, без ADMIN_ALLOWED_CIDRS закрыты для всех
+	internalOnly := middleware.IPWhitelist(opts.AdminAllowedCIDRs, proxies)
+
+	admin := r.PathPrefix("/admin").Subrouter()
+	admin.Use(internalOnly)
+	admin.Use(middleware.AdminToken(opts.AdminToken))
+	admin.HandleFunc("/loglevel", h.LogLevelHandler).Methods("PUT")
+
+	// Prometheus метрики
+	r.Handle("/metrics", internalOnly(metrics.Handler()))
+
+	if opts.Routes != nil {
+		opts.Routes(r)
+	}
+
+	// Статика
+	staticDir := opts.StaticDir
+	if staticDir == "" {
+		staticDir = "./static/"
+	}
+	r.PathPrefix("/").Handler(http.FileServer(http.Dir(staticDir)))
+
+	return r
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/crazy1997/go-api/config"
+	"github.com/crazy1997/go-api/logging"
+)
+
+// newServer создает HTTP сервер с адресом и таймаутами из конфигурации
+func newServer(cfg *config.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              "0.0.0.0:" + cfg.Port,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// logServerConfig пишет итоговую конфигурацию сервера
+func logServerConfig(logger *logging.ELKLogger, server *http.Server) {
+	logger.Info("HTTP server configuration", map[string]interface{}{
+		"addr":                server.Addr,
+		"read_timeout":        server.ReadTimeout.String(),
+		"read_header_timeout": server.ReadHeaderTimeout.String(),
+		"write_timeout":       server.WriteTimeout.String(),
+		"idle_timeout":        server.IdleTimeout.String(),
+		"max_header_bytes":    server.MaxHeaderBytes,
+	})
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/config"
+)
+
+func TestNewServer_TimeoutsFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    *http.Server
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			want: &http.Server{
+				Addr:           "0.0.0.0:8080",
+				ReadTimeout:    15 * time.Second,
+				WriteTimeout:   15 * time.Second,
+				IdleTimeout:    60 * time.Second,
+				MaxHeaderBytes: http.DefaultMaxHeaderBytes,
+			},
+		},
+		{
+			name: "custom values",
+			env: map[string]string{
+				"PORT":                             "9090",
+				"HTTP_READ_TIMEOUT_SECONDS":        "5",
+				"HTTP_READ_HEADER_TIMEOUT_SECONDS": "2",
+				"HTTP_WRITE_TIMEOUT_SECONDS":       "30",
+				"HTTP_IDLE_TIMEOUT_SECONDS":        "120",
+				"HTTP_MAX_HEADER_BYTES":            "65536",
+			},
+			want: &http.Server{
+				Addr:              "0.0.0.0:9090",
+				ReadTimeout:       5 * time.Second,
+				ReadHeaderTimeout: 2 * time.Second,
+				WriteTimeout:      30 * time.Second,
+				IdleTimeout:       120 * time.Second,
+				MaxHeaderBytes:    65536,
+			},
+		},
+		{name: "invalid timeout", env: map[string]string{"HTTP_IDLE_TIMEOUT_SECONDS": "forever"}, wantErr: "HTTP_IDLE_TIMEOUT_SECONDS"},
+		{name: "zero header limit", env: map[string]string{"HTTP_MAX_HEADER_BYTES": "0"}, wantErr: "HTTP_MAX_HEADER_BYTES"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CURSOR_SECRET", "test-secret")
+			t.Setenv("JWT_SECRET", "test-jwt-secret")
+			for _, name := range []string{"PORT", "HTTP_READ_TIMEOUT_SECONDS", "HTTP_READ_HEADER_TIMEOUT_SECONDS", "HTTP_WRITE_TIMEOUT_SECONDS", "HTTP_IDLE_TIMEOUT_SECONDS", "HTTP_MAX_HEADER_BYTES"} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			cfg, err := config.Load()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			server := newServer(cfg, http.NotFoundHandler())
+			if server.Addr != tt.want.Addr || server.MaxHeaderBytes != tt.want.MaxHeaderBytes {
+				t.Errorf("addr = %q, max header bytes = %d, want %q and %d", server.Addr, server.MaxHeaderBytes, tt.want.Addr, tt.want.MaxHeaderBytes)
+			}
+			if server.ReadTimeout != tt.want.ReadTimeout || server.ReadHeaderTimeout != tt.want.ReadHeaderTimeout {
+				t.Errorf("read = %v, read header = %v, want %v and %v", server.ReadTimeout, server.ReadHeaderTimeout, tt.want.ReadTimeout, tt.want.ReadHeaderTimeout)
+			}
+			if server.WriteTimeout != tt.want.WriteTimeout || server.IdleTimeout != tt.want.IdleTimeout {
+				t.Errorf("write = %v, idle = %v, want %v and %v", server.WriteTimeout, server.IdleTimeout, tt.want.WriteTimeout, tt.want.IdleTimeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/crazy1997/go-api/bus"
+	"github.com/crazy1997/go-api/config"
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/metrics"
+	"github.com/crazy1997/go-api/tracing"
+	"github.com/getsentry/sentry-go"
+)
+
+// ShutdownConfig задает таймауты этапов graceful shutdown
+type ShutdownConfig struct {
+	// Окно, в котором сервер еще принимает запросы, но readiness уже
+	// сообщает о недоступности, чтобы балансировщик убрал инстанс
+	GracePeriod time.Duration
+
+	// Общее время от сигнала до принудительного закрытия соединений
+	HTTPDrainTimeout time.Duration
+
+	OrderProcessingDrainTimeout time.Duration
+	TraceFlushTimeout           time.Duration
+	ErrorFlushTimeout           time.Duration
+	LogFlushTimeout             time.Duration
+}
+
+// Больший SHUTDOWN_TIMEOUT_SECONDS обычно превышает terminationGracePeriod пода
+const maxSafeShutdownTimeout = 120 * time.Second
+
+// Компонент шины здоровья, который переводится в down на время grace
+const serverComponent = "http_server"
+
+func defaultShutdownConfig() ShutdownConfig {
+	return ShutdownConfig{
+		HTTPDrainTimeout:            10 * time.Second,
+		OrderProcessingDrainTimeout: 5 * time.Second,
+		TraceFlushTimeout:           5 * time.Second,
+		ErrorFlushTimeout:           2 * time.Second,
+		LogFlushTimeout:             5 * time.Second,
+	}
+}
+
+// newShutdownConfig берет таймауты остановки HTTP из конфигурации
+func newShutdownConfig(logger logging.Logger, cfg *config.Config) ShutdownConfig {
+	shutdown := defaultShutdownConfig()
+	shutdown.HTTPDrainTimeout = cfg.ShutdownTimeout
+	shutdown.GracePeriod = cfg.ShutdownGrace
+
+	if shutdown.HTTPDrainTimeout > maxSafeShutdownTimeout {
+		logger.Warn("SHUTDOWN_TIMEOUT_SECONDS exceeds safe maximum", map[string]interface{}{
+			"shutdown_timeout": shutdown.HTTPDrainTimeout.String(),
+			"safe_maximum":     maxSafeShutdownTimeout.String(),
+		})
+	}
+
+	return shutdown
+}
+
+// gracefulShutdown останавливает сервер по этапам: grace с отказом readiness,
+// прием запросов, обработка заказов, отправка трасс, ошибок и логов.
+// Запросы, не завершившиеся за HTTPDrainTimeout, обрываются
+func gracefulShutdown(server *http.Server, logger logging.Logger, cfg ShutdownConfig) {
+	if cfg.GracePeriod > 0 {
+		bus.Default.Publish(bus.HealthEvent{
+			Component: serverComponent,
+			Status:    bus.StatusDown,
+			Error:     "shutting down",
+		})
+
+		runShutdownStage(logger, "grace", cfg.GracePeriod, func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+	}
+
+	runShutdownStage(logger, "http_drain", cfg.HTTPDrainTimeout-cfg.GracePeriod, func(ctx context.Context) error {
+		err := server.Shutdown(ctx)
+		if errors.Is(err, context.DeadlineExceeded) {
+			server.Close()
+		}
+		return err
+	})
+	runShutdownStage(logger, "order_drain", cfg.OrderProcessingDrainTimeout, handlers.WaitForOrders)
+	runShutdownStage(logger, "trace_flush", cfg.TraceFlushTimeout, tracing.Shutdown)
+	runShutdownStage(logger, "sentry_flush", cfg.ErrorFlushTimeout, func(ctx context.Context) error {
+		// Без SENTRY_DSN клиента нет и отправлять нечего
+		if sentry.CurrentHub().Client() == nil {
+			return nil
+		}
+		if !sentry.Flush(cfg.ErrorFlushTimeout) {
+			return errors.New("pending Sentry events were not sent")
+		}
+		return nil
+	})
+
+	// Итоговая запись должна попасть в очередь до Close, после него
+	// записи (включая итог этапа log_flush) уходят только в консоль
+	logger.Info("Server stopped gracefully", nil)
+	runShutdownStage(logger, "log_flush", cfg.LogFlushTimeout, logger.Close)
+}
+
+func runShutdownStage(logger logging.Logger, stage string, timeout time.Duration, fn func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	duration := time.Since(start)
+
+	metrics.RecordShutdownStage(stage, duration)
+
+	fields := map[string]interface{}{
+		"stage":       stage,
+		"duration_ms": duration.Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		logger.Error("Shutdown stage failed", fields)
+		return
+	}
+
+	logger.Info("Shutdown stage completed", fields)
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/bus"
+	"github.com/crazy1997/go-api/config"
+	"github.com/crazy1997/go-api/logging"
+)
+
+func TestGracefulShutdown_StageOrder(t *testing.T) {
+	logger := logging.NewBufferedLogger()
+
+	started := make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		logger.Info("In-flight request finished", nil)
+	})}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go server.Serve(ln)
+
+	result := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err == nil {
+			resp.Body.Close()
+		}
+		result <- err
+	}()
+	<-started
+
+	cfg := defaultShutdownConfig()
+	cfg.HTTPDrainTimeout = 2 * time.Second
+	gracefulShutdown(server, logger, cfg)
+
+	if err := <-result; err != nil {
+		t.Fatalf("in-flight request failed during shutdown: %v", err)
+	}
+
+	var got []string
+	for _, entry := range logger.Entries() {
+		if stage, ok := entry.Fields["stage"].(string); ok {
+			if entry.Message != "Shutdown stage completed" {
+				t.Errorf("stage %s: %s (%v)", stage, entry.Message, entry.Fields["error"])
+			}
+			got = append(got, stage)
+			continue
+		}
+		got = append(got, entry.Message)
+	}
+
+	want := []string{
+		"In-flight request finished",
+		"http_drain",
+		"order_drain",
+		"trace_flush",
+		"sentry_flush",
+		"Server stopped gracefully",
+		"log_flush",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("log sequence = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("log sequence = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestNewShutdownConfig_FromEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantTimeout time.Duration
+		wantGrace   time.Duration
+		wantWarn    bool
+		wantErr     string
+	}{
+		{name: "defaults", wantTimeout: 10 * time.Second},
+		{
+			name:        "batch deployment",
+			env:         map[string]string{"SHUTDOWN_TIMEOUT_SECONDS": "60", "SHUTDOWN_GRACE_SECONDS": "5"},
+			wantTimeout: 60 * time.Second,
+			wantGrace:   5 * time.Second,
+		},
+		{
+			name:        "safe maximum",
+			env:         map[string]string{"SHUTDOWN_TIMEOUT_SECONDS": "120"},
+			wantTimeout: 120 * time.Second,
+		},
+		{
+			name:        "above safe maximum",
+			env:         map[string]string{"SHUTDOWN_TIMEOUT_SECONDS": "300"},
+			wantTimeout: 300 * time.Second,
+			wantWarn:    true,
+		},
+		{name: "grace not shorter than timeout", env: map[string]string{"SHUTDOWN_TIMEOUT_SECONDS": "5", "SHUTDOWN_GRACE_SECONDS": "5"}, wantErr: "SHUTDOWN_GRACE_SECONDS"},
+		{name: "negative timeout", env: map[string]string{"SHUTDOWN_TIMEOUT_SECONDS": "-1"}, wantErr: "SHUTDOWN_TIMEOUT_SECONDS"},
+		{name: "invalid grace", env: map[string]string{"SHUTDOWN_GRACE_SECONDS": "soon"}, wantErr: "SHUTDOWN_GRACE_SECONDS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CURSOR_SECRET", "test-secret")
+			t.Setenv("JWT_SECRET", "test-jwt-secret")
+			for _, name := range []string{"SHUTDOWN_TIMEOUT_SECONDS", "SHUTDOWN_GRACE_SECONDS"} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			cfg, err := config.Load()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			logger := logging.NewBufferedLogger()
+			shutdown := newShutdownConfig(logger, cfg)
+			if shutdown.HTTPDrainTimeout != tt.wantTimeout || shutdown.GracePeriod != tt.wantGrace {
+				t.Errorf("drain = %v, grace = %v, want %v and %v", shutdown.HTTPDrainTimeout, shutdown.GracePeriod, tt.wantTimeout, tt.wantGrace)
+			}
+
+			warned := false
+			for _, entry := range logger.Entries() {
+				if entry.Level == "WARN" && entry.Message == "SHUTDOWN_TIMEOUT_SECONDS exceeds safe maximum" {
+					warned = true
+				}
+			}
+			if warned != tt.wantWarn {
+				t.Errorf("safe maximum warning = %v, want %v", warned, tt.wantWarn)
+			}
+		})
+	}
+}
+
+// По SIGTERM сервер отдает readiness down, в течение grace еще принимает
+// запросы и обрывает зависшие запросы к концу SHUTDOWN_TIMEOUT_SECONDS
+func TestGracefulShutdown_SIGTERM(t *testing.T) {
+	t.Setenv("CURSOR_SECRET", "test-secret")
+	t.Setenv("JWT_SECRET", "test-jwt-secret")
+	t.Setenv("SHUTDOWN_TIMEOUT_SECONDS", "2")
+	t.Setenv("SHUTDOWN_GRACE_SECONDS", "1")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	logger := logging.NewBufferedLogger()
+	shutdownConfig := newShutdownConfig(logger, cfg)
+
+	health := make(chan bus.HealthEvent, 4)
+	bus.Subscribe(serverComponent, health)
+	t.Cleanup(func() {
+		bus.Publish(bus.HealthEvent{Component: serverComponent, Status: bus.StatusUp})
+	})
+
+	started := make(chan struct{})
+	routes := http.NewServeMux()
+	routes.HandleFunc("/stuck", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+	routes.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := &http.Server{Handler: routes}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go server.Serve(ln)
+	url := "http://" + ln.Addr().String()
+
+	stuck := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(url + "/stuck")
+		if err == nil {
+			resp.Body.Close()
+		}
+		stuck <- err
+	}()
+	<-started
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := self.Signal(syscall.SIGTERM); err != nil {
+		t.Fatalf("send SIGTERM: %v", err)
+	}
+	select {
+	case <-stop:
+	case <-time.After(time.Second):
+		t.Fatal("SIGTERM was not delivered")
+	}
+
+	begin := time.Now()
+	done := make(chan struct{})
+	go func() {
+		gracefulShutdown(server, logger, shutdownConfig)
+		close(done)
+	}()
+
+	select {
+	case event := <-health:
+		if event.Status != bus.StatusDown {
+			t.Errorf("health event = %+v, want %s down", event, serverComponent)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("readiness was not switched off at the start of grace")
+	}
+
+	// В течение grace новые запросы еще обслуживаются
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get(url + "/")
+	if err != nil {
+		t.Fatalf("request during grace failed: %v", err)
+	}
+	resp.Body.Close()
+
+	select {
+	case <-done:
+	case <-time.After(cfg.ShutdownTimeout + 2*time.Second):
+		t.Fatal("server did not stop within SHUTDOWN_TIMEOUT_SECONDS")
+	}
+	elapsed := time.Since(begin)
+	if elapsed < cfg.ShutdownTimeout || elapsed > cfg.ShutdownTimeout+time.Second {
+		t.Errorf("shutdown took %v, want about %v", elapsed, cfg.ShutdownTimeout)
+	}
+
+	if err := <-stuck; err == nil {
+		t.Error("stuck request completed, want the connection closed after the drain timeout")
+	}
+	if _, err := client.Get(url + "/"); err == nil {
+		t.Error("server still accepts connections after shutdown")
+	}
+
+	stages := make(map[string]string)
+	for _, entry := range logger.Entries() {
+		if stage, ok := entry.Fields["stage"].(string); ok {
+			stages[stage] = entry.Message
+		}
+	}
+	if stages["grace"] != "Shutdown stage completed" {
+		t.Errorf("grace stage: %q, want completed", stages["grace"])
+	}
+	if stages["http_drain"] != "Shutdown stage failed" {
+		t.Errorf("http_drain stage: %q, want failed on the drain deadline", stages["http_drain"])
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Период повторной проверки Logstash во время запуска
+var startupPingInterval = time.Second
+
+// startupLogger - логгер с проверкой доступности Logstash, см. ELKLogger.Ping
+type startupLogger interface {
+	Info(message string, fields map[string]interface{})
+	Warn(message string, fields map[string]interface{})
+	Ping() error
+}
+
+// awaitStartup ждет доступности Logstash и переводит started в true,
+// после чего /api/health отвечает healthy. Недоступный Logstash не мешает
+// работе: по истечении timeout запуск завершается с предупреждением,
+// записи уходят в консоль и fallback
+func awaitStartup(logger startupLogger, started *atomic.Bool, timeout time.Duration) {
+	begin := time.Now()
+	deadline := begin.Add(timeout)
+
+	for {
+		err := logger.Ping()
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			fmt.Fprintf(os.Stderr, "WARNING: Logstash is unreachable at startup: %v\n", err)
+			logger.Warn("Startup timeout reached, Logstash is unreachable", map[string]interface{}{
+				"timeout_seconds": timeout.Seconds(),
+				"error":           err.Error(),
+			})
+			break
+		}
+		time.Sleep(startupPingInterval)
+	}
+
+	started.Store(true)
+	logger.Info("Service status changed from starting to healthy", map[string]interface{}{
+		"startup_ms": time.Since(begin).Milliseconds(),
+	})
+}
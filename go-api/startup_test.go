@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	handlers "github.com/crazy1997/go-api/hadnlers"
+	"github.com/crazy1997/go-api/logging"
+)
+
+// pingLogger - BufferedLogger, у которого Ping возвращает ошибки из pings
+// по очереди, затем nil. Перед каждой проверкой вызывается onPing
+type pingLogger struct {
+	*logging.BufferedLogger
+	pings  []error
+	calls  atomic.Int64
+	onPing func()
+}
+
+func (l *pingLogger) Ping() error {
+	n := int(l.calls.Add(1))
+	if l.onPing != nil {
+		l.onPing()
+	}
+	if n <= len(l.pings) {
+		return l.pings[n-1]
+	}
+	return nil
+}
+
+// getHealthStatus возвращает код и status ответа /api/health
+func getHealthStatus(t *testing.T, h *handlers.Handler) (int, string) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.HealthHandler(rec, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode health: %v", err)
+	}
+	return rec.Code, body.Status
+}
+
+func TestAwaitStartup(t *testing.T) {
+	prev := startupPingInterval
+	startupPingInterval = 5 * time.Millisecond
+	t.Cleanup(func() { startupPingInterval = prev })
+
+	errUnreachable := errors.New("connection refused")
+
+	tests := []struct {
+		name        string
+		pings       []error
+		timeout     time.Duration
+		wantPings   int64
+		wantTimeout bool
+	}{
+		{name: "logstash reachable", timeout: time.Second, wantPings: 1},
+		{name: "logstash comes up", pings: []error{errUnreachable, errUnreachable}, timeout: time.Second, wantPings: 3},
+		{
+			name:        "startup timeout",
+			pings:       []error{errUnreachable, errUnreachable, errUnreachable, errUnreachable, errUnreachable, errUnreachable, errUnreachable, errUnreachable, errUnreachable, errUnreachable},
+			timeout:     20 * time.Millisecond,
+			wantTimeout: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var started atomic.Bool
+			h := handlers.New(handlers.Config{Logger: logging.NoopLogger{}, Started: &started})
+			logger := &pingLogger{BufferedLogger: logging.NewBufferedLogger(), pings: tt.pings}
+
+			// Пока идет запуск, health отвечает 503 starting
+			logger.onPing = func() {
+				if code, status := getHealthStatus(t, h); code != http.StatusServiceUnavailable || status != "starting" {
+					t.Errorf("health during startup = %d %q, want 503 starting", code, status)
+				}
+			}
+
+			awaitStartup(logger, &started, tt.timeout)
+
+			if !started.Load() {
+				t.Fatal("started is false after awaitStartup")
+			}
+			if code, status := getHealthStatus(t, h); code != http.StatusOK || status != "healthy" {
+				t.Errorf("health after startup = %d %q, want 200 healthy", code, status)
+			}
+			if tt.wantPings > 0 && logger.calls.Load() != tt.wantPings {
+				t.Errorf("Ping called %d times, want %d", logger.calls.Load(), tt.wantPings)
+			}
+
+			var healthy, timedOut bool
+			for _, entry := range logger.Entries() {
+				switch entry.Message {
+				case "Service status changed from starting to healthy":
+					healthy = true
+				case "Startup timeout reached, Logstash is unreachable":
+					timedOut = entry.Level == "WARN"
+				}
+			}
+			if !healthy {
+				t.Error("no starting to healthy transition entry")
+			}
+			if timedOut != tt.wantTimeout {
+				t.Errorf("startup timeout warning = %v, want %v", timedOut, tt.wantTimeout)
+			}
+		})
+	}
+}
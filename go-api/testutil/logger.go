@@ -0,0 +1,78 @@
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/crazy1997/go-api/logging"
+)
+
+// MockLogger - mock Logstash, на который логгер приложения отправляет записи.
+// Записи проходят тот же путь, что и в production, включая хуки и маскирование
+type MockLogger struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	entries []logging.LogEntry
+}
+
+func newMockLogger() *MockLogger {
+	m := &MockLogger{}
+	m.server = httptest.NewServer(http.HandlerFunc(m.receive))
+	return m
+}
+
+func (m *MockLogger) receive(w http.ResponseWriter, r *http.Request) {
+	var entry logging.LogEntry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	m.entries = append(m.entries, entry)
+	m.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// URL возвращает адрес mock Logstash
+func (m *MockLogger) URL() string {
+	return m.server.URL
+}
+
+// Entries дожидается отправки логов и возвращает полученные записи
+func (m *MockLogger) Entries() []logging.LogEntry {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	logging.GetLogger().Flush(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]logging.LogEntry(nil), m.entries...)
+}
+
+// Messages возвращает сообщения полученных записей в порядке поступления
+func (m *MockLogger) Messages() []string {
+	entries := m.Entries()
+	messages := make([]string, len(entries))
+	for i, entry := range entries {
+		messages[i] = entry.Message
+	}
+	return messages
+}
+
+// Reset очищает полученные записи
+func (m *MockLogger) Reset() {
+	m.Entries()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = nil
+}
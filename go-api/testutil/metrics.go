@@ -0,0 +1,55 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// MetricValue возвращает значение счетчика, gauge или число наблюдений
+// гистограммы name с метками labels из реестра по умолчанию.
+// Метки, не указанные в labels, не учитываются. Без серии возвращает 0
+func MetricValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+	setup()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("gather metrics: %v", err)
+	}
+
+	var total float64
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if !labelsMatch(m.GetLabel(), labels) {
+				continue
+			}
+			switch {
+			case m.Counter != nil:
+				total += m.GetCounter().GetValue()
+			case m.Gauge != nil:
+				total += m.GetGauge().GetValue()
+			case m.Histogram != nil:
+				total += float64(m.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return total
+}
+
+func labelsMatch(pairs []*dto.LabelPair, want map[string]string) bool {
+	matched := 0
+	for _, pair := range pairs {
+		if v, ok := want[pair.GetName()]; ok {
+			if v != pair.GetValue() {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(want)
+}
@@ -0,0 +1,88 @@
+package testutil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/crazy1997/go-api/config"
+	"github.com/crazy1997/go-api/logging"
+	"github.com/crazy1997/go-api/metrics"
+	"github.com/crazy1997/go-api/router"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Логгер и метрики - синглтоны процесса, поэтому инициализируются один раз
+// и общие для всех тестовых серверов. Тесты, проверяющие логи, не должны
+// использовать t.Parallel()
+var (
+	initOnce   sync.Once
+	mockLogger *MockLogger
+)
+
+func setup() {
+	initOnce.Do(func() {
+		mockLogger = newMockLogger()
+		os.Setenv("LOGSTASH_URL", mockLogger.URL())
+		if os.Getenv("CURSOR_SECRET") == "" {
+			os.Setenv("CURSOR_SECRET", "test-cursor-secret")
+		}
+		if os.Getenv("JWT_SECRET") == "" {
+			os.Setenv("JWT_SECRET", "test-jwt-secret")
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			panic(err)
+		}
+
+		logging.InitLogger(context.Background(), cfg)
+		metrics.Init(context.Background(), metrics.MetricsConfig{})
+	})
+}
+
+// TestServerOptions настраивает тестовый сервер.
+// Дополнительные маршруты для теста задаются через Router.Routes
+type TestServerOptions struct {
+	Router router.Options
+}
+
+// TestServer - httptest.Server с production цепочкой middleware
+type TestServer struct {
+	*httptest.Server
+}
+
+// NewTestServer создает тестовый сервер с настройками по умолчанию
+func NewTestServer(t *testing.T) *TestServer {
+	return NewTestServerWith(t, TestServerOptions{})
+}
+
+// NewTestServerWith создает тестовый сервер с заданными опциями
+func NewTestServerWith(t *testing.T, opts TestServerOptions) *TestServer {
+	t.Helper()
+	setup()
+
+	mockLogger.Reset()
+
+	s := &TestServer{Server: httptest.NewServer(router.New(opts.Router))}
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Client возвращает HTTP клиент тестового сервера
+func (s *TestServer) Client() *http.Client {
+	return s.Server.Client()
+}
+
+// Logger возвращает mock, получающий записи логгера приложения
+func (s *TestServer) Logger() *MockLogger {
+	return mockLogger
+}
+
+// Registry возвращает реестр, в котором зарегистрированы метрики приложения
+func (s *TestServer) Registry() prometheus.Gatherer {
+	return prometheus.DefaultGatherer
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/crazy1997/go-api/config"
+	"github.com/crazy1997/go-api/logging"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// newCertManager выпускает и продлевает сертификаты Let's Encrypt для
+// ACME_DOMAINS, сертификаты хранятся в ACME_CACHE_DIR. Без ACME_DOMAINS - nil
+func newCertManager(cfg *config.Config) *autocert.Manager {
+	if !cfg.ACMEEnabled() {
+		return nil
+	}
+
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.ACMEDomains...),
+		Cache:      autocert.DirCache(cfg.ACMECacheDir),
+		Email:      cfg.ACMEEmail,
+	}
+}
+
+// logTLSMode пишет режим TLS при старте
+func logTLSMode(logger *logging.ELKLogger, cfg *config.Config) {
+	switch {
+	case cfg.ACMEEnabled():
+		logger.Info("TLS enabled", map[string]interface{}{
+			"tls_mode":       "acme",
+			"acme_domains":   strings.Join(cfg.ACMEDomains, " "),
+			"acme_cache_dir": cfg.ACMECacheDir,
+			"redirect_port":  cfg.HTTPRedirectPort,
+		})
+	case cfg.TLSEnabled():
+		logger.Info("TLS enabled", map[string]interface{}{
+			"tls_mode":      "files",
+			"cert_file":     cfg.TLSCertFile,
+			"redirect_port": cfg.HTTPRedirectPort,
+		})
+	default:
+		logger.Info("TLS disabled, serving plain HTTP", nil)
+	}
+}
+
+// listenAndServe запускает сервер по HTTPS, если TLS включен, иначе по HTTP.
+// В режиме ACME сертификат берется из server.TLSConfig, см. newCertManager
+func listenAndServe(server *http.Server, cfg *config.Config) error {
+	if cfg.TLSEnabled() {
+		return server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+	}
+	return server.ListenAndServe()
+}
+
+// newRedirectServer перенаправляет HTTP запросы на HTTPS порт сервера.
+// С certManager он же отвечает на проверки ACME HTTP-01
+func newRedirectServer(cfg *config.Config, certManager *autocert.Manager) *http.Server {
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if cfg.Port != "443" {
+			host = net.JoinHostPort(host, cfg.Port)
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+	if certManager != nil {
+		handler = certManager.HTTPHandler(handler)
+	}
+
+	return &http.Server{
+		Addr:              "0.0.0.0:" + cfg.HTTPRedirectPort,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		Handler:           handler,
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/crazy1997/go-api/config"
+)
+
+// writeSelfSignedCert создает самоподписанный сертификат для 127.0.0.1
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "go-api test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+// freePort возвращает свободный локальный порт
+func freePort(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	return port
+}
+
+func TestListenAndServe_TLSFiles(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t)
+	cfg := &config.Config{Port: freePort(t), TLSCertFile: certFile, TLSKeyFile: keyFile}
+
+	server := newServer(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secure"))
+	}))
+	server.Addr = "127.0.0.1:" + cfg.Port
+
+	errs := make(chan error, 1)
+	go func() { errs <- listenAndServe(server, cfg) }()
+	t.Cleanup(func() { server.Close() })
+
+	client := &http.Client{
+		Timeout:   time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+
+	var resp *http.Response
+	var err error
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if resp, err = client.Get("https://" + server.Addr + "/"); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		select {
+		case serveErr := <-errs:
+			t.Fatalf("server failed: %v", serveErr)
+		default:
+			t.Fatalf("HTTPS request failed: %v", err)
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.TLS == nil {
+		t.Errorf("status = %d, TLS = %v, want 200 over TLS", resp.StatusCode, resp.TLS != nil)
+	}
+}
+
+func TestRedirectServer(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     string
+		acme     bool
+		path     string
+		status   int
+		location string
+	}{
+		{"default https port", "443", false, "/api/users?limit=5", http.StatusMovedPermanently, "https://example.com/api/users?limit=5"},
+		{"custom https port", "8443", false, "/api/health", http.StatusMovedPermanently, "https://example.com:8443/api/health"},
+		{"acme redirects regular requests", "443", true, "/api/health", http.StatusMovedPermanently, "https://example.com/api/health"},
+		// Неизвестный токен проверки ACME отвечает 404, а не редиректом
+		{"acme challenge is not redirected", "443", true, "/.well-known/acme-challenge/token", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Port: tt.port, HTTPRedirectPort: "80", ACMECacheDir: t.TempDir()}
+			if tt.acme {
+				cfg.ACMEDomains = []string{"example.com"}
+			}
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "http://example.com"+tt.path, nil)
+			newRedirectServer(cfg, newCertManager(cfg)).Handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Location"); got != tt.location {
+				t.Errorf("Location = %q, want %q", got, tt.location)
+			}
+		})
+	}
+}
+
+func TestNewCertManager(t *testing.T) {
+	if m := newCertManager(&config.Config{}); m != nil {
+		t.Error("cert manager created without ACME_DOMAINS")
+	}
+
+	cfg := &config.Config{ACMEDomains: []string{"example.com", "www.example.com"}, ACMECacheDir: t.TempDir()}
+	m := newCertManager(cfg)
+	if m == nil {
+		t.Fatal("no cert manager for ACME_DOMAINS")
+	}
+	if err := m.HostPolicy(t.Context(), "www.example.com"); err != nil {
+		t.Errorf("configured domain rejected: %v", err)
+	}
+	if err := m.HostPolicy(t.Context(), "evil.example.net"); err == nil {
+		t.Error("unknown domain accepted by host policy")
+	}
+	if !cfg.TLSEnabled() {
+		t.Error("TLSEnabled = false with ACME_DOMAINS")
+	}
+}
@@ -0,0 +1,107 @@
+// Package tracing - распределенная трассировка запросов на OpenTelemetry.
+// Спаны отправляются в OpenTelemetry Collector по OTLP/HTTP, контекст
+// передается заголовком W3C traceparent, поэтому трассы продолжают
+// трассу вызывающей стороны
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Имя инструментирующей библиотеки в OTLP scope
+const instrumentationScope = "github.com/crazy1997/go-api/tracing"
+
+const defaultFlushTimeout = 5 * time.Second
+
+var (
+	providerMu sync.Mutex
+	provider   *sdktrace.TracerProvider
+)
+
+// Tracer возвращает трейсер сервиса. Без Init спаны не записываются
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationScope)
+}
+
+// SetProvider делает tp глобальным провайдером и включает передачу
+// контекста через traceparent. Тесты передают провайдер с
+// tracetest.InMemoryExporter, nil отключает трассировку
+func SetProvider(tp *sdktrace.TracerProvider) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+
+	provider = tp
+	if tp == nil {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+		return
+	}
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+// Init включает трассировку с экспортом в collectorEndpoint
+// (например http://otel-collector:4318). Пустой endpoint оставляет
+// трассировку выключенной. Возвращает функцию остановки для defer
+func Init(serviceName, collectorEndpoint string) (func(), error) {
+	if collectorEndpoint == "" {
+		return func() {}, nil
+	}
+
+	u, err := url.Parse(collectorEndpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("tracing: invalid collector endpoint %q", collectorEndpoint)
+	}
+	u.Path = "/v1/traces"
+
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(u.String()))
+	if err != nil {
+		return nil, fmt.Errorf("tracing: create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing: build resource: %w", err)
+	}
+
+	SetProvider(sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	))
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultFlushTimeout)
+		defer cancel()
+		Shutdown(ctx)
+	}, nil
+}
+
+// Shutdown отправляет оставшиеся спаны и отключает трассировку.
+// Используется на этапе graceful shutdown
+func Shutdown(ctx context.Context) error {
+	providerMu.Lock()
+	tp := provider
+	providerMu.Unlock()
+
+	if tp == nil {
+		return nil
+	}
+	SetProvider(nil)
+
+	return tp.Shutdown(ctx)
+}
@@ -0,0 +1,70 @@
+// Package validators проверяет входные данные запросов API
+package validators
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxOrderItems - максимальное число позиций в заказе
+const MaxOrderItems = 50
+
+// OrderItem - позиция заказа для проверки
+type OrderItem struct {
+	ProductID int
+	Quantity  int
+}
+
+// FieldError - нарушение правила для поля, Field - путь вида items[0].quantity
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// Errors - все нарушения, найденные при проверке
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	return strings.Join(e.Messages(), "; ")
+}
+
+// Messages возвращает нарушения в виде "items[0].quantity must be >= 1"
+func (e Errors) Messages() []string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Error()
+	}
+	return messages
+}
+
+// ValidateOrder проверяет заказ и возвращает все нарушения сразу,
+// nil - заказ корректен
+func ValidateOrder(userID int, items []OrderItem) Errors {
+	var errs Errors
+
+	if userID <= 0 {
+		errs = append(errs, FieldError{Field: "user_id", Message: "must be positive"})
+	}
+
+	switch {
+	case len(items) == 0:
+		errs = append(errs, FieldError{Field: "items", Message: "must not be empty"})
+	case len(items) > MaxOrderItems:
+		errs = append(errs, FieldError{Field: "items", Message: fmt.Sprintf("must contain at most %d items", MaxOrderItems)})
+	}
+
+	for i, item := range items {
+		if item.ProductID <= 0 {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("items[%d].product_id", i), Message: "must be positive"})
+		}
+		if item.Quantity < 1 {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("items[%d].quantity", i), Message: "must be >= 1"})
+		}
+	}
+
+	return errs
+}
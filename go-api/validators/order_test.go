@@ -0,0 +1,82 @@
+package validators
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateOrder(t *testing.T) {
+	valid := []OrderItem{{ProductID: 1, Quantity: 2}}
+	maxItems := make([]OrderItem, MaxOrderItems)
+	for i := range maxItems {
+		maxItems[i] = OrderItem{ProductID: i + 1, Quantity: 1}
+	}
+
+	tests := []struct {
+		name   string
+		userID int
+		items  []OrderItem
+		want   []string
+	}{
+		{name: "valid", userID: 1, items: valid},
+		{name: "at the item limit", userID: 1, items: maxItems},
+		{name: "zero user", userID: 0, items: valid, want: []string{"user_id must be positive"}},
+		{name: "negative user", userID: -5, items: valid, want: []string{"user_id must be positive"}},
+		{name: "no items", userID: 1, want: []string{"items must not be empty"}},
+		{name: "empty items", userID: 1, items: []OrderItem{}, want: []string{"items must not be empty"}},
+		{
+			name:   "too many items",
+			userID: 1,
+			items:  append(maxItems, OrderItem{ProductID: 99, Quantity: 1}),
+			want:   []string{"items must contain at most 50 items"},
+		},
+		{name: "zero product", userID: 1, items: []OrderItem{{ProductID: 0, Quantity: 1}}, want: []string{"items[0].product_id must be positive"}},
+		{name: "negative product", userID: 1, items: []OrderItem{{ProductID: -1, Quantity: 1}}, want: []string{"items[0].product_id must be positive"}},
+		{name: "zero quantity", userID: 1, items: []OrderItem{{ProductID: 1, Quantity: 0}}, want: []string{"items[0].quantity must be >= 1"}},
+		{name: "negative quantity", userID: 1, items: []OrderItem{{ProductID: 1, Quantity: -3}}, want: []string{"items[0].quantity must be >= 1"}},
+		{
+			name:   "both item fields",
+			userID: 1,
+			items:  []OrderItem{{ProductID: -1, Quantity: 0}},
+			want:   []string{"items[0].product_id must be positive", "items[0].quantity must be >= 1"},
+		},
+		{
+			name:   "errors in several items",
+			userID: 1,
+			items:  []OrderItem{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 0}, {ProductID: 0, Quantity: 1}},
+			want:   []string{"items[1].quantity must be >= 1", "items[2].product_id must be positive"},
+		},
+		{
+			name:   "all rules",
+			userID: 0,
+			items:  []OrderItem{{ProductID: -1, Quantity: 0}},
+			want:   []string{"user_id must be positive", "items[0].product_id must be positive", "items[0].quantity must be >= 1"},
+		},
+		{name: "no user and no items", userID: -1, want: []string{"user_id must be positive", "items must not be empty"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateOrder(tt.userID, tt.items)
+			if tt.want == nil {
+				if errs != nil {
+					t.Fatalf("ValidateOrder = %v, want nil", errs)
+				}
+				return
+			}
+			if got := errs.Messages(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("messages = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrors_Error(t *testing.T) {
+	errs := Errors{
+		{Field: "user_id", Message: "must be positive"},
+		{Field: "items[0].quantity", Message: "must be >= 1"},
+	}
+	if got, want := errs.Error(), "user_id must be positive; items[0].quantity must be >= 1"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}